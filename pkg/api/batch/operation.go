@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package batch
 
 import (
+	"time"
+
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 )
 
@@ -79,4 +81,7 @@ const (
 type OperationInfo struct {
 	Data         []byte
 	UniqueSuffix string
+
+	// QueuedTime is the time at which the operation was added to the operation queue (set by the batch writer)
+	QueuedTime time.Time
 }
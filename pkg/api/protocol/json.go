@@ -0,0 +1,241 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+)
+
+const (
+	// DefaultHashAlgorithmInMultiHashCode is the default hash algorithm (SHA2-256)
+	DefaultHashAlgorithmInMultiHashCode = 18
+
+	// DefaultMaxOperationsPerBatch is the default maximum number of operations per batch
+	DefaultMaxOperationsPerBatch = 10000
+
+	// DefaultMaxDeltaByteSize is the default maximum size (in bytes) of the `delta` property of an operation
+	DefaultMaxDeltaByteSize = 1000
+)
+
+var supportedPatches = map[string]bool{
+	string(patch.AddPublicKeys):          true,
+	string(patch.RemovePublicKeys):       true,
+	string(patch.AddServiceEndpoints):    true,
+	string(patch.RemoveServiceEndpoints): true,
+	string(patch.JSONPatch):              true,
+}
+
+// Default returns the protocol parameters that are used for the properties that are not specified
+// when loading a protocol from JSON
+func Default() Protocol {
+	return Protocol{
+		HashAlgorithmInMultiHashCode: DefaultHashAlgorithmInMultiHashCode,
+		MaxOperationsPerBatch:        DefaultMaxOperationsPerBatch,
+		MaxDeltaByteSize:             DefaultMaxDeltaByteSize,
+	}
+}
+
+// LoadOption is an option for loading protocol parameters
+type LoadOption func(opts *loadOptions)
+
+type loadOptions struct {
+	envPrefix string
+	lookupEnv func(key string) (string, bool)
+}
+
+// WithEnvOverrides overrides the protocol parameters with the environment variables that have the given prefix.
+// The name of the variable for a parameter is the prefix followed by the JSON property name in upper snake case,
+// e.g. with prefix "SIDETREE_" the variable for "maxOperationsPerBatch" is SIDETREE_MAX_OPERATIONS_PER_BATCH.
+// List values are comma-separated.
+func WithEnvOverrides(prefix string) LoadOption {
+	return func(opts *loadOptions) {
+		opts.envPrefix = prefix
+		opts.lookupEnv = os.LookupEnv
+	}
+}
+
+// withEnvLookup sets the function used to look up environment variables (for testing)
+func withEnvLookup(prefix string, lookup func(key string) (string, bool)) LoadOption {
+	return func(opts *loadOptions) {
+		opts.envPrefix = prefix
+		opts.lookupEnv = lookup
+	}
+}
+
+// FromFile loads the protocol parameters from the given JSON file (see FromJSON)
+func FromFile(path string, opts ...LoadOption) (Protocol, error) {
+	data, err := ioutil.ReadFile(path) //nolint:gosec // path is provided by the operator
+	if err != nil {
+		return Protocol{}, fmt.Errorf("failed to read protocol file: %s", err)
+	}
+
+	p, err := FromJSON(data, opts...)
+	if err != nil {
+		return Protocol{}, fmt.Errorf("failed to load protocol from [%s]: %s", path, err)
+	}
+
+	return p, nil
+}
+
+// FromJSON loads the protocol parameters from the given JSON. Properties that are not specified are set
+// to their defaults (see Default) and environment variable overrides (see WithEnvOverrides) are applied
+// before the protocol is validated.
+func FromJSON(data []byte, opts ...LoadOption) (Protocol, error) {
+	options := &loadOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	p := Default()
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&p); err != nil {
+		return Protocol{}, fmt.Errorf("failed to unmarshal protocol: %s", err)
+	}
+
+	if options.lookupEnv != nil {
+		if err := applyEnvOverrides(&p, options.envPrefix, options.lookupEnv); err != nil {
+			return Protocol{}, err
+		}
+	}
+
+	if err := p.Validate(); err != nil {
+		return Protocol{}, err
+	}
+
+	return p, nil
+}
+
+// Validate validates the protocol parameters
+func (p Protocol) Validate() error {
+	if _, err := docutil.GetHash(p.HashAlgorithmInMultiHashCode); err != nil {
+		return fmt.Errorf("invalid hashAlgorithmInMultihashCode: %s", err)
+	}
+
+	if p.MaxOperationsPerBatch == 0 {
+		return fmt.Errorf("maxOperationsPerBatch must be greater than zero")
+	}
+
+	if p.MaxDeltaByteSize == 0 {
+		return fmt.Errorf("maxDeltaByteSize must be greater than zero")
+	}
+
+	if p.MinRevealValueLength > 0 && p.MaxRevealValueLength > 0 && p.MinRevealValueLength > p.MaxRevealValueLength {
+		return fmt.Errorf("minRevealValueLength [%d] is greater than maxRevealValueLength [%d]",
+			p.MinRevealValueLength, p.MaxRevealValueLength)
+	}
+
+	for _, action := range p.Patches {
+		if !supportedPatches[action] {
+			return fmt.Errorf("patch action not supported: %s", action)
+		}
+	}
+
+	return nil
+}
+
+func applyEnvOverrides(p *Protocol, prefix string, lookupEnv func(key string) (string, bool)) error {
+	v := reflect.ValueOf(p).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := prefix + toUpperSnakeCase(name)
+
+		value, ok := lookupEnv(key)
+		if !ok {
+			continue
+		}
+
+		if err := setField(v.Field(i), strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("invalid value for environment variable %s: %s", key, err)
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() { //nolint:exhaustive // only the kinds used by Protocol are supported
+	case reflect.Uint:
+		u, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return err
+		}
+
+		field.SetUint(u)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(b)
+	case reflect.Slice:
+		items := splitList(value)
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+
+		for i, item := range items {
+			if err := setField(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+
+		field.Set(slice)
+	case reflect.String:
+		field.SetString(value)
+	default:
+		return fmt.Errorf("unsupported type: %s", field.Type())
+	}
+
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// toUpperSnakeCase converts a camel case name (e.g. "maxOperationsPerDid") to upper snake case
+// (e.g. "MAX_OPERATIONS_PER_DID")
+func toUpperSnakeCase(name string) string {
+	var b strings.Builder
+
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteRune('_')
+		}
+
+		b.WriteRune(unicode.ToUpper(r))
+	}
+
+	return b.String()
+}
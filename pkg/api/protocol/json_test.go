@@ -0,0 +1,181 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSON(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		p, err := FromJSON([]byte(`{}`))
+		require.NoError(t, err)
+		require.Equal(t, Default(), p)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		p, err := FromJSON([]byte(`{
+			"startingBlockchainTime": 100,
+			"maxOperationsPerBatch": 50,
+			"patches": ["add-public-keys", "ietf-json-patch"],
+			"didTypes": [1, 2],
+			"legacyRevealValues": true
+		}`))
+		require.NoError(t, err)
+		require.Equal(t, uint(100), p.StartingBlockChainTime)
+		require.Equal(t, uint(50), p.MaxOperationsPerBatch)
+		require.Equal(t, uint(DefaultMaxDeltaByteSize), p.MaxDeltaByteSize)
+		require.Equal(t, uint(DefaultHashAlgorithmInMultiHashCode), p.HashAlgorithmInMultiHashCode)
+		require.Equal(t, []string{"add-public-keys", "ietf-json-patch"}, p.Patches)
+		require.Equal(t, []uint{1, 2}, p.DIDTypes)
+		require.True(t, p.LegacyRevealValues)
+	})
+
+	t.Run("error - invalid JSON", func(t *testing.T) {
+		p, err := FromJSON([]byte(`{"maxOperationsPerBatch": "ten"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal protocol")
+		require.Empty(t, p)
+	})
+
+	t.Run("error - unknown property", func(t *testing.T) {
+		p, err := FromJSON([]byte(`{"maxOperationsPerBach": 10}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown field")
+		require.Empty(t, p)
+	})
+
+	t.Run("error - validation", func(t *testing.T) {
+		p, err := FromJSON([]byte(`{"maxDeltaByteSize": 0}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "maxDeltaByteSize must be greater than zero")
+		require.Empty(t, p)
+	})
+}
+
+func TestFromJSON_EnvOverrides(t *testing.T) {
+	env := map[string]string{
+		"SIDETREE_MAX_OPERATIONS_PER_BATCH":           "20",
+		"SIDETREE_PATCHES":                            "add-public-keys, remove-public-keys",
+		"SIDETREE_DID_TYPES":                          "3,4",
+		"SIDETREE_LEGACY_REVEAL_VALUES":               "true",
+		"SIDETREE_JSON_PATCH_PROTECTED_PROPERTIES":    "",
+		"OTHER_MAX_DELTA_BYTE_SIZE":                   "1",
+		"SIDETREE_HASH_ALGORITHM_IN_MULTIHASH_CODE_X": "1",
+	}
+
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	t.Run("success", func(t *testing.T) {
+		p, err := FromJSON([]byte(`{"maxOperationsPerBatch": 10, "maxDeltaByteSize": 500}`),
+			withEnvLookup("SIDETREE_", lookup))
+		require.NoError(t, err)
+		require.Equal(t, uint(20), p.MaxOperationsPerBatch)
+		require.Equal(t, uint(500), p.MaxDeltaByteSize)
+		require.Equal(t, []string{"add-public-keys", "remove-public-keys"}, p.Patches)
+		require.Equal(t, []uint{3, 4}, p.DIDTypes)
+		require.True(t, p.LegacyRevealValues)
+		require.Empty(t, p.JSONPatchProtectedProperties)
+	})
+
+	t.Run("error - invalid value", func(t *testing.T) {
+		p, err := FromJSON([]byte(`{}`), withEnvLookup("SIDETREE_", func(key string) (string, bool) {
+			if key == "SIDETREE_MAX_DELTA_BYTE_SIZE" {
+				return "-1", true
+			}
+
+			return "", false
+		}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for environment variable SIDETREE_MAX_DELTA_BYTE_SIZE")
+		require.Empty(t, p)
+	})
+
+	t.Run("error - override fails validation", func(t *testing.T) {
+		p, err := FromJSON([]byte(`{}`), withEnvLookup("SIDETREE_", func(key string) (string, bool) {
+			if key == "SIDETREE_PATCHES" {
+				return "replace", true
+			}
+
+			return "", false
+		}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "patch action not supported: replace")
+		require.Empty(t, p)
+	})
+
+	t.Run("os environment", func(t *testing.T) {
+		require.NoError(t, os.Setenv("TEST_PROTOCOL_MAX_DELTA_BYTE_SIZE", "4000"))
+		defer func() { require.NoError(t, os.Unsetenv("TEST_PROTOCOL_MAX_DELTA_BYTE_SIZE")) }()
+
+		p, err := FromJSON([]byte(`{}`), WithEnvOverrides("TEST_PROTOCOL_"))
+		require.NoError(t, err)
+		require.Equal(t, uint(4000), p.MaxDeltaByteSize)
+	})
+}
+
+func TestFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protocol")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	t.Run("success", func(t *testing.T) {
+		path := filepath.Join(dir, "protocol.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{"maxOperationsPerBatch": 5}`), 0600))
+
+		p, err := FromFile(path)
+		require.NoError(t, err)
+		require.Equal(t, uint(5), p.MaxOperationsPerBatch)
+	})
+
+	t.Run("error - file not found", func(t *testing.T) {
+		p, err := FromFile(filepath.Join(dir, "missing.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read protocol file")
+		require.Empty(t, p)
+	})
+
+	t.Run("error - invalid protocol", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{"hashAlgorithmInMultihashCode": 99}`), 0600))
+
+		p, err := FromFile(path)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to load protocol from")
+		require.Contains(t, err.Error(), "invalid hashAlgorithmInMultihashCode")
+		require.Empty(t, p)
+	})
+}
+
+func TestProtocol_Validate(t *testing.T) {
+	require.NoError(t, Default().Validate())
+
+	p := Default()
+	p.MaxOperationsPerBatch = 0
+	require.EqualError(t, p.Validate(), "maxOperationsPerBatch must be greater than zero")
+
+	p = Default()
+	p.MinRevealValueLength = 10
+	p.MaxRevealValueLength = 5
+	require.EqualError(t, p.Validate(), "minRevealValueLength [10] is greater than maxRevealValueLength [5]")
+
+	p.MaxRevealValueLength = 0
+	require.NoError(t, p.Validate())
+}
+
+func TestToUpperSnakeCase(t *testing.T) {
+	require.Equal(t, "MAX_OPERATIONS_PER_DID", toUpperSnakeCase("maxOperationsPerDid"))
+	require.Equal(t, "PATCHES", toUpperSnakeCase("patches"))
+}
@@ -6,21 +6,84 @@ SPDX-License-Identifier: Apache-2.0
 
 package protocol
 
+import (
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
 // Protocol defines protocol parameters
 type Protocol struct {
 	// StartingBlockChainTime is inclusive starting logical blockchain time that this protocol applies to.
-	StartingBlockChainTime uint
+	StartingBlockChainTime uint `json:"startingBlockchainTime"`
 	// HashAlgorithmInMultiHashCode is hash algorithm in multihash code
-	HashAlgorithmInMultiHashCode uint
+	HashAlgorithmInMultiHashCode uint `json:"hashAlgorithmInMultihashCode"`
 	// MaxOperationsPerBatch defines maximum operations per batch
-	MaxOperationsPerBatch uint
+	MaxOperationsPerBatch uint `json:"maxOperationsPerBatch"`
 	// MaxDeltaByteSize is maximum size of the `delta` property in bytes
-	MaxDeltaByteSize uint
+	MaxDeltaByteSize uint `json:"maxDeltaByteSize"`
+	// MaxBatchFileSize is the maximum size (in bytes) of a batch file (the chunk file of the Sidetree specification).
+	// Not checked if zero.
+	MaxBatchFileSize uint `json:"maxBatchFileSize,omitempty"`
+	// Patches contains the patch actions that are allowed (e.g. "add-public-keys", "ietf-json-patch").
+	// All patch actions are allowed if empty.
+	Patches []string `json:"patches,omitempty"`
+	// DIDTypes contains the DID types that may be set in the suffix data of a create operation.
+	// All DID types are allowed if empty.
+	DIDTypes []uint `json:"didTypes,omitempty"`
+	// JSONPatchProtectedProperties contains additional top-level document properties (e.g. "authentication") that
+	// may not be modified by "ietf-json-patch" patches. The id, controller, publicKey and service properties are
+	// always protected.
+	JSONPatchProtectedProperties []string `json:"jsonPatchProtectedProperties,omitempty"`
+	// MinRevealValueLength is the minimum length (in bytes) of a next reveal value. Not checked if zero.
+	// The bounds apply when next reveal values are generated (and to the reveal values sent by legacy clients)
+	// but not to the reveal value of an operation, since its commitment may have been created under the bounds
+	// of an earlier protocol version.
+	MinRevealValueLength uint `json:"minRevealValueLength,omitempty"`
+	// MaxRevealValueLength is the maximum length (in bytes) of a next reveal value. Not checked if zero.
+	MaxRevealValueLength uint `json:"maxRevealValueLength,omitempty"`
+	// MaxOperationsPerDID is the maximum number of operations that are accepted for a DID within the rate limit window.
+	// Operations are not rate limited if zero.
+	MaxOperationsPerDID uint `json:"maxOperationsPerDid,omitempty"`
+	// RateLimitWindow is the length (in seconds) of the window that MaxOperationsPerDID applies to (one minute if zero)
+	RateLimitWindow uint `json:"rateLimitWindow,omitempty"`
+	// LegacyRevealValues enables the deprecated scheme in which clients send the next update/recovery reveal values
+	// (instead of their commitments). The reveal values are translated to commitments when the operation is parsed
+	// and a deprecation warning is logged (and returned to the client in the Warning header). This is intended for a transition window only and should be disabled by a
+	// later protocol version. (Note that anchored operations are parsed again by the observer using the protocol
+	// version at the transaction time, so the version that disables legacy reveal values must not take effect
+	// before all of the legacy operations accepted by the previous version have been anchored.)
+	LegacyRevealValues bool `json:"legacyRevealValues,omitempty"`
+	// DeactivateConfirmation requires deactivate operations that are submitted to the REST API to be confirmed
+	// by including the DID suffix in the Sidetree-Deactivate-Confirmation request header. Since deactivation is
+	// irreversible, this protects against deactivate requests that were submitted by mistake.
+	DeactivateConfirmation bool `json:"deactivateConfirmation,omitempty"`
+	// CharacterPolicy defines the characters that are permitted in the service types and endpoints of documents.
+	// Only printable ASCII characters are permitted by default. The policy is applied when operations are
+	// submitted, when anchored operations are parsed by the observer and when documents are resolved.
+	CharacterPolicy document.CharacterPolicy `json:"characterPolicy"`
+}
+
+// OperationParser defines the functions for parsing operations
+type OperationParser interface {
+	// Parse parses and validates the operation request and returns the operation for the given namespace
+	Parse(namespace string, operation []byte) (*batch.Operation, error)
+}
+
+// Version contains the protocol parameters and the implementations that are compatible with the protocol version
+type Version interface {
+	// Protocol returns the protocol parameters for this version
+	Protocol() Protocol
+
+	// OperationParser returns the operation parser for this version
+	OperationParser() OperationParser
 }
 
 // Client defines interface for accessing protocol version/information
 type Client interface {
 
 	// Current returns latest version of protocol
-	Current() Protocol
+	Current() (Version, error)
+
+	// Get returns the version of protocol that applies to the given transaction time
+	Get(transactionTime uint64) (Version, error)
 }
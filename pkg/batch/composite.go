@@ -0,0 +1,150 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+const defaultCompositeWindow = 500 * time.Millisecond
+
+// CompositeAnchorWriter combines the anchors written by the batch writers of multiple namespaces into a
+// single ledger transaction (see observer.EncodeCompositeAnchor). Each batch writer is given the blockchain
+// client returned by ForNamespace. A call to WriteAnchor blocks until the composite anchor was written
+// to the ledger, which happens as soon as all registered namespaces have provided an anchor or when the
+// window (which starts with the first anchor) expires, whichever comes first.
+type CompositeAnchorWriter struct {
+	ledger BlockchainClient
+	window time.Duration
+	clock  Clock
+
+	mutex      sync.Mutex
+	namespaces map[string]struct{}
+	current    *compositeBatch
+}
+
+type compositeBatch struct {
+	anchors []observer.NamespaceAnchor
+	done    chan struct{}
+	err     error
+}
+
+// CompositeOption is an option for the composite anchor writer
+type CompositeOption func(w *CompositeAnchorWriter)
+
+// WithCompositeWindow sets the maximum amount of time to wait for the anchors of other namespaces
+// before the composite anchor is written to the ledger
+func WithCompositeWindow(window time.Duration) CompositeOption {
+	return func(w *CompositeAnchorWriter) {
+		w.window = window
+	}
+}
+
+// WithCompositeClock sets the clock used for the window timer (a deterministic clock may be provided for testing)
+func WithCompositeClock(clock Clock) CompositeOption {
+	return func(w *CompositeAnchorWriter) {
+		w.clock = clock
+	}
+}
+
+// NewCompositeAnchorWriter returns a new composite anchor writer that writes to the given ledger
+func NewCompositeAnchorWriter(ledger BlockchainClient, opts ...CompositeOption) *CompositeAnchorWriter {
+	w := &CompositeAnchorWriter{
+		ledger:     ledger,
+		window:     defaultCompositeWindow,
+		clock:      systemClock{},
+		namespaces: make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// ForNamespace registers the given namespace and returns the blockchain client to be used
+// by the batch writer of the namespace
+func (w *CompositeAnchorWriter) ForNamespace(namespace string) BlockchainClient {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.namespaces[namespace] = struct{}{}
+
+	return &namespaceBlockchainClient{namespace: namespace, writer: w}
+}
+
+func (w *CompositeAnchorWriter) write(namespace, anchor string) error {
+	w.mutex.Lock()
+
+	b := w.current
+	if b == nil {
+		b = &compositeBatch{done: make(chan struct{})}
+		w.current = b
+
+		go func(timer <-chan time.Time) {
+			<-timer
+			w.flush(b)
+		}(w.clock.After(w.window))
+	}
+
+	b.anchors = append(b.anchors, observer.NamespaceAnchor{Namespace: namespace, Anchor: anchor})
+	full := len(b.anchors) >= len(w.namespaces)
+
+	w.mutex.Unlock()
+
+	if full {
+		w.flush(b)
+	}
+
+	<-b.done
+
+	return b.err
+}
+
+// flush writes the composite anchor for the given batch to the ledger unless it was already flushed
+func (w *CompositeAnchorWriter) flush(b *compositeBatch) {
+	w.mutex.Lock()
+
+	if w.current != b {
+		w.mutex.Unlock()
+		return
+	}
+
+	w.current = nil
+
+	w.mutex.Unlock()
+
+	anchor, err := observer.EncodeCompositeAnchor(b.anchors)
+	if err != nil {
+		b.err = errors.Wrap(err, "failed to encode composite anchor")
+	} else {
+		b.err = w.ledger.WriteAnchor(anchor)
+	}
+
+	close(b.done)
+}
+
+type namespaceBlockchainClient struct {
+	namespace string
+	writer    *CompositeAnchorWriter
+}
+
+// WriteAnchor adds the anchor to the composite anchor and blocks until the composite anchor is written
+func (c *namespaceBlockchainClient) WriteAnchor(anchor string) error {
+	return c.writer.write(c.namespace, anchor)
+}
+
+// Read reads the ledger transaction from the underlying ledger
+func (c *namespaceBlockchainClient) Read(sinceTransactionNumber int) (bool, *observer.SidetreeTxn) {
+	return c.writer.ledger.Read(sinceTransactionNumber)
+}
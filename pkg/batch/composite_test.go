@@ -0,0 +1,110 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+	"github.com/trustbloc/sidetree-core-go/pkg/simulator"
+)
+
+func TestCompositeAnchorWriter(t *testing.T) {
+	t.Run("all namespaces written", func(t *testing.T) {
+		ledger := mocks.NewMockBlockchainClient(nil)
+		clock := simulator.NewClock(time.Now())
+
+		w := NewCompositeAnchorWriter(ledger, WithCompositeClock(clock))
+		bc1 := w.ForNamespace("did:ns1")
+		bc2 := w.ForNamespace("did:ns2")
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			require.NoError(t, bc1.WriteAnchor("anchor1"))
+		}()
+
+		go func() {
+			defer wg.Done()
+			require.NoError(t, bc2.WriteAnchor("anchor2"))
+		}()
+
+		wg.Wait()
+
+		anchors := ledger.GetAnchors()
+		require.Len(t, anchors, 1)
+
+		decoded, err := observer.DecodeAnchor(anchors[0])
+		require.NoError(t, err)
+		require.ElementsMatch(t, []observer.NamespaceAnchor{
+			{Namespace: "did:ns1", Anchor: "anchor1"},
+			{Namespace: "did:ns2", Anchor: "anchor2"},
+		}, decoded)
+	})
+
+	t.Run("window expired", func(t *testing.T) {
+		ledger := mocks.NewMockBlockchainClient(nil)
+		clock := simulator.NewClock(time.Now())
+
+		w := NewCompositeAnchorWriter(ledger, WithCompositeClock(clock), WithCompositeWindow(time.Second))
+		bc1 := w.ForNamespace("did:ns1")
+		w.ForNamespace("did:ns2")
+
+		errCh := make(chan error, 1)
+
+		go func() {
+			errCh <- bc1.WriteAnchor("anchor1")
+		}()
+
+		require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, 5*time.Millisecond)
+
+		select {
+		case <-errCh:
+			t.Fatal("expecting write to block until the window expires")
+		default:
+		}
+
+		clock.Advance(time.Second)
+
+		require.NoError(t, <-errCh)
+
+		// Only one anchor was written so the plain anchor is written to the ledger
+		require.Equal(t, []string{"anchor1"}, ledger.GetAnchors())
+	})
+
+	t.Run("ledger error", func(t *testing.T) {
+		errExpected := errors.New("injected ledger error")
+
+		w := NewCompositeAnchorWriter(mocks.NewMockBlockchainClient(errExpected))
+		bc := w.ForNamespace("did:ns1")
+
+		err := bc.WriteAnchor("anchor1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("read", func(t *testing.T) {
+		ledger := mocks.NewMockBlockchainClient(nil)
+		require.NoError(t, ledger.WriteAnchor("anchor0"))
+		require.NoError(t, ledger.WriteAnchor("anchor1"))
+
+		bc := NewCompositeAnchorWriter(ledger).ForNamespace("did:ns1")
+
+		more, txn := bc.Read(0)
+		require.False(t, more)
+		require.NotNil(t, txn)
+		require.Equal(t, "anchor1", txn.AnchorAddress)
+	})
+}
@@ -7,14 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package cutter
 
 import (
-	"github.com/sirupsen/logrus"
+	"fmt"
+	"sync"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
 )
 
-var logger = logrus.New()
-
 // OperationQueue defines the functions for adding and removing operations from a queue
 type OperationQueue interface {
 	// Add adds the given operation to the tail of the queue and returns the new length of the queue
@@ -32,59 +32,300 @@ type OperationQueue interface {
 // in the queue is returned.
 type Committer = func() (pending uint, err error)
 
+// OperationSizer returns the size (in bytes) of a batch file that contains only the given operation along with
+// the number of bytes that the operation adds to a batch file that already contains other operations
+type OperationSizer func(op *batch.OperationInfo) (size int, increment int, err error)
+
+// RejectHandler is invoked when an operation is removed from the queue since it can never be batched
+type RejectHandler func(op *batch.OperationInfo, err error)
+
+// Option is a batch cutter option
+type Option func(c *BatchCutter)
+
+// WithOperationSizer sets the function that is used to determine the size of the batch file. If set (and the
+// protocol defines MaxBatchFileSize) then the batch is cut so that the batch file doesn't exceed the maximum size.
+func WithOperationSizer(sizer OperationSizer) Option {
+	return func(c *BatchCutter) {
+		c.sizer = sizer
+	}
+}
+
+// WithRejectHandler sets the handler that is notified when an operation is rejected since the batch file
+// of the operation alone exceeds the max batch file size
+func WithRejectHandler(handler RejectHandler) Option {
+	return func(c *BatchCutter) {
+		c.rejectHandler = handler
+	}
+}
+
+// WithLogger sets the logger of the batch cutter
+func WithLogger(logger log.Logger) Option {
+	return func(c *BatchCutter) {
+		c.logger = logger
+	}
+}
+
 // BatchCutter implements batch cutting
 type BatchCutter struct {
-	pendingBatch OperationQueue
-	client       protocol.Client
+	pendingBatch  OperationQueue
+	client        protocol.Client
+	sizer         OperationSizer
+	rejectHandler RejectHandler
+	logger        log.Logger
+
+	// sizes holds the sizes of the queued operations (in queue order) so that each operation is sized only once
+	sizes []operationSize
+	mutex sync.Mutex
+}
+
+type operationSize struct {
+	size      int
+	increment int
 }
 
 // New creates a Cutter implementation
-func New(client protocol.Client, queue OperationQueue) *BatchCutter {
-	return &BatchCutter{
+func New(client protocol.Client, queue OperationQueue, opts ...Option) *BatchCutter {
+	c := &BatchCutter{
 		client:       client,
 		pendingBatch: queue,
+		logger:       log.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
 }
 
 // Add adds the given operation to pending batch queue and returns the total
-// number of pending operations.
+// number of pending operations. If an operation sizer is set then the operation is sized once when it is added.
 func (r *BatchCutter) Add(operation *batch.OperationInfo) (uint, error) {
+	if r.sizer == nil {
+		// Enqueuing operation into batch
+		return r.pendingBatch.Add(operation)
+	}
+
+	size, increment, err := r.sizer(operation)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
 	// Enqueuing operation into batch
-	return r.pendingBatch.Add(operation)
+	pending, err := r.pendingBatch.Add(operation)
+	if err != nil {
+		return 0, err
+	}
+
+	r.sizes = append(r.sizes, operationSize{size: size, increment: increment})
+
+	return pending, nil
 }
 
 // Cut returns the current batch along with number of items that should be remaining in the queue after the committer is called.
 // If force is false then the batch will be cut only if it has reached the max batch size (as specified in the protocol)
+// or if the batch file of the pending operations would exceed the max batch file size.
 // If force is true then the batch will be cut if there is at least one Data in the batch
 // Note that the operations are removed from the queue when the committer is invoked, otherwise they remain in the queue.
+// An operation whose batch file alone exceeds the max batch file size is removed from the queue immediately
+// (and passed to the reject handler) since it would otherwise block the queue.
 func (r *BatchCutter) Cut(force bool) ([]*batch.OperationInfo, uint, Committer, error) {
-	pending := r.pendingBatch.Len()
+	return r.CutFrom(0, force)
+}
+
+// CutFrom cuts the batch that follows the given number of operations at the head of the queue, i.e. the operations
+// of batches that were cut but not committed yet, so that the next batch may be processed while the preceding
+// batches are still being processed. The batches must be committed in the order in which they were cut since the
+// committer removes the operations from the head of the queue. The returned number of pending operations excludes
+// the preceding batches. An oversized operation is only rejected once it is at the head of the queue
+// (no batch is cut until then).
+func (r *BatchCutter) CutFrom(offset uint, force bool) ([]*batch.OperationInfo, uint, Committer, error) {
+	pv, err := r.client.Current()
+	if err != nil {
+		return nil, r.pendingAfter(offset), nil, err
+	}
+
+	maxOperationsPerBatch := pv.Protocol().MaxOperationsPerBatch
+	maxBatchFileSize := pv.Protocol().MaxBatchFileSize
+
+	if r.sizer != nil {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
 
-	maxOperationsPerBatch := r.client.Current().MaxOperationsPerBatch
-	if !force && pending < maxOperationsPerBatch {
+		if err := r.syncSizes(); err != nil {
+			return nil, r.pendingAfter(offset), nil, err
+		}
+	}
+
+	checkSize := r.sizer != nil && maxBatchFileSize > 0
+
+	if checkSize && offset == 0 {
+		if err := r.rejectOversized(int(maxBatchFileSize)); err != nil {
+			return nil, r.pendingBatch.Len(), nil, err
+		}
+	}
+
+	pending := r.pendingAfter(offset)
+
+	if !force && !checkSize && pending < maxOperationsPerBatch {
 		return nil, pending, nil, nil
 	}
 
 	batchSize := min(pending, maxOperationsPerBatch)
-	ops, err := r.pendingBatch.Peek(batchSize)
+
+	ops, err := r.pendingBatch.Peek(offset + batchSize)
 	if err != nil {
 		return nil, pending, nil, err
 	}
 
+	ops = ops[min(offset, uint(len(ops))):]
+
+	if checkSize {
+		n := r.fit(int(offset), len(ops), int(maxBatchFileSize))
+
+		if !force && n == len(ops) && pending < maxOperationsPerBatch {
+			// the batch is neither full nor too large
+			return nil, pending, nil, nil
+		}
+
+		if n == 0 && len(ops) > 0 {
+			// the operation is oversized and will be rejected once the preceding batches were committed
+			return nil, pending, nil, nil
+		}
+
+		if n < len(ops) {
+			r.logger.Debugf("Cutting %d of %d operations so that the batch file doesn't exceed %d bytes", n, len(ops), maxBatchFileSize)
+
+			ops = ops[:n]
+			batchSize = uint(n)
+		}
+	}
+
 	pending -= batchSize
 
-	logger.Debugf("Pending Size: %d, MaxOperationsPerBatch: %d, Batch Size: %d", pending, maxOperationsPerBatch, batchSize)
+	r.logger.Debugf("Pending Size: %d, MaxOperationsPerBatch: %d, Batch Size: %d", pending, maxOperationsPerBatch, batchSize)
 
 	committer := func() (uint, error) {
-		logger.Debugf("Removing %d operations from the queue", batchSize)
+		r.logger.Debugf("Removing %d operations from the queue", batchSize)
 
-		_, p, err := r.pendingBatch.Remove(batchSize)
-		return p, err
+		return r.remove(batchSize)
 	}
 
 	return ops, pending, committer, nil
 }
 
+// pendingAfter returns the number of operations in the queue that follow the given number of operations
+func (r *BatchCutter) pendingAfter(offset uint) uint {
+	pending := r.pendingBatch.Len()
+	if pending <= offset {
+		return 0
+	}
+
+	return pending - offset
+}
+
+// rejectOversized removes the operations from the head of the queue whose batch file alone exceeds the given size.
+// Such an operation can never be included in a batch.
+func (r *BatchCutter) rejectOversized(maxSize int) error {
+	for len(r.sizes) > 0 && r.sizes[0].size > maxSize {
+		ops, _, err := r.pendingBatch.Remove(1)
+		if err != nil {
+			return err
+		}
+
+		r.sizes = r.sizes[1:]
+
+		if len(ops) == 0 {
+			return nil
+		}
+
+		err = fmt.Errorf("operation for [%s] exceeds the max batch file size [%d]", ops[0].UniqueSuffix, maxSize)
+
+		r.logger.Warnf("Removed operation from the queue: %s", err)
+
+		if r.rejectHandler != nil {
+			r.rejectHandler(ops[0], err)
+		}
+	}
+
+	return nil
+}
+
+// fit returns the largest number of operations (starting at the given index of the queue, up to the given number)
+// whose batch file doesn't exceed the given size. The size of the batch file is accumulated as each operation is added.
+func (r *BatchCutter) fit(start, num, maxSize int) int {
+	total := 0
+
+	for i := 0; i < num; i++ {
+		if i == 0 {
+			total = r.sizes[start+i].size
+		} else {
+			total += r.sizes[start+i].increment
+		}
+
+		if total > maxSize {
+			return i
+		}
+	}
+
+	return num
+}
+
+// remove removes the given number of operations (along with their sizes) from the head of the queue
+func (r *BatchCutter) remove(num uint) (uint, error) {
+	if r.sizer == nil {
+		_, pending, err := r.pendingBatch.Remove(num)
+		return pending, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ops, pending, err := r.pendingBatch.Remove(num)
+	if err != nil {
+		return pending, err
+	}
+
+	r.sizes = r.sizes[min(uint(len(ops)), uint(len(r.sizes))):]
+
+	return pending, nil
+}
+
+// syncSizes sizes the operations that were in the queue before they were added by this cutter (e.g. operations
+// that were persisted by a previous instance). Such operations are always at the head of the queue.
+func (r *BatchCutter) syncSizes() error {
+	pending := int(r.pendingBatch.Len())
+
+	if pending <= len(r.sizes) {
+		r.sizes = r.sizes[len(r.sizes)-pending:]
+
+		return nil
+	}
+
+	ops, err := r.pendingBatch.Peek(uint(pending - len(r.sizes)))
+	if err != nil {
+		return err
+	}
+
+	sizes := make([]operationSize, len(ops), len(ops)+len(r.sizes))
+
+	for i, op := range ops {
+		size, increment, err := r.sizer(op)
+		if err != nil {
+			return err
+		}
+
+		sizes[i] = operationSize{size: size, increment: increment}
+	}
+
+	r.sizes = append(sizes, r.sizes...)
+
+	return nil
+}
+
 func min(i, j uint) uint {
 	if i < j {
 		return i
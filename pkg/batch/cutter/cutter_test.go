@@ -7,12 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package cutter
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/opqueue"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
 )
 
@@ -114,3 +118,295 @@ func TestBatchCutter(t *testing.T) {
 	require.NoError(t, err)
 	require.Zero(t, pending)
 }
+
+func TestBatchCutter_CutFrom(t *testing.T) {
+	c := mocks.NewMockProtocolClient()
+	c.Protocol.MaxOperationsPerBatch = 2
+
+	t.Run("consecutive batches", func(t *testing.T) {
+		r := New(c, &opqueue.MemQueue{})
+
+		for _, op := range []*batch.OperationInfo{operation1, operation2, operation3, operation4} {
+			_, err := r.Add(op)
+			require.NoError(t, err)
+		}
+
+		ops1, pending, commit1, err := r.CutFrom(0, false)
+		require.NoError(t, err)
+		require.Equal(t, []*batch.OperationInfo{operation1, operation2}, ops1)
+		require.Equal(t, uint(2), pending)
+
+		ops2, pending, commit2, err := r.CutFrom(uint(len(ops1)), false)
+		require.NoError(t, err)
+		require.Equal(t, []*batch.OperationInfo{operation3, operation4}, ops2)
+		require.Zero(t, pending)
+
+		ops, pending, _, err := r.CutFrom(uint(len(ops1)+len(ops2)), true)
+		require.NoError(t, err)
+		require.Empty(t, ops)
+		require.Zero(t, pending)
+
+		pending, err = commit1()
+		require.NoError(t, err)
+		require.Equal(t, uint(2), pending)
+
+		pending, err = commit2()
+		require.NoError(t, err)
+		require.Zero(t, pending)
+	})
+
+	t.Run("oversized operation is only rejected at the head of the queue", func(t *testing.T) {
+		sc := mocks.NewMockProtocolClient()
+		sc.Protocol.MaxOperationsPerBatch = 2
+		sc.Protocol.MaxBatchFileSize = 15
+
+		var rejected []*batch.OperationInfo
+
+		r := New(sc, &opqueue.MemQueue{},
+			WithOperationSizer(func(op *batch.OperationInfo) (int, int, error) {
+				if op == operation3 {
+					return 20, 20, nil
+				}
+				return 5, 5, nil
+			}),
+			WithRejectHandler(func(op *batch.OperationInfo, err error) {
+				rejected = append(rejected, op)
+			}),
+		)
+
+		for _, op := range []*batch.OperationInfo{operation1, operation2, operation3, operation4} {
+			_, err := r.Add(op)
+			require.NoError(t, err)
+		}
+
+		ops, _, commit, err := r.CutFrom(0, false)
+		require.NoError(t, err)
+		require.Equal(t, []*batch.OperationInfo{operation1, operation2}, ops)
+
+		ops, pending, next, err := r.CutFrom(2, true)
+		require.NoError(t, err)
+		require.Empty(t, ops)
+		require.Equal(t, uint(2), pending)
+		require.Nil(t, next)
+		require.Empty(t, rejected)
+
+		_, err = commit()
+		require.NoError(t, err)
+
+		ops, _, _, err = r.Cut(true)
+		require.NoError(t, err)
+		require.Equal(t, []*batch.OperationInfo{operation4}, ops)
+		require.Equal(t, []*batch.OperationInfo{operation3}, rejected)
+	})
+}
+
+func TestBatchCutter_MaxBatchFileSize(t *testing.T) {
+	// each operation adds 10 bytes to the batch file
+	sizer := func(*batch.OperationInfo) (int, int, error) {
+		return 10, 10, nil
+	}
+
+	c := mocks.NewMockProtocolClient()
+	c.Protocol.MaxOperationsPerBatch = 3
+	c.Protocol.MaxBatchFileSize = 25
+
+	t.Run("batch is cut early when the batch file would exceed the max size", func(t *testing.T) {
+		r := New(c, &opqueue.MemQueue{}, WithOperationSizer(sizer))
+
+		_, err := r.Add(operation1)
+		require.NoError(t, err)
+
+		// not full
+		ops, pending, commit, err := r.Cut(false)
+		require.NoError(t, err)
+		require.Empty(t, ops)
+		require.Equal(t, uint(1), pending)
+		require.Nil(t, commit)
+
+		_, err = r.Add(operation2)
+		require.NoError(t, err)
+		_, err = r.Add(operation3)
+		require.NoError(t, err)
+
+		ops, pending, commit, err = r.Cut(false)
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+		require.Equal(t, operation1, ops[0])
+		require.Equal(t, operation2, ops[1])
+		require.Equal(t, uint(1), pending)
+
+		pending, err = commit()
+		require.NoError(t, err)
+		require.Equal(t, uint(1), pending)
+
+		ops, pending, commit, err = r.Cut(true)
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		require.Equal(t, operation3, ops[0])
+		require.Zero(t, pending)
+
+		pending, err = commit()
+		require.NoError(t, err)
+		require.Zero(t, pending)
+	})
+
+	t.Run("operation exceeds max size - with logger", func(t *testing.T) {
+		l, hook := logtest.NewNullLogger()
+
+		r := New(c, &opqueue.MemQueue{},
+			WithOperationSizer(func(op *batch.OperationInfo) (int, int, error) {
+				return 30, 30, nil
+			}),
+			WithLogger(log.New(l)),
+		)
+
+		_, err := r.Add(operation1)
+		require.NoError(t, err)
+
+		ops, _, _, err := r.Cut(true)
+		require.NoError(t, err)
+		require.Empty(t, ops)
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		require.Equal(t, logrus.WarnLevel, entry.Level)
+		require.Contains(t, entry.Message, "operation for [1] exceeds the max batch file size [25]")
+	})
+
+	t.Run("size not checked without sizer", func(t *testing.T) {
+		r := New(c, &opqueue.MemQueue{})
+
+		_, err := r.Add(operation1)
+		require.NoError(t, err)
+		_, err = r.Add(operation2)
+		require.NoError(t, err)
+		_, err = r.Add(operation3)
+		require.NoError(t, err)
+
+		ops, _, _, err := r.Cut(false)
+		require.NoError(t, err)
+		require.Len(t, ops, 3)
+	})
+
+	t.Run("operation exceeds max size", func(t *testing.T) {
+		var rejected []*batch.OperationInfo
+
+		r := New(c, &opqueue.MemQueue{},
+			WithOperationSizer(func(op *batch.OperationInfo) (int, int, error) {
+				if op == operation2 {
+					return 30, 30, nil
+				}
+
+				return 10, 10, nil
+			}),
+			WithRejectHandler(func(op *batch.OperationInfo, err error) {
+				require.EqualError(t, err, "operation for [2] exceeds the max batch file size [25]")
+
+				rejected = append(rejected, op)
+			}),
+		)
+
+		_, err := r.Add(operation2)
+		require.NoError(t, err)
+		_, err = r.Add(operation3)
+		require.NoError(t, err)
+
+		// the oversized operation is removed so that it doesn't block the queue
+		ops, pending, commit, err := r.Cut(true)
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		require.Equal(t, operation3, ops[0])
+		require.Zero(t, pending)
+		require.Equal(t, []*batch.OperationInfo{operation2}, rejected)
+
+		pending, err = commit()
+		require.NoError(t, err)
+		require.Zero(t, pending)
+	})
+
+	t.Run("operation exceeds max size - no reject handler", func(t *testing.T) {
+		r := New(c, &opqueue.MemQueue{}, WithOperationSizer(func(*batch.OperationInfo) (int, int, error) {
+			return 30, 30, nil
+		}))
+
+		_, err := r.Add(operation1)
+		require.NoError(t, err)
+
+		ops, pending, commit, err := r.Cut(true)
+		require.NoError(t, err)
+		require.Empty(t, ops)
+		require.Zero(t, pending)
+		require.NotNil(t, commit)
+	})
+
+	t.Run("sizer error", func(t *testing.T) {
+		r := New(c, &opqueue.MemQueue{}, WithOperationSizer(func(*batch.OperationInfo) (int, int, error) {
+			return 0, 0, errors.New("sizer error")
+		}))
+
+		_, err := r.Add(operation1)
+		require.EqualError(t, err, "sizer error")
+		require.Zero(t, r.pendingBatch.Len())
+	})
+
+	t.Run("sizer error - queued operations", func(t *testing.T) {
+		q := &opqueue.MemQueue{}
+		_, err := q.Add(operation1)
+		require.NoError(t, err)
+
+		r := New(c, q, WithOperationSizer(func(*batch.OperationInfo) (int, int, error) {
+			return 0, 0, errors.New("sizer error")
+		}))
+
+		ops, _, _, err := r.Cut(true)
+		require.EqualError(t, err, "sizer error")
+		require.Empty(t, ops)
+	})
+
+	t.Run("operations are sized once", func(t *testing.T) {
+		sized := make(map[string]int)
+
+		q := &opqueue.MemQueue{}
+
+		// operation queued before the cutter was created
+		_, err := q.Add(operation1)
+		require.NoError(t, err)
+
+		r := New(c, q, WithOperationSizer(func(op *batch.OperationInfo) (int, int, error) {
+			sized[op.UniqueSuffix]++
+
+			return 10, 10, nil
+		}))
+
+		_, err = r.Add(operation2)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			ops, pending, commit, err := r.Cut(false)
+			require.NoError(t, err)
+			require.Empty(t, ops)
+			require.Equal(t, uint(2), pending)
+			require.Nil(t, commit)
+		}
+
+		_, err = r.Add(operation3)
+		require.NoError(t, err)
+		_, err = r.Add(operation4)
+		require.NoError(t, err)
+
+		ops, pending, commit, err := r.Cut(false)
+		require.NoError(t, err)
+		require.Equal(t, []*batch.OperationInfo{operation1, operation2}, ops)
+		require.Equal(t, uint(2), pending)
+
+		pending, err = commit()
+		require.NoError(t, err)
+		require.Equal(t, uint(2), pending)
+
+		ops, _, _, err = r.Cut(true)
+		require.NoError(t, err)
+		require.Equal(t, []*batch.OperationInfo{operation3, operation4}, ops)
+
+		require.Equal(t, map[string]int{"1": 1, "2": 1, "3": 1, "4": 1}, sized)
+	})
+}
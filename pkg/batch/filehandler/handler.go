@@ -7,11 +7,31 @@ SPDX-License-Identifier: Apache-2.0
 package filehandler
 
 import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 )
 
 // Handler creates batch/anchor files from operations
-type Handler struct{}
+type Handler struct {
+	compactDeactivate bool
+}
+
+// Option is an option for the file handler
+type Option func(h *Handler)
+
+// WithCompactDeactivate writes deactivate operations to the batch file in compact form, i.e. only the ID and the
+// signed data are included (the DID suffix and recovery reveal value are contained in the signed data). This
+// significantly reduces the size of batch files for mass deactivations. Note that all observers must support
+// compact deactivate operations before this option is enabled.
+func WithCompactDeactivate() Option {
+	return func(h *Handler) {
+		h.compactDeactivate = true
+	}
+}
 
 // AnchorFile defines the schema of a Anchor File
 type AnchorFile struct {
@@ -23,6 +43,18 @@ type AnchorFile struct {
 	UniqueSuffixes []string `json:"uniqueSuffixes"`
 }
 
+// CompactDeactivateOperation is the compact representation of a deactivate operation in a batch file
+type CompactDeactivateOperation struct {
+	// Type is always "deactivate"
+	Type batch.OperationType `json:"type"`
+
+	// ID is the ID of the document (namespace + unique suffix)
+	ID string `json:"id"`
+
+	// SignedData contains the signed DID suffix and recovery reveal value
+	SignedData *model.JWS `json:"signedData"`
+}
+
 // BatchFile defines the schema of a Batch File and its related operations.
 type BatchFile struct {
 	// operations included in this batch file, each operation is an encoded string
@@ -30,8 +62,14 @@ type BatchFile struct {
 }
 
 // New returns new operations handler
-func New() *Handler {
-	return &Handler{}
+func New(opts ...Option) *Handler {
+	h := &Handler{}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 // CreateBatchFile will combine all operations into batch file
@@ -42,6 +80,15 @@ func (h *Handler) CreateBatchFile(operations [][]byte) ([]byte, error) {
 	// as specified by the Sidetree protocol.
 	var ops []string
 	for _, op := range operations {
+		if h.compactDeactivate {
+			compactOp, err := compact(op)
+			if err != nil {
+				return nil, err
+			}
+
+			op = compactOp
+		}
+
 		opStr := docutil.EncodeToString(op)
 		ops = append(ops, opStr)
 	}
@@ -61,3 +108,22 @@ func (h *Handler) CreateAnchorFile(uniqueSuffixes []string, batchAddress string)
 
 	return docutil.MarshalCanonical(af)
 }
+
+// compact returns the compact form of the given operation if it's a deactivate operation; otherwise the
+// operation is returned as is
+func compact(opBytes []byte) ([]byte, error) {
+	op := &batch.Operation{}
+	if err := json.Unmarshal(opBytes, op); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal operation: %s", err)
+	}
+
+	if op.Type != batch.OperationTypeDeactivate {
+		return opBytes, nil
+	}
+
+	return docutil.MarshalCanonical(&CompactDeactivateOperation{
+		Type:       op.Type,
+		ID:         op.ID,
+		SignedData: op.SignedData,
+	})
+}
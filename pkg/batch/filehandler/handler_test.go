@@ -11,16 +11,72 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 )
 
-var batch = [][]byte{[]byte("op1"), []byte("op2")}
+var operations = [][]byte{[]byte("op1"), []byte("op2")}
 
 func TestProcessBatch(t *testing.T) {
 	handler := Handler{}
 
-	batch, err := handler.CreateBatchFile(batch)
+	batchBytes, err := handler.CreateBatchFile(operations)
 	require.Nil(t, err)
-	require.NotNil(t, batch)
+	require.NotNil(t, batchBytes)
+}
+
+func TestCreateBatchFile_CompactDeactivate(t *testing.T) {
+	deactivateOp := &batch.Operation{
+		Type:                batch.OperationTypeDeactivate,
+		ID:                  "did:sidetree:suffix",
+		UniqueSuffix:        "suffix",
+		OperationBuffer:     []byte(`{"type":"deactivate"}`),
+		RecoveryRevealValue: "reveal",
+		SignedData:          &model.JWS{Payload: "payload", Signature: "signature"},
+	}
+
+	deactivateBytes, err := docutil.MarshalCanonical(deactivateOp)
+	require.NoError(t, err)
+
+	updateBytes, err := docutil.MarshalCanonical(&batch.Operation{Type: batch.OperationTypeUpdate, ID: "did:sidetree:other"})
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		batchBytes, err := New(WithCompactDeactivate()).CreateBatchFile([][]byte{updateBytes, deactivateBytes})
+		require.NoError(t, err)
+
+		bf := BatchFile{}
+		require.NoError(t, json.Unmarshal(batchBytes, &bf))
+		require.Len(t, bf.Operations, 2)
+
+		op, err := docutil.DecodeString(bf.Operations[0])
+		require.NoError(t, err)
+		require.Equal(t, updateBytes, op)
+
+		op, err = docutil.DecodeString(bf.Operations[1])
+		require.NoError(t, err)
+
+		compactOp := CompactDeactivateOperation{}
+		require.NoError(t, json.Unmarshal(op, &compactOp))
+		require.Equal(t, CompactDeactivateOperation{
+			Type:       deactivateOp.Type,
+			ID:         deactivateOp.ID,
+			SignedData: deactivateOp.SignedData,
+		}, compactOp)
+
+		fullBatchBytes, err := New().CreateBatchFile([][]byte{updateBytes, deactivateBytes})
+		require.NoError(t, err)
+		require.Less(t, len(batchBytes), len(fullBatchBytes))
+	})
+
+	t.Run("invalid operation", func(t *testing.T) {
+		batchBytes, err := New(WithCompactDeactivate()).CreateBatchFile(operations)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal operation")
+		require.Nil(t, batchBytes)
+	})
 }
 
 func TestCreateAnchorFile(t *testing.T) {
@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+// OperationState is the state of an operation that was added to the batch writer
+type OperationState string
+
+const (
+	// OperationStateQueued indicates that the operation was added to the operation queue
+	OperationStateQueued OperationState = "queued"
+
+	// OperationStateBatched indicates that the operation was cut into a batch file which was stored in CAS
+	OperationStateBatched OperationState = "batched"
+
+	// OperationStateAnchored indicates that the anchor file of the operation's batch was written to the blockchain
+	OperationStateAnchored OperationState = "anchored"
+
+	// OperationStateFailed indicates that the operation's batch could not be anchored. The operation
+	// remains in the queue and is retried, so a failed event may be followed by batched/anchored events.
+	OperationStateFailed OperationState = "failed"
+
+	// OperationStateRejected indicates that the operation was removed from the queue since it can never be
+	// anchored (e.g. it exceeds the max batch file size). This state is final.
+	OperationStateRejected OperationState = "rejected"
+)
+
+// OperationEvent is published to the lifecycle listeners when an operation transitions to a new state
+type OperationEvent struct {
+	UniqueSuffix string
+	State        OperationState
+
+	// BatchAddress is the CAS address of the batch file (set for batched and anchored events)
+	BatchAddress string
+
+	// AnchorAddress is the CAS address of the anchor file that was written to the blockchain (set for anchored events)
+	AnchorAddress string
+
+	// Error is the reason for the failure (set for failed and rejected events)
+	Error error
+}
+
+// LifecycleListener is notified of operation state transitions. Listeners are invoked synchronously
+// by the batch writer so they should not block.
+type LifecycleListener func(event *OperationEvent)
+
+// NewChannelListener returns a lifecycle listener that publishes events to the given channel.
+// Events are dropped if the channel is full so that a slow consumer doesn't block the batch writer.
+func NewChannelListener(events chan<- *OperationEvent) LifecycleListener {
+	return func(event *OperationEvent) {
+		select {
+		case events <- event:
+		default:
+			log.Default().Warnf("Lifecycle event channel is full. Dropping %s event for [%s]", event.State, event.UniqueSuffix)
+		}
+	}
+}
+
+func (r *Writer) notify(ops []string, state OperationState, batchAddr, anchorAddr string, err error) {
+	if len(r.listeners) == 0 {
+		return
+	}
+
+	for _, suffix := range ops {
+		event := &OperationEvent{
+			UniqueSuffix:  suffix,
+			State:         state,
+			BatchAddress:  batchAddr,
+			AnchorAddress: anchorAddr,
+			Error:         err,
+		}
+
+		for _, listener := range r.listeners {
+			listener(event)
+		}
+	}
+}
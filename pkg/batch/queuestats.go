@@ -0,0 +1,142 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+)
+
+const infiniteBucket = "+Inf"
+
+// defaultQueueAgeBuckets are the default upper bounds of the time-in-queue buckets
+var defaultQueueAgeBuckets = []time.Duration{
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 5 * time.Minute, 10 * time.Minute, 30 * time.Minute, time.Hour,
+}
+
+// QueueStats contains statistics about the time that operations spend in the operation queue of a batch writer
+type QueueStats struct {
+	// Pending is the number of operations in the queue
+	Pending uint `json:"pending"`
+
+	// PendingAge contains the number of pending operations bucketed by their time in the queue so far
+	PendingAge []Bucket `json:"pendingAge"`
+
+	// PendingUnknownAge is the number of pending operations whose queued time is unknown (e.g. operations
+	// that were added to a persistent queue before the node was upgraded)
+	PendingUnknownAge uint `json:"pendingUnknownAge,omitempty"`
+
+	// WaitTime contains the number of anchored operations (since the writer was created) bucketed by the
+	// time that they spent in the queue
+	WaitTime []Bucket `json:"waitTime"`
+}
+
+// Bucket contains the number of operations whose time in the queue is greater than the upper bound
+// of the previous bucket and less than or equal to the upper bound of this bucket
+type Bucket struct {
+	// UpperBound is the upper bound of the bucket (zero for the last bucket, which has no upper bound)
+	UpperBound time.Duration `json:"-"`
+
+	// LE is the upper bound of the bucket in human readable form (e.g. "1m0s") or "+Inf" for the last bucket
+	LE string `json:"le"`
+
+	// Count is the number of operations in the bucket
+	Count uint64 `json:"count"`
+}
+
+// histogram counts durations in buckets with the given upper bounds (and a final bucket without an upper bound)
+type histogram struct {
+	mutex  sync.Mutex
+	bounds []time.Duration
+	counts []uint64
+}
+
+func newHistogram(bounds []time.Duration) *histogram {
+	sorted := append([]time.Duration(nil), bounds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &histogram{
+		bounds: sorted,
+		counts: make([]uint64, len(sorted)+1),
+	}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] })
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.counts[i]++
+}
+
+func (h *histogram) buckets() []Bucket {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	buckets := make([]Bucket, len(h.counts))
+
+	for i, count := range h.counts {
+		if i < len(h.bounds) {
+			buckets[i] = Bucket{UpperBound: h.bounds[i], LE: h.bounds[i].String(), Count: count}
+		} else {
+			buckets[i] = Bucket{LE: infiniteBucket, Count: count}
+		}
+	}
+
+	return buckets
+}
+
+// QueueStats returns the number of pending operations bucketed by their time in the queue along with
+// the histogram of the wait times of the operations that were anchored
+func (r *Writer) QueueStats() (*QueueStats, error) {
+	queue := r.context.OperationQueue()
+
+	ops, err := queue.Peek(queue.Len())
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.clock.Now()
+	pendingAge := newHistogram(r.waitTime.bounds)
+
+	stats := &QueueStats{Pending: uint(len(ops))}
+
+	for _, op := range ops {
+		if op.QueuedTime.IsZero() {
+			stats.PendingUnknownAge++
+			continue
+		}
+
+		pendingAge.observe(now.Sub(op.QueuedTime))
+	}
+
+	stats.PendingAge = pendingAge.buckets()
+	stats.WaitTime = r.waitTime.buckets()
+
+	return stats, nil
+}
+
+// recordWaitTimes reports the time that each of the given (anchored) operations spent in the queue
+func (r *Writer) recordWaitTimes(ops []*batch.OperationInfo) {
+	now := r.clock.Now()
+
+	for _, op := range ops {
+		if op.QueuedTime.IsZero() {
+			continue
+		}
+
+		wait := now.Sub(op.QueuedTime)
+
+		r.waitTime.observe(wait)
+		r.metrics.QueueWaitTime(wait)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/simulator"
+)
+
+func TestWriter_QueueStats(t *testing.T) {
+	ctx := newMockContext()
+	clock := simulator.NewClock(time.Now())
+	m := mocks.NewMockMetrics()
+
+	writer, err := New("test", ctx, WithBatchTimeout(time.Hour), WithClock(clock), WithMetrics(m),
+		WithQueueAgeBuckets(10*time.Second, time.Second, 2*time.Hour))
+	require.NoError(t, err)
+
+	stats, err := writer.QueueStats()
+	require.NoError(t, err)
+	require.Zero(t, stats.Pending)
+	require.Equal(t, []string{"1s", "10s", "2h0m0s", "+Inf"}, bucketBounds(stats.PendingAge))
+
+	writer.Start()
+	defer writer.Stop()
+
+	// wait for the writer to process the startup notification
+	time.Sleep(100 * time.Millisecond)
+
+	ops := generateOperations(1)
+	require.NoError(t, writer.Add(ops[0]))
+
+	require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, 10*time.Millisecond)
+
+	clock.Advance(3 * time.Second)
+
+	// an operation without a queued time (e.g. queued by a previous version of the node)
+	_, err = ctx.OpQueue.Add(&batch.OperationInfo{UniqueSuffix: "unknown", Data: []byte("data")})
+	require.NoError(t, err)
+
+	stats, err = writer.QueueStats()
+	require.NoError(t, err)
+	require.Equal(t, uint(2), stats.Pending)
+	require.Equal(t, uint(1), stats.PendingUnknownAge)
+	require.Equal(t, []uint64{0, 1, 0, 0}, bucketCounts(stats.PendingAge))
+	require.Equal(t, []uint64{0, 0, 0, 0}, bucketCounts(stats.WaitTime))
+
+	clock.Advance(time.Hour)
+
+	require.Eventually(t, func() bool { return len(ctx.BlockchainClient.GetAnchors()) == 1 }, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return len(m.QueueWaitTimes()) == 1 }, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, time.Hour+3*time.Second, m.QueueWaitTimes()[0])
+
+	stats, err = writer.QueueStats()
+	require.NoError(t, err)
+	require.Zero(t, stats.Pending)
+	require.Equal(t, []uint64{0, 0, 0, 0}, bucketCounts(stats.PendingAge))
+	require.Equal(t, []uint64{0, 0, 1, 0}, bucketCounts(stats.WaitTime))
+
+	statsBytes, err := json.Marshal(stats)
+	require.NoError(t, err)
+	require.Contains(t, string(statsBytes), `{"le":"2h0m0s","count":1}`)
+}
+
+func TestWriter_QueueStatsError(t *testing.T) {
+	ctx := newMockContext()
+	ctx.OpQueue = &failingQueue{err: errors.New("injected queue error")}
+
+	writer, err := New("test", ctx)
+	require.NoError(t, err)
+
+	stats, err := writer.QueueStats()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "injected queue error")
+	require.Nil(t, stats)
+}
+
+func TestWithQueueAgeBuckets(t *testing.T) {
+	writer, err := New("test", newMockContext(), WithQueueAgeBuckets(time.Second, 0))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid queue age bucket")
+	require.Nil(t, writer)
+}
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]time.Duration{time.Second, time.Minute})
+
+	h.observe(0)
+	h.observe(time.Second)
+	h.observe(time.Second + 1)
+	h.observe(time.Hour)
+
+	require.Equal(t, []Bucket{
+		{UpperBound: time.Second, LE: "1s", Count: 2},
+		{UpperBound: time.Minute, LE: "1m0s", Count: 1},
+		{LE: "+Inf", Count: 1},
+	}, h.buckets())
+}
+
+func bucketBounds(buckets []Bucket) []string {
+	bounds := make([]string, len(buckets))
+	for i, b := range buckets {
+		bounds[i] = b.LE
+	}
+
+	return bounds
+}
+
+func bucketCounts(buckets []Bucket) []uint64 {
+	counts := make([]uint64, len(buckets))
+	for i, b := range buckets {
+		counts[i] = b.Count
+	}
+
+	return counts
+}
+
+type failingQueue struct {
+	err error
+}
+
+func (q *failingQueue) Add(*batch.OperationInfo) (uint, error) {
+	return 0, q.err
+}
+
+func (q *failingQueue) Peek(uint) ([]*batch.OperationInfo, error) {
+	return nil, q.err
+}
+
+func (q *failingQueue) Remove(uint) ([]*batch.OperationInfo, uint, error) {
+	return nil, 0, q.err
+}
+
+func (q *failingQueue) Len() uint {
+	return 0
+}
@@ -16,20 +16,27 @@ SPDX-License-Identifier: Apache-2.0
 // 4) create an anchor file based on batch file address
 // 5) store anchor file into CAS
 // 6) write the address of anchor file to the underlying blockchain
+//
+// If concurrent batches are enabled then the files of several consecutive batches are written to CAS in parallel.
+// The anchor files are written to the blockchain in the order in which the batches were cut and a batch is only
+// anchored after its files were written. If a batch fails then the files of the following batches are discarded
+// and their operations remain in the queue.
 package batch
 
 import (
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/cutter"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/filehandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/metrics"
 	"github.com/trustbloc/sidetree-core-go/pkg/observer"
 )
 
@@ -43,7 +50,23 @@ type Option func(opts *Options) error
 
 type batchCutter interface {
 	Add(operation *batch.OperationInfo) (uint, error)
-	Cut(force bool) (ops []*batch.OperationInfo, pending uint, commit cutter.Committer, err error)
+	CutFrom(offset uint, force bool) (ops []*batch.OperationInfo, pending uint, commit cutter.Committer, err error)
+}
+
+// Clock provides the current time and timers to the writer. A deterministic clock may be provided for testing.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
 }
 
 type process struct {
@@ -61,7 +84,14 @@ type Writer struct {
 	exitChan     chan struct{}
 	batchTimeout time.Duration
 	opsHandler   OperationHandler
+	metrics      metrics.Metrics
+	logger       log.Logger
+	listeners    []LifecycleListener
+	clock        Clock
+	cleanup      bool
+	concurrency  uint
 	stopped      uint32
+	waitTime     *histogram
 }
 
 // Context contains batch writer context
@@ -94,6 +124,12 @@ type CASClient interface {
 	Read(address string) ([]byte, error)
 }
 
+// CASDeleter is implemented by CAS clients that support deleting content
+type CASDeleter interface {
+	// Delete deletes the content at the given address
+	Delete(address string) error
+}
+
 // OperationHandler defines an interface for creating batch and anchor files
 type OperationHandler interface {
 	// CreateBatchFile will create batch file bytes
@@ -125,15 +161,56 @@ func New(name string, context Context, options ...Option) (*Writer, error) {
 		opsHandler = filehandler.New()
 	}
 
-	return &Writer{
+	var m metrics.Metrics
+	if rOpts.Metrics != nil {
+		m = rOpts.Metrics
+	} else {
+		m = metrics.NewNoop()
+	}
+
+	logger := rOpts.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	queueAgeBuckets := defaultQueueAgeBuckets
+	if len(rOpts.QueueAgeBuckets) > 0 {
+		queueAgeBuckets = rOpts.QueueAgeBuckets
+	}
+
+	var clock Clock = systemClock{}
+	if rOpts.Clock != nil {
+		clock = rOpts.Clock
+	}
+
+	concurrency := rOpts.ConcurrentBatches
+	if concurrency == 0 {
+		concurrency = 1
+	}
+
+	w := &Writer{
 		name:         name,
-		batchCutter:  cutter.New(context.Protocol(), context.OperationQueue()),
 		sendChan:     make(chan process, defaultSendChannelSize),
 		exitChan:     make(chan struct{}),
 		batchTimeout: batchTimeout,
 		context:      context,
 		opsHandler:   opsHandler,
-	}, nil
+		metrics:      m,
+		logger:       logger.WithFields(log.Fields{log.FieldWriter: name}),
+		listeners:    rOpts.Listeners,
+		clock:        clock,
+		cleanup:      rOpts.OrphanCleanup,
+		concurrency:  concurrency,
+		waitTime:     newHistogram(queueAgeBuckets),
+	}
+
+	w.batchCutter = cutter.New(context.Protocol(), context.OperationQueue(),
+		cutter.WithOperationSizer(operationSizer(opsHandler)),
+		cutter.WithRejectHandler(w.reject),
+		cutter.WithLogger(w.logger),
+	)
+
+	return w, nil
 }
 
 // Start periodic anchoring of operation batches to blockchain.
@@ -167,11 +244,17 @@ func (r *Writer) Add(operation *batch.OperationInfo) error {
 		return errors.New("writer is stopped")
 	}
 
+	if operation.QueuedTime.IsZero() {
+		operation.QueuedTime = r.clock.Now()
+	}
+
 	_, err := r.batchCutter.Add(operation)
 	if err != nil {
 		return err
 	}
 
+	r.notify([]string{operation.UniqueSuffix}, OperationStateQueued, "", "", nil)
+
 	select {
 	case r.sendChan <- process{force: false}:
 		// Send a notification that an operation was added to the queue
@@ -191,17 +274,17 @@ func (r *Writer) main() {
 	for {
 		select {
 		case p := <-r.sendChan:
-			log.Debugf("[%s] Handling process notification: %v", r.name, p)
+			r.logger.Debugf("Handling process notification: %v", p)
 			pending := r.processAvailable(p.force) > 0
 			timer = r.handleTimer(timer, pending)
 
 		case <-timer:
-			log.Debugf("[%s] Handling batch timeout", r.name)
+			r.logger.Debugf("Handling batch timeout")
 			pending := r.processAvailable(true) > 0
 			timer = r.handleTimer(nil, pending)
 
 		case <-r.exitChan:
-			log.Debugf("[%s] exiting batch writer", r.name)
+			r.logger.Debugf("exiting batch writer")
 			return
 		}
 	}
@@ -211,23 +294,23 @@ func (r *Writer) processAvailable(forceCut bool) uint {
 	// First drain the queue of all of the operations that are ready to form a batch
 	pending, err := r.drain()
 	if err != nil {
-		log.Warnf("[%s] Error draining operations queue: %s. Pending operations: %d.", r.name, err, pending)
+		r.logger.Warnf("Error draining operations queue: %s. Pending operations: %d.", err, pending)
 		return pending
 	}
 
 	if pending == 0 || !forceCut {
-		log.Debugf("[%s] No further processing necessary. Pending operations: %d", r.name, pending)
+		r.logger.Debugf("No further processing necessary. Pending operations: %d", pending)
 		return pending
 	}
 
-	log.Debugf("[%s] Forcefully processing operations. Pending operations: %d", r.name, pending)
+	r.logger.Debugf("Forcefully processing operations. Pending operations: %d", pending)
 
 	// Now process the remaining operations
 	n, pending, err := r.cutAndProcess(true)
 	if err != nil {
-		log.Warnf("[%s] Error processing operations: %s. Pending operations: %d.", r.name, err, pending)
+		r.logger.Warnf("Error processing operations: %s. Pending operations: %d.", err, pending)
 	} else {
-		log.Debugf("[%s] Successfully processed %d operations. Pending operations: %d.", r.name, n, pending)
+		r.logger.Debugf("Successfully processed %d operations. Pending operations: %d.", n, pending)
 	}
 
 	return pending
@@ -235,98 +318,293 @@ func (r *Writer) processAvailable(forceCut bool) uint {
 
 // drain cuts and processes all pending operations that are ready to form a batch.
 func (r *Writer) drain() (pending uint, err error) {
-	log.Debugf("[%s] Draining operations queue...", r.name)
+	r.logger.Debugf("Draining operations queue...")
 	for {
 		n, pending, err := r.cutAndProcess(false)
 		if err != nil {
-			log.Errorf("[%s] Error draining operations: cutting and processing returned an error: %s", r.name, err)
+			r.logger.Errorf("Error draining operations: cutting and processing returned an error: %s", err)
 			return pending, err
 		}
 		if n == 0 {
-			log.Debugf("[%s] ... no more operations to be processed. Pending operations: %d", r.name, pending)
+			r.logger.Debugf("... no more operations to be processed. Pending operations: %d", pending)
 			return pending, nil
 		}
-		log.Debugf("[%s] ... processed %d operations. Pending operations: %d", r.name, n, pending)
+		r.logger.Debugf("... processed %d operations. Pending operations: %d", n, pending)
 	}
 }
 
+// pendingBatch is a batch that was cut from the queue along with the files that were written to CAS for the batch
+type pendingBatch struct {
+	ops        []*batch.OperationInfo
+	commit     cutter.Committer
+	startTime  time.Time
+	batchAddr  string
+	anchorAddr string
+	err        error
+}
+
+// cutAndProcess cuts up to the configured number of concurrent batches, writes the files of the batches to CAS
+// concurrently and then writes the anchors of the batches to the blockchain in the order in which the batches
+// were cut. A batch is only committed (removed from the queue) after its anchor was written.
 func (r *Writer) cutAndProcess(forceCut bool) (numProcessed int, pending uint, err error) {
-	operations, pending, commit, err := r.batchCutter.Cut(forceCut)
+	batches, pending, err := r.cut(forceCut)
 	if err != nil {
-		log.Errorf("[%s] Error cutting batch: %s", r.name, err)
+		r.logger.Errorf("Error cutting batch: %s", err)
 		return 0, pending, err
 	}
 
-	if len(operations) == 0 {
-		log.Debugf("[%s] No operations to be processed", r.name)
+	if len(batches) == 0 {
+		r.logger.Debugf("No operations to be processed")
 		return 0, pending, nil
 	}
 
-	log.Debugf("[%s] processing %d batch operations ...", r.name, len(operations))
+	r.writeFiles(batches)
 
-	err = r.process(operations)
-	if err != nil {
-		log.Errorf("[%s] Error processing %d batch operations: %s", r.name, len(operations), err)
-		return 0, pending + uint(len(operations)), err
-	}
+	for i, b := range batches {
+		err = r.anchor(b)
+		if err != nil {
+			r.logger.Errorf("Error processing %d batch operations: %s", len(b.ops), err)
+			r.notify(uniqueSuffixes(b.ops), OperationStateFailed, "", "", err)
 
-	log.Debugf("[%s] Successfully processed %d batch operations. Committing to batch cutter ...", r.name, len(operations))
+			// the anchors of the batches that follow must be written after this anchor so they're processed again
+			r.discard(batches[i+1:])
 
-	pending, err = commit()
-	if err != nil {
-		log.Errorf("[%s] Batch operations were committed but could not be removed from the queue due to error [%s]. Stopping the batch writer so that no further operations are added.", r.name, err)
-		r.Stop()
-		return 0, pending, errors.WithMessagef(err, "operations were committed but could not be removed from the queue")
+			return numProcessed, pending + numOperations(batches[i:]), err
+		}
+
+		r.logger.Debugf("Successfully processed %d batch operations. Committing to batch cutter ...", len(b.ops))
+
+		_, err = b.commit()
+		if err != nil {
+			r.logger.Errorf("Batch operations were committed but could not be removed from the queue due to error [%s]. Stopping the batch writer so that no further operations are added.", err)
+			r.Stop()
+			r.discard(batches[i+1:])
+			return numProcessed, pending + numOperations(batches[i+1:]), errors.WithMessagef(err, "operations were committed but could not be removed from the queue")
+		}
+
+		numProcessed += len(b.ops)
 	}
 
-	log.Debugf("[%s] Successfully committed to batch cutter. Pending operations: %d", r.name, pending)
+	r.logger.Debugf("Successfully committed to batch cutter. Pending operations: %d", pending)
+
+	return numProcessed, pending, nil
+}
+
+// cut cuts up to the configured number of concurrent batches and returns the batches along with the number
+// of operations that remain in the queue once the batches are committed
+func (r *Writer) cut(forceCut bool) ([]*pendingBatch, uint, error) {
+	var batches []*pendingBatch
+	var offset, pending uint
+
+	for uint(len(batches)) < r.concurrency {
+		ops, p, commit, err := r.batchCutter.CutFrom(offset, forceCut)
+		if err != nil {
+			if len(batches) == 0 {
+				return nil, p, err
+			}
+
+			// process the batches that were cut so far
+			r.logger.Warnf("Error cutting batch: %s", err)
+
+			break
+		}
+
+		pending = p
+
+		if len(ops) == 0 {
+			break
+		}
+
+		r.logger.Debugf("processing %d batch operations ...", len(ops))
+
+		r.metrics.BatchCutSize(len(ops))
+
+		batches = append(batches, &pendingBatch{ops: ops, commit: commit})
+		offset += uint(len(ops))
+	}
 
-	return len(operations), pending, nil
+	return batches, pending, nil
 }
 
-func (r *Writer) process(ops []*batch.OperationInfo) error {
-	if len(ops) == 0 {
-		return errors.New("create batch called with no pending operations, should not happen")
+// writeFiles writes the files of the given batches to CAS concurrently
+func (r *Writer) writeFiles(batches []*pendingBatch) {
+	if len(batches) == 1 {
+		r.writeBatchFiles(batches[0])
+		return
 	}
 
-	operations := make([][]byte, len(ops))
-	for i, d := range ops {
-		operations[i] = d.Data
+	var wg sync.WaitGroup
+
+	for _, b := range batches {
+		wg.Add(1)
+
+		go func(b *pendingBatch) {
+			defer wg.Done()
+
+			r.writeBatchFiles(b)
+		}(b)
 	}
 
-	batchBytes, err := r.opsHandler.CreateBatchFile(operations)
+	wg.Wait()
+}
+
+// writeBatchFiles writes the batch file and then the anchor file (which references the batch file) to CAS. (The
+// files of a batch can't be written in parallel since the anchor file contains the CAS address of the batch file.)
+// If orphan cleanup is enabled, the batch file is deleted from CAS if the anchor file can't be written.
+func (r *Writer) writeBatchFiles(b *pendingBatch) {
+	b.startTime = r.clock.Now()
+
+	batchBytes, err := r.opsHandler.CreateBatchFile(operationData(b.ops))
 	if err != nil {
-		return err
+		b.err = err
+		return
 	}
 
-	log.Debugf("[%s] batch: %s", r.name, string(batchBytes))
+	r.logger.Debugf("batch: %s", string(batchBytes))
 
 	// Make the batch file available in CAS
 	batchAddr, err := r.context.CAS().Write(batchBytes)
 	if err != nil {
-		return err
-	}
-
-	uniqueSuffixes := make([]string, len(ops))
-	for i, d := range ops {
-		uniqueSuffixes[i] = d.UniqueSuffix
+		b.err = err
+		return
 	}
 
-	anchorBytes, err := r.opsHandler.CreateAnchorFile(uniqueSuffixes, batchAddr)
+	anchorBytes, err := r.opsHandler.CreateAnchorFile(uniqueSuffixes(b.ops), batchAddr)
 	if err != nil {
-		return err
+		r.deleteOrphans([]string{batchAddr})
+		b.err = err
+		return
 	}
 
-	log.Debugf("[%s] anchor: %s", r.name, string(anchorBytes))
+	r.logger.Debugf("anchor: %s", string(anchorBytes))
 
 	// Make the anchor file available in CAS
 	anchorAddr, err := r.context.CAS().Write(anchorBytes)
 	if err != nil {
-		return err
+		r.deleteOrphans([]string{batchAddr})
+		b.err = err
+		return
 	}
 
+	b.batchAddr = batchAddr
+	b.anchorAddr = anchorAddr
+}
+
+// anchor writes the anchor of the batch to the blockchain. The anchor is only written if all of the files of
+// the batch were written to CAS.
+//
+// Files are never deleted once the anchor was submitted since a blockchain error is ambiguous (e.g. after a
+// timeout the transaction may still be anchored) and deleting the files of an anchored transaction would make
+// its operations unresolvable.
+func (r *Writer) anchor(b *pendingBatch) error {
+	if b.err != nil {
+		return b.err
+	}
+
+	suffixes := uniqueSuffixes(b.ops)
+
+	r.notify(suffixes, OperationStateBatched, b.batchAddr, "", nil)
+
 	// Create Sidetree transaction in blockchain
-	return r.context.Blockchain().WriteAnchor(anchorAddr)
+	err := r.context.Blockchain().WriteAnchor(b.anchorAddr)
+	if err != nil {
+		r.logger.Warnf("Failed to write anchor [%s] to the blockchain. The batch and anchor files are kept in CAS "+
+			"since the anchor may have been written: %s", b.anchorAddr, err)
+		return err
+	}
+
+	r.metrics.AnchorWriteTime(r.clock.Now().Sub(b.startTime))
+
+	r.recordWaitTimes(b.ops)
+
+	r.notify(suffixes, OperationStateAnchored, b.batchAddr, b.anchorAddr, nil)
+
+	return nil
+}
+
+// discard deletes the files of batches whose anchors won't be written (if orphan cleanup is enabled). The
+// operations of the batches remain in the queue.
+func (r *Writer) discard(batches []*pendingBatch) {
+	for _, b := range batches {
+		if b.err == nil {
+			r.deleteOrphans([]string{b.batchAddr, b.anchorAddr})
+		}
+	}
+}
+
+func numOperations(batches []*pendingBatch) uint {
+	var n uint
+	for _, b := range batches {
+		n += uint(len(b.ops))
+	}
+
+	return n
+}
+
+// deleteOrphans deletes the given files from CAS if orphan cleanup is enabled and the CAS client supports deletion
+func (r *Writer) deleteOrphans(addresses []string) {
+	if !r.cleanup || len(addresses) == 0 {
+		return
+	}
+
+	deleter, ok := r.context.CAS().(CASDeleter)
+	if !ok {
+		r.logger.Debugf("CAS client doesn't support deletion. Orphaned files will not be deleted: %s", addresses)
+		return
+	}
+
+	for _, addr := range addresses {
+		if err := deleter.Delete(addr); err != nil {
+			r.logger.Warnf("Unable to delete orphaned file [%s] from CAS: %s", addr, err)
+			continue
+		}
+
+		r.logger.Debugf("Deleted orphaned file [%s] from CAS", addr)
+	}
+}
+
+// reject is invoked by the batch cutter when an operation was removed from the queue since it can't be batched
+func (r *Writer) reject(op *batch.OperationInfo, err error) {
+	r.logger.Warnf("Operation for [%s] was rejected: %s", op.UniqueSuffix, err)
+
+	r.notify([]string{op.UniqueSuffix}, OperationStateRejected, "", "", err)
+}
+
+// operationSizer returns a function that determines the size that an operation adds to the batch file that is
+// created by the given handler. The size of a batch file with the operation alone is returned along with the
+// number of bytes that the operation adds to a larger batch file (e.g. the operation plus a separator).
+func operationSizer(opsHandler OperationHandler) cutter.OperationSizer {
+	return func(op *batch.OperationInfo) (int, int, error) {
+		single, err := opsHandler.CreateBatchFile([][]byte{op.Data})
+		if err != nil {
+			return 0, 0, err
+		}
+
+		double, err := opsHandler.CreateBatchFile([][]byte{op.Data, op.Data})
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return len(single), len(double) - len(single), nil
+	}
+}
+
+func operationData(ops []*batch.OperationInfo) [][]byte {
+	operations := make([][]byte, len(ops))
+	for i, d := range ops {
+		operations[i] = d.Data
+	}
+
+	return operations
+}
+
+func uniqueSuffixes(ops []*batch.OperationInfo) []string {
+	suffixes := make([]string, len(ops))
+	for i, d := range ops {
+		suffixes[i] = d.UniqueSuffix
+	}
+
+	return suffixes
 }
 
 func (r *Writer) handleTimer(timer <-chan time.Time, pending bool) <-chan time.Time {
@@ -336,7 +614,7 @@ func (r *Writer) handleTimer(timer <-chan time.Time, pending bool) <-chan time.T
 		return nil
 	case timer == nil && pending:
 		// Timer is not already running and there are messages pending, so start it
-		return time.After(r.batchTimeout)
+		return r.clock.After(r.batchTimeout)
 	default:
 		// Do nothing when:
 		// 1. Timer is already running and there are messages pending
@@ -361,10 +639,92 @@ func WithOperationHandler(opsHandler OperationHandler) Option {
 	}
 }
 
+//WithMetrics allows for specifying the metrics hooks
+func WithMetrics(m metrics.Metrics) Option {
+	return func(o *Options) error {
+		o.Metrics = m
+		return nil
+	}
+}
+
+//WithLogger allows for specifying the logger
+func WithLogger(logger log.Logger) Option {
+	return func(o *Options) error {
+		o.Logger = logger
+		return nil
+	}
+}
+
+//WithLifecycleListener allows for specifying a listener that is notified when an operation
+//transitions through the queued, batched, anchored, failed and rejected states
+func WithLifecycleListener(listener LifecycleListener) Option {
+	return func(o *Options) error {
+		if listener == nil {
+			return errors.New("lifecycle listener is nil")
+		}
+
+		o.Listeners = append(o.Listeners, listener)
+		return nil
+	}
+}
+
+//WithClock allows for specifying the clock that is used for the batch timeout (e.g. a deterministic clock for testing)
+func WithClock(clock Clock) Option {
+	return func(o *Options) error {
+		o.Clock = clock
+		return nil
+	}
+}
+
+//WithOrphanCleanup deletes the files that were written to CAS for a batch that failed before its anchor was
+//written to the blockchain (the CAS client must implement CASDeleter). Files are kept if writing the anchor fails
+//since the anchor may have been written regardless. Since CAS is content-addressed, this should only be enabled
+//if the files are not shared with other writers.
+func WithOrphanCleanup() Option {
+	return func(o *Options) error {
+		o.OrphanCleanup = true
+		return nil
+	}
+}
+
+//WithConcurrentBatches allows for specifying the maximum number of batches whose files are written to CAS
+//concurrently (one by default). The anchors of the batches are still written to the blockchain in the order in
+//which the batches were cut, and only after all of the files of the batch were written. If a batch fails then the
+//batches that follow it are processed again.
+func WithConcurrentBatches(n uint) Option {
+	return func(o *Options) error {
+		o.ConcurrentBatches = n
+		return nil
+	}
+}
+
+//WithQueueAgeBuckets allows for specifying the upper bounds of the buckets of the queue statistics
+//(operations are bucketed by their time in the queue)
+func WithQueueAgeBuckets(buckets ...time.Duration) Option {
+	return func(o *Options) error {
+		for _, b := range buckets {
+			if b <= 0 {
+				return errors.Errorf("invalid queue age bucket: %s", b)
+			}
+		}
+
+		o.QueueAgeBuckets = buckets
+		return nil
+	}
+}
+
 // Options allows the user to specify more advanced options
 type Options struct {
-	BatchTimeout time.Duration
-	OpsHandler   OperationHandler
+	BatchTimeout  time.Duration
+	OpsHandler    OperationHandler
+	Metrics       metrics.Metrics
+	Logger        log.Logger
+	Listeners     []LifecycleListener
+	Clock         Clock
+	OrphanCleanup bool
+
+	ConcurrentBatches uint
+	QueueAgeBuckets   []time.Duration
 }
 
 //prepareOptsFromOptions reads options
@@ -7,12 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package batch
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
@@ -20,7 +24,10 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/cutter"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/filehandler"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/opqueue"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/simulator"
 )
 
 //go:generate counterfeiter -o ../mocks/operationqueue.gen.go --fake-name OperationQueue ./cutter OperationQueue
@@ -94,6 +101,93 @@ func TestStart(t *testing.T) {
 	require.Equal(t, 2, len(bf.Operations))
 }
 
+func TestStart_MaxBatchFileSize(t *testing.T) {
+	operations := generateOperations(4)
+
+	// only one operation fits in a batch file
+	batchBytes, err := filehandler.New().CreateBatchFile([][]byte{operations[0].Data})
+	require.NoError(t, err)
+
+	ctx := newMockContext()
+	ctx.ProtocolClient.Protocol.MaxBatchFileSize = uint(len(batchBytes))
+
+	writer, err := New("test", ctx)
+	require.Nil(t, err)
+
+	writer.Start()
+	defer writer.Stop()
+
+	for _, op := range operations {
+		err = writer.Add(op)
+		require.Nil(t, err)
+	}
+
+	time.Sleep(time.Second)
+
+	anchors := ctx.BlockchainClient.GetAnchors()
+	require.Equal(t, 4, len(anchors))
+
+	for _, anchor := range anchors {
+		bytes, err := ctx.CasClient.Read(anchor)
+		require.NoError(t, err)
+
+		var af filehandler.AnchorFile
+		require.NoError(t, json.Unmarshal(bytes, &af))
+
+		bytes, err = ctx.CasClient.Read(af.BatchFileHash)
+		require.NoError(t, err)
+		require.True(t, len(bytes) <= len(batchBytes))
+
+		var bf filehandler.BatchFile
+		require.NoError(t, json.Unmarshal(bytes, &bf))
+		require.Len(t, bf.Operations, 1)
+	}
+}
+
+func TestStart_WithMetrics(t *testing.T) {
+	ctx := newMockContext()
+	m := mocks.NewMockMetrics()
+
+	writer, err := New("test", ctx, WithMetrics(m))
+	require.Nil(t, err)
+
+	writer.Start()
+	defer writer.Stop()
+
+	for _, op := range generateOperations(4) {
+		err = writer.Add(op)
+		require.Nil(t, err)
+	}
+
+	time.Sleep(time.Second)
+
+	require.Equal(t, 2, len(ctx.BlockchainClient.GetAnchors()))
+	require.Equal(t, []int{2, 2}, m.BatchCutSizes())
+	require.Len(t, m.AnchorWriteTimes(), 2)
+}
+
+func TestStart_WithLogger(t *testing.T) {
+	l, hook := logtest.NewNullLogger()
+	l.SetLevel(logrus.DebugLevel)
+
+	ctx := newMockContext()
+	writer, err := New("test", ctx, WithLogger(log.New(l)))
+	require.Nil(t, err)
+
+	writer.Start()
+	defer writer.Stop()
+
+	err = writer.Add(testOp)
+	require.Nil(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.NotEmpty(t, hook.AllEntries())
+	for _, entry := range hook.AllEntries() {
+		require.Equal(t, "test", entry.Data[log.FieldWriter])
+	}
+}
+
 func TestBatchTimer(t *testing.T) {
 	ctx := newMockContext()
 	writer, err := New("test", ctx, WithBatchTimeout(2*time.Second))
@@ -131,6 +225,163 @@ func TestBatchTimer(t *testing.T) {
 	require.Equal(t, 1, len(bf.Operations))
 }
 
+func TestBatchTimer_WithClock(t *testing.T) {
+	ctx := newMockContext()
+	clock := simulator.NewClock(time.Now())
+
+	writer, err := New("test", ctx, WithBatchTimeout(time.Hour), WithClock(clock))
+	require.Nil(t, err)
+
+	writer.Start()
+	defer writer.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	err = writer.Add(testOp)
+	require.Nil(t, err)
+
+	require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, 10*time.Millisecond)
+	require.Empty(t, ctx.BlockchainClient.GetAnchors())
+
+	clock.Advance(time.Hour)
+
+	require.Eventually(t, func() bool { return len(ctx.BlockchainClient.GetAnchors()) == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStart_WithLifecycleListener(t *testing.T) {
+	ctx := newMockContext()
+	events := make(chan *OperationEvent, 100)
+
+	writer, err := New("test", ctx, WithLifecycleListener(NewChannelListener(events)))
+	require.Nil(t, err)
+
+	writer.Start()
+	defer writer.Stop()
+
+	operations := generateOperations(2)
+	for _, op := range operations {
+		err = writer.Add(op)
+		require.Nil(t, err)
+	}
+
+	time.Sleep(time.Second)
+
+	require.Equal(t, 1, len(ctx.BlockchainClient.GetAnchors()))
+
+	states := make(map[string][]OperationState)
+	for len(events) > 0 {
+		event := <-events
+		states[event.UniqueSuffix] = append(states[event.UniqueSuffix], event.State)
+
+		switch event.State {
+		case OperationStateBatched:
+			require.NotEmpty(t, event.BatchAddress)
+		case OperationStateAnchored:
+			require.NotEmpty(t, event.BatchAddress)
+			require.Equal(t, ctx.BlockchainClient.GetAnchors()[0], event.AnchorAddress)
+		}
+	}
+
+	for _, op := range operations {
+		require.Equal(t, []OperationState{OperationStateQueued, OperationStateBatched, OperationStateAnchored},
+			states[op.UniqueSuffix])
+	}
+
+	_, err = New("test", ctx, WithLifecycleListener(nil))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "lifecycle listener is nil")
+}
+
+func TestLifecycleListener_Failed(t *testing.T) {
+	ctx := newMockContext()
+	ctx.BlockchainClient = mocks.NewMockBlockchainClient(fmt.Errorf("blockchain error"))
+
+	var failed []*OperationEvent
+	var mutex sync.Mutex
+
+	writer, err := New("test", ctx, WithLifecycleListener(func(event *OperationEvent) {
+		if event.State == OperationStateFailed {
+			mutex.Lock()
+			failed = append(failed, event)
+			mutex.Unlock()
+		}
+	}))
+	require.Nil(t, err)
+
+	writer.Start()
+	defer writer.Stop()
+
+	for _, op := range generateOperations(2) {
+		err = writer.Add(op)
+		require.Nil(t, err)
+	}
+
+	time.Sleep(time.Second)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	require.NotEmpty(t, failed)
+	require.Contains(t, failed[0].Error.Error(), "blockchain error")
+}
+
+func TestLifecycleListener_OperationTooLarge(t *testing.T) {
+	operations := generateOperations(2)
+	operations[0].Data = []byte("operation that is too large")
+
+	batchBytes, err := filehandler.New().CreateBatchFile([][]byte{operations[1].Data})
+	require.NoError(t, err)
+
+	ctx := newMockContext()
+	ctx.ProtocolClient.Protocol.MaxBatchFileSize = uint(len(batchBytes))
+
+	var rejected []*OperationEvent
+	var mutex sync.Mutex
+
+	writer, err := New("test", ctx, WithLifecycleListener(func(event *OperationEvent) {
+		require.NotEqual(t, OperationStateFailed, event.State)
+
+		if event.State == OperationStateRejected {
+			mutex.Lock()
+			rejected = append(rejected, event)
+			mutex.Unlock()
+		}
+	}))
+	require.Nil(t, err)
+
+	writer.Start()
+	defer writer.Stop()
+
+	for _, op := range operations {
+		err = writer.Add(op)
+		require.Nil(t, err)
+	}
+
+	time.Sleep(time.Second)
+
+	// the operation that is too large is rejected and the next operation is anchored
+	require.Len(t, ctx.BlockchainClient.GetAnchors(), 1)
+	require.Zero(t, ctx.OpQueue.Len())
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	require.Len(t, rejected, 1)
+	require.Equal(t, operations[0].UniqueSuffix, rejected[0].UniqueSuffix)
+	require.Contains(t, rejected[0].Error.Error(), "exceeds the max batch file size")
+}
+
+func TestNewChannelListener_Full(t *testing.T) {
+	events := make(chan *OperationEvent, 1)
+	listener := NewChannelListener(events)
+
+	listener(&OperationEvent{UniqueSuffix: "1", State: OperationStateQueued})
+	listener(&OperationEvent{UniqueSuffix: "2", State: OperationStateQueued})
+
+	require.Len(t, events, 1)
+	require.Equal(t, "1", (<-events).UniqueSuffix)
+}
+
 func TestCasError(t *testing.T) {
 	ctx := newMockContext()
 	writer, err := New("test", ctx, WithBatchTimeout(2*time.Second))
@@ -172,6 +423,217 @@ func TestBlockchainError(t *testing.T) {
 	require.Equal(t, 0, len(ctx.BlockchainClient.GetAnchors()))
 }
 
+func TestOrphanCleanup(t *testing.T) {
+	t.Run("anchor file error - cleanup enabled", func(t *testing.T) {
+		ctx := newMockContext()
+
+		writer, err := New("test", ctx, WithBatchTimeout(500*time.Millisecond), WithOrphanCleanup(),
+			WithOperationHandler(&anchorFileErrHandler{OperationHandler: filehandler.New()}))
+		require.Nil(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		for _, op := range generateOperations(2) {
+			require.NoError(t, writer.Add(op))
+		}
+
+		time.Sleep(time.Second)
+
+		require.Equal(t, 0, len(ctx.BlockchainClient.GetAnchors()))
+		require.Equal(t, 0, ctx.CasClient.Len())
+	})
+
+	t.Run("anchor file error - cleanup disabled", func(t *testing.T) {
+		ctx := newMockContext()
+
+		writer, err := New("test", ctx, WithBatchTimeout(500*time.Millisecond),
+			WithOperationHandler(&anchorFileErrHandler{OperationHandler: filehandler.New()}))
+		require.Nil(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		for _, op := range generateOperations(2) {
+			require.NoError(t, writer.Add(op))
+		}
+
+		time.Sleep(time.Second)
+
+		require.Equal(t, 0, len(ctx.BlockchainClient.GetAnchors()))
+		require.True(t, ctx.CasClient.Len() > 0)
+	})
+
+	t.Run("blockchain error - files are kept", func(t *testing.T) {
+		ctx := newMockContext()
+		ctx.BlockchainClient = mocks.NewMockBlockchainClient(fmt.Errorf("blockchain error"))
+
+		writer, err := New("test", ctx, WithBatchTimeout(500*time.Millisecond), WithOrphanCleanup())
+		require.Nil(t, err)
+
+		writer.Start()
+		defer writer.Stop()
+
+		for _, op := range generateOperations(2) {
+			require.NoError(t, writer.Add(op))
+		}
+
+		time.Sleep(time.Second)
+
+		// the anchor may have been written regardless of the error so the batch and anchor files must be kept
+		require.Equal(t, 0, len(ctx.BlockchainClient.GetAnchors()))
+		require.Equal(t, 2, ctx.CasClient.Len())
+	})
+}
+
+func TestConcurrentBatches(t *testing.T) {
+	t.Run("anchors are written in order", func(t *testing.T) {
+		ctx := newMockContext()
+		ctx.ProtocolClient.Protocol.MaxOperationsPerBatch = 2
+
+		for _, op := range generateOperations(7) {
+			_, err := ctx.OpQueue.Add(op)
+			require.NoError(t, err)
+		}
+
+		writer, err := New("test", ctx, WithConcurrentBatches(3))
+		require.NoError(t, err)
+
+		n, pending, err := writer.cutAndProcess(false)
+		require.NoError(t, err)
+		require.Equal(t, 6, n)
+		require.Equal(t, uint(1), pending)
+
+		n, pending, err = writer.cutAndProcess(true)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Zero(t, pending)
+
+		require.Equal(t, [][]string{{"op1", "op2"}, {"op3", "op4"}, {"op5", "op6"}, {"op7"}}, anchoredOperations(t, ctx))
+	})
+
+	t.Run("batch fails", func(t *testing.T) {
+		ctx := newMockContext()
+		ctx.ProtocolClient.Protocol.MaxOperationsPerBatch = 2
+
+		for _, op := range generateOperations(6) {
+			_, err := ctx.OpQueue.Add(op)
+			require.NoError(t, err)
+		}
+
+		// the batch file of the second batch can't be written
+		cas := &failingCAS{MockCasClient: ctx.CasClient, fail: []byte(docutil.EncodeToString([]byte("op3")))}
+
+		writer, err := New("test", &casContext{mockContext: ctx, cas: cas}, WithConcurrentBatches(3), WithOrphanCleanup())
+		require.NoError(t, err)
+
+		n, pending, err := writer.cutAndProcess(false)
+		require.EqualError(t, err, "CAS error")
+		require.Equal(t, 2, n)
+		require.Equal(t, uint(4), pending)
+
+		// the files of the third batch were deleted since its anchor must be written after the second anchor
+		require.Equal(t, [][]string{{"op1", "op2"}}, anchoredOperations(t, ctx))
+		require.Equal(t, 2, ctx.CasClient.Len())
+		require.Equal(t, uint(4), ctx.OpQueue.Len())
+
+		cas.fail = nil
+
+		n, pending, err = writer.cutAndProcess(false)
+		require.NoError(t, err)
+		require.Equal(t, 4, n)
+		require.Zero(t, pending)
+
+		require.Equal(t, [][]string{{"op1", "op2"}, {"op3", "op4"}, {"op5", "op6"}}, anchoredOperations(t, ctx))
+	})
+
+	t.Run("blockchain error", func(t *testing.T) {
+		ctx := newMockContext()
+		ctx.ProtocolClient.Protocol.MaxOperationsPerBatch = 2
+		ctx.BlockchainClient = mocks.NewMockBlockchainClient(fmt.Errorf("blockchain error"))
+
+		for _, op := range generateOperations(4) {
+			_, err := ctx.OpQueue.Add(op)
+			require.NoError(t, err)
+		}
+
+		writer, err := New("test", ctx, WithConcurrentBatches(2), WithOrphanCleanup())
+		require.NoError(t, err)
+
+		n, pending, err := writer.cutAndProcess(false)
+		require.EqualError(t, err, "blockchain error")
+		require.Zero(t, n)
+		require.Equal(t, uint(4), pending)
+
+		// the files of the first batch are kept since its anchor may have been written
+		require.Equal(t, 2, ctx.CasClient.Len())
+		require.Equal(t, uint(4), ctx.OpQueue.Len())
+	})
+}
+
+// anchoredOperations returns the operations of each anchored batch
+func anchoredOperations(t *testing.T, ctx *mockContext) [][]string {
+	var batches [][]string
+
+	for _, anchor := range ctx.BlockchainClient.GetAnchors() {
+		bytes, err := ctx.CasClient.Read(anchor)
+		require.NoError(t, err)
+
+		var af filehandler.AnchorFile
+		require.NoError(t, json.Unmarshal(bytes, &af))
+
+		bytes, err = ctx.CasClient.Read(af.BatchFileHash)
+		require.NoError(t, err)
+
+		var bf filehandler.BatchFile
+		require.NoError(t, json.Unmarshal(bytes, &bf))
+
+		var ops []string
+
+		for _, op := range bf.Operations {
+			decoded, err := docutil.DecodeString(op)
+			require.NoError(t, err)
+
+			ops = append(ops, string(decoded))
+		}
+
+		batches = append(batches, ops)
+	}
+
+	return batches
+}
+
+// failingCAS fails to write content that contains the given bytes
+type failingCAS struct {
+	*mocks.MockCasClient
+	fail []byte
+}
+
+func (c *failingCAS) Write(content []byte) (string, error) {
+	if c.fail != nil && bytes.Contains(content, c.fail) {
+		return "", errors.New("CAS error")
+	}
+
+	return c.MockCasClient.Write(content)
+}
+
+type casContext struct {
+	*mockContext
+	cas CASClient
+}
+
+func (c *casContext) CAS() CASClient {
+	return c.cas
+}
+
+type anchorFileErrHandler struct {
+	OperationHandler
+}
+
+func (h *anchorFileErrHandler) CreateAnchorFile([]string, string) ([]byte, error) {
+	return nil, errors.New("anchor file error")
+}
+
 func TestAddAfterStop(t *testing.T) {
 	writer, err := New("test", newMockContext())
 	require.Nil(t, err)
@@ -317,7 +779,7 @@ func withError() Option {
 func generateOperations(numOfOperations int) (ops []*batch.OperationInfo) {
 	for j := 1; j <= numOfOperations; j++ {
 		op := &batch.OperationInfo{
-			UniqueSuffix: string(j),
+			UniqueSuffix: fmt.Sprintf("%d", j),
 			Data:         []byte(fmt.Sprintf("op%d", j)),
 		}
 		ops = append(ops, op)
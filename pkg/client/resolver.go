@@ -0,0 +1,70 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+var (
+	// ErrInvalidRequest is returned if the DID or the operation request is malformed
+	ErrInvalidRequest = errors.New("invalid request")
+
+	// ErrNotFound is returned if the document doesn't exist
+	ErrNotFound = errors.New("document not found")
+
+	// ErrDeactivated is returned if the document was deactivated
+	ErrDeactivated = errors.New("document was deactivated")
+)
+
+// Resolver resolves documents in-process, i.e. without a round trip through the REST API. It is implemented
+// by the document handler (see dochandler.DocumentHandler) so that applications which embed a Sidetree node
+// (e.g. a VDR driver) may use the document handler directly.
+//
+// The returned errors wrap ErrInvalidRequest, ErrNotFound or ErrDeactivated where applicable.
+type Resolver interface {
+	Resolve(ctx context.Context, did string, opts ...ResolveOption) (*document.ResolutionResult, error)
+}
+
+// OperationApplier submits operation requests in-process. It is implemented by the document handler.
+//
+// The request is the JSON-encoded operation request (i.e. the body of a request to the operations endpoint).
+// A resolution result is only returned for create operations. Parse and validation errors wrap ErrInvalidRequest.
+type OperationApplier interface {
+	ApplyOperation(ctx context.Context, request []byte) (*document.ResolutionResult, error)
+}
+
+// ResolveOptions contains the options for a resolution request
+type ResolveOptions struct {
+	// IncludeSkippedOperations includes the operations that were skipped during resolution (debugging information)
+	// in the method metadata. Skipped operations are not included by default.
+	IncludeSkippedOperations bool
+}
+
+// ResolveOption is a resolution option
+type ResolveOption func(opts *ResolveOptions)
+
+// WithSkippedOperations includes the operations that were skipped during resolution in the method metadata
+func WithSkippedOperations() ResolveOption {
+	return func(opts *ResolveOptions) {
+		opts.IncludeSkippedOperations = true
+	}
+}
+
+// GetResolveOptions returns the resolution options for the given option functions
+func GetResolveOptions(opts ...ResolveOption) *ResolveOptions {
+	options := &ResolveOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
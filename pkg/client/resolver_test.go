@@ -0,0 +1,18 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetResolveOptions(t *testing.T) {
+	require.False(t, GetResolveOptions().IncludeSkippedOperations)
+	require.True(t, GetResolveOptions(WithSkippedOperations()).IncludeSkippedOperations)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package client provides typed operation statuses for applications that submit operations to a Sidetree node,
+// so that application code doesn't need to parse error strings.
+//
+// A status is derived from the response to the operation request (StatusFromResponse), from the method metadata
+// of a resolution result (StatusFromResolution) and from the lifecycle events of the batch writer
+// (StatusFromEvent). A Tracker combines these statuses for a single operation and reports the operation as
+// expired if it isn't anchored within a given time.
+//
+// Applications that embed a Sidetree node may resolve documents and submit operations in-process (without
+// the REST API) using the Resolver and OperationApplier interfaces, which are implemented by the document handler.
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// Status is the status of an operation
+type Status string
+
+const (
+	// StatusQueued indicates that the operation was accepted by the node but has not been anchored yet
+	StatusQueued Status = "queued"
+
+	// StatusAnchored indicates that the operation was anchored
+	StatusAnchored Status = "anchored"
+
+	// StatusRejected indicates that the node rejected the operation
+	StatusRejected Status = "rejected"
+
+	// StatusExpired indicates that the operation was not anchored within the expected time
+	StatusExpired Status = "expired"
+)
+
+// ErrExpired is returned by a tracker if the operation was not anchored within the expected time
+var ErrExpired = errors.New("operation expired before it was anchored")
+
+// OperationStatus is the status of an operation
+type OperationStatus struct {
+	Status Status
+
+	// AnchorAddress is the address of the anchor file (anchored only and only if known)
+	AnchorAddress string
+
+	// Rejection contains the reason that the operation was rejected (rejected only)
+	Rejection *RejectedError
+}
+
+// Err returns the typed error for the status: *RejectedError if the operation was rejected, ErrExpired if
+// the operation expired, otherwise nil
+func (s *OperationStatus) Err() error {
+	switch s.Status {
+	case StatusRejected:
+		return s.Rejection
+	case StatusExpired:
+		return ErrExpired
+	default:
+		return nil
+	}
+}
+
+// IsFinal returns true if the status will not change
+func (s *OperationStatus) IsFinal() bool {
+	return s.Status == StatusAnchored || s.Status == StatusRejected
+}
+
+// RejectedError is returned when the node rejected an operation
+type RejectedError struct {
+	// HTTPStatus is the HTTP status code of the response. It is zero if the operation was rejected by the
+	// batch writer after it was accepted.
+	HTTPStatus int
+
+	// Code is the error code returned by the node (e.g. "unauthorized", "rate_limited"). It is empty if the
+	// node didn't return an error code.
+	Code string
+
+	// Reason is the error message returned by the node
+	Reason string
+}
+
+// Error returns the error message
+func (e *RejectedError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("operation rejected (%d %s): %s", e.HTTPStatus, e.Code, e.Reason)
+	}
+
+	if e.HTTPStatus != 0 {
+		return fmt.Sprintf("operation rejected (%d): %s", e.HTTPStatus, e.Reason)
+	}
+
+	return fmt.Sprintf("operation rejected: %s", e.Reason)
+}
+
+// Retryable returns true if the operation may be accepted if it is submitted again later
+func (e *RejectedError) Retryable() bool {
+	return e.HTTPStatus == http.StatusTooManyRequests || e.HTTPStatus >= http.StatusInternalServerError
+}
+
+// StatusFromResponse returns the status of an operation from the HTTP response to the operation request
+func StatusFromResponse(httpStatus int, body []byte) *OperationStatus {
+	if httpStatus == http.StatusOK {
+		return &OperationStatus{Status: StatusQueued}
+	}
+
+	rejection := &RejectedError{HTTPStatus: httpStatus}
+
+	var errResp common.ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Message != "" {
+		rejection.Code = errResp.Code
+		rejection.Reason = errResp.Message
+	} else {
+		rejection.Reason = strings.TrimSpace(string(body))
+	}
+
+	return &OperationStatus{Status: StatusRejected, Rejection: rejection}
+}
+
+// StatusFromResolution returns the status of the latest operation of a document from its resolution result
+func StatusFromResolution(result *document.ResolutionResult) *OperationStatus {
+	if result.MethodMetadata.Published {
+		return &OperationStatus{Status: StatusAnchored}
+	}
+
+	return &OperationStatus{Status: StatusQueued}
+}
+
+// StatusFromEvent returns the status of an operation from a batch writer lifecycle event. Note that failed
+// events are reported as queued since the batch writer retries failed batches.
+func StatusFromEvent(event *batch.OperationEvent) *OperationStatus {
+	switch event.State {
+	case batch.OperationStateAnchored:
+		return &OperationStatus{Status: StatusAnchored, AnchorAddress: event.AnchorAddress}
+	case batch.OperationStateRejected:
+		rejection := &RejectedError{}
+		if event.Error != nil {
+			rejection.Reason = event.Error.Error()
+		}
+
+		return &OperationStatus{Status: StatusRejected, Rejection: rejection}
+	default:
+		return &OperationStatus{Status: StatusQueued}
+	}
+}
+
+// Tracker combines the statuses of a single operation. Once the operation is anchored or rejected
+// its status no longer changes.
+type Tracker struct {
+	mutex       sync.RWMutex
+	status      *OperationStatus
+	submittedAt time.Time
+	timeout     time.Duration
+	now         func() time.Time
+}
+
+// NewTracker returns a tracker for an operation that was just submitted. The operation is reported
+// as expired if it isn't anchored within the given timeout (zero means the operation never expires).
+func NewTracker(timeout time.Duration) *Tracker {
+	return &Tracker{
+		status:      &OperationStatus{Status: StatusQueued},
+		submittedAt: time.Now(),
+		timeout:     timeout,
+		now:         time.Now,
+	}
+}
+
+// Update updates the status of the operation. The update is ignored if the current status is final.
+func (t *Tracker) Update(status *OperationStatus) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.status.IsFinal() {
+		return
+	}
+
+	t.status = status
+}
+
+// Status returns the current status of the operation
+func (t *Tracker) Status() *OperationStatus {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if t.status.Status == StatusQueued && t.timeout > 0 && t.now().Sub(t.submittedAt) >= t.timeout {
+		return &OperationStatus{Status: StatusExpired}
+	}
+
+	return t.status
+}
+
+// Err returns the typed error for the current status (see OperationStatus.Err)
+func (t *Tracker) Err() error {
+	return t.Status().Err()
+}
@@ -0,0 +1,125 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+func TestStatusFromResponse(t *testing.T) {
+	t.Run("accepted", func(t *testing.T) {
+		s := StatusFromResponse(http.StatusOK, []byte(`{}`))
+		require.Equal(t, StatusQueued, s.Status)
+		require.NoError(t, s.Err())
+		require.False(t, s.IsFinal())
+	})
+
+	t.Run("rejected with error code", func(t *testing.T) {
+		s := StatusFromResponse(http.StatusTooManyRequests, []byte(`{"code":"rate_limited","message":"rate limit exceeded"}`))
+		require.Equal(t, StatusRejected, s.Status)
+		require.True(t, s.IsFinal())
+
+		var rejected *RejectedError
+		require.True(t, errors.As(s.Err(), &rejected))
+		require.Equal(t, http.StatusTooManyRequests, rejected.HTTPStatus)
+		require.Equal(t, "rate_limited", rejected.Code)
+		require.Equal(t, "rate limit exceeded", rejected.Reason)
+		require.True(t, rejected.Retryable())
+		require.EqualError(t, rejected, "operation rejected (429 rate_limited): rate limit exceeded")
+	})
+
+	t.Run("rejected without error code", func(t *testing.T) {
+		s := StatusFromResponse(http.StatusBadRequest, []byte("bad request: missing signed data\n"))
+		require.Equal(t, StatusRejected, s.Status)
+
+		var rejected *RejectedError
+		require.True(t, errors.As(s.Err(), &rejected))
+		require.Empty(t, rejected.Code)
+		require.Equal(t, "bad request: missing signed data", rejected.Reason)
+		require.False(t, rejected.Retryable())
+		require.EqualError(t, rejected, "operation rejected (400): bad request: missing signed data")
+	})
+}
+
+func TestStatusFromResolution(t *testing.T) {
+	result := &document.ResolutionResult{}
+	require.Equal(t, StatusQueued, StatusFromResolution(result).Status)
+
+	result.MethodMetadata.Published = true
+	require.Equal(t, StatusAnchored, StatusFromResolution(result).Status)
+}
+
+func TestStatusFromEvent(t *testing.T) {
+	require.Equal(t, StatusQueued, StatusFromEvent(&batch.OperationEvent{State: batch.OperationStateQueued}).Status)
+	require.Equal(t, StatusQueued, StatusFromEvent(&batch.OperationEvent{State: batch.OperationStateBatched}).Status)
+	require.Equal(t, StatusQueued, StatusFromEvent(&batch.OperationEvent{State: batch.OperationStateFailed}).Status)
+
+	s := StatusFromEvent(&batch.OperationEvent{State: batch.OperationStateRejected, Error: errors.New("too large")})
+	require.Equal(t, StatusRejected, s.Status)
+	require.True(t, s.IsFinal())
+	require.False(t, s.Rejection.Retryable())
+	require.EqualError(t, s.Err(), "operation rejected: too large")
+
+	s = StatusFromEvent(&batch.OperationEvent{State: batch.OperationStateAnchored, AnchorAddress: "anchor"})
+	require.Equal(t, StatusAnchored, s.Status)
+	require.Equal(t, "anchor", s.AnchorAddress)
+}
+
+func TestTracker(t *testing.T) {
+	t.Run("anchored", func(t *testing.T) {
+		tracker := NewTracker(time.Minute)
+		require.Equal(t, StatusQueued, tracker.Status().Status)
+
+		tracker.Update(StatusFromEvent(&batch.OperationEvent{State: batch.OperationStateAnchored, AnchorAddress: "anchor"}))
+		require.Equal(t, StatusAnchored, tracker.Status().Status)
+		require.NoError(t, tracker.Err())
+
+		// final status doesn't change
+		tracker.Update(&OperationStatus{Status: StatusQueued})
+		require.Equal(t, StatusAnchored, tracker.Status().Status)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		tracker := NewTracker(time.Minute)
+		tracker.Update(StatusFromResponse(http.StatusForbidden, []byte(`{"code":"forbidden","message":"forbidden"}`)))
+
+		var rejected *RejectedError
+		require.True(t, errors.As(tracker.Err(), &rejected))
+		require.Equal(t, "forbidden", rejected.Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		now := time.Now()
+
+		tracker := NewTracker(time.Minute)
+		tracker.submittedAt = now
+		tracker.now = func() time.Time { return now }
+		require.Equal(t, StatusQueued, tracker.Status().Status)
+
+		now = now.Add(time.Minute)
+		require.Equal(t, StatusExpired, tracker.Status().Status)
+		require.True(t, errors.Is(tracker.Err(), ErrExpired))
+
+		// anchored operations don't expire
+		tracker.Update(&OperationStatus{Status: StatusAnchored})
+		require.Equal(t, StatusAnchored, tracker.Status().Status)
+	})
+
+	t.Run("no timeout", func(t *testing.T) {
+		tracker := NewTracker(0)
+		tracker.now = func() time.Time { return time.Now().Add(time.Hour) }
+		require.Equal(t, StatusQueued, tracker.Status().Status)
+	})
+}
@@ -8,6 +8,7 @@ package composer
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -17,13 +18,18 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
 )
 
-// ApplyPatches applies patches to the document
-func ApplyPatches(doc document.Document, patches []patch.Patch) (document.Document, error) {
+// ApplyPatches applies patches to the document. The patches are validated using the given options.
+func ApplyPatches(doc document.Document, patches []patch.Patch, opts ...document.ValidationOpt) (document.Document, error) {
 	var err error
 
-	for _, p := range patches {
-		doc, err = applyPatch(doc, p)
+	for i, p := range patches {
+		doc, err = applyPatch(doc, p, opts...)
 		if err != nil {
+			var ve *patch.ValidationError
+			if errors.As(err, &ve) {
+				return nil, &patch.ValidationError{Index: i, Path: ve.Path, Err: ve.Err}
+			}
+
 			return nil, err
 		}
 	}
@@ -32,8 +38,8 @@ func ApplyPatches(doc document.Document, patches []patch.Patch) (document.Docume
 }
 
 // applyPatch applies a patch to the document
-func applyPatch(doc document.Document, p patch.Patch) (document.Document, error) {
-	if err := p.Validate(); err != nil {
+func applyPatch(doc document.Document, p patch.Patch, opts ...document.ValidationOpt) (document.Document, error) {
+	if err := p.Validate(opts...); err != nil {
 		return nil, err
 	}
 
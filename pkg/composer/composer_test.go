@@ -276,6 +276,25 @@ func TestApplyPatches_AddServiceEndpoints(t *testing.T) {
 		diddoc := document.DidDocumentFromJSONLDObject(doc)
 		require.Equal(t, 3, len(diddoc.Services()))
 	})
+	t.Run("character policy", func(t *testing.T) {
+		doc, err := setupDefaultDoc()
+		require.NoError(t, err)
+
+		policy := document.WithCharacterPolicy(document.CharacterPolicy{AllowNonASCII: true})
+
+		addServices, err := patch.NewAddServiceEndpointsPatch(
+			`[{"id": "hub", "type": "IdentitätHub", "serviceEndpoint": "https://example.com/hub"}]`, policy)
+		require.NoError(t, err)
+
+		result, err := ApplyPatches(doc, []patch.Patch{addServices})
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "non-ASCII character U+00E4 is not allowed")
+
+		result, err = ApplyPatches(doc, []patch.Patch{addServices}, policy)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(document.DidDocumentFromJSONLDObject(result).Services()))
+	})
 	t.Run("invalid json", func(t *testing.T) {
 		doc, err := setupDefaultDoc()
 		require.NoError(t, err)
@@ -0,0 +1,232 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package consistency re-derives documents from the raw operations in the operation store and compares them
+// against the documents that were previously materialized (e.g. cached or stored by an embedder). It is intended
+// to be used as a safety net after bug fixes or store migrations in order to detect documents that need to be
+// re-materialized.
+package consistency
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/canonicalizer"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+// Resolver derives the document for the given unique suffix from the raw operations (e.g. processor.OperationProcessor)
+type Resolver interface {
+	Resolve(uniqueSuffix string) (*document.ResolutionResult, error)
+}
+
+// MaterializedStore returns the materialized document for the given unique suffix. Nil (with no error)
+// should be returned if the document has not been materialized.
+type MaterializedStore interface {
+	Get(uniqueSuffix string) (*document.ResolutionResult, error)
+}
+
+// Divergence describes a difference between the document derived from the raw operations and the materialized document
+type Divergence struct {
+	// UniqueSuffix is the unique suffix of the document
+	UniqueSuffix string
+
+	// Reason describes the divergence
+	Reason string
+
+	// Expected is the document derived from the raw operations (nil if the document was deactivated)
+	Expected *document.ResolutionResult
+
+	// Actual is the materialized document
+	Actual *document.ResolutionResult
+}
+
+// Report contains the results of a consistency check
+type Report struct {
+	// Checked is the number of documents that were compared
+	Checked int
+
+	// Skipped contains the unique suffixes of documents that were not materialized
+	Skipped []string
+
+	// Divergences contains the documents that are not consistent
+	Divergences []*Divergence
+}
+
+// Consistent returns true if no divergences were found
+func (r *Report) Consistent() bool {
+	return len(r.Divergences) == 0
+}
+
+// Option is a consistency checker option
+type Option func(c *Checker)
+
+// WithSampleSize checks a random sample of the given size instead of all of the provided documents
+func WithSampleSize(size int) Option {
+	return func(c *Checker) {
+		c.sampleSize = size
+	}
+}
+
+// WithRand sets the random source used for sampling (mainly used for deterministic tests)
+func WithRand(r *rand.Rand) Option {
+	return func(c *Checker) {
+		c.rand = r
+	}
+}
+
+// WithLogger sets the logger of the consistency checker
+func WithLogger(logger log.Logger) Option {
+	return func(c *Checker) {
+		c.logger = logger
+	}
+}
+
+// Checker compares documents derived from raw operations against materialized documents
+type Checker struct {
+	resolver   Resolver
+	store      MaterializedStore
+	sampleSize int
+	rand       *rand.Rand
+	logger     log.Logger
+}
+
+// New returns a new consistency checker
+func New(resolver Resolver, store MaterializedStore, opts ...Option) *Checker {
+	c := &Checker{
+		resolver: resolver,
+		store:    store,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+		logger:   log.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Check compares the documents for the given unique suffixes (or a random sample of them if a sample size is set)
+// and reports the divergences
+func (c *Checker) Check(uniqueSuffixes []string) (*Report, error) {
+	report := &Report{}
+
+	for _, suffix := range c.sample(uniqueSuffixes) {
+		actual, err := c.store.Get(suffix)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to get materialized document [%s]", suffix)
+		}
+
+		if actual == nil {
+			report.Skipped = append(report.Skipped, suffix)
+			continue
+		}
+
+		divergence, err := c.check(suffix, actual)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Checked++
+
+		if divergence != nil {
+			c.logger.Warnf("Document [%s] is not consistent: %s", suffix, divergence.Reason)
+			report.Divergences = append(report.Divergences, divergence)
+		}
+	}
+
+	c.logger.Infof("Consistency check completed. Checked: %d, Skipped: %d, Divergences: %d", report.Checked, len(report.Skipped), len(report.Divergences))
+
+	return report, nil
+}
+
+func (c *Checker) check(suffix string, actual *document.ResolutionResult) (*Divergence, error) {
+	expected, err := c.resolver.Resolve(suffix)
+	if err != nil {
+		if strings.Contains(err.Error(), "was deactivated") {
+			return &Divergence{UniqueSuffix: suffix, Reason: "document was deactivated", Actual: actual}, nil
+		}
+
+		if strings.Contains(err.Error(), "not found") {
+			return &Divergence{UniqueSuffix: suffix, Reason: "document not found in operation store", Actual: actual}, nil
+		}
+
+		return nil, errors.WithMessagef(err, "failed to resolve document [%s]", suffix)
+	}
+
+	reason, err := compare(expected, actual)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to compare document [%s]", suffix)
+	}
+
+	if reason == "" {
+		return nil, nil
+	}
+
+	return &Divergence{UniqueSuffix: suffix, Reason: reason, Expected: expected, Actual: actual}, nil
+}
+
+func (c *Checker) sample(uniqueSuffixes []string) []string {
+	if c.sampleSize <= 0 || c.sampleSize >= len(uniqueSuffixes) {
+		return uniqueSuffixes
+	}
+
+	sample := make([]string, c.sampleSize)
+	for i, j := range c.rand.Perm(len(uniqueSuffixes))[:c.sampleSize] {
+		sample[i] = uniqueSuffixes[j]
+	}
+
+	return sample
+}
+
+// compare compares the document, recovery key and DID type of the expected and actual results and returns
+// the reason for the divergence or empty string if they are the same
+func compare(expected, actual *document.ResolutionResult) (string, error) {
+	equal, err := canonicallyEqual(expected.Document, actual.Document)
+	if err != nil {
+		return "", err
+	}
+
+	if !equal {
+		return "document content differs", nil
+	}
+
+	equal, err = canonicallyEqual(expected.MethodMetadata.RecoveryKey, actual.MethodMetadata.RecoveryKey)
+	if err != nil {
+		return "", err
+	}
+
+	if !equal {
+		return "recovery key differs", nil
+	}
+
+	if expected.MethodMetadata.Type != actual.MethodMetadata.Type {
+		return "DID type differs", nil
+	}
+
+	return "", nil
+}
+
+func canonicallyEqual(v1, v2 interface{}) (bool, error) {
+	b1, err := canonicalizer.MarshalCanonical(v1)
+	if err != nil {
+		return false, fmt.Errorf("marshal expected: %s", err)
+	}
+
+	b2, err := canonicalizer.MarshalCanonical(v2)
+	if err != nil {
+		return false, fmt.Errorf("marshal actual: %s", err)
+	}
+
+	return bytes.Equal(b1, b2), nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package consistency
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+func TestChecker_Check(t *testing.T) {
+	recoveryKey := &jws.JWK{Kty: "EC", Crv: "P-256", X: "x", Y: "y"}
+
+	resolver := &mockResolver{
+		results: map[string]*document.ResolutionResult{
+			"s1": newResult(`{"id":"s1","name":"a"}`, recoveryKey),
+			"s2": newResult(`{"id":"s2","name":"b"}`, recoveryKey),
+			"s3": newResult(`{"id":"s3","name":"c"}`, recoveryKey),
+		},
+		errors: map[string]error{
+			"deactivated": errors.New("document was deactivated"),
+			"missing":     errors.New("uniqueSuffix not found in the store"),
+		},
+	}
+
+	t.Run("consistent", func(t *testing.T) {
+		store := &mockStore{results: map[string]*document.ResolutionResult{
+			"s1": newResult(`{"name":"a","id":"s1"}`, recoveryKey),
+			"s2": newResult(`{"id":"s2","name":"b"}`, recoveryKey),
+		}}
+
+		report, err := New(resolver, store).Check([]string{"s1", "s2", "s3"})
+		require.NoError(t, err)
+		require.True(t, report.Consistent())
+		require.Equal(t, 2, report.Checked)
+		require.Equal(t, []string{"s3"}, report.Skipped)
+	})
+	t.Run("with logger", func(t *testing.T) {
+		l, hook := logtest.NewNullLogger()
+
+		store := &mockStore{results: map[string]*document.ResolutionResult{
+			"s1": newResult(`{"id":"s1","name":"a"}`, recoveryKey),
+		}}
+
+		_, err := New(resolver, store, WithLogger(log.New(l))).Check([]string{"s1", "s3"})
+		require.NoError(t, err)
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		require.Equal(t, "Consistency check completed. Checked: 1, Skipped: 1, Divergences: 0", entry.Message)
+	})
+	t.Run("divergences", func(t *testing.T) {
+		store := &mockStore{results: map[string]*document.ResolutionResult{
+			"s1":          newResult(`{"id":"s1","name":"x"}`, recoveryKey),
+			"s2":          newResult(`{"id":"s2","name":"b"}`, &jws.JWK{Kty: "EC", Crv: "P-256", X: "x2", Y: "y2"}),
+			"s3":          newResult(`{"id":"s3","name":"c"}`, recoveryKey),
+			"deactivated": newResult(`{"id":"deactivated"}`, recoveryKey),
+			"missing":     newResult(`{"id":"missing"}`, recoveryKey),
+		}}
+
+		report, err := New(resolver, store).Check([]string{"s1", "s2", "s3", "deactivated", "missing"})
+		require.NoError(t, err)
+		require.False(t, report.Consistent())
+		require.Equal(t, 5, report.Checked)
+		require.Len(t, report.Divergences, 4)
+
+		reasons := make(map[string]string)
+		for _, d := range report.Divergences {
+			reasons[d.UniqueSuffix] = d.Reason
+		}
+
+		require.Equal(t, "document content differs", reasons["s1"])
+		require.Equal(t, "recovery key differs", reasons["s2"])
+		require.Equal(t, "document was deactivated", reasons["deactivated"])
+		require.Equal(t, "document not found in operation store", reasons["missing"])
+	})
+	t.Run("sample", func(t *testing.T) {
+		store := &mockStore{results: map[string]*document.ResolutionResult{
+			"s1": newResult(`{"id":"s1","name":"a"}`, recoveryKey),
+			"s2": newResult(`{"id":"s2","name":"b"}`, recoveryKey),
+			"s3": newResult(`{"id":"s3","name":"c"}`, recoveryKey),
+		}}
+
+		c := New(resolver, store, WithSampleSize(2), WithRand(rand.New(rand.NewSource(1)))) //nolint:gosec
+
+		report, err := c.Check([]string{"s1", "s2", "s3"})
+		require.NoError(t, err)
+		require.True(t, report.Consistent())
+		require.Equal(t, 2, report.Checked)
+	})
+	t.Run("store error", func(t *testing.T) {
+		errExpected := errors.New("store error")
+
+		report, err := New(resolver, &mockStore{err: errExpected}).Check([]string{"s1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Nil(t, report)
+	})
+	t.Run("resolve error", func(t *testing.T) {
+		r := &mockResolver{errors: map[string]error{"s1": errors.New("resolve error")}}
+		store := &mockStore{results: map[string]*document.ResolutionResult{
+			"s1": newResult(`{"id":"s1"}`, recoveryKey),
+		}}
+
+		report, err := New(r, store).Check([]string{"s1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolve error")
+		require.Nil(t, report)
+	})
+	t.Run("compare error", func(t *testing.T) {
+		r := &mockResolver{results: map[string]*document.ResolutionResult{
+			"s1": {Document: document.Document{"invalid": make(chan int)}},
+		}}
+		store := &mockStore{results: map[string]*document.ResolutionResult{
+			"s1": newResult(`{"id":"s1"}`, recoveryKey),
+		}}
+
+		report, err := New(r, store).Check([]string{"s1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to compare document [s1]")
+		require.Nil(t, report)
+	})
+}
+
+func newResult(doc string, recoveryKey *jws.JWK) *document.ResolutionResult {
+	d, err := document.FromBytes([]byte(doc))
+	if err != nil {
+		panic(err)
+	}
+
+	return &document.ResolutionResult{
+		Document:       d,
+		MethodMetadata: document.MethodMetadata{RecoveryKey: recoveryKey},
+	}
+}
+
+type mockResolver struct {
+	results map[string]*document.ResolutionResult
+	errors  map[string]error
+}
+
+func (m *mockResolver) Resolve(uniqueSuffix string) (*document.ResolutionResult, error) {
+	if err, ok := m.errors[uniqueSuffix]; ok {
+		return nil, err
+	}
+
+	return m.results[uniqueSuffix], nil
+}
+
+type mockStore struct {
+	results map[string]*document.ResolutionResult
+	err     error
+}
+
+func (m *mockStore) Get(uniqueSuffix string) (*document.ResolutionResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.results[uniqueSuffix], nil
+}
@@ -0,0 +1,35 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didvalidator
+
+import (
+	"testing"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler/transformertest"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+// TestTransformDocument_Golden checks the transformed documents against the golden files in
+// testdata/transformer/golden. Run with UPDATE_GOLDEN=true to regenerate the golden files after an intended change.
+func TestTransformDocument_Golden(t *testing.T) {
+	suite := transformertest.NewSuite("testdata/transformer/input", "testdata/transformer/golden")
+
+	// golden files are kept per protocol version (starting blockchain time) so that a protocol version which changes
+	// the public representation of documents doesn't alter the output for earlier versions
+	versions := []struct {
+		version     string
+		transformer transformertest.Transformer
+	}{
+		{version: "0", transformer: New(mocks.NewMockOperationStore(nil))},
+	}
+
+	for _, v := range versions {
+		t.Run(v.version, func(t *testing.T) {
+			suite.Run(t, v.version, v.transformer)
+		})
+	}
+}
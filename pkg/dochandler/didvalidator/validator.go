@@ -12,6 +12,7 @@ import (
 	"github.com/btcsuite/btcutil/base58"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	internaljws "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
@@ -30,6 +31,19 @@ const (
 // Validator is responsible for validating did operations and sidetree rules
 type Validator struct {
 	store OperationStoreClient
+	pc    protocol.Client
+}
+
+// Option is a did validator option
+type Option func(v *Validator)
+
+// WithProtocolClient sets the protocol client whose current version provides the policy for characters that are
+// permitted in document string fields (service types and endpoints). If not set, only printable ASCII characters
+// are permitted.
+func WithProtocolClient(pc protocol.Client) Option {
+	return func(v *Validator) {
+		v.pc = pc
+	}
 }
 
 // OperationStoreClient defines interface for retrieving all operations related to document
@@ -40,10 +54,30 @@ type OperationStoreClient interface {
 }
 
 // New creates a new did validator
-func New(store OperationStoreClient) *Validator {
-	return &Validator{
+func New(store OperationStoreClient, opts ...Option) *Validator {
+	v := &Validator{
 		store: store,
 	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// characterPolicy returns the character policy of the current protocol version
+func (v *Validator) characterPolicy() (document.CharacterPolicy, error) {
+	if v.pc == nil {
+		return document.DefaultCharacterPolicy(), nil
+	}
+
+	pv, err := v.pc.Current()
+	if err != nil {
+		return document.CharacterPolicy{}, err
+	}
+
+	return pv.Protocol().CharacterPolicy, nil
 }
 
 // IsValidPayload verifies that the given payload is a valid Sidetree specific payload
@@ -90,7 +124,12 @@ func (v *Validator) IsValidOriginalDocument(payload []byte) error {
 	}
 
 	// Sidetree rule: validate services
-	if err := document.ValidateServices(didDoc.Services()); err != nil {
+	policy, err := v.characterPolicy()
+	if err != nil {
+		return err
+	}
+
+	if err := document.ValidateServices(didDoc.Services(), document.WithCharacterPolicy(policy)); err != nil {
 		return err
 	}
 
@@ -10,6 +10,7 @@ import (
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -49,6 +50,32 @@ func TestIsValidOriginalDocument_ServiceErrors(t *testing.T) {
 	require.Contains(t, err.Error(), "service id is missing")
 }
 
+func TestIsValidOriginalDocument_CharacterPolicy(t *testing.T) {
+	t.Run("error - non-ASCII service endpoint with default policy", func(t *testing.T) {
+		v := getDefaultValidator()
+
+		err := v.IsValidOriginalDocument(serviceNonASCIIEndpoint)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "service endpoint: non-ASCII character")
+	})
+	t.Run("success - non-ASCII allowed", func(t *testing.T) {
+		pc := mocks.NewMockProtocolClient()
+		pc.Protocol.CharacterPolicy = document.CharacterPolicy{AllowNonASCII: true}
+
+		v := New(mocks.NewMockOperationStore(nil), WithProtocolClient(pc))
+
+		err := v.IsValidOriginalDocument(serviceNonASCIIEndpoint)
+		require.NoError(t, err)
+	})
+	t.Run("error - protocol client error", func(t *testing.T) {
+		pc := mocks.NewMockProtocolClient()
+		pc.Err = errors.New("injected protocol error")
+
+		err := New(mocks.NewMockOperationStore(nil), WithProtocolClient(pc)).IsValidOriginalDocument(serviceNonASCIIEndpoint)
+		require.EqualError(t, err, "injected protocol error")
+	})
+}
+
 func TestIsValidOriginalDocument_PublicKeyErrors(t *testing.T) {
 	v := getDefaultValidator()
 
@@ -289,6 +316,7 @@ var docWithContext = []byte(`{
 
 var pubKeyNoID = []byte(`{ "publicKey": [{"id": "", "type": "JwsVerificationKey2020"}]}`)
 var serviceNoID = []byte(`{ "service": [{"id": "", "type": "IdentityHub", "serviceEndpoint": "https://example.com/hub"}]}`)
+var serviceNonASCIIEndpoint = []byte(`{ "service": [{"id": "hub", "type": "IdentityHub", "serviceEndpoint": "https://bücher.example.com/hub"}]}`)
 var docWithID = []byte(`{ "id" : "001", "name": "John Smith" }`)
 
 var validUpdate = []byte(`{ "did_suffix": "abc" }`)
@@ -0,0 +1,159 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/request"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+// EquivalentIDProvider computes additional equivalent IDs of a document (e.g. hashlink-based or ledger-specific
+// identifiers) which are included in the equivalentId method metadata of the resolution result
+type EquivalentIDProvider interface {
+	EquivalentIDs(id string, result *document.ResolutionResult) ([]string, error)
+}
+
+// EquivalentIDProviderFunc is a function that implements EquivalentIDProvider
+type EquivalentIDProviderFunc func(id string, result *document.ResolutionResult) ([]string, error)
+
+// EquivalentIDs returns the equivalent IDs of the document with the given ID
+func (f EquivalentIDProviderFunc) EquivalentIDs(id string, result *document.ResolutionResult) ([]string, error) {
+	return f(id, result)
+}
+
+// WithEquivalentIDProvider adds a provider of equivalent IDs. The equivalent IDs of all of the providers
+// are included (in the order the providers were added) in the method metadata of the resolution result.
+func WithEquivalentIDProvider(provider EquivalentIDProvider) Option {
+	return func(opts *DocumentHandler) {
+		opts.equivalentIDProviders = append(opts.equivalentIDProviders, provider)
+	}
+}
+
+// WithAliases sets the aliases of the namespace (e.g. did:sidetree:mainnet for did:sidetree). IDs in an aliased
+// namespace are resolved the same as IDs in the namespace and the aliased IDs of a document are included in the
+// equivalentId method metadata of the resolution result. (Long-form IDs may only be resolved with an alias
+// of the same DID method since the name of the initial state parameter is derived from the method.)
+func WithAliases(aliases ...string) Option {
+	return func(opts *DocumentHandler) {
+		opts.aliases = append(opts.aliases, aliases...)
+	}
+}
+
+// addIDs sets the canonical ID of a published document and adds the equivalent IDs (the long-form ID if known,
+// the IDs in the aliased namespaces and the IDs computed by the registered providers) to the method metadata
+func (r *DocumentHandler) addIDs(id, longFormID string, published bool, result *document.ResolutionResult) error {
+	if published {
+		result.MethodMetadata.CanonicalID = id
+	}
+
+	uniqueSuffix := strings.TrimPrefix(id, r.namespace+docutil.NamespaceDelimiter)
+
+	equivalentIDs := []string{longFormID}
+	for _, alias := range r.aliases {
+		equivalentIDs = append(equivalentIDs, alias+docutil.NamespaceDelimiter+uniqueSuffix)
+	}
+
+	addEquivalentIDs(id, result, equivalentIDs)
+
+	for _, provider := range r.equivalentIDProviders {
+		ids, err := provider.EquivalentIDs(id, result)
+		if err != nil {
+			return fmt.Errorf("failed to compute equivalent IDs for [%s]: %s", id, err)
+		}
+
+		addEquivalentIDs(id, result, ids)
+	}
+
+	return nil
+}
+
+func addEquivalentIDs(id string, result *document.ResolutionResult, ids []string) {
+	for _, equivalentID := range ids {
+		if equivalentID == "" || equivalentID == id || contains(result.MethodMetadata.EquivalentID, equivalentID) {
+			continue
+		}
+
+		result.MethodMetadata.EquivalentID = append(result.MethodMetadata.EquivalentID, equivalentID)
+	}
+}
+
+// resolveAlias replaces the aliased namespace of the given ID with the namespace of the handler. The longest
+// matching namespace wins so that an alias may extend the namespace (or vice versa).
+func (r *DocumentHandler) resolveAlias(idOrInitialDoc string) string {
+	matched := r.namespace
+	if !strings.HasPrefix(idOrInitialDoc, matched+docutil.NamespaceDelimiter) {
+		matched = ""
+	}
+
+	for _, alias := range r.aliases {
+		if len(alias) > len(matched) && strings.HasPrefix(idOrInitialDoc, alias+docutil.NamespaceDelimiter) {
+			matched = alias
+		}
+	}
+
+	if matched == "" || matched == r.namespace {
+		return idOrInitialDoc
+	}
+
+	return r.namespace + idOrInitialDoc[len(matched):]
+}
+
+// longFormID returns the long-form ID (the ID with the initial state parameter) of the given create
+// operation or an empty string if the original create request isn't available
+func (r *DocumentHandler) longFormID(operation *batch.Operation) string {
+	var create model.CreateRequest
+	if err := json.Unmarshal(operation.OperationBuffer, &create); err != nil {
+		return ""
+	}
+
+	return r.getLongFormID(operation.ID, &create)
+}
+
+func (r *DocumentHandler) getLongFormID(id string, initial *model.CreateRequest) string {
+	if initial.SuffixData == "" || initial.Delta == "" {
+		return ""
+	}
+
+	return id + "?" + request.GetInitialStateParam(r.namespace) + "=" + initial.SuffixData + "." + initial.Delta
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifiedLongFormID returns the long-form ID of a resolution request if the initial state was provided and
+// it is the initial state of the document with the given unique suffix
+func (r *DocumentHandler) verifiedLongFormID(id, uniqueSuffix string, initial *model.CreateRequest) string {
+	if initial == nil {
+		return ""
+	}
+
+	pv, err := r.protocol.Current()
+	if err != nil {
+		return ""
+	}
+
+	suffix, err := r.suffixDeriver().DeriveSuffix(initial.SuffixData, pv.Protocol().HashAlgorithmInMultiHashCode)
+	if err != nil || suffix != uniqueSuffix {
+		return ""
+	}
+
+	return r.getLongFormID(id, initial)
+}
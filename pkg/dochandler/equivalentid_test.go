@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	batchapi "github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+func TestDocumentHandler_WithEquivalentIDProvider(t *testing.T) {
+	hashlinkProvider := EquivalentIDProviderFunc(func(id string, result *document.ResolutionResult) ([]string, error) {
+		return []string{id + ":hl", id}, nil
+	})
+
+	ledgerProvider := EquivalentIDProviderFunc(func(id string, result *document.ResolutionResult) ([]string, error) {
+		return []string{"", id + ":ledger", id + ":hl"}, nil
+	})
+
+	t.Run("published", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(getCreateOperation()))
+
+		dh := newSuffixTestHandler(store, WithEquivalentIDProvider(hashlinkProvider),
+			WithEquivalentIDProvider(ledgerProvider))
+
+		docID := getCreateOperation().ID
+
+		result, err := dh.ResolveDocument(docID)
+		require.NoError(t, err)
+		require.True(t, result.MethodMetadata.Published)
+		require.Equal(t, []string{docID + ":hl", docID + ":ledger"}, result.MethodMetadata.EquivalentID)
+	})
+
+	t.Run("unpublished", func(t *testing.T) {
+		dh := newSuffixTestHandler(mocks.NewMockOperationStore(nil), WithEquivalentIDProvider(hashlinkProvider))
+
+		createOp := getCreateOperation()
+
+		result, err := dh.ProcessOperation(createOp)
+		require.NoError(t, err)
+		require.False(t, result.MethodMetadata.Published)
+		require.Len(t, result.MethodMetadata.EquivalentID, 2)
+		require.True(t, strings.HasPrefix(result.MethodMetadata.EquivalentID[0], createOp.ID+initialStateParam))
+		require.Equal(t, createOp.ID+":hl", result.MethodMetadata.EquivalentID[1])
+	})
+
+	t.Run("no providers", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(getCreateOperation()))
+
+		result, err := newSuffixTestHandler(store).ResolveDocument(getCreateOperation().ID)
+		require.NoError(t, err)
+		require.Empty(t, result.MethodMetadata.EquivalentID)
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		errExpected := errors.New("injected provider error")
+
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(getCreateOperation()))
+
+		dh := newSuffixTestHandler(store, WithEquivalentIDProvider(
+			EquivalentIDProviderFunc(func(string, *document.ResolutionResult) ([]string, error) {
+				return nil, errExpected
+			}),
+		))
+
+		result, err := dh.ResolveDocument(getCreateOperation().ID)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "failed to compute equivalent IDs")
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestDocumentHandler_CanonicalAndEquivalentIDs(t *testing.T) {
+	const alias = "did:sidetree:mainnet"
+
+	createReq, err := getCreateRequest()
+	require.NoError(t, err)
+
+	docID := getCreateOperation().ID
+	uniqueSuffix := getCreateOperation().UniqueSuffix
+	longFormID := docID + initialStateParam + createReq.SuffixData + "." + createReq.Delta
+	aliasID := alias + docutil.NamespaceDelimiter + uniqueSuffix
+
+	t.Run("published - short-form", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(getCreateOperation()))
+
+		result, err := newSuffixTestHandler(store).ResolveDocument(docID)
+		require.NoError(t, err)
+		require.Equal(t, docID, result.MethodMetadata.CanonicalID)
+		require.Empty(t, result.MethodMetadata.EquivalentID)
+	})
+
+	t.Run("published - long-form", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(getCreateOperation()))
+
+		result, err := newSuffixTestHandler(store).ResolveDocument(longFormID)
+		require.NoError(t, err)
+		require.True(t, result.MethodMetadata.Published)
+		require.Equal(t, docID, result.MethodMetadata.CanonicalID)
+		require.Equal(t, []string{longFormID}, result.MethodMetadata.EquivalentID)
+	})
+
+	t.Run("published - long-form with initial state of another document", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(getCreateOperation()))
+
+		otherID := namespace + docutil.NamespaceDelimiter + "other"
+		require.NoError(t, store.Put(&batchapi.Operation{
+			Type:         batchapi.OperationTypeCreate,
+			ID:           otherID,
+			UniqueSuffix: "other",
+			Delta:        getCreateOperation().Delta,
+			SuffixData:   getCreateOperation().SuffixData,
+		}))
+
+		result, err := newSuffixTestHandler(store).ResolveDocument(
+			otherID + initialStateParam + createReq.SuffixData + "." + createReq.Delta)
+		require.NoError(t, err)
+		require.Equal(t, otherID, result.MethodMetadata.CanonicalID)
+		require.Empty(t, result.MethodMetadata.EquivalentID)
+	})
+
+	t.Run("unpublished - long-form", func(t *testing.T) {
+		result, err := newSuffixTestHandler(mocks.NewMockOperationStore(nil), WithAliases(alias)).ResolveDocument(longFormID)
+		require.NoError(t, err)
+		require.False(t, result.MethodMetadata.Published)
+		require.Empty(t, result.MethodMetadata.CanonicalID)
+		require.Equal(t, []string{longFormID, aliasID}, result.MethodMetadata.EquivalentID)
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(getCreateOperation()))
+
+		dh := newSuffixTestHandler(store, WithAliases(alias, "did:alt"))
+
+		for _, id := range []string{docID, aliasID, "did:alt" + docutil.NamespaceDelimiter + uniqueSuffix} {
+			result, err := dh.ResolveDocument(id)
+			require.NoError(t, err)
+			require.Equal(t, docID, result.Document.ID())
+			require.Equal(t, docID, result.MethodMetadata.CanonicalID)
+			require.Equal(t, []string{aliasID, "did:alt" + docutil.NamespaceDelimiter + uniqueSuffix},
+				result.MethodMetadata.EquivalentID)
+		}
+
+		result, err := dh.ResolveDocument("did:other" + docutil.NamespaceDelimiter + uniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "must start with configured namespace")
+	})
+}
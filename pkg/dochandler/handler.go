@@ -24,15 +24,15 @@ import (
 	"fmt"
 	"strings"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/batch/filehandler"
 	"github.com/trustbloc/sidetree-core-go/pkg/composer"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/request"
-	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/metrics"
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 )
@@ -43,6 +43,9 @@ const (
 	badRequest = "bad request"
 )
 
+// ErrOperationTooLarge is returned when an operation exceeds the size limits of the protocol
+var ErrOperationTooLarge = errors.New("operation is too large")
+
 // DocumentHandler implements document handler
 type DocumentHandler struct {
 	protocol  protocol.Client
@@ -50,6 +53,15 @@ type DocumentHandler struct {
 	writer    BatchWriter
 	validator DocumentValidator
 	namespace string
+	logger    log.Logger
+	metrics   metrics.Metrics
+	store     OperationStore
+	denyList  SuffixDenyList
+	deriver   docutil.SuffixDeriver
+	files     BatchFileHandler
+
+	equivalentIDProviders []EquivalentIDProvider
+	aliases               []string
 }
 
 // OperationProcessor is an interface which resolves the document based on the ID
@@ -62,6 +74,11 @@ type BatchWriter interface {
 	Add(operation *batch.OperationInfo) error
 }
 
+// BatchFileHandler creates the batch file for the given operations
+type BatchFileHandler interface {
+	CreateBatchFile(operations [][]byte) ([]byte, error)
+}
+
 // DocumentValidator is an interface for validating document operations
 type DocumentValidator interface {
 	IsValidOriginalDocument(payload []byte) error
@@ -69,15 +86,60 @@ type DocumentValidator interface {
 	TransformDocument(doc document.Document) (*document.ResolutionResult, error)
 }
 
+// Option is a document handler option
+type Option func(opts *DocumentHandler)
+
+// WithLogger sets the logger (the standard logrus logger is used by default)
+func WithLogger(logger log.Logger) Option {
+	return func(opts *DocumentHandler) {
+		opts.logger = logger
+	}
+}
+
+// WithMetrics sets the metrics hooks (no-op metrics are used by default)
+func WithMetrics(m metrics.Metrics) Option {
+	return func(opts *DocumentHandler) {
+		opts.metrics = m
+	}
+}
+
+// WithSuffixDeriver sets the suffix deriver of the namespace (which must be the same as the deriver of the operation
+// parser). The unique suffixes of resolution requests and operations are validated against the deriver.
+func WithSuffixDeriver(deriver docutil.SuffixDeriver) Option {
+	return func(opts *DocumentHandler) {
+		opts.deriver = deriver
+	}
+}
+
+// WithBatchFileHandler sets the handler that is used to create batch files (the default file handler is used by
+// default). This must be the same handler as the one used by the batch writer so that operations are validated
+// against the size of the batch file that will actually be written.
+func WithBatchFileHandler(handler BatchFileHandler) Option {
+	return func(opts *DocumentHandler) {
+		opts.files = handler
+	}
+}
+
 // New creates a new requestHandler with the context
-func New(namespace string, protocol protocol.Client, validator DocumentValidator, writer BatchWriter, processor OperationProcessor) *DocumentHandler {
-	return &DocumentHandler{
+func New(namespace string, protocol protocol.Client, validator DocumentValidator, writer BatchWriter, processor OperationProcessor, opts ...Option) *DocumentHandler {
+	r := &DocumentHandler{
 		protocol:  protocol,
 		processor: processor,
 		writer:    writer,
 		validator: validator,
 		namespace: namespace,
+		logger:    log.Default(),
+		metrics:   metrics.NewNoop(),
+		files:     filehandler.New(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
+
+	r.logger = r.logger.WithFields(log.Fields{log.FieldNamespace: namespace})
+
+	return r
 }
 
 // Namespace returns the namespace of the document handler
@@ -92,15 +154,20 @@ func (r *DocumentHandler) Protocol() protocol.Client {
 
 //ProcessOperation validates operation and adds it to the batch
 func (r *DocumentHandler) ProcessOperation(operation *batch.Operation) (*document.ResolutionResult, error) {
+	logger := r.logger.WithFields(log.Fields{
+		log.FieldSuffix:        operation.UniqueSuffix,
+		log.FieldOperationType: operation.Type,
+	})
+
 	// perform validation for operation request
 	if err := r.validateOperation(operation); err != nil {
-		log.Warnf("Failed to validate operation: %s", err.Error())
+		logger.Warnf("Failed to validate operation: %s", err.Error())
 		return nil, err
 	}
 
 	// validated operation will be added to the batch
 	if err := r.addToBatch(operation); err != nil {
-		log.Errorf("Failed to add operation to batch: %s", err.Error())
+		logger.Errorf("Failed to add operation to batch: %s", err.Error())
 		return nil, err
 	}
 
@@ -113,7 +180,7 @@ func (r *DocumentHandler) ProcessOperation(operation *batch.Operation) (*documen
 }
 
 func (r *DocumentHandler) getCreateResponse(operation *batch.Operation) (*document.ResolutionResult, error) {
-	doc, err := getInitialDocument(operation.Delta.Patches)
+	doc, err := r.getInitialDocument(operation.Delta.Patches)
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +192,11 @@ func (r *DocumentHandler) getCreateResponse(operation *batch.Operation) (*docume
 
 	externalResult.MethodMetadata.Published = false
 	externalResult.MethodMetadata.RecoveryKey = operation.SuffixData.RecoveryKey
+	externalResult.MethodMetadata.Type = operation.SuffixData.Type
+
+	if err := r.addIDs(operation.ID, r.longFormID(operation), false, externalResult); err != nil {
+		return nil, err
+	}
 
 	return externalResult, nil
 }
@@ -141,6 +213,8 @@ func (r *DocumentHandler) getCreateResponse(operation *batch.Operation) (*docume
 // to generate and return as the resolved DID Document, in which case the supplied encoded DID Document is subject to
 // the same validation as an original DID Document in a create operation
 func (r *DocumentHandler) ResolveDocument(idOrInitialDoc string) (*document.ResolutionResult, error) {
+	idOrInitialDoc = r.resolveAlias(idOrInitialDoc)
+
 	if !strings.HasPrefix(idOrInitialDoc, r.namespace+docutil.NamespaceDelimiter) {
 		return nil, errors.New("must start with configured namespace")
 	}
@@ -156,8 +230,12 @@ func (r *DocumentHandler) ResolveDocument(idOrInitialDoc string) (*document.Reso
 		return nil, fmt.Errorf("%s: %s", badRequest, err.Error())
 	}
 
+	if err := r.validateSuffixFormat(uniquePortion); err != nil {
+		return nil, fmt.Errorf("%s: %s", badRequest, err.Error())
+	}
+
 	// resolve document from the blockchain
-	doc, err := r.resolveRequestWithID(uniquePortion)
+	doc, err := r.resolveRequestWithID(uniquePortion, r.verifiedLongFormID(id, uniquePortion, initial))
 	if err == nil {
 		return doc, nil
 	}
@@ -170,27 +248,41 @@ func (r *DocumentHandler) ResolveDocument(idOrInitialDoc string) (*document.Reso
 	return nil, err
 }
 
-func (r *DocumentHandler) resolveRequestWithID(uniquePortion string) (*document.ResolutionResult, error) {
+func (r *DocumentHandler) resolveRequestWithID(uniquePortion, longFormID string) (*document.ResolutionResult, error) {
 	internalResult, err := r.processor.Resolve(uniquePortion)
 	if err != nil {
-		log.Errorf("Failed to resolve uniquePortion[%s]: %s", uniquePortion, err.Error())
+		r.logger.WithFields(log.Fields{log.FieldSuffix: uniquePortion}).Errorf("Failed to resolve document: %s", err.Error())
 		return nil, err
 	}
 
-	externalResult, err := r.transformToExternalDoc(internalResult.Document, r.namespace+docutil.NamespaceDelimiter+uniquePortion)
+	id := r.namespace + docutil.NamespaceDelimiter + uniquePortion
+
+	externalResult, err := r.transformToExternalDoc(internalResult.Document, id)
 	if err != nil {
 		return nil, err
 	}
 
 	externalResult.MethodMetadata.Published = true
 	externalResult.MethodMetadata.RecoveryKey = internalResult.MethodMetadata.RecoveryKey
+	externalResult.MethodMetadata.Type = internalResult.MethodMetadata.Type
+	externalResult.MethodMetadata.SkippedOperations = internalResult.MethodMetadata.SkippedOperations
+	externalResult.MethodMetadata.Integrity = internalResult.MethodMetadata.Integrity
+
+	if err := r.addIDs(id, longFormID, true, externalResult); err != nil {
+		return nil, err
+	}
 
 	return externalResult, nil
 }
 
 func (r *DocumentHandler) resolveRequestWithDocument(id string, initial *model.CreateRequest) (*document.ResolutionResult, error) {
+	pv, err := r.protocol.Current()
+	if err != nil {
+		return nil, err
+	}
+
 	// verify size of each delta does not exceed the maximum allowed limit
-	if len(initial.Delta) > int(r.protocol.Current().MaxDeltaByteSize) {
+	if len(initial.Delta) > int(pv.Protocol().MaxDeltaByteSize) {
 		return nil, fmt.Errorf("%s: delta byte size exceeds protocol max delta byte size", badRequest)
 	}
 
@@ -199,12 +291,11 @@ func (r *DocumentHandler) resolveRequestWithDocument(id string, initial *model.C
 		return nil, fmt.Errorf("%s: marshal initial state: %s", badRequest, err.Error())
 	}
 
-	op, err := operation.ParseCreateOperation(initialBytes, r.protocol.Current())
+	op, err := pv.OperationParser().Parse(r.namespace, initialBytes)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %s", badRequest, err.Error())
 	}
 
-	op.ID = r.namespace + docutil.NamespaceDelimiter + op.UniqueSuffix
 	if id != op.ID {
 		return nil, fmt.Errorf("%s: provided did doesn't match did created from initial state", badRequest)
 	}
@@ -213,7 +304,20 @@ func (r *DocumentHandler) resolveRequestWithDocument(id string, initial *model.C
 		return nil, fmt.Errorf("%s: validate initial document: %s", badRequest, err.Error())
 	}
 
-	return r.getCreateResponse(op)
+	result, err := r.getCreateResponse(op)
+	if err != nil {
+		return nil, err
+	}
+
+	result.MethodMetadata.Integrity = &document.Integrity{
+		SuffixData: initial.SuffixData,
+		Operations: []document.IntegrityOperation{{
+			Type:  string(batch.OperationTypeCreate),
+			Delta: initial.Delta,
+		}},
+	}
+
+	return result, nil
 }
 
 // helper function to transform internal into external document and return resolution result
@@ -243,20 +347,79 @@ func (r *DocumentHandler) addToBatch(operation *batch.Operation) error {
 
 // validateOperation validates the operation
 func (r *DocumentHandler) validateOperation(operation *batch.Operation) error {
+	pv, err := r.protocol.Current()
+	if err != nil {
+		return err
+	}
+
 	// check maximum operation size against protocol
-	if len(operation.EncodedDelta) > int(r.protocol.Current().MaxDeltaByteSize) {
-		return errors.New("delta byte size exceeds protocol max delta byte size")
+	if len(operation.EncodedDelta) > int(pv.Protocol().MaxDeltaByteSize) {
+		return fmt.Errorf("%w: delta byte size exceeds protocol max delta byte size", ErrOperationTooLarge)
+	}
+
+	// an operation that doesn't fit in a batch file on its own could never be anchored
+	if err := r.validateBatchFileSize(operation, pv.Protocol().MaxBatchFileSize); err != nil {
+		return err
+	}
+
+	if err := r.validateSuffixFormat(operation.UniqueSuffix); err != nil {
+		return err
 	}
 
 	if operation.Type == batch.OperationTypeCreate {
+		if err := r.validateSuffix(operation); err != nil {
+			return err
+		}
+
 		return r.validateInitialDocument(operation.Delta.Patches)
 	}
 
 	return r.validator.IsValidPayload(operation.OperationBuffer)
 }
 
+func (r *DocumentHandler) validateBatchFileSize(operation *batch.Operation, maxBatchFileSize uint) error {
+	if maxBatchFileSize == 0 {
+		return nil
+	}
+
+	opBytes, err := docutil.MarshalCanonical(operation)
+	if err != nil {
+		return err
+	}
+
+	batchBytes, err := r.files.CreateBatchFile([][]byte{opBytes})
+	if err != nil {
+		return err
+	}
+
+	if len(batchBytes) > int(maxBatchFileSize) {
+		return fmt.Errorf("%w: operation size [%d] exceeds protocol max batch file size [%d]",
+			ErrOperationTooLarge, len(batchBytes), maxBatchFileSize)
+	}
+
+	return nil
+}
+
+// suffixDeriver returns the suffix deriver of the namespace (multihash by default)
+func (r *DocumentHandler) suffixDeriver() docutil.SuffixDeriver {
+	if r.deriver == nil {
+		return docutil.MultihashSuffixDeriver{}
+	}
+
+	return r.deriver
+}
+
+// validateSuffixFormat checks that the unique suffix could have been derived by the suffix deriver of the namespace
+func (r *DocumentHandler) validateSuffixFormat(uniqueSuffix string) error {
+	if r.deriver == nil {
+		return nil
+	}
+
+	return r.deriver.ValidateSuffix(uniqueSuffix)
+}
+
 func (r *DocumentHandler) validateInitialDocument(patches []patch.Patch) error {
-	doc, err := getInitialDocument(patches)
+	doc, err := r.getInitialDocument(patches)
 	if err != nil {
 		return err
 	}
@@ -285,6 +448,11 @@ func getSuffix(namespace, idOrDocument string) (string, error) {
 	return idOrDocument[adjustedPos:], nil
 }
 
-func getInitialDocument(patches []patch.Patch) (document.Document, error) {
-	return composer.ApplyPatches(make(document.Document), patches)
+func (r *DocumentHandler) getInitialDocument(patches []patch.Patch) (document.Document, error) {
+	pv, err := r.protocol.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	return composer.ApplyPatches(make(document.Document), patches, document.WithCharacterPolicy(pv.Protocol().CharacterPolicy))
 }
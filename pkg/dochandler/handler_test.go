@@ -8,8 +8,10 @@ package dochandler
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
 
 	batchapi "github.com/trustbloc/sidetree-core-go/pkg/api/batch"
@@ -23,6 +25,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/canonicalizer"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
 	"github.com/trustbloc/sidetree-core-go/pkg/processor"
@@ -47,6 +50,24 @@ func TestDocumentHandler_Protocol(t *testing.T) {
 	require.Equal(t, pc, dh.Protocol())
 }
 
+func TestDocumentHandler_WithLogger(t *testing.T) {
+	l, hook := logtest.NewNullLogger()
+
+	store := mocks.NewMockOperationStore(nil)
+	dh := New(namespace, mocks.NewMockProtocolClient(), docvalidator.New(store), nil, nil, WithLogger(log.New(l)))
+
+	// update fails validation since document doesn't exist in the store
+	doc, err := dh.ProcessOperation(getUpdateOperation())
+	require.Error(t, err)
+	require.Nil(t, doc)
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	require.Contains(t, entry.Message, "Failed to validate operation")
+	require.Equal(t, namespace, entry.Data[log.FieldNamespace])
+	require.Equal(t, getUpdateOperation().UniqueSuffix, entry.Data[log.FieldSuffix])
+}
+
 func TestDocumentHandler_ProcessOperation_Create(t *testing.T) {
 	dochandler := getDocumentHandler(mocks.NewMockOperationStore(nil))
 	require.NotNil(t, dochandler)
@@ -93,6 +114,63 @@ func TestDocumentHandler_ProcessOperation_MaxDeltaSizeError(t *testing.T) {
 	require.NotNil(t, err)
 	require.Nil(t, doc)
 	require.Contains(t, err.Error(), "delta byte size exceeds protocol max delta byte size")
+	require.True(t, errors.Is(err, ErrOperationTooLarge))
+}
+
+func TestDocumentHandler_ProcessOperation_MaxBatchFileSizeError(t *testing.T) {
+	dochandler := getDocumentHandler(mocks.NewMockOperationStore(nil))
+	require.NotNil(t, dochandler)
+
+	protocol := mocks.NewMockProtocolClient()
+	protocol.Protocol.MaxBatchFileSize = 100
+	dochandler.protocol = protocol
+
+	doc, err := dochandler.ProcessOperation(getCreateOperation())
+	require.NotNil(t, err)
+	require.Nil(t, doc)
+	require.Contains(t, err.Error(), "exceeds protocol max batch file size [100]")
+	require.True(t, errors.Is(err, ErrOperationTooLarge))
+
+	protocol.Protocol.MaxBatchFileSize = 100000
+
+	doc, err = dochandler.ProcessOperation(getCreateOperation())
+	require.NoError(t, err)
+	require.NotNil(t, doc)
+
+	t.Run("batch file handler is injected", func(t *testing.T) {
+		protocol.Protocol.MaxBatchFileSize = 100000
+
+		handler := &mockBatchFileHandler{size: 100001}
+		WithBatchFileHandler(handler)(dochandler)
+
+		doc, err := dochandler.ProcessOperation(getCreateOperation())
+		require.Error(t, err)
+		require.Nil(t, doc)
+		require.Contains(t, err.Error(), "operation size [100001] exceeds protocol max batch file size [100000]")
+		require.Equal(t, 1, handler.calls)
+
+		handler.err = errors.New("batch file error")
+
+		doc, err = dochandler.ProcessOperation(getCreateOperation())
+		require.EqualError(t, err, "batch file error")
+		require.Nil(t, doc)
+	})
+}
+
+type mockBatchFileHandler struct {
+	size  int
+	err   error
+	calls int
+}
+
+func (m *mockBatchFileHandler) CreateBatchFile([][]byte) ([]byte, error) {
+	m.calls++
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return make([]byte, m.size), nil
 }
 
 func TestDocumentHandler_ResolveDocument_DID(t *testing.T) {
@@ -118,6 +196,11 @@ func TestDocumentHandler_ResolveDocument_DID(t *testing.T) {
 	require.NotNil(t, result)
 	require.Equal(t, true, result.MethodMetadata.Published)
 
+	// the resolved document is consistent with its create operation
+	integrity := result.MethodMetadata.Integrity
+	require.NotNil(t, integrity)
+	require.NoError(t, dochandler.VerifyIntegrity(result))
+
 	// scenario: invalid namespace
 	result, err = dochandler.ResolveDocument("doc:invalid:")
 	require.NotNil(t, err)
@@ -147,6 +230,10 @@ func TestDocumentHandler_ResolveDocument_InitialValue(t *testing.T) {
 	require.NotNil(t, result)
 	require.Equal(t, false, result.MethodMetadata.Published)
 
+	integrity := result.MethodMetadata.Integrity
+	require.Equal(t, createReq.SuffixData, integrity.SuffixData)
+	require.NoError(t, dochandler.VerifyIntegrity(result))
+
 	result, err = dochandler.ResolveDocument(docID + initialStateParam)
 	require.NotNil(t, err)
 	require.Nil(t, result)
@@ -178,7 +265,7 @@ func TestDocumentHandler_ResolveDocument_Interop(t *testing.T) {
 	result, err := dochandler.ResolveDocument(interopResolveDidWithInitialState)
 	require.Error(t, err)
 	require.Nil(t, result)
-	require.Contains(t, err.Error(), "bad request: action 'replace' is not supported")
+	require.Contains(t, err.Error(), "bad request: patch[0]: path /action: action 'replace' is not supported")
 }
 
 func TestDocumentHandler_ResolveDocument_InitialValue_MaxDeltaSizeError(t *testing.T) {
@@ -432,7 +519,7 @@ func getCreateRequestWithDoc(doc string) (*model.CreateRequest, error) {
 
 	encodedDelta := docutil.EncodeToString(deltaBytes)
 
-	suffixDataBytes, err := canonicalizer.MarshalCanonical(getSuffixData(encodedDelta))
+	suffixDataBytes, err := canonicalizer.MarshalCanonical(getSuffixData(deltaBytes))
 	if err != nil {
 		return nil, err
 	}
@@ -472,9 +559,9 @@ func newAddPublicKeysPatch(doc string) (patch.Patch, error) {
 	return p, nil
 }
 
-func getSuffixData(encodedDelta string) *model.SuffixDataModel {
+func getSuffixData(delta []byte) *model.SuffixDataModel {
 	return &model.SuffixDataModel{
-		DeltaHash: encodedMultihash(encodedDelta),
+		DeltaHash: encodedMultihash(string(delta)),
 		RecoveryKey: &jws.JWK{
 			Kty: "kty",
 			Crv: "crv",
@@ -533,3 +620,47 @@ func getUpdateOperation() *batchapi.Operation {
 
 // test value taken from reference implementation
 const interopResolveDidWithInitialState = `did:sidetree:EiAhCWPxdLFgyDVwR1yz94VVFC6NPWQpYJ2JSXr07tFCug?-sidetree-initial-state=eyJkZWx0YV9oYXNoIjoiRWlEc0YySVZJV3oxSEN2eHpLS2ItXzVISW1PQVhZN2RkZUFyZURZVkYtVFRjUSIsInJlY292ZXJ5X2tleSI6eyJrdHkiOiJFQyIsImNydiI6InNlY3AyNTZrMSIsIngiOiJuWEdmTlN6ZU9pemZiYjlsZy1ZT1VYS0c0SWl1a2t5YmVtbXlZTGpYVmZ3IiwieSI6ImNsd0hobmNJRnd5ZHp4RTVTYnE5YjNHNGlZWXJHa0VULVhQUEFNaEx1TkUifSwicmVjb3ZlcnlfY29tbWl0bWVudCI6IkVpQWQzb2MydEtMeXR0eGJzSEZjel9MOUl1WEZNQ3NSOGlQMVl5R1VQU1V5T2cifQ.eyJ1cGRhdGVfY29tbWl0bWVudCI6IkVpRGl3YWI0b0EyTno2a25qSVp0dEctSzBSb05xVlJCM2lQbzJLT2Nvb3MyUlEiLCJwYXRjaGVzIjpbeyJhY3Rpb24iOiJyZXBsYWNlIiwiZG9jdW1lbnQiOnsicHVibGljS2V5cyI6W3siaWQiOiJzaWduaW5nS2V5IiwidHlwZSI6IlNlY3AyNTZrMVZlcmlmaWNhdGlvbktleTIwMTkiLCJqd2siOnsia3R5IjoiRUMiLCJjcnYiOiJzZWNwMjU2azEiLCJ4IjoidHRzcFN6TnR0RUhoRk1CeG5BZUxEb0stLTJRTGVLeWFuTlVBQ3ZjUnFWVSIsInkiOiJtaTFvaFFONW93dWxRRlFiamQtOS05bG1uQ1piVGFSZ2Rta2hBcEVsVnRzIn0sInVzYWdlIjpbIm9wcyIsImF1dGgiLCJnZW5lcmFsIl19XSwic2VydmljZUVuZHBvaW50cyI6W3siaWQiOiJzZXJ2aWNlRW5kcG9pbnRJZDEyMyIsInR5cGUiOiJzb21lVHlwZSIsInNlcnZpY2VFbmRwb2ludCI6Imh0dHBzOi8vd3d3LnVybC5jb20ifV19fV19`
+
+func TestDocumentHandler_WithSuffixDeriver(t *testing.T) {
+	store := mocks.NewMockOperationStore(nil)
+
+	t.Run("multihash", func(t *testing.T) {
+		dh := newSuffixTestHandler(store, WithSuffixDeriver(docutil.MultihashSuffixDeriver{}))
+
+		doc, err := dh.ProcessOperation(getCreateOperation())
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+
+		result, err := dh.ResolveDocument(namespace + docutil.NamespaceDelimiter + "invalid")
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "bad request: invalid unique suffix")
+	})
+
+	t.Run("UUID", func(t *testing.T) {
+		deriver, err := docutil.NewUUIDSuffixDeriver("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		require.NoError(t, err)
+
+		dh := newSuffixTestHandler(store, WithSuffixDeriver(deriver))
+
+		// suffix of the create operation is a multihash
+		doc, err := dh.ProcessOperation(getCreateOperation())
+		require.Error(t, err)
+		require.Nil(t, doc)
+		require.Contains(t, err.Error(), "is not a UUID")
+
+		result, err := dh.ResolveDocument(getCreateOperation().ID)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "bad request: invalid unique suffix")
+
+		suffix, err := deriver.DeriveSuffix("suffixData", sha2_256)
+		require.NoError(t, err)
+
+		// valid suffix that doesn't exist
+		result, err = dh.ResolveDocument(namespace + docutil.NamespaceDelimiter + suffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "not found")
+	})
+}
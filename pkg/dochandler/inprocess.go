@@ -0,0 +1,71 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+// Resolve resolves the given DID (or long-form DID) in-process (see client.Resolver). Unlike ResolveDocument,
+// the returned errors wrap the typed errors of the client package.
+func (r *DocumentHandler) Resolve(ctx context.Context, did string, opts ...client.ResolveOption) (*document.ResolutionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	options := client.GetResolveOptions(opts...)
+
+	result, err := r.ResolveDocument(did)
+	if err != nil {
+		return nil, resolveError(err)
+	}
+
+	if !options.IncludeSkippedOperations {
+		result.MethodMetadata.SkippedOperations = nil
+	}
+
+	return result, nil
+}
+
+// ApplyOperation parses the given operation request using the current protocol version, validates the operation
+// and adds it to the batch (see client.OperationApplier). The resolution result is only returned for create
+// operations.
+func (r *DocumentHandler) ApplyOperation(ctx context.Context, request []byte) (*document.ResolutionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pv, err := r.protocol.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err := pv.OperationParser().Parse(r.namespace, request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", client.ErrInvalidRequest, err)
+	}
+
+	return r.ProcessOperation(operation)
+}
+
+func resolveError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), badRequest) || strings.Contains(err.Error(), "must start with configured namespace"):
+		return fmt.Errorf("%w: %s", client.ErrInvalidRequest, err)
+	case strings.Contains(err.Error(), "not found"):
+		return fmt.Errorf("%w: %s", client.ErrNotFound, err)
+	case strings.Contains(err.Error(), "was deactivated"):
+		return fmt.Errorf("%w: %s", client.ErrDeactivated, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+// make sure that the document handler implements the in-process client interfaces
+var (
+	_ client.Resolver         = (*DocumentHandler)(nil)
+	_ client.OperationApplier = (*DocumentHandler)(nil)
+)
+
+func TestDocumentHandler_Resolve(t *testing.T) {
+	store := mocks.NewMockOperationStore(nil)
+	dochandler := getDocumentHandler(store)
+
+	docID := getCreateOperation().ID
+
+	t.Run("not found", func(t *testing.T) {
+		result, err := dochandler.Resolve(context.Background(), docID)
+		require.True(t, errors.Is(err, client.ErrNotFound))
+		require.Nil(t, result)
+	})
+
+	t.Run("invalid DID", func(t *testing.T) {
+		result, err := dochandler.Resolve(context.Background(), "doc:invalid:")
+		require.True(t, errors.Is(err, client.ErrInvalidRequest))
+		require.Nil(t, result)
+
+		result, err = dochandler.Resolve(context.Background(), namespace+docutil.NamespaceDelimiter)
+		require.True(t, errors.Is(err, client.ErrInvalidRequest))
+		require.Nil(t, result)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result, err := dochandler.Resolve(ctx, docID)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Nil(t, result)
+	})
+
+	require.NoError(t, store.Put(getCreateOperation()))
+
+	t.Run("success", func(t *testing.T) {
+		result, err := dochandler.Resolve(context.Background(), docID)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, result.MethodMetadata.Published)
+		require.Nil(t, result.MethodMetadata.SkippedOperations)
+
+		result, err = dochandler.Resolve(context.Background(), docID, client.WithSkippedOperations())
+		require.NoError(t, err)
+		require.NotNil(t, result)
+	})
+}
+
+func TestDocumentHandler_ApplyOperation(t *testing.T) {
+	dochandler := getDocumentHandler(mocks.NewMockOperationStore(nil))
+
+	t.Run("success", func(t *testing.T) {
+		result, err := dochandler.ApplyOperation(context.Background(), getCreateOperation().OperationBuffer)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.False(t, result.MethodMetadata.Published)
+		require.Equal(t, getCreateOperation().ID, result.Document.ID())
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		result, err := dochandler.ApplyOperation(context.Background(), []byte("{}"))
+		require.True(t, errors.Is(err, client.ErrInvalidRequest))
+		require.Nil(t, result)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result, err := dochandler.ApplyOperation(ctx, getCreateOperation().OperationBuffer)
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Nil(t, result)
+	})
+}
+
+func TestResolveError(t *testing.T) {
+	require.True(t, errors.Is(resolveError(errors.New("document was deactivated")), client.ErrDeactivated))
+	require.True(t, errors.Is(resolveError(errors.New("bad request: invalid suffix")), client.ErrInvalidRequest))
+	require.True(t, errors.Is(resolveError(errors.New("document not found")), client.ErrNotFound))
+
+	errExpected := errors.New("store error")
+	require.Equal(t, errExpected, resolveError(errExpected))
+}
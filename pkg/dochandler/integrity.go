@@ -0,0 +1,266 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/composer"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/verifier"
+)
+
+// ErrIntegrityUnavailable is returned by VerifyIntegrity if the resolution result doesn't contain integrity data
+var ErrIntegrityUnavailable = errors.New("integrity data is not available for the resolved document")
+
+// VerifyIntegrity checks that the resolved document is consistent with its anchored operations. The unique suffix
+// of the document is derived from the suffix data of the create operation and the document is re-computed from
+// the integrity data: the delta of each operation is verified against the delta hash that the operation committed
+// to (the suffix data for create operations, the signed data for update and recover operations) before it is
+// applied. The signed data of an update operation must be signed by an operations key of the document and the
+// signed data of a recover operation by the current recovery key. The re-computed document must match the
+// resolved document.
+func (r *DocumentHandler) VerifyIntegrity(result *document.ResolutionResult) error {
+	integrity := result.MethodMetadata.Integrity
+	if integrity == nil || len(integrity.Operations) == 0 {
+		return ErrIntegrityUnavailable
+	}
+
+	id := result.Document.ID()
+
+	internal, err := r.computeDocument(strings.TrimPrefix(id, r.namespace+docutil.NamespaceDelimiter), integrity,
+		result.MethodMetadata.Published)
+	if err != nil {
+		return err
+	}
+
+	expected, err := r.transformToExternalDoc(internal, id)
+	if err != nil {
+		return err
+	}
+
+	expectedBytes, err := docutil.MarshalCanonical(expected.Document)
+	if err != nil {
+		return err
+	}
+
+	resolvedBytes, err := docutil.MarshalCanonical(result.Document)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(expectedBytes, resolvedBytes) {
+		return errors.New("resolved document doesn't match the document computed from its operations")
+	}
+
+	return nil
+}
+
+// computeDocument verifies the integrity data against the unique suffix and applies the operations
+func (r *DocumentHandler) computeDocument(uniqueSuffix string, integrity *document.Integrity, published bool) (document.Document, error) {
+	suffixDataBytes, err := docutil.DecodeString(integrity.SuffixData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid suffix data: %s", err.Error())
+	}
+
+	var suffixData model.SuffixDataModel
+	if err := json.Unmarshal(suffixDataBytes, &suffixData); err != nil {
+		return nil, fmt.Errorf("invalid suffix data: %s", err.Error())
+	}
+
+	code, err := docutil.GetMultihashCode(suffixData.DeltaHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delta hash: %s", err.Error())
+	}
+
+	suffix, err := r.suffixDeriver().DeriveSuffix(integrity.SuffixData, uint(code))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute unique suffix: %s", err.Error())
+	}
+
+	if suffix != uniqueSuffix {
+		return nil, fmt.Errorf("unique suffix [%s] doesn't match suffix data", uniqueSuffix)
+	}
+
+	var doc document.Document
+
+	recoveryKey := suffixData.RecoveryKey
+
+	for i, op := range integrity.Operations {
+		if (i == 0) != (op.Type == string(batch.OperationTypeCreate)) {
+			return nil, fmt.Errorf("unexpected %s operation at index %d", op.Type, i)
+		}
+
+		var deltaHash string
+		var policy document.CharacterPolicy
+
+		switch op.Type {
+		case string(batch.OperationTypeCreate):
+			doc = make(document.Document)
+			deltaHash = suffixData.DeltaHash
+
+		case string(batch.OperationTypeUpdate):
+			deltaHash, err = updateDeltaHash(doc, op.SignedData)
+
+		case string(batch.OperationTypeRecover):
+			doc = make(document.Document)
+			deltaHash, recoveryKey, err = recoverDeltaHash(recoveryKey, op.SignedData)
+
+		default:
+			return nil, fmt.Errorf("unexpected %s operation at index %d", op.Type, i)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid signed data of %s operation at index %d: %s", op.Type, i, err.Error())
+		}
+
+		policy, err = r.characterPolicy(op, published)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err = applyDelta(doc, op.Delta, deltaHash, policy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delta of %s operation at index %d: %s", op.Type, i, err.Error())
+		}
+	}
+
+	return doc, nil
+}
+
+// characterPolicy returns the character policy of the protocol version in effect at the transaction time of the
+// operation (or of the current protocol version if the document is not published)
+func (r *DocumentHandler) characterPolicy(op document.IntegrityOperation, published bool) (document.CharacterPolicy, error) {
+	var pv protocol.Version
+	var err error
+
+	if published {
+		pv, err = r.protocol.Get(op.TransactionTime)
+	} else {
+		pv, err = r.protocol.Current()
+	}
+
+	if err != nil {
+		return document.CharacterPolicy{}, err
+	}
+
+	return pv.Protocol().CharacterPolicy, nil
+}
+
+// applyDelta verifies the encoded delta against the delta hash and applies its patches to the document
+func applyDelta(doc document.Document, encodedDelta, deltaHash string, policy document.CharacterPolicy) (document.Document, error) {
+	code, err := docutil.GetMultihashCode(deltaHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delta hash: %s", err.Error())
+	}
+
+	deltaBytes, err := docutil.DecodeString(encodedDelta)
+	if err != nil {
+		return nil, err
+	}
+
+	computed, err := docutil.ComputeMultihash(uint(code), deltaBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute delta hash: %s", err.Error())
+	}
+
+	if docutil.EncodeToString(computed) != deltaHash {
+		return nil, fmt.Errorf("delta doesn't match delta hash [%s]", deltaHash)
+	}
+
+	var delta model.DeltaModel
+	if err := json.Unmarshal(deltaBytes, &delta); err != nil {
+		return nil, err
+	}
+
+	return composer.ApplyPatches(doc, delta.Patches, document.WithCharacterPolicy(policy))
+}
+
+// updateDeltaHash verifies the signed data of an update operation with the operations key of the document
+// and returns the signed delta hash
+func updateDeltaHash(doc document.Document, signedData string) (string, error) {
+	kid, err := signedDataKeyID(signedData)
+	if err != nil {
+		return "", err
+	}
+
+	jwk, err := document.GetOperationsKey(doc, kid)
+	if err != nil {
+		return "", err
+	}
+
+	var signedDataModel model.UpdateSignedDataModel
+	if err := verifySignedData(signedData, jwk, &signedDataModel); err != nil {
+		return "", err
+	}
+
+	return signedDataModel.DeltaHash, nil
+}
+
+// recoverDeltaHash verifies the signed data of a recover operation with the recovery key and returns the
+// signed delta hash along with the new recovery key
+func recoverDeltaHash(recoveryKey *jws.JWK, signedData string) (string, *jws.JWK, error) {
+	if recoveryKey == nil {
+		return "", nil, errors.New("recovery key is not available")
+	}
+
+	var signedDataModel model.RecoverSignedDataModel
+	if err := verifySignedData(signedData, recoveryKey, &signedDataModel); err != nil {
+		return "", nil, err
+	}
+
+	return signedDataModel.DeltaHash, signedDataModel.RecoveryKey, nil
+}
+
+func verifySignedData(signedData string, jwk *jws.JWK, signedDataModel interface{}) error {
+	payload, err := verifier.VerifyJWS(signedData, jwk)
+	if err != nil {
+		return err
+	}
+
+	decoded, err := docutil.DecodeString(string(payload))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(decoded, signedDataModel)
+}
+
+// signedDataKeyID returns the 'kid' protected header of the compact JWS
+func signedDataKeyID(signedData string) (string, error) {
+	parts := strings.Split(signedData, ".")
+	if len(parts) != 3 {
+		return "", errors.New("not a compact JWS")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("decode protected header: %s", err)
+	}
+
+	var headers jws.Headers
+	if err := json.Unmarshal(headerBytes, &headers); err != nil {
+		return "", fmt.Errorf("unmarshal protected header: %s", err)
+	}
+
+	kid, ok := headers.KeyID()
+	if !ok || kid == "" {
+		return "", errors.New("missing kid in protected header")
+	}
+
+	return kid, nil
+}
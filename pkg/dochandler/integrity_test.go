@@ -0,0 +1,262 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	batchapi "github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+const integrityDocTemplate = `{
+	"publicKey": [{
+		"id": "%s",
+		"type": "JwsVerificationKey2020",
+		"usage": ["ops"],
+		"jwk": %s
+	}]
+}`
+
+func TestDocumentHandler_VerifyIntegrity(t *testing.T) {
+	f := newIntegrityFixture(t)
+
+	store := mocks.NewMockOperationStore(nil)
+	handler := getDocumentHandler(store)
+
+	create := f.create(t)
+	require.NoError(t, store.Put(create))
+
+	update := f.update(t, create.UniqueSuffix, 1)
+	require.NoError(t, store.Put(update))
+
+	t.Run("create and update", func(t *testing.T) {
+		result, err := handler.ResolveDocument(create.ID)
+		require.NoError(t, err)
+		require.Len(t, result.MethodMetadata.Integrity.Operations, 2)
+		require.Equal(t, "value1", result.Document["name"])
+
+		require.NoError(t, handler.VerifyIntegrity(result))
+	})
+
+	t.Run("integrity unavailable", func(t *testing.T) {
+		result, err := handler.ResolveDocument(create.ID)
+		require.NoError(t, err)
+
+		result.MethodMetadata.Integrity = nil
+		require.Equal(t, ErrIntegrityUnavailable, handler.VerifyIntegrity(result))
+	})
+
+	t.Run("document doesn't match", func(t *testing.T) {
+		result, err := handler.ResolveDocument(create.ID)
+		require.NoError(t, err)
+
+		result.Document["name"] = "other"
+		require.EqualError(t, handler.VerifyIntegrity(result),
+			"resolved document doesn't match the document computed from its operations")
+	})
+
+	t.Run("suffix data doesn't match", func(t *testing.T) {
+		result, err := handler.ResolveDocument(create.ID)
+		require.NoError(t, err)
+
+		other := newIntegrityFixture(t).create(t)
+
+		var request struct {
+			SuffixData string `json:"suffix_data"`
+		}
+		require.NoError(t, json.Unmarshal(other.OperationBuffer, &request))
+
+		result.MethodMetadata.Integrity.SuffixData = request.SuffixData
+		require.Contains(t, handler.VerifyIntegrity(result).Error(), "doesn't match suffix data")
+	})
+
+	t.Run("delta doesn't match signed delta hash", func(t *testing.T) {
+		result, err := handler.ResolveDocument(create.ID)
+		require.NoError(t, err)
+
+		// the update delta is replaced with another delta (the delta hash of the server can't be trusted)
+		result.MethodMetadata.Integrity.Operations[1].Delta = f.update(t, create.UniqueSuffix, 2).EncodedDelta
+		err = handler.VerifyIntegrity(result)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid delta of update operation at index 1: delta doesn't match delta hash")
+	})
+
+	t.Run("signed data not signed by an operations key", func(t *testing.T) {
+		result, err := handler.ResolveDocument(create.ID)
+		require.NoError(t, err)
+
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		f.opsKey, otherKey = otherKey, f.opsKey
+		result.MethodMetadata.Integrity.Operations[1].SignedData = f.update(t, create.UniqueSuffix, 2).SignedData.Signature
+		f.opsKey = otherKey
+
+		err = handler.VerifyIntegrity(result)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid signed data of update operation at index 1")
+	})
+
+	t.Run("update is first operation", func(t *testing.T) {
+		result, err := handler.ResolveDocument(create.ID)
+		require.NoError(t, err)
+
+		ops := result.MethodMetadata.Integrity.Operations
+		result.MethodMetadata.Integrity.Operations = []document.IntegrityOperation{ops[1], ops[0]}
+
+		require.EqualError(t, handler.VerifyIntegrity(result), "unexpected update operation at index 0")
+	})
+
+	t.Run("recover", func(t *testing.T) {
+		recoverStore := mocks.NewMockOperationStore(nil)
+		recoverHandler := getDocumentHandler(recoverStore)
+
+		require.NoError(t, recoverStore.Put(create))
+		require.NoError(t, recoverStore.Put(update))
+
+		recoverOp := f.recover(t, create.UniqueSuffix, 2)
+		require.NoError(t, recoverStore.Put(recoverOp))
+
+		result, err := recoverHandler.ResolveDocument(create.ID)
+		require.NoError(t, err)
+		// the document is replaced by the recover operation
+		require.Len(t, result.MethodMetadata.Integrity.Operations, 2)
+		require.Nil(t, result.Document["name"])
+
+		require.NoError(t, recoverHandler.VerifyIntegrity(result))
+
+		// the recover operation must be signed by the recovery key
+		result.MethodMetadata.Integrity.Operations[1].SignedData = update.SignedData.Signature
+		err = recoverHandler.VerifyIntegrity(result)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid signed data of recover operation at index 1")
+	})
+
+	t.Run("UUID suffix deriver", func(t *testing.T) {
+		deriver, err := docutil.NewUUIDSuffixDeriver("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		require.NoError(t, err)
+
+		uuidStore := mocks.NewMockOperationStore(nil)
+		uuidHandler := getDocumentHandler(uuidStore)
+		WithSuffixDeriver(deriver)(uuidHandler)
+
+		uuidCreate := f.create(t, operation.WithSuffixDeriver(deriver))
+		require.NoError(t, uuidStore.Put(uuidCreate))
+
+		result, err := uuidHandler.ResolveDocument(uuidCreate.ID)
+		require.NoError(t, err)
+		require.NoError(t, uuidHandler.VerifyIntegrity(result))
+
+		// the suffix is verified with the suffix deriver of the namespace
+		require.Contains(t, handler.VerifyIntegrity(result).Error(), "doesn't match suffix data")
+	})
+}
+
+type integrityFixture struct {
+	opsKey      *ecdsa.PrivateKey
+	recoveryKey *ecdsa.PrivateKey
+}
+
+func newIntegrityFixture(t *testing.T) *integrityFixture {
+	opsKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	recoveryKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return &integrityFixture{opsKey: opsKey, recoveryKey: recoveryKey}
+}
+
+func (f *integrityFixture) create(t *testing.T, opts ...operation.ParserOption) *batchapi.Operation {
+	recoveryJWK, err := pubkey.GetPublicKeyJWK(&f.recoveryKey.PublicKey)
+	require.NoError(t, err)
+
+	request, err := helper.NewCreateRequest(&helper.CreateRequestInfo{
+		OpaqueDocument:          f.doc(t),
+		RecoveryKey:             recoveryJWK,
+		NextRecoveryRevealValue: []byte("recoveryReveal"),
+		NextUpdateRevealValue:   []byte("updateReveal1"),
+		MultihashCode:           sha2_256,
+	})
+	require.NoError(t, err)
+
+	return f.parse(t, request, 0, opts...)
+}
+
+func (f *integrityFixture) update(t *testing.T, uniqueSuffix string, n int) *batchapi.Operation {
+	p, err := patch.NewJSONPatch(fmt.Sprintf(`[{"op": "replace", "path": "/name", "value": "value%d"}]`, n))
+	require.NoError(t, err)
+
+	request, err := helper.NewUpdateRequest(&helper.UpdateRequestInfo{
+		DidSuffix:             uniqueSuffix,
+		Patch:                 p,
+		UpdateRevealValue:     []byte(fmt.Sprintf("updateReveal%d", n)),
+		NextUpdateRevealValue: []byte(fmt.Sprintf("updateReveal%d", n+1)),
+		MultihashCode:         sha2_256,
+		Signer:                ecsigner.New(f.opsKey, "ES256", "key1"),
+	})
+	require.NoError(t, err)
+
+	return f.parse(t, request, uint64(n))
+}
+
+func (f *integrityFixture) recover(t *testing.T, uniqueSuffix string, n int) *batchapi.Operation {
+	newRecoveryKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	newRecoveryJWK, err := pubkey.GetPublicKeyJWK(&newRecoveryKey.PublicKey)
+	require.NoError(t, err)
+
+	request, err := helper.NewRecoverRequest(&helper.RecoverRequestInfo{
+		DidSuffix:               uniqueSuffix,
+		RecoveryRevealValue:     []byte("recoveryReveal"),
+		RecoveryKey:             newRecoveryJWK,
+		OpaqueDocument:          f.doc(t),
+		NextRecoveryRevealValue: []byte("recoveryReveal2"),
+		NextUpdateRevealValue:   []byte(fmt.Sprintf("updateReveal%d", n+1)),
+		MultihashCode:           sha2_256,
+		Signer:                  ecsigner.New(f.recoveryKey, "ES256", ""),
+	})
+	require.NoError(t, err)
+
+	return f.parse(t, request, uint64(n))
+}
+
+func (f *integrityFixture) doc(t *testing.T) string {
+	jwk, err := pubkey.GetPublicKeyJWK(&f.opsKey.PublicKey)
+	require.NoError(t, err)
+
+	jwkBytes, err := json.Marshal(jwk)
+	require.NoError(t, err)
+
+	return fmt.Sprintf(integrityDocTemplate, "key1", jwkBytes)
+}
+
+func (f *integrityFixture) parse(t *testing.T, request []byte, txnNumber uint64, opts ...operation.ParserOption) *batchapi.Operation {
+	op, err := operation.NewParser(mocks.NewMockProtocolClient().Protocol, opts...).Parse(namespace, request)
+	require.NoError(t, err)
+
+	op.TransactionTime = txnNumber
+	op.TransactionNumber = txnNumber
+
+	return op
+}
@@ -0,0 +1,172 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+var (
+	// ErrSuffixCollision is returned when the suffix of a create operation collides with an existing
+	// create operation that has a different payload
+	ErrSuffixCollision = errors.New("suffix collides with an existing create operation")
+
+	// ErrSuffixDenied is returned when the suffix of a create operation is in the deny-list
+	ErrSuffixDenied = errors.New("suffix is denied")
+)
+
+// OperationStore retrieves all operations related to a document
+type OperationStore interface {
+	Get(uniqueSuffix string) ([]*batch.Operation, error)
+}
+
+// SuffixDenyList is consulted for every create operation in order to reject suffixes (e.g. suffixes that were
+// reported as ground or abusive)
+type SuffixDenyList interface {
+	IsDenied(uniqueSuffix string) (bool, error)
+}
+
+// SuffixDenyListFunc is a function that implements SuffixDenyList
+type SuffixDenyListFunc func(uniqueSuffix string) (bool, error)
+
+// IsDenied returns true if the suffix is denied
+func (f SuffixDenyListFunc) IsDenied(uniqueSuffix string) (bool, error) {
+	return f(uniqueSuffix)
+}
+
+// WithOperationStore sets the operation store that is used to reject create operations whose suffix collides
+// with an existing create operation that has a different payload. Collisions are not checked if not set.
+func WithOperationStore(store OperationStore) Option {
+	return func(opts *DocumentHandler) {
+		opts.store = store
+	}
+}
+
+// WithSuffixDenyList sets the deny-list that is consulted for every create operation
+func WithSuffixDenyList(denyList SuffixDenyList) Option {
+	return func(opts *DocumentHandler) {
+		opts.denyList = denyList
+	}
+}
+
+// CheckSuffix checks whether the given unique suffix is already in use so that clients can detect collisions
+// (or duplicate registrations) before submitting a create operation. Operations that are still pending in
+// the batch writer are not taken into account.
+func (r *DocumentHandler) CheckSuffix(uniqueSuffix string) (*document.SuffixCheck, error) {
+	if uniqueSuffix == "" {
+		return nil, fmt.Errorf("%s: missing unique suffix", badRequest)
+	}
+
+	if err := r.validateSuffixFormat(uniqueSuffix); err != nil {
+		return nil, fmt.Errorf("%s: %s", badRequest, err.Error())
+	}
+
+	check := &document.SuffixCheck{UniqueSuffix: uniqueSuffix}
+
+	if r.denyList != nil {
+		denied, err := r.denyList.IsDenied(uniqueSuffix)
+		if err != nil {
+			return nil, fmt.Errorf("check suffix deny-list: %s", err.Error())
+		}
+
+		if denied {
+			check.Status = document.SuffixDenied
+
+			return check, nil
+		}
+	}
+
+	_, err := r.processor.Resolve(uniqueSuffix)
+
+	switch {
+	case err == nil:
+		check.Status = document.SuffixPublished
+	case strings.Contains(err.Error(), "not found"):
+		check.Status = document.SuffixAvailable
+	case strings.Contains(err.Error(), "was deactivated"):
+		check.Status = document.SuffixDeactivated
+	default:
+		return nil, err
+	}
+
+	return check, nil
+}
+
+// validateSuffix checks the suffix of the create operation against the deny-list and the existing create operations
+func (r *DocumentHandler) validateSuffix(operation *batch.Operation) error {
+	if r.denyList != nil {
+		denied, err := r.denyList.IsDenied(operation.UniqueSuffix)
+		if err != nil {
+			return fmt.Errorf("check suffix deny-list: %s", err.Error())
+		}
+
+		if denied {
+			return ErrSuffixDenied
+		}
+	}
+
+	if r.store == nil {
+		return nil
+	}
+
+	ops, err := r.store.Get(operation.UniqueSuffix)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, op := range ops {
+		if op.Type != batch.OperationTypeCreate {
+			continue
+		}
+
+		same, err := isSameCreatePayload(op, operation)
+		if err != nil {
+			return err
+		}
+
+		if !same {
+			r.logger.WithFields(log.Fields{log.FieldSuffix: operation.UniqueSuffix}).Warnf(
+				"Suspected suffix grinding: create operation collides with an existing create operation with a different payload")
+
+			r.metrics.SuffixCollision()
+
+			return ErrSuffixCollision
+		}
+	}
+
+	return nil
+}
+
+func isSameCreatePayload(op1, op2 *batch.Operation) (bool, error) {
+	if op1.EncodedDelta != op2.EncodedDelta {
+		return false, nil
+	}
+
+	suffixData1, err := docutil.MarshalCanonical(op1.SuffixData)
+	if err != nil {
+		return false, err
+	}
+
+	suffixData2, err := docutil.MarshalCanonical(op2.SuffixData)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(suffixData1, suffixData2), nil
+}
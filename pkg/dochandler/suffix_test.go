@@ -0,0 +1,220 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	batchapi "github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler/docvalidator"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/processor"
+)
+
+func TestDocumentHandler_ValidateSuffix(t *testing.T) {
+	t.Run("no existing create operation", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+		m := mocks.NewMockMetrics()
+
+		dh := newSuffixTestHandler(store, WithOperationStore(store), WithMetrics(m))
+
+		doc, err := dh.ProcessOperation(getCreateOperation())
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+		require.Equal(t, 0, m.SuffixCollisions())
+	})
+
+	t.Run("same create payload", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(getCreateOperation()))
+
+		m := mocks.NewMockMetrics()
+
+		dh := newSuffixTestHandler(store, WithOperationStore(store), WithMetrics(m))
+
+		doc, err := dh.ProcessOperation(getCreateOperation())
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+		require.Equal(t, 0, m.SuffixCollisions())
+	})
+
+	t.Run("collision with different create payload", func(t *testing.T) {
+		existing := getCreateOperation()
+		existing.EncodedDelta = "other"
+
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(existing))
+
+		m := mocks.NewMockMetrics()
+
+		dh := newSuffixTestHandler(store, WithOperationStore(store), WithMetrics(m))
+
+		doc, err := dh.ProcessOperation(getCreateOperation())
+		require.True(t, errors.Is(err, ErrSuffixCollision))
+		require.Nil(t, doc)
+		require.Equal(t, 1, m.SuffixCollisions())
+	})
+
+	t.Run("collisions not checked without operation store", func(t *testing.T) {
+		existing := getCreateOperation()
+		existing.EncodedDelta = "other"
+
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(existing))
+
+		doc, err := newSuffixTestHandler(store).ProcessOperation(getCreateOperation())
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+	})
+
+	t.Run("operation store error", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(errors.New("store error"))
+
+		dh := newSuffixTestHandler(store, WithOperationStore(store))
+
+		doc, err := dh.ProcessOperation(getCreateOperation())
+		require.EqualError(t, err, "store error")
+		require.Nil(t, doc)
+	})
+
+	t.Run("denied suffix", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+		op := getCreateOperation()
+
+		dh := newSuffixTestHandler(store, WithSuffixDenyList(SuffixDenyListFunc(func(suffix string) (bool, error) {
+			return suffix == op.UniqueSuffix, nil
+		})))
+
+		doc, err := dh.ProcessOperation(op)
+		require.True(t, errors.Is(err, ErrSuffixDenied))
+		require.Nil(t, doc)
+	})
+
+	t.Run("deny-list error", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+
+		dh := newSuffixTestHandler(store, WithSuffixDenyList(SuffixDenyListFunc(func(string) (bool, error) {
+			return false, errors.New("deny-list error")
+		})))
+
+		doc, err := dh.ProcessOperation(getCreateOperation())
+		require.EqualError(t, err, "check suffix deny-list: deny-list error")
+		require.Nil(t, doc)
+	})
+}
+
+func TestDocumentHandler_CheckSuffix(t *testing.T) {
+	const suffix = "EiDOQXC2GnoVyHwIRbjhLx_cNc6vmZaS04SZjZdlLLAPRg"
+
+	t.Run("available", func(t *testing.T) {
+		dh := newSuffixCheckHandler(errors.New("not found"))
+
+		check, err := dh.CheckSuffix(suffix)
+		require.NoError(t, err)
+		require.Equal(t, suffix, check.UniqueSuffix)
+		require.Equal(t, document.SuffixAvailable, check.Status)
+		require.True(t, check.Available())
+	})
+
+	t.Run("published", func(t *testing.T) {
+		dh := newSuffixCheckHandler(nil)
+
+		check, err := dh.CheckSuffix(suffix)
+		require.NoError(t, err)
+		require.Equal(t, document.SuffixPublished, check.Status)
+		require.False(t, check.Available())
+	})
+
+	t.Run("deactivated", func(t *testing.T) {
+		dh := newSuffixCheckHandler(errors.New("document was deactivated"))
+
+		check, err := dh.CheckSuffix(suffix)
+		require.NoError(t, err)
+		require.Equal(t, document.SuffixDeactivated, check.Status)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		dh := newSuffixCheckHandler(errors.New("not found"),
+			WithSuffixDenyList(SuffixDenyListFunc(func(string) (bool, error) { return true, nil })))
+
+		check, err := dh.CheckSuffix(suffix)
+		require.NoError(t, err)
+		require.Equal(t, document.SuffixDenied, check.Status)
+	})
+
+	t.Run("deny-list error", func(t *testing.T) {
+		dh := newSuffixCheckHandler(errors.New("not found"),
+			WithSuffixDenyList(SuffixDenyListFunc(func(string) (bool, error) { return false, errors.New("deny-list error") })))
+
+		check, err := dh.CheckSuffix(suffix)
+		require.Error(t, err)
+		require.Nil(t, check)
+		require.Contains(t, err.Error(), "deny-list error")
+	})
+
+	t.Run("missing suffix", func(t *testing.T) {
+		dh := newSuffixCheckHandler(nil)
+
+		check, err := dh.CheckSuffix("")
+		require.Error(t, err)
+		require.Nil(t, check)
+		require.Contains(t, err.Error(), "bad request: missing unique suffix")
+	})
+
+	t.Run("invalid suffix format", func(t *testing.T) {
+		deriver, err := docutil.NewUUIDSuffixDeriver("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		require.NoError(t, err)
+
+		dh := newSuffixCheckHandler(nil, WithSuffixDeriver(deriver))
+
+		check, err := dh.CheckSuffix(suffix)
+		require.Error(t, err)
+		require.Nil(t, check)
+		require.Contains(t, err.Error(), "bad request")
+	})
+
+	t.Run("resolve error", func(t *testing.T) {
+		dh := newSuffixCheckHandler(errors.New("store error"))
+
+		check, err := dh.CheckSuffix(suffix)
+		require.EqualError(t, err, "store error")
+		require.Nil(t, check)
+	})
+}
+
+func newSuffixCheckHandler(resolveErr error, opts ...Option) *DocumentHandler {
+	return New(namespace, mocks.NewMockProtocolClient(), nil, &mockBatchWriter{},
+		&mockResolver{err: resolveErr}, opts...)
+}
+
+type mockResolver struct {
+	err error
+}
+
+func (m *mockResolver) Resolve(string) (*document.ResolutionResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &document.ResolutionResult{}, nil
+}
+
+func newSuffixTestHandler(store processor.OperationStoreClient, opts ...Option) *DocumentHandler {
+	return New(namespace, mocks.NewMockProtocolClient(), docvalidator.New(store), &mockBatchWriter{},
+		processor.New("test", store), opts...)
+}
+
+type mockBatchWriter struct{}
+
+func (m *mockBatchWriter) Add(*batchapi.OperationInfo) error {
+	return nil
+}
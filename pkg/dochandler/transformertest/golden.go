@@ -0,0 +1,312 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package transformertest provides a golden-file regression suite for document transformers
+// (see dochandler.DocumentValidator).
+//
+// The suite transforms a library of internal documents (the *.json files in the input directory) and compares
+// the canonical transformed documents with the golden files of the protocol version, i.e.
+// <golden dir>/<version>/<input name>.json. A transformer change that alters the public output causes the suite
+// to fail until the golden files are regenerated (by setting the UPDATE_GOLDEN environment variable to true) and
+// the changes to the golden files are reviewed and committed.
+package transformertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+)
+
+// UpdateEnvVar is the environment variable that enables the regeneration of the golden files
+const UpdateEnvVar = "UPDATE_GOLDEN"
+
+const fileExt = ".json"
+
+// Transformer transforms the internal representation of a document to the external representation
+type Transformer interface {
+	TransformDocument(doc document.Document) (*document.ResolutionResult, error)
+}
+
+// Case is an internal document of the input library
+type Case struct {
+	// Name is the name of the input file (without extension)
+	Name string
+
+	// Document is the internal document
+	Document document.Document
+}
+
+// Mismatch describes a golden file that doesn't match the transformed document
+type Mismatch struct {
+	// Case is the name of the input document
+	Case string
+
+	// GoldenFile is the path of the golden file
+	GoldenFile string
+
+	// Expected is the content of the golden file (nil if the golden file doesn't exist)
+	Expected []byte
+
+	// Actual is the canonical transformed document (nil if the golden file doesn't have an input document)
+	Actual []byte
+}
+
+func (m *Mismatch) String() string {
+	switch {
+	case m.Expected == nil:
+		return fmt.Sprintf("golden file [%s] is missing for input [%s]", m.GoldenFile, m.Case)
+	case m.Actual == nil:
+		return fmt.Sprintf("golden file [%s] doesn't have an input document", m.GoldenFile)
+	default:
+		return fmt.Sprintf("transformed document for input [%s] doesn't match golden file [%s]\nexpected:\n%s\nactual:\n%s",
+			m.Case, m.GoldenFile, m.Expected, m.Actual)
+	}
+}
+
+// Suite compares the output of document transformers with golden files
+type Suite struct {
+	inputDir  string
+	goldenDir string
+	update    bool
+}
+
+// Option is a suite option
+type Option func(s *Suite)
+
+// WithUpdate sets whether the golden files are regenerated (instead of compared). By default, the golden files
+// are regenerated if the UPDATE_GOLDEN environment variable is set to true.
+func WithUpdate(update bool) Option {
+	return func(s *Suite) {
+		s.update = update
+	}
+}
+
+// NewSuite returns a new golden-file suite for the input documents in inputDir and the golden files in goldenDir
+func NewSuite(inputDir, goldenDir string, opts ...Option) *Suite {
+	update, _ := strconv.ParseBool(os.Getenv(UpdateEnvVar))
+
+	s := &Suite{
+		inputDir:  inputDir,
+		goldenDir: goldenDir,
+		update:    update,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Run checks the output of the transformer against the golden files of the given protocol version and reports
+// a test error for each mismatch
+func (s *Suite) Run(t *testing.T, version string, transformer Transformer) {
+	t.Helper()
+
+	mismatches, err := s.Check(version, transformer)
+	if err != nil {
+		t.Fatalf("golden-file check for protocol version [%s] failed: %s", version, err)
+	}
+
+	for _, m := range mismatches {
+		t.Errorf("protocol version [%s]: %s", version, m)
+	}
+
+	if len(mismatches) > 0 {
+		t.Logf("If the changes to the transformed documents are intended then re-run the tests with %s=true "+
+			"and review the changes to the golden files in [%s]", UpdateEnvVar, filepath.Join(s.goldenDir, version))
+	}
+}
+
+// Check transforms all of the input documents and compares the canonical transformed documents with the golden
+// files of the given protocol version. If the suite is in update mode then the golden files are regenerated
+// (and golden files without an input document are removed) and no mismatches are returned.
+func (s *Suite) Check(version string, transformer Transformer) ([]*Mismatch, error) {
+	cases, err := LoadCases(s.inputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(s.goldenDir, version)
+
+	if s.update {
+		if err := os.MkdirAll(dir, 0755); err != nil { //nolint:gosec
+			return nil, err
+		}
+	}
+
+	var mismatches []*Mismatch
+
+	names := make(map[string]bool)
+
+	for _, c := range cases {
+		names[c.Name] = true
+
+		m, err := s.check(dir, c, transformer)
+		if err != nil {
+			return nil, err
+		}
+
+		if m != nil {
+			mismatches = append(mismatches, m)
+		}
+	}
+
+	stale, err := s.checkStale(dir, names)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(mismatches, stale...), nil
+}
+
+func (s *Suite) check(dir string, c *Case, transformer Transformer) (*Mismatch, error) {
+	result, err := transformer.TransformDocument(c.Document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform input [%s]: %w", c.Name, err)
+	}
+
+	actual, err := Canonicalize(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to canonicalize transformed document for input [%s]: %w", c.Name, err)
+	}
+
+	goldenFile := filepath.Join(dir, c.Name+fileExt)
+
+	if s.update {
+		return nil, ioutil.WriteFile(goldenFile, actual, 0644) //nolint:gosec
+	}
+
+	expected, err := ioutil.ReadFile(goldenFile) //nolint:gosec
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Mismatch{Case: c.Name, GoldenFile: goldenFile, Actual: actual}, nil
+		}
+
+		return nil, err
+	}
+
+	if bytes.Equal(expected, actual) {
+		return nil, nil
+	}
+
+	return &Mismatch{Case: c.Name, GoldenFile: goldenFile, Expected: expected, Actual: actual}, nil
+}
+
+// checkStale returns a mismatch for each golden file that doesn't have an input document (or removes the file
+// in update mode)
+func (s *Suite) checkStale(dir string, names map[string]bool) ([]*Mismatch, error) {
+	files, err := listFiles(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var mismatches []*Mismatch
+
+	for _, name := range files {
+		if names[name] {
+			continue
+		}
+
+		goldenFile := filepath.Join(dir, name+fileExt)
+
+		if s.update {
+			if err := os.Remove(goldenFile); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		expected, err := ioutil.ReadFile(goldenFile) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+
+		mismatches = append(mismatches, &Mismatch{Case: name, GoldenFile: goldenFile, Expected: expected})
+	}
+
+	return mismatches, nil
+}
+
+// LoadCases loads the internal documents (*.json files) in the given directory, sorted by name
+func LoadCases(dir string) ([]*Case, error) {
+	names, err := listFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input directory [%s]: %w", dir, err)
+	}
+
+	var cases []*Case
+
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name+fileExt)) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err := document.FromBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input document [%s]: %w", name, err)
+		}
+
+		cases = append(cases, &Case{Name: name, Document: doc})
+	}
+
+	return cases, nil
+}
+
+// Canonicalize returns the canonical (JCS) form of the resolution result, indented for readability
+func Canonicalize(result *document.ResolutionResult) ([]byte, error) {
+	canonical, err := docutil.MarshalCanonical(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, canonical, "", "  "); err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// listFiles returns the names (without extension) of the *.json files in the given directory, sorted by name
+func listFiles(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	for _, info := range infos {
+		if info.IsDir() || filepath.Ext(info.Name()) != fileExt {
+			continue
+		}
+
+		names = append(names, strings.TrimSuffix(info.Name(), fileExt))
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transformertest
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+const version = "0"
+
+func TestSuite_Check(t *testing.T) {
+	t.Run("update and compare", func(t *testing.T) {
+		inputDir, goldenDir, cleanup := newDirs(t)
+		defer cleanup()
+
+		writeFile(t, filepath.Join(inputDir, "doc1.json"), `{"id":"did:example:1"}`)
+		writeFile(t, filepath.Join(inputDir, "doc2.json"), `{"id":"did:example:2"}`)
+		writeFile(t, filepath.Join(inputDir, "README.md"), "ignored")
+
+		mismatches, err := NewSuite(inputDir, goldenDir, WithUpdate(true)).Check(version, &mockTransformer{})
+		require.NoError(t, err)
+		require.Empty(t, mismatches)
+
+		golden, err := ioutil.ReadFile(filepath.Join(goldenDir, version, "doc1.json"))
+		require.NoError(t, err)
+		require.Equal(t, "{\n  \"@context\": \"https://www.w3.org/ns/did-resolution/v1\",\n  \"didDocument\": {\n    \"id\": \"did:example:1\"\n  },\n  \"methodMetadata\": {\n    \"published\": true\n  }\n}\n", string(golden))
+
+		mismatches, err = NewSuite(inputDir, goldenDir).Check(version, &mockTransformer{})
+		require.NoError(t, err)
+		require.Empty(t, mismatches)
+	})
+
+	t.Run("changed output", func(t *testing.T) {
+		inputDir, goldenDir, cleanup := newDirs(t)
+		defer cleanup()
+
+		writeFile(t, filepath.Join(inputDir, "doc1.json"), `{"id":"did:example:1"}`)
+
+		_, err := NewSuite(inputDir, goldenDir, WithUpdate(true)).Check(version, &mockTransformer{})
+		require.NoError(t, err)
+
+		mismatches, err := NewSuite(inputDir, goldenDir).Check(version, &mockTransformer{unpublished: true})
+		require.NoError(t, err)
+		require.Len(t, mismatches, 1)
+		require.Equal(t, "doc1", mismatches[0].Case)
+		require.Contains(t, string(mismatches[0].Expected), `"published": true`)
+		require.Contains(t, string(mismatches[0].Actual), `"published": false`)
+		require.Contains(t, mismatches[0].String(), "transformed document for input [doc1] doesn't match golden file")
+	})
+
+	t.Run("missing golden file", func(t *testing.T) {
+		inputDir, goldenDir, cleanup := newDirs(t)
+		defer cleanup()
+
+		writeFile(t, filepath.Join(inputDir, "doc1.json"), `{"id":"did:example:1"}`)
+
+		mismatches, err := NewSuite(inputDir, goldenDir, WithUpdate(false)).Check(version, &mockTransformer{})
+		require.NoError(t, err)
+		require.Len(t, mismatches, 1)
+		require.Nil(t, mismatches[0].Expected)
+		require.Contains(t, mismatches[0].String(), "is missing for input [doc1]")
+	})
+
+	t.Run("stale golden file", func(t *testing.T) {
+		inputDir, goldenDir, cleanup := newDirs(t)
+		defer cleanup()
+
+		writeFile(t, filepath.Join(inputDir, "doc1.json"), `{"id":"did:example:1"}`)
+		writeFile(t, filepath.Join(inputDir, "doc2.json"), `{"id":"did:example:2"}`)
+
+		_, err := NewSuite(inputDir, goldenDir, WithUpdate(true)).Check(version, &mockTransformer{})
+		require.NoError(t, err)
+
+		require.NoError(t, os.Remove(filepath.Join(inputDir, "doc2.json")))
+
+		mismatches, err := NewSuite(inputDir, goldenDir).Check(version, &mockTransformer{})
+		require.NoError(t, err)
+		require.Len(t, mismatches, 1)
+		require.Equal(t, "doc2", mismatches[0].Case)
+		require.Nil(t, mismatches[0].Actual)
+		require.Contains(t, mismatches[0].String(), "doesn't have an input document")
+
+		// the stale golden file is removed in update mode
+		_, err = NewSuite(inputDir, goldenDir, WithUpdate(true)).Check(version, &mockTransformer{})
+		require.NoError(t, err)
+
+		_, err = os.Stat(filepath.Join(goldenDir, version, "doc2.json"))
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("transform error", func(t *testing.T) {
+		inputDir, goldenDir, cleanup := newDirs(t)
+		defer cleanup()
+
+		writeFile(t, filepath.Join(inputDir, "doc1.json"), `{"id":"did:example:1"}`)
+
+		_, err := NewSuite(inputDir, goldenDir).Check(version, &mockTransformer{err: errors.New("transform error")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to transform input [doc1]: transform error")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		inputDir, goldenDir, cleanup := newDirs(t)
+		defer cleanup()
+
+		writeFile(t, filepath.Join(inputDir, "doc1.json"), `{`)
+
+		_, err := NewSuite(inputDir, goldenDir).Check(version, &mockTransformer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid input document [doc1]")
+	})
+
+	t.Run("missing input directory", func(t *testing.T) {
+		_, goldenDir, cleanup := newDirs(t)
+		defer cleanup()
+
+		_, err := NewSuite("./invalid", goldenDir).Check(version, &mockTransformer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read input directory")
+	})
+}
+
+func TestSuite_Run(t *testing.T) {
+	inputDir, goldenDir, cleanup := newDirs(t)
+	defer cleanup()
+
+	writeFile(t, filepath.Join(inputDir, "doc1.json"), `{"id":"did:example:1"}`)
+
+	NewSuite(inputDir, goldenDir, WithUpdate(true)).Run(t, version, &mockTransformer{})
+	NewSuite(inputDir, goldenDir, WithUpdate(false)).Run(t, version, &mockTransformer{})
+}
+
+func TestUpdateEnvVar(t *testing.T) {
+	require.NoError(t, os.Setenv(UpdateEnvVar, "true"))
+	defer func() {
+		require.NoError(t, os.Unsetenv(UpdateEnvVar))
+	}()
+
+	require.True(t, NewSuite("input", "golden").update)
+	require.False(t, NewSuite("input", "golden", WithUpdate(false)).update)
+}
+
+func newDirs(t *testing.T) (string, string, func()) {
+	dir, err := ioutil.TempDir("", "golden")
+	require.NoError(t, err)
+
+	inputDir := filepath.Join(dir, "input")
+	require.NoError(t, os.Mkdir(inputDir, 0700))
+
+	return inputDir, filepath.Join(dir, "golden"), func() { require.NoError(t, os.RemoveAll(dir)) }
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+}
+
+type mockTransformer struct {
+	unpublished bool
+	err         error
+}
+
+func (m *mockTransformer) TransformDocument(doc document.Document) (*document.ResolutionResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &document.ResolutionResult{
+		Context:        "https://www.w3.org/ns/did-resolution/v1",
+		Document:       document.Document{"id": doc.ID()},
+		MethodMetadata: document.MethodMetadata{Published: !m.unpublished},
+	}, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package document
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CharacterPolicy defines the characters that are permitted in document string fields (service types and
+// service endpoints). Public key and service ids are always restricted to [A-Za-z0-9_-].
+//
+// The following characters are never permitted: invalid UTF-8, control characters and format characters
+// (e.g. zero-width joiners and bidirectional overrides) since they are invisible in resolved documents.
+// Values must also be in Unicode Normalization Form C (NFC).
+//
+// The character policy is a protocol parameter (see protocol.Protocol) so that operations are validated with the
+// same policy when they are submitted, anchored and resolved.
+type CharacterPolicy struct {
+	// AllowNonASCII permits non-ASCII characters. If not set, only printable ASCII characters are permitted.
+	AllowNonASCII bool `json:"allowNonAscii,omitempty"`
+
+	// AllowCombiningMarks permits combining marks (e.g. U+0301). Combining marks allow the same visual string
+	// to be encoded in different ways (composed vs. decomposed) so they are rejected unless explicitly allowed.
+	// Note that a combining mark that composes with the preceding character is not detected by the NFC
+	// validation so values should be normalized by the client if combining marks are allowed.
+	// Only applies if AllowNonASCII is set.
+	AllowCombiningMarks bool `json:"allowCombiningMarks,omitempty"`
+
+	// AllowMixedScripts permits characters from different scripts (e.g. Latin and Cyrillic) within the same word
+	// (a sequence of letters delimited by digits, punctuation, etc.). Mixed scripts are the most common way of
+	// creating visually confusable values so they are rejected unless explicitly allowed.
+	// Only applies if AllowNonASCII is set.
+	AllowMixedScripts bool `json:"allowMixedScripts,omitempty"`
+}
+
+// DefaultCharacterPolicy returns the default character policy which only permits printable ASCII characters
+func DefaultCharacterPolicy() CharacterPolicy {
+	return CharacterPolicy{}
+}
+
+// nolint:gochecknoglobals
+var scripts = map[string]*unicode.RangeTable{
+	"Arabic":     unicode.Arabic,
+	"Armenian":   unicode.Armenian,
+	"Bengali":    unicode.Bengali,
+	"Cherokee":   unicode.Cherokee,
+	"Cyrillic":   unicode.Cyrillic,
+	"Devanagari": unicode.Devanagari,
+	"Georgian":   unicode.Georgian,
+	"Greek":      unicode.Greek,
+	"Han":        unicode.Han,
+	"Hangul":     unicode.Hangul,
+	"Hebrew":     unicode.Hebrew,
+	"Hiragana":   unicode.Hiragana,
+	"Katakana":   unicode.Katakana,
+	"Latin":      unicode.Latin,
+	"Thai":       unicode.Thai,
+}
+
+// ValidateString validates that the given value only contains characters that are permitted by the policy
+func (p CharacterPolicy) ValidateString(value string) error {
+	if !utf8.ValidString(value) {
+		return errors.New("invalid UTF-8 encoding")
+	}
+
+	script := ""
+
+	for _, r := range value {
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return fmt.Errorf("invalid character %U", r)
+		}
+
+		if r > unicode.MaxASCII {
+			if err := p.validateNonASCII(r); err != nil {
+				return err
+			}
+		}
+
+		if p.AllowMixedScripts {
+			continue
+		}
+
+		s := scriptOf(r)
+		if s == "" {
+			if !unicode.IsLetter(r) && !unicode.Is(unicode.M, r) {
+				// word boundary
+				script = ""
+			}
+
+			continue
+		}
+
+		if script != "" && script != s {
+			return fmt.Errorf("mixed scripts are not allowed: %s and %s", script, s)
+		}
+
+		script = s
+	}
+
+	if p.AllowNonASCII {
+		return validateNFC(value)
+	}
+
+	return nil
+}
+
+func (p CharacterPolicy) validateNonASCII(r rune) error {
+	if !p.AllowNonASCII {
+		return fmt.Errorf("non-ASCII character %U is not allowed", r)
+	}
+
+	if !p.AllowCombiningMarks && unicode.Is(unicode.M, r) {
+		return fmt.Errorf("combining mark %U is not allowed", r)
+	}
+
+	if !unicode.IsPrint(r) {
+		return fmt.Errorf("invalid character %U", r)
+	}
+
+	return nil
+}
+
+// scriptOf returns the script of the given character or empty string for characters that are
+// common to all scripts (e.g. digits and punctuation)
+func scriptOf(r rune) string {
+	if r <= unicode.MaxASCII {
+		if unicode.IsLetter(r) {
+			return "Latin"
+		}
+
+		return ""
+	}
+
+	for name, table := range scripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+
+	return ""
+}
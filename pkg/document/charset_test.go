@@ -0,0 +1,139 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package document
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharacterPolicy_ValidateString(t *testing.T) {
+	t.Run("default policy", func(t *testing.T) {
+		policy := DefaultCharacterPolicy()
+
+		require.NoError(t, policy.ValidateString("https://example.com/hub?a=1#frag"))
+		require.NoError(t, policy.ValidateString("IdentityHub"))
+
+		err := policy.ValidateString("https://bücher.example.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "non-ASCII character U+00FC is not allowed")
+
+		err = policy.ValidateString("Identity\u0007Hub")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid character U+0007")
+
+		err = policy.ValidateString("Identity​Hub")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid character U+200B")
+
+		err = policy.ValidateString("‮IdentityHub")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid character U+202E")
+
+		err = policy.ValidateString(string([]byte{0xff, 0xfe}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid UTF-8 encoding")
+	})
+	t.Run("non-ASCII allowed", func(t *testing.T) {
+		policy := CharacterPolicy{AllowNonASCII: true}
+
+		require.NoError(t, policy.ValidateString("https://bücher.example.com"))
+		require.NoError(t, policy.ValidateString("https://пример.рф"))
+		require.NoError(t, policy.ValidateString("https://例子.测试/1"))
+
+		// Cyrillic 'а' (U+0430) in otherwise Latin value
+		err := policy.ValidateString("https://exаmple.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mixed scripts are not allowed")
+
+		// decomposed 'ü'
+		err = policy.ValidateString("https://bücher.example.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "combining mark U+0308 is not allowed")
+
+		err = policy.ValidateString("Identity Hub")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid character U+2028")
+
+		err = policy.ValidateString("Identity‍Hub")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid character U+200D")
+	})
+	t.Run("mixed scripts and combining marks allowed", func(t *testing.T) {
+		policy := CharacterPolicy{AllowNonASCII: true, AllowMixedScripts: true, AllowCombiningMarks: true}
+
+		require.NoError(t, policy.ValidateString("https://exаmple.com"))
+		require.NoError(t, policy.ValidateString("https://bücher.example.com"))
+
+		err := policy.ValidateString("Identity​Hub")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid character U+200B")
+	})
+}
+
+func TestCharacterPolicy_NFC(t *testing.T) {
+	policy := CharacterPolicy{AllowNonASCII: true}
+
+	t.Run("normalized", func(t *testing.T) {
+		require.NoError(t, policy.ValidateString("https://\u00c5ngstr\u00f6m.example.com"))
+		require.NoError(t, policy.ValidateString("\uac00\ub098"))
+
+		// leading consonant that isn't followed by a conjoining vowel
+		require.NoError(t, policy.ValidateString("\u1100-a"))
+	})
+	t.Run("character never occurs in NFC", func(t *testing.T) {
+		// ANGSTROM SIGN (normalized to U+00C5)
+		err := policy.ValidateString("https://\u212bngstr\u00f6m.example.com")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "character U+212B is not in Unicode Normalization Form C")
+
+		// OHM SIGN (normalized to U+03A9)
+		err = policy.ValidateString("1\u2126")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "character U+2126 is not in Unicode Normalization Form C")
+
+		// CJK compatibility ideograph
+		err = policy.ValidateString("\uf900")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "character U+F900 is not in Unicode Normalization Form C")
+	})
+	t.Run("decomposed Hangul syllable", func(t *testing.T) {
+		// U+AC00 as leading consonant and vowel
+		err := policy.ValidateString("\u1100\u1161")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "character sequence U+1100 U+1161 is not in Unicode Normalization Form C")
+
+		// U+AC01 as LV syllable and trailing consonant
+		err = policy.ValidateString("\uac00\u11a8")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "character sequence U+AC00 U+11A8 is not in Unicode Normalization Form C")
+
+		// LVT syllable followed by a trailing consonant doesn't compose
+		require.NoError(t, policy.ValidateString("\uac01\u11a8"))
+	})
+}
+
+func TestValidateServices_CharacterPolicy(t *testing.T) {
+	doc, err := DidDocumentFromBytes([]byte(serviceDocNonASCII))
+	require.NoError(t, err)
+
+	err = ValidateServices(doc.Services())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "service type: non-ASCII character")
+
+	err = ValidateServices(doc.Services(), WithCharacterPolicy(CharacterPolicy{AllowNonASCII: true}))
+	require.NoError(t, err)
+}
+
+const serviceDocNonASCII = `{
+	"service": [{
+		"id": "vcs",
+		"type": "VerifiableCredentialService™",
+		"serviceEndpoint": "https://example.com/vc/"
+	}]
+}`
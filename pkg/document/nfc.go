@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package document
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// Hangul syllable composition constants (The Unicode Standard, section 3.12)
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// notNFC contains the characters that never occur in Unicode Normalization Form C (NFC_Quick_Check=No),
+// e.g. U+212B ANGSTROM SIGN which is normalized to U+00C5.
+// nolint:gochecknoglobals
+var notNFC = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0340, 0x0341, 1},
+		{0x0343, 0x0344, 1},
+		{0x0374, 0x0374, 1},
+		{0x037e, 0x037e, 1},
+		{0x0387, 0x0387, 1},
+		{0x0958, 0x095f, 1},
+		{0x09dc, 0x09dd, 1},
+		{0x09df, 0x09df, 1},
+		{0x0a33, 0x0a33, 1},
+		{0x0a36, 0x0a36, 1},
+		{0x0a59, 0x0a5b, 1},
+		{0x0a5e, 0x0a5e, 1},
+		{0x0b5c, 0x0b5d, 1},
+		{0x0f43, 0x0f43, 1},
+		{0x0f4d, 0x0f4d, 1},
+		{0x0f52, 0x0f52, 1},
+		{0x0f57, 0x0f57, 1},
+		{0x0f5c, 0x0f5c, 1},
+		{0x0f69, 0x0f69, 1},
+		{0x0f73, 0x0f73, 1},
+		{0x0f75, 0x0f76, 1},
+		{0x0f78, 0x0f78, 1},
+		{0x0f81, 0x0f81, 1},
+		{0x0f93, 0x0f93, 1},
+		{0x0f9d, 0x0f9d, 1},
+		{0x0fa2, 0x0fa2, 1},
+		{0x0fa7, 0x0fa7, 1},
+		{0x0fac, 0x0fac, 1},
+		{0x0fb9, 0x0fb9, 1},
+		{0x1f71, 0x1f71, 1},
+		{0x1f73, 0x1f73, 1},
+		{0x1f75, 0x1f75, 1},
+		{0x1f77, 0x1f77, 1},
+		{0x1f79, 0x1f79, 1},
+		{0x1f7b, 0x1f7b, 1},
+		{0x1f7d, 0x1f7d, 1},
+		{0x1fbb, 0x1fbb, 1},
+		{0x1fbe, 0x1fbe, 1},
+		{0x1fc9, 0x1fc9, 1},
+		{0x1fcb, 0x1fcb, 1},
+		{0x1fd3, 0x1fd3, 1},
+		{0x1fdb, 0x1fdb, 1},
+		{0x1fe3, 0x1fe3, 1},
+		{0x1feb, 0x1feb, 1},
+		{0x1fee, 0x1fef, 1},
+		{0x1ff9, 0x1ff9, 1},
+		{0x1ffb, 0x1ffb, 1},
+		{0x1ffd, 0x1ffd, 1},
+		{0x2000, 0x2001, 1},
+		{0x2126, 0x2126, 1},
+		{0x212a, 0x212b, 1},
+		{0x2329, 0x232a, 1},
+		{0x2adc, 0x2adc, 1},
+		{0xf900, 0xfa0d, 1},
+		{0xfa10, 0xfa10, 1},
+		{0xfa12, 0xfa12, 1},
+		{0xfa15, 0xfa1e, 1},
+		{0xfa20, 0xfa20, 1},
+		{0xfa22, 0xfa22, 1},
+		{0xfa25, 0xfa26, 1},
+		{0xfa2a, 0xfa6d, 1},
+		{0xfa70, 0xfad9, 1},
+		{0xfb1d, 0xfb1d, 1},
+		{0xfb1f, 0xfb1f, 1},
+		{0xfb2a, 0xfb36, 1},
+		{0xfb38, 0xfb3c, 1},
+		{0xfb3e, 0xfb3e, 1},
+		{0xfb40, 0xfb41, 1},
+		{0xfb43, 0xfb44, 1},
+		{0xfb46, 0xfb4e, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1d15e, 0x1d164, 1},
+		{0x1d1bb, 0x1d1c0, 1},
+		{0x2f800, 0x2fa1d, 1},
+	},
+}
+
+// validateNFC validates that the given value is in Unicode Normalization Form C so that the same visual
+// string can't be encoded in different ways. Characters that never occur in NFC and conjoining Hangul jamo
+// that compose to a precomposed syllable are rejected. (Combining marks are validated by the character policy.)
+//
+// TODO: replace this quick check (and the notNFC table) with norm.NFC.IsNormalString from
+// golang.org/x/text/unicode/norm once golang.org/x/text is added to the module dependencies.
+func validateNFC(value string) error {
+	var prev rune = -1
+
+	for _, r := range value {
+		if unicode.Is(notNFC, r) {
+			return fmt.Errorf("character %U is not in Unicode Normalization Form C", r)
+		}
+
+		if composesHangul(prev, r) {
+			return fmt.Errorf("character sequence %U %U is not in Unicode Normalization Form C", prev, r)
+		}
+
+		prev = r
+	}
+
+	return nil
+}
+
+// composesHangul returns true if the given characters compose to a precomposed Hangul syllable, i.e.
+// a leading consonant followed by a vowel or an LV syllable followed by a trailing consonant
+func composesHangul(first, second rune) bool {
+	if first >= hangulLBase && first < hangulLBase+hangulLCount &&
+		second >= hangulVBase && second < hangulVBase+hangulVCount {
+		return true
+	}
+
+	return first >= hangulSBase && first < hangulSBase+hangulSCount && (first-hangulSBase)%hangulTCount == 0 &&
+		second > hangulTBase && second < hangulTBase+hangulTCount
+}
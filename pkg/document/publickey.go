@@ -6,6 +6,12 @@ SPDX-License-Identifier: Apache-2.0
 
 package document
 
+import (
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
 const (
 
 	// ControllerProperty defines key for controller
@@ -92,3 +98,37 @@ func (pk PublicKey) Usage() []string {
 func (pk PublicKey) JSONLdObject() map[string]interface{} {
 	return pk
 }
+
+// GetOperationsKey returns the JWK of the public key with the given ID. An error is returned if the document
+// doesn't contain the key or if the key is not a valid operations key.
+func GetOperationsKey(doc Document, kid string) (*jws.JWK, error) {
+	didDoc := DidDocumentFromJSONLDObject(doc.JSONLdObject())
+	for _, pk := range didDoc.PublicKeys() {
+		if pk.ID() != kid {
+			continue
+		}
+
+		if err := ValidateOperationsKey(pk); err != nil {
+			return nil, err
+		}
+
+		return JWKFromPublicKey(pk)
+	}
+
+	return nil, fmt.Errorf("signing public key [%s] not found in the document", kid)
+}
+
+// JWKFromPublicKey returns the JWK of the given document public key
+func JWKFromPublicKey(pk PublicKey) (*jws.JWK, error) {
+	jwk := pk.JWK()
+	if jwk == nil {
+		return nil, fmt.Errorf("public key [%s] doesn't contain a JWK", pk.ID())
+	}
+
+	return &jws.JWK{
+		Kty: jwk.Kty(),
+		Crv: jwk.Crv(),
+		X:   jwk.X(),
+		Y:   jwk.Y(),
+	}, nil
+}
@@ -10,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
 )
 
 func TestPublicKey(t *testing.T) {
@@ -81,3 +83,55 @@ func TestPublicKeyJWK(t *testing.T) {
 	jwk = pk.PublicKeyJwk()
 	require.Nil(t, jwk)
 }
+
+func TestGetOperationsKey(t *testing.T) {
+	doc, err := FromBytes([]byte(`{
+		"publicKey": [{
+			"id": "key-1",
+			"type": "JwsVerificationKey2020",
+			"usage": ["ops"],
+			"jwk": {"kty": "EC", "crv": "P-256", "x": "x", "y": "y"}
+		}, {
+			"id": "key-2",
+			"type": "JwsVerificationKey2020",
+			"usage": ["general"],
+			"jwk": {"kty": "EC", "crv": "P-256", "x": "x", "y": "y"}
+		}]
+	}`))
+	require.NoError(t, err)
+
+	jwk, err := GetOperationsKey(doc, "key-1")
+	require.NoError(t, err)
+	require.Equal(t, &jws.JWK{Kty: "EC", Crv: "P-256", X: "x", Y: "y"}, jwk)
+
+	jwk, err = GetOperationsKey(doc, "key-2")
+	require.Error(t, err)
+	require.Nil(t, jwk)
+	require.Contains(t, err.Error(), "key 'key-2' is not an operations key")
+
+	jwk, err = GetOperationsKey(doc, "key-3")
+	require.Error(t, err)
+	require.Nil(t, jwk)
+	require.Contains(t, err.Error(), "signing public key [key-3] not found in the document")
+}
+
+func TestJWKFromPublicKey(t *testing.T) {
+	pk := PublicKey{
+		"id": "key-1",
+		"jwk": map[string]interface{}{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   "x",
+			"y":   "y",
+		},
+	}
+
+	jwk, err := JWKFromPublicKey(pk)
+	require.NoError(t, err)
+	require.Equal(t, &jws.JWK{Kty: "EC", Crv: "P-256", X: "x", Y: "y"}, jwk)
+
+	jwk, err = JWKFromPublicKey(PublicKey{"id": "key-2"})
+	require.Error(t, err)
+	require.Nil(t, jwk)
+	require.Contains(t, err.Error(), "public key [key-2] doesn't contain a JWK")
+}
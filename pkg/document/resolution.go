@@ -20,4 +20,44 @@ type MethodMetadata struct {
 	OperationPublicKeys []PublicKey `json:"operationPublicKeys,omitempty"`
 	RecoveryKey         *jws.JWK    `json:"recoveryKey,omitempty"`
 	Published           bool        `json:"published"`
+	Type                uint        `json:"type,omitempty"`
+	EquivalentID        []string    `json:"equivalentId,omitempty"`
+	CanonicalID         string      `json:"canonicalId,omitempty"`
+	// SkippedOperations contains the anchored operations that were not applied during resolution
+	// (only included if requested)
+	SkippedOperations []SkippedOperation `json:"skippedOperations,omitempty"`
+	// Integrity contains the data that the document may be verified with (only included if requested)
+	Integrity *Integrity `json:"integrity,omitempty"`
+}
+
+// Integrity contains the anchored data that a resolved document may be verified with. The document is re-computed
+// by applying the deltas of the operations (starting with the create operation) after verifying each delta against
+// the delta hash that the operation committed to.
+type Integrity struct {
+	// SuffixData is the encoded suffix data of the create operation
+	SuffixData string `json:"suffixData"`
+	// Operations contains the operations that were applied to the document, in order
+	Operations []IntegrityOperation `json:"operations"`
+}
+
+// IntegrityOperation contains the anchored data of an operation that was applied to the document
+type IntegrityOperation struct {
+	// Type is the type of the operation (create, update or recover)
+	Type string `json:"type"`
+	// SignedData is the compact JWS of the signed data that contains the delta hash (update and recover only)
+	SignedData string `json:"signedData,omitempty"`
+	// Delta is the encoded delta of the operation
+	Delta string `json:"delta"`
+	// TransactionTime is the transaction time of the operation which selects the protocol version (e.g. the
+	// character policy) that the operation is applied with (not set for unpublished documents)
+	TransactionTime uint64 `json:"transactionTime,omitempty"`
+}
+
+// SkippedOperation describes an anchored operation that was not applied during resolution and why
+type SkippedOperation struct {
+	Type              string `json:"type"`
+	TransactionTime   uint64 `json:"transactionTime"`
+	TransactionNumber uint64 `json:"transactionNumber"`
+	UpdateCommitment  string `json:"updateCommitment,omitempty"`
+	Reason            string `json:"reason"`
 }
@@ -0,0 +1,35 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package document
+
+// SuffixStatus is the status of a unique suffix
+type SuffixStatus string
+
+const (
+	// SuffixAvailable indicates that no document exists for the suffix
+	SuffixAvailable SuffixStatus = "available"
+
+	// SuffixPublished indicates that a document was published for the suffix
+	SuffixPublished SuffixStatus = "published"
+
+	// SuffixDeactivated indicates that the document for the suffix was deactivated
+	SuffixDeactivated SuffixStatus = "deactivated"
+
+	// SuffixDenied indicates that create operations for the suffix are rejected
+	SuffixDenied SuffixStatus = "denied"
+)
+
+// SuffixCheck is the result of checking whether a unique suffix is in use
+type SuffixCheck struct {
+	UniqueSuffix string       `json:"uniqueSuffix"`
+	Status       SuffixStatus `json:"status"`
+}
+
+// Available returns true if a create operation may be submitted for the suffix
+func (c *SuffixCheck) Available() bool {
+	return c.Status == SuffixAvailable
+}
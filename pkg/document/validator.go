@@ -98,42 +98,68 @@ var allowedKeyTypes = map[string]existenceMap{
 	invocation: allowedKeyTypesVerification,
 }
 
-// ValidatePublicKeys validates public keys
+// PropertyError is returned by the validators when a property is invalid. Path is the JSON Pointer (RFC 6901)
+// of the property relative to the validated value, e.g. /0/jwk for the JWK of the first public key.
+type PropertyError struct {
+	Path string
+	Err  error
+}
+
+// Error returns the error message (without the path)
+func (e *PropertyError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *PropertyError) Unwrap() error {
+	return e.Err
+}
+
+func newPropertyError(index int, property string, err error) error {
+	path := fmt.Sprintf("/%d", index)
+	if property != "" {
+		path += "/" + property
+	}
+
+	return &PropertyError{Path: path, Err: err}
+}
+
+// ValidatePublicKeys validates public keys. A PropertyError is returned if a public key is invalid.
 func ValidatePublicKeys(pubKeys []PublicKey) error {
 	ids := make(map[string]string)
 
 	// the expected fields are id, usage, type and jwk
-	for _, pubKey := range pubKeys {
+	for i, pubKey := range pubKeys {
 		kid := pubKey.ID()
 		if err := validateKID(kid); err != nil {
-			return err
+			return newPropertyError(i, IDProperty, err)
 		}
 
 		if len(pubKey) != maxPublicKeyProperties {
-			return errors.New("invalid number of public key properties")
+			return newPropertyError(i, "", errors.New("invalid number of public key properties"))
 		}
 
 		if _, ok := ids[kid]; ok {
-			return fmt.Errorf("duplicate public key id: %s", kid)
+			return newPropertyError(i, IDProperty, fmt.Errorf("duplicate public key id: %s", kid))
 		}
 		ids[kid] = kid
 
 		if err := validateKeyUsage(pubKey); err != nil {
-			return err
+			return newPropertyError(i, UsageProperty, err)
 		}
 
 		if IsOperationsKey(pubKey.Usage()) {
 			if err := ValidateOperationsKey(pubKey); err != nil {
-				return err
+				return newPropertyError(i, JwkProperty, err)
 			}
 		}
 
 		if !validateKeyTypeUsage(pubKey) {
-			return fmt.Errorf("invalid key type: %s", pubKey.Type())
+			return newPropertyError(i, TypeProperty, fmt.Errorf("invalid key type: %s", pubKey.Type()))
 		}
 
 		if err := ValidateJWK(pubKey.JWK()); err != nil {
-			return err
+			return newPropertyError(i, JwkProperty, err)
 		}
 	}
 
@@ -165,10 +191,31 @@ func ValidateID(id string) error {
 	return nil
 }
 
-// ValidateServices validates services
-func ValidateServices(services []Service) error {
-	for _, service := range services {
-		if err := validateService(service); err != nil {
+// validationOpts holds options for document validation
+type validationOpts struct {
+	charPolicy CharacterPolicy
+}
+
+// ValidationOpt is a document validation option
+type ValidationOpt func(opts *validationOpts)
+
+// WithCharacterPolicy sets the policy for characters that are permitted in document string fields
+func WithCharacterPolicy(policy CharacterPolicy) ValidationOpt {
+	return func(opts *validationOpts) {
+		opts.charPolicy = policy
+	}
+}
+
+// ValidateServices validates services. A PropertyError is returned if a service is invalid.
+func ValidateServices(services []Service, opts ...ValidationOpt) error {
+	vOpts := &validationOpts{charPolicy: DefaultCharacterPolicy()}
+
+	for _, opt := range opts {
+		opt(vOpts)
+	}
+
+	for i, service := range services {
+		if err := validateService(i, service, vOpts.charPolicy); err != nil {
 			return err
 		}
 	}
@@ -176,19 +223,19 @@ func ValidateServices(services []Service) error {
 	return nil
 }
 
-func validateService(service Service) error {
+func validateService(index int, service Service, policy CharacterPolicy) error {
 	// expected fields are type, id, and serviceEndpoint
 
 	if err := validateServiceID(service.ID()); err != nil {
-		return err
+		return newPropertyError(index, IDProperty, err)
 	}
 
-	if err := validateServiceType(service.Type()); err != nil {
-		return err
+	if err := validateServiceType(service.Type(), policy); err != nil {
+		return newPropertyError(index, TypeProperty, err)
 	}
 
-	if err := validateServiceEndpoint(service.Endpoint()); err != nil {
-		return err
+	if err := validateServiceEndpoint(service.Endpoint(), policy); err != nil {
+		return newPropertyError(index, ServiceEndpointProperty, err)
 	}
 
 	return nil
@@ -206,7 +253,7 @@ func validateServiceID(id string) error {
 	return nil
 }
 
-func validateServiceType(serviceType string) error {
+func validateServiceType(serviceType string, policy CharacterPolicy) error {
 	if serviceType == "" {
 		return errors.New("service type is missing")
 	}
@@ -215,10 +262,14 @@ func validateServiceType(serviceType string) error {
 		return fmt.Errorf("service type exceeds maximum length: %d", maxServiceTypeLength)
 	}
 
+	if err := policy.ValidateString(serviceType); err != nil {
+		return fmt.Errorf("service type: %s", err.Error())
+	}
+
 	return nil
 }
 
-func validateServiceEndpoint(serviceEndpoint string) error {
+func validateServiceEndpoint(serviceEndpoint string, policy CharacterPolicy) error {
 	if serviceEndpoint == "" {
 		return errors.New("service endpoint is missing")
 	}
@@ -227,6 +278,10 @@ func validateServiceEndpoint(serviceEndpoint string) error {
 		return fmt.Errorf("service endpoint exceeds maximum length: %d", maxServiceEndpointLength)
 	}
 
+	if err := policy.ValidateString(serviceEndpoint); err != nil {
+		return fmt.Errorf("service endpoint: %s", err.Error())
+	}
+
 	if _, err := url.ParseRequestURI(serviceEndpoint); err != nil {
 		return fmt.Errorf("service endpoint is not valid URI: %s", err.Error())
 	}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package document
 
 import (
+	"errors"
 	"io/ioutil"
 	"testing"
 
@@ -58,6 +59,10 @@ func TestValidatePublicKeysErrors(t *testing.T) {
 		err = ValidatePublicKeys(doc.PublicKeys())
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "invalid key type")
+
+		var propErr *PropertyError
+		require.True(t, errors.As(err, &propErr))
+		require.Equal(t, "/0/type", propErr.Path)
 	})
 	t.Run("missing id", func(t *testing.T) {
 		doc, err := DidDocumentFromBytes([]byte(noID))
@@ -10,25 +10,52 @@ import (
 	"crypto"
 	"fmt"
 	"hash"
+	"sync"
 
 	"github.com/multiformats/go-multihash"
 )
 
-const sha2_256 = 18
+const (
+	sha2_256 = 18
+
+	// maxDigestSize is the maximum size of a digest of the supported hash algorithms
+	maxDigestSize = 64
+)
+
+// hasherPools contains a pool of reusable hashers for each of the supported multihash codes. Allocating a new
+// hasher for each hash is expensive when parsing large batches of operations.
+var hasherPools = map[uint]*sync.Pool{
+	sha2_256: {New: func() interface{} { return crypto.SHA256.New() }},
+}
 
 // ComputeMultihash will compute the hash for the supplied bytes using multihash code
 func ComputeMultihash(multihashCode uint, bytes []byte) ([]byte, error) {
-	h, err := GetHash(multihashCode)
+	pool, ok := hasherPools[multihashCode]
+	if !ok {
+		return nil, fmt.Errorf("algorithm not supported, unable to compute hash")
+	}
+
+	h := pool.Get().(hash.Hash)
+	defer pool.Put(h)
+
+	var digest [maxDigestSize]byte
+
+	sum, err := computeHash(h, bytes, digest[:0])
 	if err != nil {
 		return nil, err
 	}
 
-	if _, hashErr := h.Write(bytes); hashErr != nil {
-		return nil, hashErr
+	return multihash.Encode(sum, uint64(multihashCode))
+}
+
+func computeHash(h hash.Hash, value, digest []byte) ([]byte, error) {
+	h.Reset()
+
+	if _, err := h.Write(value); err != nil {
+		return nil, err
 	}
-	hash := h.Sum(nil)
 
-	return multihash.Encode(hash, uint64(multihashCode))
+	return h.Sum(digest), nil
 }
 
 // GetHash will return hash based on specified multihash code
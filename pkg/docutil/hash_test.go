@@ -7,8 +7,11 @@ SPDX-License-Identifier: Apache-2.0
 package docutil
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
+	"github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
 )
 
@@ -71,3 +74,75 @@ func TestIsComputedUsingHashAlgorithm(t *testing.T) {
 	ok = IsComputedUsingHashAlgorithm("invalid", sha2_256)
 	require.False(t, ok)
 }
+
+func TestComputeMultihash_Concurrent(t *testing.T) {
+	expected, err := ComputeMultihash(sha2_256, sample)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				hash, err := ComputeMultihash(sha2_256, sample)
+				require.NoError(t, err)
+				require.Equal(t, expected, hash)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+const benchmarkBatchSize = 10000
+
+func BenchmarkComputeMultihash_Unpooled(b *testing.B) {
+	values := benchmarkValues()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for _, value := range values {
+			h, err := GetHash(sha2_256)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			h.Write(value) //nolint:errcheck
+
+			if _, err := multihash.Encode(h.Sum(nil), sha2_256); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkComputeMultihash(b *testing.B) {
+	values := benchmarkValues()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for _, value := range values {
+			if _, err := ComputeMultihash(sha2_256, value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// benchmarkValues returns values the size of typical operation deltas for a batch of benchmarkBatchSize operations
+func benchmarkValues() [][]byte {
+	values := make([][]byte, benchmarkBatchSize)
+	for i := range values {
+		values[i] = []byte(fmt.Sprintf(`{"patches":[{"action":"replace","document":{"id":"%d"}}],"update_commitment":"EiD"}`, i))
+	}
+
+	return values
+}
@@ -0,0 +1,27 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package docutil
+
+import (
+	"fmt"
+)
+
+// ValidateRevealValue checks that the length of the reveal value is within the given bounds.
+// A bound of zero is not checked.
+func ValidateRevealValue(revealValue []byte, minLength, maxLength uint) error {
+	length := uint(len(revealValue))
+
+	if minLength > 0 && length < minLength {
+		return fmt.Errorf("reveal value length [%d] is less than the minimum length [%d]", length, minLength)
+	}
+
+	if maxLength > 0 && length > maxLength {
+		return fmt.Errorf("reveal value length [%d] exceeds the maximum length [%d]", length, maxLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package docutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRevealValue(t *testing.T) {
+	t.Run("no bounds", func(t *testing.T) {
+		require.NoError(t, ValidateRevealValue(nil, 0, 0))
+		require.NoError(t, ValidateRevealValue([]byte("a"), 0, 0))
+	})
+
+	t.Run("within bounds", func(t *testing.T) {
+		require.NoError(t, ValidateRevealValue([]byte("1234"), 4, 8))
+		require.NoError(t, ValidateRevealValue([]byte("12345678"), 4, 8))
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		err := ValidateRevealValue([]byte("123"), 4, 8)
+		require.EqualError(t, err, "reveal value length [3] is less than the minimum length [4]")
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		err := ValidateRevealValue([]byte("123456789"), 4, 8)
+		require.EqualError(t, err, "reveal value length [9] exceeds the maximum length [8]")
+	})
+}
@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package docutil
+
+import (
+	"crypto/sha1" //nolint:gosec // SHA-1 is mandated by RFC 4122 for name-based (version 5) UUIDs
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/multiformats/go-multihash"
+)
+
+// SuffixDeriver derives the unique suffix of a DID from the encoded suffix data of its create operation.
+// Every node of a namespace must be configured with the same deriver.
+type SuffixDeriver interface {
+	// DeriveSuffix returns the unique suffix for the encoded suffix data
+	DeriveSuffix(encodedSuffixData string, hashAlgorithmAsMultihashCode uint) (string, error)
+
+	// ValidateSuffix returns an error if the unique suffix could not have been derived by this deriver
+	ValidateSuffix(uniqueSuffix string) error
+}
+
+// MultihashSuffixDeriver is the standard Sidetree suffix deriver: the suffix is the encoded multihash
+// of the encoded suffix data (see CalculateUniqueSuffix)
+type MultihashSuffixDeriver struct{}
+
+// DeriveSuffix returns the encoded multihash of the encoded suffix data
+func (MultihashSuffixDeriver) DeriveSuffix(encodedSuffixData string, hashAlgorithmAsMultihashCode uint) (string, error) {
+	return CalculateUniqueSuffix(encodedSuffixData, hashAlgorithmAsMultihashCode)
+}
+
+// ValidateSuffix checks that the unique suffix is an encoded multihash
+func (MultihashSuffixDeriver) ValidateSuffix(uniqueSuffix string) error {
+	multihashBytes, err := DecodeString(uniqueSuffix)
+	if err != nil {
+		return fmt.Errorf("invalid unique suffix: %s", err.Error())
+	}
+
+	if _, err := multihash.Decode(multihashBytes); err != nil {
+		return fmt.Errorf("invalid unique suffix: %s", err.Error())
+	}
+
+	return nil
+}
+
+// UUIDSuffixDeriver derives name-based (version 5) UUIDs from the encoded suffix data. It may be used by
+// private deployments that require UUID identifiers. Note that the suffix does not depend on the hash algorithm.
+type UUIDSuffixDeriver struct {
+	namespace []byte
+}
+
+// NewUUIDSuffixDeriver returns a UUID suffix deriver for the given namespace UUID
+// (e.g. "6ba7b811-9dad-11d1-80b4-00c04fd430c8")
+func NewUUIDSuffixDeriver(namespaceUUID string) (*UUIDSuffixDeriver, error) {
+	namespace, err := parseUUID(namespaceUUID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace UUID: %s", err.Error())
+	}
+
+	return &UUIDSuffixDeriver{namespace: namespace}, nil
+}
+
+// DeriveSuffix returns the version 5 UUID of the encoded suffix data
+func (d *UUIDSuffixDeriver) DeriveSuffix(encodedSuffixData string, _ uint) (string, error) {
+	h := sha1.New() //nolint:gosec
+	h.Write(d.namespace)
+	h.Write([]byte(encodedSuffixData))
+
+	u := h.Sum(nil)[:16]
+	u[6] = (u[6] & 0x0f) | 0x50 // version 5
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return formatUUID(u), nil
+}
+
+// ValidateSuffix checks that the unique suffix is a version 5 UUID
+func (d *UUIDSuffixDeriver) ValidateSuffix(uniqueSuffix string) error {
+	u, err := parseUUID(uniqueSuffix)
+	if err != nil {
+		return fmt.Errorf("invalid unique suffix: %s", err.Error())
+	}
+
+	if u[6]>>4 != 5 || u[8]>>6 != 2 { //nolint:gomnd
+		return errors.New("invalid unique suffix: not a version 5 UUID")
+	}
+
+	return nil
+}
+
+func parseUUID(s string) ([]byte, error) {
+	const uuidLength = 36
+
+	if len(s) != uuidLength || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return nil, fmt.Errorf("[%s] is not a UUID", s)
+	}
+
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil {
+		return nil, fmt.Errorf("[%s] is not a UUID", s)
+	}
+
+	return b, nil
+}
+
+func formatUUID(u []byte) string {
+	s := hex.EncodeToString(u)
+
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package docutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const dnsNamespaceUUID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+func TestMultihashSuffixDeriver(t *testing.T) {
+	d := MultihashSuffixDeriver{}
+
+	suffix, err := d.DeriveSuffix("suffixData", sha2_256)
+	require.NoError(t, err)
+
+	expected, err := CalculateUniqueSuffix("suffixData", sha2_256)
+	require.NoError(t, err)
+	require.Equal(t, expected, suffix)
+
+	require.NoError(t, d.ValidateSuffix(suffix))
+
+	err = d.ValidateSuffix("!!!")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid unique suffix")
+
+	err = d.ValidateSuffix(EncodeToString([]byte("not a multihash")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid unique suffix")
+
+	_, err = d.DeriveSuffix("suffixData", 55)
+	require.Error(t, err)
+}
+
+func TestUUIDSuffixDeriver(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		d, err := NewUUIDSuffixDeriver(dnsNamespaceUUID)
+		require.NoError(t, err)
+
+		// test vector from the Python uuid module: uuid.uuid5(uuid.NAMESPACE_DNS, 'python.org')
+		suffix, err := d.DeriveSuffix("python.org", sha2_256)
+		require.NoError(t, err)
+		require.Equal(t, "886313e1-3b8a-5372-9b90-0c9aee199e5d", suffix)
+
+		require.NoError(t, d.ValidateSuffix(suffix))
+	})
+
+	t.Run("invalid namespace", func(t *testing.T) {
+		d, err := NewUUIDSuffixDeriver("invalid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid namespace UUID")
+		require.Nil(t, d)
+	})
+
+	t.Run("invalid suffix", func(t *testing.T) {
+		d, err := NewUUIDSuffixDeriver(dnsNamespaceUUID)
+		require.NoError(t, err)
+
+		err = d.ValidateSuffix("suffix")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not a UUID")
+
+		err = d.ValidateSuffix("zza7b810-9dad-11d1-80b4-00c04fd430c8")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not a UUID")
+
+		// version 1 UUID
+		err = d.ValidateSuffix(dnsNamespaceUUID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not a version 5 UUID")
+	})
+}
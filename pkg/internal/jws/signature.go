@@ -11,6 +11,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -40,6 +41,35 @@ func VerifySignature(jwk *jws.JWK, signature, msg []byte) error {
 	}
 }
 
+// ValidatePublicKey validates that the JWK contains a public key of a supported key type (EC with the P-256, P-384,
+// P-521 or secp256k1 curve, or OKP with the Ed25519 curve) that can be used for verifying signatures
+func ValidatePublicKey(jwk *jws.JWK) error {
+	switch jwk.Kty {
+	case "EC":
+		if parseEllipticCurve(jwk.Crv) == nil {
+			return fmt.Errorf("ecdsa: unsupported elliptic curve '%s'", jwk.Crv)
+		}
+
+		_, err := getECPublicKey(jwk)
+
+		return err
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return fmt.Errorf("'%s' curve is not supported for key type '%s'", jwk.Crv, jwk.Kty)
+		}
+
+		// the JWK parser pads (or truncates) x to the size of an ed25519 key so the size is checked here
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return errors.New("ed25519: invalid key")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("'%s' key type is not supported", jwk.Kty)
+	}
+}
+
 func verifyEd25519Signature(jwk *jws.JWK, signature, msg []byte) error {
 	pubKey, err := GetED25519PublicKey(jwk)
 	if err != nil {
@@ -86,26 +116,11 @@ func verifyECSignature(jwk *jws.JWK, signature, msg []byte) error {
 		return fmt.Errorf("ecdsa: unsupported elliptic curve '%s'", jwk.Crv)
 	}
 
-	jwkBytes, err := json.Marshal(jwk)
-	if err != nil {
-		return err
-	}
-
-	internalJWK := JWK{
-		Kty: jwk.Kty,
-		Crv: jwk.Crv,
-	}
-
-	err = internalJWK.UnmarshalJSON(jwkBytes)
+	ecdsaPubKey, err := getECPublicKey(jwk)
 	if err != nil {
 		return err
 	}
 
-	ecdsaPubKey, ok := internalJWK.JSONWebKey.Key.(*ecdsa.PublicKey)
-	if !ok {
-		return errors.New("not an EC public key")
-	}
-
 	if len(signature) != 2*ec.keySize {
 		return errors.New("ecdsa: invalid signature size")
 	}
@@ -130,6 +145,30 @@ func verifyECSignature(jwk *jws.JWK, signature, msg []byte) error {
 	return nil
 }
 
+func getECPublicKey(jwk *jws.JWK) (*ecdsa.PublicKey, error) {
+	jwkBytes, err := json.Marshal(jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	internalJWK := JWK{
+		Kty: jwk.Kty,
+		Crv: jwk.Crv,
+	}
+
+	err = internalJWK.UnmarshalJSON(jwkBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdsaPubKey, ok := internalJWK.JSONWebKey.Key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an EC public key")
+	}
+
+	return ecdsaPubKey, nil
+}
+
 type ellipticCurve struct {
 	curve   elliptic.Curve
 	keySize int
@@ -14,6 +14,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/btcsuite/btcd/btcec"
@@ -214,6 +215,61 @@ func TestVerifyED25519Signature(t *testing.T) {
 	})
 }
 
+func TestValidatePublicKey(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		secp256k1Key, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+		require.NoError(t, err)
+
+		ed25519Key, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		for _, pubKey := range []interface{}{&p256Key.PublicKey, &secp256k1Key.PublicKey, ed25519Key} {
+			jwk, err := getPublicKeyJWK(pubKey)
+			require.NoError(t, err)
+
+			require.NoError(t, ValidatePublicKey(jwk))
+		}
+	})
+
+	t.Run("unsupported key type", func(t *testing.T) {
+		err := ValidatePublicKey(&jws.JWK{Kty: "RSA"})
+		require.EqualError(t, err, "'RSA' key type is not supported")
+	})
+
+	t.Run("unsupported elliptic curve", func(t *testing.T) {
+		err := ValidatePublicKey(&jws.JWK{Kty: "EC", Crv: "P-224"})
+		require.EqualError(t, err, "ecdsa: unsupported elliptic curve 'P-224'")
+	})
+
+	t.Run("unsupported OKP curve", func(t *testing.T) {
+		err := ValidatePublicKey(&jws.JWK{Kty: "OKP", Crv: "X25519"})
+		require.EqualError(t, err, "'X25519' curve is not supported for key type 'OKP'")
+	})
+
+	t.Run("invalid EC key", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := getPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		jwk.X, jwk.Y = jwk.Y, jwk.X
+
+		require.Error(t, ValidatePublicKey(jwk))
+	})
+
+	t.Run("invalid Ed25519 key", func(t *testing.T) {
+		err := ValidatePublicKey(&jws.JWK{Kty: "OKP", Crv: "Ed25519", X: strings.Repeat("A", 64)})
+		require.EqualError(t, err, "ed25519: invalid key")
+
+		err = ValidatePublicKey(&jws.JWK{Kty: "OKP", Crv: "Ed25519", X: "!"})
+		require.EqualError(t, err, "ed25519: invalid key")
+	})
+}
+
 func getECSignatureSHA256(privateKey *ecdsa.PrivateKey, payload []byte) []byte {
 	return getECSignature(privateKey, payload, crypto.SHA256)
 }
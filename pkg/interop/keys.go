@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interop
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/verifier"
+)
+
+// KeySet contains the keys that the vectors are generated for. Keys are either ECDSA keys (*ecdsa.PrivateKey on
+// the P-256 or secp256k1 curve) or Ed25519 keys (ed25519.PrivateKey).
+type KeySet struct {
+	// RecoveryKey is the recovery key of the created document (signs the recover operation)
+	RecoveryKey crypto.PrivateKey
+
+	// NextRecoveryKey is the recovery key that is set by the recover operation (signs the deactivate operation)
+	NextRecoveryKey crypto.PrivateKey
+
+	// UpdateKey is the operations key of the created document (signs the update operation)
+	UpdateKey crypto.PrivateKey
+
+	// NextUpdateKey is the operations key of the recovered document (signs the update after the recover operation)
+	NextUpdateKey crypto.PrivateKey
+
+	// Seed is used to derive the reveal values of the operations. The reveal value with a given name
+	// (e.g. "update-1") is the SHA-256 hash of the seed followed by the name.
+	Seed []byte
+}
+
+// RevealValue returns the reveal value with the given name that is derived from the seed
+func (k *KeySet) RevealValue(name string) []byte {
+	hash := sha256.Sum256(append(append([]byte(nil), k.Seed...), name...))
+
+	return hash[:]
+}
+
+func (k *KeySet) validate() error {
+	if k == nil {
+		return errors.New("missing key set")
+	}
+
+	if len(k.Seed) == 0 {
+		return errors.New("missing seed")
+	}
+
+	keys := []struct {
+		name string
+		key  crypto.PrivateKey
+	}{
+		{"recovery key", k.RecoveryKey},
+		{"next recovery key", k.NextRecoveryKey},
+		{"update key", k.UpdateKey},
+		{"next update key", k.NextUpdateKey},
+	}
+
+	for _, key := range keys {
+		if key.key == nil {
+			return fmt.Errorf("missing %s", key.name)
+		}
+	}
+
+	return nil
+}
+
+// newSigner returns a signer with the given key ID (omitted if empty) and the public key of the given private key
+func newSigner(key crypto.PrivateKey, kid string) (helper.Signer, *jws.JWK, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		var alg string
+
+		switch k.Curve {
+		case elliptic.P256():
+			alg = verifier.ES256
+		case btcec.S256():
+			alg = verifier.ES256K
+		default:
+			return nil, nil, fmt.Errorf("unsupported curve '%s'", k.Curve.Params().Name)
+		}
+
+		jwk, err := pubkey.GetPublicKeyJWK(&k.PublicKey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return ecsigner.New(k, alg, kid), jwk, nil
+	case ed25519.PrivateKey:
+		jwk, err := pubkey.GetPublicKeyJWK(k.Public())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return edsigner.New(k, verifier.EdDSA, kid), jwk, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type '%s'", reflect.TypeOf(key))
+	}
+}
@@ -0,0 +1,412 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package interop generates interoperability test vectors so that other Sidetree implementations can be
+// cross-tested against this implementation.
+//
+// The vectors for a key set contain the requests of a create, update, recover, update (signed by the recovered
+// operations key) and deactivate operation that are built with the request SDK (see the helper package), the
+// unique suffix that is expected for each request and the resolution result that is expected once the operation
+// has been anchored. The expected values are computed by running the requests through the document handler and
+// the operation processor of this implementation against an in-memory ledger, without any network access.
+//
+// Note that ECDSA signatures are randomized, so the requests (but not the expected suffixes and resolution
+// results) differ between runs unless Ed25519 keys are used.
+package interop
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler/didvalidator"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/processor"
+	"github.com/trustbloc/sidetree-core-go/pkg/protocolclient"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+)
+
+const (
+	defaultNamespace = "did:sidetree"
+
+	updateKeyID     = "update-key"
+	nextUpdateKeyID = "next-update-key"
+
+	publicKeyType = "JwsVerificationKey2020"
+	opsUsage      = "ops"
+)
+
+// Vectors contains the interop vectors for a key set
+type Vectors struct {
+	Namespace string            `json:"namespace"`
+	Protocol  protocol.Protocol `json:"protocol"`
+	// PublicKeys contains the public keys of the key set by role
+	// (recoveryKey, nextRecoveryKey, updateKey and nextUpdateKey)
+	PublicKeys map[string]*jws.JWK `json:"publicKeys"`
+	Vectors    []*Vector           `json:"vectors"`
+}
+
+// Vector contains an operation request and the expected results of the request. The vectors of a key set
+// must be applied in order.
+type Vector struct {
+	Name          string              `json:"name"`
+	OperationType batch.OperationType `json:"operationType"`
+	Request       json.RawMessage     `json:"request"`
+	// ExpectedSuffix is the unique suffix of the DID that the request applies to
+	ExpectedSuffix string `json:"expectedSuffix"`
+	// ExpectedResolution is the result of resolving the DID once the operation has been anchored
+	// (not set if the operation deactivates the document)
+	ExpectedResolution *document.ResolutionResult `json:"expectedResolution,omitempty"`
+	// ExpectedDeactivated is true if the document is expected to be deactivated once the operation has been anchored
+	ExpectedDeactivated bool `json:"expectedDeactivated,omitempty"`
+}
+
+// Write writes the vectors as indented JSON
+func (v *Vectors) Write(w io.Writer) error {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(bytes, '\n'))
+
+	return err
+}
+
+// Option is a vector generation option
+type Option func(g *generator)
+
+// WithNamespace sets the namespace of the DIDs (default "did:sidetree")
+func WithNamespace(namespace string) Option {
+	return func(g *generator) {
+		g.namespace = namespace
+	}
+}
+
+// WithProtocol sets the protocol parameters that the requests are built and processed with
+// (protocol.Default() by default)
+func WithProtocol(p protocol.Protocol) Option {
+	return func(g *generator) {
+		g.protocol = p
+	}
+}
+
+// Generate generates the interop vectors for the given key set
+func Generate(keys *KeySet, opts ...Option) (*Vectors, error) {
+	if err := keys.validate(); err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		keys:      keys,
+		namespace: defaultNamespace,
+		protocol:  protocol.Default(),
+		ledger:    &ledger{ops: make(map[string][]*batch.Operation)},
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if err := g.init(); err != nil {
+		return nil, err
+	}
+
+	steps := []func() (*Vector, error){
+		g.create,
+		g.update,
+		g.recover,
+		g.updateAfterRecover,
+		g.deactivate,
+	}
+
+	vectors := &Vectors{
+		Namespace:  g.namespace,
+		Protocol:   g.protocol,
+		PublicKeys: g.publicKeys,
+	}
+
+	for _, step := range steps {
+		vector, err := step()
+		if err != nil {
+			return nil, err
+		}
+
+		vectors.Vectors = append(vectors.Vectors, vector)
+	}
+
+	return vectors, nil
+}
+
+type generator struct {
+	keys      *KeySet
+	namespace string
+	protocol  protocol.Protocol
+	ledger    *ledger
+	pc        protocol.Client
+	handler   *dochandler.DocumentHandler
+
+	recoverySigner, nextRecoverySigner, updateSigner, nextUpdateSigner helper.Signer
+	publicKeys                                                         map[string]*jws.JWK
+
+	suffix string
+}
+
+func (g *generator) init() error {
+	pc, err := protocolclient.New([]protocol.Protocol{g.protocol})
+	if err != nil {
+		return err
+	}
+
+	g.pc = pc
+	g.handler = dochandler.New(g.namespace, pc, didvalidator.New(g.ledger), g.ledger,
+		processor.New("interop", g.ledger, processor.WithProtocolClient(pc)))
+
+	g.publicKeys = make(map[string]*jws.JWK)
+
+	signers := []struct {
+		role   string
+		key    crypto.PrivateKey
+		kid    string
+		signer *helper.Signer
+	}{
+		{"recoveryKey", g.keys.RecoveryKey, "", &g.recoverySigner},
+		{"nextRecoveryKey", g.keys.NextRecoveryKey, "", &g.nextRecoverySigner},
+		{"updateKey", g.keys.UpdateKey, updateKeyID, &g.updateSigner},
+		{"nextUpdateKey", g.keys.NextUpdateKey, nextUpdateKeyID, &g.nextUpdateSigner},
+	}
+
+	for _, s := range signers {
+		signer, jwk, err := newSigner(s.key, s.kid)
+		if err != nil {
+			return fmt.Errorf("%s: %s", s.role, err)
+		}
+
+		*s.signer = signer
+		g.publicKeys[s.role] = jwk
+	}
+
+	return nil
+}
+
+func (g *generator) create() (*Vector, error) {
+	doc, err := g.document(updateKeyID, g.publicKeys["updateKey"])
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := helper.NewCreateRequest(&helper.CreateRequestInfo{
+		OpaqueDocument:          doc,
+		RecoveryKey:             g.publicKeys["recoveryKey"],
+		NextRecoveryRevealValue: g.keys.RevealValue("recovery-1"),
+		NextUpdateRevealValue:   g.keys.RevealValue("update-1"),
+		MultihashCode:           g.protocol.HashAlgorithmInMultiHashCode,
+		MinRevealValueLength:    g.protocol.MinRevealValueLength,
+		MaxRevealValueLength:    g.protocol.MaxRevealValueLength,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create request: %s", err)
+	}
+
+	return g.apply("create", request)
+}
+
+func (g *generator) update() (*Vector, error) {
+	p, err := patch.NewAddServiceEndpointsPatch(
+		`[{"id": "interop-service", "type": "InteropService", "serviceEndpoint": "https://example.com/interop"}]`)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := helper.NewUpdateRequest(&helper.UpdateRequestInfo{
+		DidSuffix:             g.suffix,
+		Patch:                 p,
+		UpdateRevealValue:     g.keys.RevealValue("update-1"),
+		NextUpdateRevealValue: g.keys.RevealValue("update-2"),
+		MultihashCode:         g.protocol.HashAlgorithmInMultiHashCode,
+		MinRevealValueLength:  g.protocol.MinRevealValueLength,
+		MaxRevealValueLength:  g.protocol.MaxRevealValueLength,
+		Signer:                g.updateSigner,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update request: %s", err)
+	}
+
+	return g.apply("update", request)
+}
+
+func (g *generator) recover() (*Vector, error) {
+	doc, err := g.document(nextUpdateKeyID, g.publicKeys["nextUpdateKey"])
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := helper.NewRecoverRequest(&helper.RecoverRequestInfo{
+		DidSuffix:               g.suffix,
+		RecoveryRevealValue:     g.keys.RevealValue("recovery-1"),
+		RecoveryKey:             g.publicKeys["nextRecoveryKey"],
+		OpaqueDocument:          doc,
+		NextRecoveryRevealValue: g.keys.RevealValue("recovery-2"),
+		NextUpdateRevealValue:   g.keys.RevealValue("update-3"),
+		MultihashCode:           g.protocol.HashAlgorithmInMultiHashCode,
+		MinRevealValueLength:    g.protocol.MinRevealValueLength,
+		MaxRevealValueLength:    g.protocol.MaxRevealValueLength,
+		Signer:                  g.recoverySigner,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("recover request: %s", err)
+	}
+
+	return g.apply("recover", request)
+}
+
+func (g *generator) updateAfterRecover() (*Vector, error) {
+	p, err := patch.NewAddServiceEndpointsPatch(
+		`[{"id": "recovered-service", "type": "InteropService", "serviceEndpoint": "https://example.com/recovered"}]`)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := helper.NewUpdateRequest(&helper.UpdateRequestInfo{
+		DidSuffix:             g.suffix,
+		Patch:                 p,
+		UpdateRevealValue:     g.keys.RevealValue("update-3"),
+		NextUpdateRevealValue: g.keys.RevealValue("update-4"),
+		MultihashCode:         g.protocol.HashAlgorithmInMultiHashCode,
+		MinRevealValueLength:  g.protocol.MinRevealValueLength,
+		MaxRevealValueLength:  g.protocol.MaxRevealValueLength,
+		Signer:                g.nextUpdateSigner,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update request: %s", err)
+	}
+
+	return g.apply("update-after-recover", request)
+}
+
+func (g *generator) deactivate() (*Vector, error) {
+	request, err := helper.NewDeactivateRequest(&helper.DeactivateRequestInfo{
+		DidSuffix:           g.suffix,
+		RecoveryRevealValue: g.keys.RevealValue("recovery-2"),
+		Signer:              g.nextRecoverySigner,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("deactivate request: %s", err)
+	}
+
+	return g.apply("deactivate", request)
+}
+
+// apply processes and anchors the given request and returns the vector with the expected results
+func (g *generator) apply(name string, request []byte) (*Vector, error) {
+	pv, err := g.pc.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err := pv.OperationParser().Parse(g.namespace, request)
+	if err != nil {
+		return nil, fmt.Errorf("%s: parse request: %s", name, err)
+	}
+
+	if _, err := g.handler.ProcessOperation(operation); err != nil {
+		return nil, fmt.Errorf("%s: process operation: %s", name, err)
+	}
+
+	g.suffix = operation.UniqueSuffix
+
+	vector := &Vector{
+		Name:           name,
+		OperationType:  operation.Type,
+		Request:        request,
+		ExpectedSuffix: operation.UniqueSuffix,
+	}
+
+	result, err := g.handler.ResolveDocument(g.namespace + docutil.NamespaceDelimiter + operation.UniqueSuffix)
+
+	switch {
+	case err == nil:
+		vector.ExpectedResolution = result
+	case operation.Type == batch.OperationTypeDeactivate && strings.Contains(err.Error(), "was deactivated"):
+		vector.ExpectedDeactivated = true
+	default:
+		return nil, fmt.Errorf("%s: resolve document: %s", name, err)
+	}
+
+	return vector, nil
+}
+
+// document returns the opaque document with the given operations key
+func (g *generator) document(kid string, jwk *jws.JWK) (string, error) {
+	doc := map[string]interface{}{
+		"publicKey": []interface{}{
+			map[string]interface{}{
+				"id":    kid,
+				"type":  publicKeyType,
+				"usage": []string{opsUsage},
+				"jwk":   jwk,
+			},
+		},
+	}
+
+	bytes, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes), nil
+}
+
+// ledger anchors operations as soon as they are added (each operation in its own transaction) and serves
+// as the operation store of the processor
+type ledger struct {
+	mutex           sync.RWMutex
+	ops             map[string][]*batch.Operation
+	transactionTime uint64
+}
+
+// Add anchors the given operation
+func (l *ledger) Add(info *batch.OperationInfo) error {
+	op := &batch.Operation{}
+	if err := json.Unmarshal(info.Data, op); err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.transactionTime++
+
+	op.TransactionTime = l.transactionTime
+	op.TransactionNumber = l.transactionTime
+
+	l.ops[op.UniqueSuffix] = append(l.ops[op.UniqueSuffix], op)
+
+	return nil
+}
+
+// Get returns the anchored operations of the given suffix
+func (l *ledger) Get(uniqueSuffix string) ([]*batch.Operation, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	ops, ok := l.ops[uniqueSuffix]
+	if !ok {
+		return nil, errors.New("uniqueSuffix not found in the store")
+	}
+
+	return append([]*batch.Operation(nil), ops...), nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interop
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("mixed key types", func(t *testing.T) {
+		keys := &KeySet{
+			RecoveryKey:     newECKey(t, elliptic.P256()),
+			NextRecoveryKey: newECKey(t, btcec.S256()),
+			UpdateKey:       newEdKey(1),
+			NextUpdateKey:   newECKey(t, elliptic.P256()),
+			Seed:            []byte("seed"),
+		}
+
+		vectors, err := Generate(keys, WithNamespace("did:interop"))
+		require.NoError(t, err)
+		require.Equal(t, "did:interop", vectors.Namespace)
+		require.Equal(t, protocol.Default(), vectors.Protocol)
+		require.Len(t, vectors.PublicKeys, 4)
+		require.Equal(t, "secp256k1", vectors.PublicKeys["nextRecoveryKey"].Crv)
+		require.Equal(t, "Ed25519", vectors.PublicKeys["updateKey"].Crv)
+
+		require.Len(t, vectors.Vectors, 5)
+
+		types := []batch.OperationType{
+			batch.OperationTypeCreate,
+			batch.OperationTypeUpdate,
+			batch.OperationTypeRecover,
+			batch.OperationTypeUpdate,
+			batch.OperationTypeDeactivate,
+		}
+
+		suffix := vectors.Vectors[0].ExpectedSuffix
+		require.NotEmpty(t, suffix)
+
+		for i, v := range vectors.Vectors {
+			require.Equal(t, types[i], v.OperationType)
+			require.Equal(t, suffix, v.ExpectedSuffix)
+			require.NotEmpty(t, v.Request)
+		}
+
+		create := vectors.Vectors[0].ExpectedResolution
+		require.NotNil(t, create)
+		require.True(t, create.MethodMetadata.Published)
+		require.Equal(t, "did:interop:"+suffix, create.Document.ID())
+		require.Equal(t, vectors.PublicKeys["recoveryKey"], create.MethodMetadata.RecoveryKey)
+
+		require.Len(t, services(t, vectors.Vectors[1].ExpectedResolution), 1)
+
+		recovered := vectors.Vectors[2].ExpectedResolution
+		require.Equal(t, vectors.PublicKeys["nextRecoveryKey"], recovered.MethodMetadata.RecoveryKey)
+		require.Empty(t, services(t, recovered))
+
+		updated := services(t, vectors.Vectors[3].ExpectedResolution)
+		require.Len(t, updated, 1)
+		require.Equal(t, "did:interop:"+suffix+"#recovered-service", updated[0].ID())
+
+		deactivate := vectors.Vectors[4]
+		require.Nil(t, deactivate.ExpectedResolution)
+		require.True(t, deactivate.ExpectedDeactivated)
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, vectors.Write(buf))
+
+		decoded := &Vectors{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), decoded))
+		require.Len(t, decoded.Vectors, 5)
+		require.Equal(t, suffix, decoded.Vectors[0].ExpectedSuffix)
+	})
+
+	t.Run("Ed25519 vectors are reproducible", func(t *testing.T) {
+		keys := &KeySet{
+			RecoveryKey:     newEdKey(1),
+			NextRecoveryKey: newEdKey(2),
+			UpdateKey:       newEdKey(3),
+			NextUpdateKey:   newEdKey(4),
+			Seed:            []byte("seed"),
+		}
+
+		first, err := Generate(keys)
+		require.NoError(t, err)
+
+		second, err := Generate(keys)
+		require.NoError(t, err)
+
+		firstBytes := &bytes.Buffer{}
+		require.NoError(t, first.Write(firstBytes))
+
+		secondBytes := &bytes.Buffer{}
+		require.NoError(t, second.Write(secondBytes))
+
+		require.Equal(t, firstBytes.String(), secondBytes.String())
+
+		keys.Seed = []byte("other")
+
+		other, err := Generate(keys)
+		require.NoError(t, err)
+		require.NotEqual(t, first.Vectors[0].ExpectedSuffix, other.Vectors[0].ExpectedSuffix)
+	})
+
+	t.Run("error - missing key set", func(t *testing.T) {
+		vectors, err := Generate(nil)
+		require.EqualError(t, err, "missing key set")
+		require.Nil(t, vectors)
+	})
+
+	t.Run("error - missing seed", func(t *testing.T) {
+		vectors, err := Generate(&KeySet{})
+		require.EqualError(t, err, "missing seed")
+		require.Nil(t, vectors)
+	})
+
+	t.Run("error - missing key", func(t *testing.T) {
+		vectors, err := Generate(&KeySet{RecoveryKey: newEdKey(1), Seed: []byte("seed")})
+		require.EqualError(t, err, "missing next recovery key")
+		require.Nil(t, vectors)
+	})
+
+	t.Run("error - unsupported key", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+
+		vectors, err := Generate(&KeySet{
+			RecoveryKey:     newEdKey(1),
+			NextRecoveryKey: newEdKey(2),
+			UpdateKey:       rsaKey,
+			NextUpdateKey:   newEdKey(4),
+			Seed:            []byte("seed"),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "updateKey: unsupported key type")
+		require.Nil(t, vectors)
+	})
+
+	t.Run("error - unsupported curve", func(t *testing.T) {
+		vectors, err := Generate(&KeySet{
+			RecoveryKey:     newECKey(t, elliptic.P384()),
+			NextRecoveryKey: newEdKey(2),
+			UpdateKey:       newEdKey(3),
+			NextUpdateKey:   newEdKey(4),
+			Seed:            []byte("seed"),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "recoveryKey: unsupported curve 'P-384'")
+		require.Nil(t, vectors)
+	})
+
+	t.Run("error - invalid protocol", func(t *testing.T) {
+		p := protocol.Default()
+		p.HashAlgorithmInMultiHashCode = 999
+
+		vectors, err := Generate(&KeySet{
+			RecoveryKey:     newEdKey(1),
+			NextRecoveryKey: newEdKey(2),
+			UpdateKey:       newEdKey(3),
+			NextUpdateKey:   newEdKey(4),
+			Seed:            []byte("seed"),
+		}, WithProtocol(p))
+		require.Error(t, err)
+		require.Nil(t, vectors)
+	})
+}
+
+func TestKeySet_RevealValue(t *testing.T) {
+	keys := &KeySet{Seed: []byte("seed")}
+
+	require.Len(t, keys.RevealValue("update-1"), 32)
+	require.Equal(t, keys.RevealValue("update-1"), keys.RevealValue("update-1"))
+	require.NotEqual(t, keys.RevealValue("update-1"), keys.RevealValue("update-2"))
+}
+
+// services returns the services of the JSON-encoded resolved document
+func services(t *testing.T, result *document.ResolutionResult) []document.Service {
+	docBytes, err := json.Marshal(result.Document)
+	require.NoError(t, err)
+
+	doc, err := document.DidDocumentFromBytes(docBytes)
+	require.NoError(t, err)
+
+	return doc.Services()
+}
+
+func newECKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+
+	return key
+}
+
+func newEdKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+}
@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package log defines the structured logger that may be injected into the Sidetree components (document handler,
+// batch writer, observer, operation processor, etc.) so that embedders can route logs to their own logging systems.
+// By default, the standard logrus logger is used.
+package log
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Field names that are used by the Sidetree components
+const (
+	// FieldNamespace is the namespace of the document
+	FieldNamespace = "namespace"
+	// FieldSuffix is the unique suffix of the document
+	FieldSuffix = "suffix"
+	// FieldOperationType is the type of the operation
+	FieldOperationType = "operationType"
+	// FieldTxnTime is the transaction time of the Sidetree transaction
+	FieldTxnTime = "txnTime"
+	// FieldTxnNumber is the transaction number of the Sidetree transaction
+	FieldTxnNumber = "txnNumber"
+	// FieldAnchorAddress is the address of the anchor file
+	FieldAnchorAddress = "anchorAddress"
+	// FieldWriter is the name of the batch writer
+	FieldWriter = "writer"
+	// FieldProcessor is the name of the operation processor
+	FieldProcessor = "processor"
+	// FieldOperationCount is the number of operations of the document
+	FieldOperationCount = "operationCount"
+)
+
+// Fields contains structured logging fields
+type Fields map[string]interface{}
+
+// Logger is a level-aware, structured logger
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+
+	// WithFields returns a logger that adds the given fields to each log entry
+	WithFields(fields Fields) Logger
+}
+
+// New returns a logger that is backed by the given logrus logger
+func New(l logrus.FieldLogger) Logger {
+	return &logrusLogger{FieldLogger: l}
+}
+
+// Default returns a logger that is backed by the standard logrus logger
+func Default() Logger {
+	return New(logrus.StandardLogger())
+}
+
+type logrusLogger struct {
+	logrus.FieldLogger
+}
+
+// WithFields returns a logger that adds the given fields to each log entry
+func (l *logrusLogger) WithFields(fields Fields) Logger {
+	return &logrusLogger{FieldLogger: l.FieldLogger.WithFields(logrus.Fields(fields))}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	require.NotNil(t, Default())
+
+	buf := &bytes.Buffer{}
+
+	l := logrus.New()
+	l.SetOutput(buf)
+	l.SetLevel(logrus.DebugLevel)
+	l.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	logger := New(l).WithFields(Fields{FieldNamespace: "did:sidetree"})
+
+	logger.Debugf("debug %d", 1)
+	logger.Infof("info %d", 2)
+	logger.WithFields(Fields{FieldSuffix: "abc"}).Warnf("warn %d", 3)
+	logger.Errorf("error %d", 4)
+
+	out := buf.String()
+	require.Contains(t, out, `level=debug msg="debug 1" namespace="did:sidetree"`)
+	require.Contains(t, out, `level=info msg="info 2" namespace="did:sidetree"`)
+	require.Contains(t, out, `level=warning msg="warn 3" namespace="did:sidetree" suffix=abc`)
+	require.Contains(t, out, `level=error msg="error 4" namespace="did:sidetree"`)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package metrics defines the hooks that are invoked by the REST handlers, batch writer, observer
+// and operation processor in order to collect node metrics. By default a no-op implementation is used.
+//
+// The following is an example of a Prometheus (github.com/prometheus/client_golang) implementation:
+//
+//	type promMetrics struct {
+//		requests      *prometheus.CounterVec
+//		parseFailures prometheus.Counter
+//		batchSize     prometheus.Histogram
+//		anchorTime    prometheus.Histogram
+//		queueWaitTime prometheus.Histogram
+//		resolveTime   prometheus.Histogram
+//		txnTime       prometheus.Histogram
+//		txnFailures   prometheus.Counter
+//	}
+//
+//	func newPromMetrics() *promMetrics {
+//		m := &promMetrics{
+//			requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+//				Namespace: "sidetree", Name: "operation_requests_total",
+//			}, []string{"type"}),
+//			parseFailures: prometheus.NewCounter(prometheus.CounterOpts{
+//				Namespace: "sidetree", Name: "operation_parse_failures_total",
+//			}),
+//			batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+//				Namespace: "sidetree", Name: "batch_cut_size",
+//				Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+//			}),
+//			anchorTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+//				Namespace: "sidetree", Name: "anchor_write_seconds",
+//			}),
+//			...
+//		}
+//
+//		prometheus.MustRegister(m.requests, m.parseFailures, m.batchSize, m.anchorTime, ...)
+//
+//		return m
+//	}
+//
+//	func (m *promMetrics) OperationRequest(t batch.OperationType) { m.requests.WithLabelValues(string(t)).Inc() }
+//	func (m *promMetrics) OperationParseFailure()                 { m.parseFailures.Inc() }
+//	func (m *promMetrics) BatchCutSize(size int)                  { m.batchSize.Observe(float64(size)) }
+//	func (m *promMetrics) AnchorWriteTime(d time.Duration)        { m.anchorTime.Observe(d.Seconds()) }
+//	func (m *promMetrics) QueueWaitTime(d time.Duration)          { m.queueWaitTime.Observe(d.Seconds()) }
+//	...
+package metrics
+
+import (
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+)
+
+// Metrics defines the hooks for collecting node metrics
+type Metrics interface {
+	// OperationRequest is invoked by the REST update handler for each valid operation request
+	OperationRequest(operationType batch.OperationType)
+
+	// OperationParseFailure is invoked by the REST update handler when an operation request fails parsing/validation
+	OperationParseFailure()
+
+	// BatchCutSize is invoked by the batch writer with the number of operations in each cut batch
+	BatchCutSize(size int)
+
+	// AnchorWriteTime is invoked by the batch writer with the time taken to store the batch and anchor files
+	// in CAS and to write the anchor to the ledger
+	AnchorWriteTime(duration time.Duration)
+
+	// QueueWaitTime is invoked by the batch writer for each anchored operation with the time that the operation
+	// spent in the operation queue (i.e. from the time it was added to the writer until its batch was anchored)
+	QueueWaitTime(duration time.Duration)
+
+	// TxnProcessTime is invoked by the observer with the time taken to process a Sidetree transaction
+	TxnProcessTime(duration time.Duration)
+
+	// TxnProcessFailure is invoked by the observer when a Sidetree transaction fails processing
+	TxnProcessFailure()
+
+	// ResolveTime is invoked by the operation processor with the time taken to resolve a document
+	ResolveTime(duration time.Duration)
+
+	// ResolveOperationCount is invoked by the operation processor with the number of operations that were
+	// retrieved in order to resolve a document
+	ResolveOperationCount(count int)
+
+	// ResolveCacheHit is invoked by the operation processor when a resolution result was served from the cache
+	ResolveCacheHit()
+
+	// ResolveCacheMiss is invoked by the operation processor when a resolution result was not found in the cache
+	ResolveCacheMiss()
+
+	// SuffixCollision is invoked by the document handler when a create operation is rejected since its suffix
+	// collides with an existing create operation with a different payload (a suspected suffix-grinding attempt)
+	SuffixCollision()
+}
+
+// Noop implements metrics that do nothing
+type Noop struct{}
+
+// NewNoop returns metrics that do nothing
+func NewNoop() *Noop {
+	return &Noop{}
+}
+
+// OperationRequest does nothing
+func (m *Noop) OperationRequest(batch.OperationType) {}
+
+// OperationParseFailure does nothing
+func (m *Noop) OperationParseFailure() {}
+
+// BatchCutSize does nothing
+func (m *Noop) BatchCutSize(int) {}
+
+// AnchorWriteTime does nothing
+func (m *Noop) AnchorWriteTime(time.Duration) {}
+
+// QueueWaitTime does nothing
+func (m *Noop) QueueWaitTime(time.Duration) {}
+
+// TxnProcessTime does nothing
+func (m *Noop) TxnProcessTime(time.Duration) {}
+
+// TxnProcessFailure does nothing
+func (m *Noop) TxnProcessFailure() {}
+
+// ResolveTime does nothing
+func (m *Noop) ResolveTime(time.Duration) {}
+
+// ResolveOperationCount does nothing
+func (m *Noop) ResolveOperationCount(int) {}
+
+// ResolveCacheHit does nothing
+func (m *Noop) ResolveCacheHit() {}
+
+// ResolveCacheMiss does nothing
+func (m *Noop) ResolveCacheMiss() {}
+
+// SuffixCollision does nothing
+func (m *Noop) SuffixCollision() {}
@@ -86,6 +86,29 @@ func (m *MockCasClient) Read(address string) ([]byte, error) {
 	return value, nil
 }
 
+// Delete deletes the content at the given address
+func (m *MockCasClient) Delete(address string) error {
+	err := m.GetError()
+	if err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.m, address)
+
+	return nil
+}
+
+// Len returns the number of items in CAS
+func (m *MockCasClient) Len() int {
+	m.RLock()
+	defer m.RUnlock()
+
+	return len(m.m)
+}
+
 // SetError injects an error into the mock client
 func (m *MockCasClient) SetError(err error) {
 	m.Lock()
@@ -118,6 +118,28 @@ func (m *MockDocumentHandler) ResolveDocument(idOrDocument string) (*document.Re
 	}, nil
 }
 
+// CheckSuffix mocks checking whether a unique suffix is in use
+func (m *MockDocumentHandler) CheckSuffix(uniqueSuffix string) (*document.SuffixCheck, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	check := &document.SuffixCheck{UniqueSuffix: uniqueSuffix}
+
+	doc, ok := m.store[m.namespace+docutil.NamespaceDelimiter+uniqueSuffix]
+
+	switch {
+	case !ok:
+		check.Status = document.SuffixAvailable
+	case doc == nil:
+		check.Status = document.SuffixDeactivated
+	default:
+		check.Status = document.SuffixPublished
+	}
+
+	return check, nil
+}
+
 // helper function to insert ID into document
 func applyID(doc document.Document, id string) document.Document {
 	// apply id to document
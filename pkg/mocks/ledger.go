@@ -0,0 +1,108 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+const ledgerChannelSize = 100
+
+// MockLedger mocks a ledger for testing purposes. It can be used as the batch writer's blockchain client and
+// as the observer's ledger. Every anchor that is written is immediately published as a transaction with
+// the current transaction time (see SetTransactionTime). Transactions are published to a buffered channel
+// (see RegisterForSidetreeTxn); if nothing consumes the channel and the buffer is full then the transaction
+// isn't published, although it may still be read with Read and Transactions.
+type MockLedger struct {
+	sync.RWMutex
+	txns    []observer.SidetreeTxn
+	txnTime uint64
+	txnsCh  chan []observer.SidetreeTxn
+	err     error
+}
+
+// NewMockLedger creates a mock ledger
+func NewMockLedger() *MockLedger {
+	return &MockLedger{
+		txnsCh: make(chan []observer.SidetreeTxn, ledgerChannelSize),
+	}
+}
+
+// SetTransactionTime sets the transaction time of subsequently written anchors
+func (m *MockLedger) SetTransactionTime(txnTime uint64) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.txnTime = txnTime
+}
+
+// SetError sets the error that is returned by WriteAnchor (nil to clear the error)
+func (m *MockLedger) SetError(err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.err = err
+}
+
+// WriteAnchor writes the anchor as a transaction and publishes the transaction
+func (m *MockLedger) WriteAnchor(anchor string) error {
+	m.Lock()
+
+	if m.err != nil {
+		m.Unlock()
+		return m.err
+	}
+
+	txn := observer.SidetreeTxn{
+		TransactionTime:   m.txnTime,
+		TransactionNumber: uint64(len(m.txns)),
+		AnchorAddress:     anchor,
+	}
+
+	m.txns = append(m.txns, txn)
+
+	// publish while holding the lock so that concurrent writers publish in transaction number order
+	select {
+	case m.txnsCh <- []observer.SidetreeTxn{txn}:
+	default:
+		// the buffer is full since nothing consumes the channel
+	}
+
+	m.Unlock()
+
+	return nil
+}
+
+// Read returns the transaction that follows the given transaction number
+func (m *MockLedger) Read(sinceTransactionNumber int) (bool, *observer.SidetreeTxn) {
+	m.RLock()
+	defer m.RUnlock()
+
+	next := sinceTransactionNumber + 1
+	if next < 0 || next >= len(m.txns) {
+		return false, nil
+	}
+
+	txn := m.txns[next]
+
+	return next < len(m.txns)-1, &txn
+}
+
+// RegisterForSidetreeTxn returns the channel that receives the transactions
+func (m *MockLedger) RegisterForSidetreeTxn() <-chan []observer.SidetreeTxn {
+	return m.txnsCh
+}
+
+// Transactions returns all transactions
+func (m *MockLedger) Transactions() []observer.SidetreeTxn {
+	m.RLock()
+	defer m.RUnlock()
+
+	return append([]observer.SidetreeTxn(nil), m.txns...)
+}
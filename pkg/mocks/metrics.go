@@ -0,0 +1,229 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+)
+
+// MockMetrics mocks metrics for testing purposes. It records the values that are reported.
+type MockMetrics struct {
+	mutex sync.RWMutex
+
+	operationRequests      map[batch.OperationType]int
+	operationParseFailures int
+	batchCutSizes          []int
+	anchorWriteTimes       []time.Duration
+	queueWaitTimes         []time.Duration
+	txnProcessTimes        []time.Duration
+	txnProcessFailures     int
+	resolveTimes           []time.Duration
+	resolveOperationCounts []int
+	resolveCacheHits       int
+	resolveCacheMisses     int
+	suffixCollisions       int
+}
+
+// NewMockMetrics returns new mock metrics
+func NewMockMetrics() *MockMetrics {
+	return &MockMetrics{operationRequests: make(map[batch.OperationType]int)}
+}
+
+// OperationRequest records an operation request
+func (m *MockMetrics) OperationRequest(operationType batch.OperationType) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.operationRequests[operationType]++
+}
+
+// OperationParseFailure records an operation parse failure
+func (m *MockMetrics) OperationParseFailure() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.operationParseFailures++
+}
+
+// BatchCutSize records the batch cut size
+func (m *MockMetrics) BatchCutSize(size int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.batchCutSizes = append(m.batchCutSizes, size)
+}
+
+// AnchorWriteTime records the anchor write time
+func (m *MockMetrics) AnchorWriteTime(duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.anchorWriteTimes = append(m.anchorWriteTimes, duration)
+}
+
+// QueueWaitTime records the time that an operation spent in the operation queue
+func (m *MockMetrics) QueueWaitTime(duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.queueWaitTimes = append(m.queueWaitTimes, duration)
+}
+
+// TxnProcessTime records the transaction processing time
+func (m *MockMetrics) TxnProcessTime(duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.txnProcessTimes = append(m.txnProcessTimes, duration)
+}
+
+// TxnProcessFailure records a transaction processing failure
+func (m *MockMetrics) TxnProcessFailure() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.txnProcessFailures++
+}
+
+// ResolveTime records the document resolution time
+func (m *MockMetrics) ResolveTime(duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.resolveTimes = append(m.resolveTimes, duration)
+}
+
+// ResolveOperationCount records the number of operations of a resolved document
+func (m *MockMetrics) ResolveOperationCount(count int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.resolveOperationCounts = append(m.resolveOperationCounts, count)
+}
+
+// ResolveCacheHit records a resolution cache hit
+func (m *MockMetrics) ResolveCacheHit() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.resolveCacheHits++
+}
+
+// ResolveCacheMiss records a resolution cache miss
+func (m *MockMetrics) ResolveCacheMiss() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.resolveCacheMisses++
+}
+
+// SuffixCollision records a suffix collision
+func (m *MockMetrics) SuffixCollision() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.suffixCollisions++
+}
+
+// OperationRequests returns the number of recorded operation requests for the given operation type
+func (m *MockMetrics) OperationRequests(operationType batch.OperationType) int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.operationRequests[operationType]
+}
+
+// OperationParseFailures returns the number of recorded operation parse failures
+func (m *MockMetrics) OperationParseFailures() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.operationParseFailures
+}
+
+// BatchCutSizes returns the recorded batch cut sizes
+func (m *MockMetrics) BatchCutSizes() []int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]int(nil), m.batchCutSizes...)
+}
+
+// AnchorWriteTimes returns the recorded anchor write times
+func (m *MockMetrics) AnchorWriteTimes() []time.Duration {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]time.Duration(nil), m.anchorWriteTimes...)
+}
+
+// QueueWaitTimes returns the recorded queue wait times
+func (m *MockMetrics) QueueWaitTimes() []time.Duration {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]time.Duration(nil), m.queueWaitTimes...)
+}
+
+// TxnProcessTimes returns the recorded transaction processing times
+func (m *MockMetrics) TxnProcessTimes() []time.Duration {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]time.Duration(nil), m.txnProcessTimes...)
+}
+
+// TxnProcessFailures returns the number of recorded transaction processing failures
+func (m *MockMetrics) TxnProcessFailures() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.txnProcessFailures
+}
+
+// ResolveTimes returns the recorded document resolution times
+func (m *MockMetrics) ResolveTimes() []time.Duration {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]time.Duration(nil), m.resolveTimes...)
+}
+
+// ResolveOperationCounts returns the recorded numbers of operations of resolved documents
+func (m *MockMetrics) ResolveOperationCounts() []int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]int(nil), m.resolveOperationCounts...)
+}
+
+// ResolveCacheHits returns the number of recorded resolution cache hits
+func (m *MockMetrics) ResolveCacheHits() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.resolveCacheHits
+}
+
+// ResolveCacheMisses returns the number of recorded resolution cache misses
+func (m *MockMetrics) ResolveCacheMisses() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.resolveCacheMisses
+}
+
+// SuffixCollisions returns the number of recorded suffix collisions
+func (m *MockMetrics) SuffixCollisions() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.suffixCollisions
+}
@@ -7,28 +7,104 @@ SPDX-License-Identifier: Apache-2.0
 package mocks
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
 )
 
 // MockProtocolClient mocks protocol for testing purposes.
 type MockProtocolClient struct {
+	// Protocol is the protocol that is used if no versions are defined
 	Protocol protocol.Protocol
+
+	// Versions contains the protocol versions ordered by starting blockchain time
+	Versions []protocol.Protocol
+
+	// Err is returned by Current and Get if set
+	Err error
 }
 
 // NewMockProtocolClient creates mocks protocol client
 func NewMockProtocolClient() *MockProtocolClient {
-	return &MockProtocolClient{
-		//nolint:gomnd // mock values are defined below.
-		Protocol: protocol.Protocol{
-			StartingBlockChainTime:       0,
-			HashAlgorithmInMultiHashCode: sha2_256,
-			MaxOperationsPerBatch:        2,
-			MaxDeltaByteSize:             2000,
-		},
+	p := protocol.Default()
+	p.HashAlgorithmInMultiHashCode = sha2_256
+	p.MaxOperationsPerBatch = 2 //nolint:gomnd // small batches for testing
+	p.MaxDeltaByteSize = 2000   //nolint:gomnd // mock value
+
+	return &MockProtocolClient{Protocol: p}
+}
+
+// NewMockProtocolClientWithVersions creates a mock protocol client with the given protocol versions, each
+// of which is in effect from its starting blockchain time. Versions are sorted by starting blockchain time and
+// an error is returned if two versions have the same starting blockchain time.
+func NewMockProtocolClientWithVersions(versions ...protocol.Protocol) (*MockProtocolClient, error) {
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("at least one protocol version is required")
+	}
+
+	sorted := append([]protocol.Protocol(nil), versions...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartingBlockChainTime < sorted[j].StartingBlockChainTime
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].StartingBlockChainTime == sorted[i-1].StartingBlockChainTime {
+			return nil, fmt.Errorf("duplicate protocol version for starting blockchain time [%d]", sorted[i].StartingBlockChainTime)
+		}
 	}
+
+	return &MockProtocolClient{
+		Protocol: sorted[len(sorted)-1],
+		Versions: sorted,
+	}, nil
 }
 
 // Current mocks getting last protocol version
-func (m *MockProtocolClient) Current() protocol.Protocol {
-	return m.Protocol
+func (m *MockProtocolClient) Current() (protocol.Version, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	if len(m.Versions) > 0 {
+		return &MockProtocolVersion{p: m.Versions[len(m.Versions)-1]}, nil
+	}
+
+	return &MockProtocolVersion{p: m.Protocol}, nil
+}
+
+// Get mocks getting the protocol version at the given transaction time
+func (m *MockProtocolClient) Get(transactionTime uint64) (protocol.Version, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	if len(m.Versions) == 0 {
+		return &MockProtocolVersion{p: m.Protocol}, nil
+	}
+
+	for i := len(m.Versions) - 1; i >= 0; i-- {
+		if uint64(m.Versions[i].StartingBlockChainTime) <= transactionTime {
+			return &MockProtocolVersion{p: m.Versions[i]}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("protocol parameters are not defined for blockchain time [%d]", transactionTime)
+}
+
+// MockProtocolVersion mocks a protocol version for testing purposes.
+type MockProtocolVersion struct {
+	p protocol.Protocol
+}
+
+// Protocol returns the protocol parameters
+func (m *MockProtocolVersion) Protocol() protocol.Protocol {
+	return m.p
+}
+
+// OperationParser returns the operation parser
+func (m *MockProtocolVersion) OperationParser() protocol.OperationParser {
+	return operation.NewParser(m.p)
 }
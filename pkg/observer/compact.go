@@ -0,0 +1,67 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+// isCompactDeactivate returns true if the given anchored operation is a deactivate operation in compact form,
+// i.e. only the ID and the signed data were anchored
+func isCompactDeactivate(op *batch.Operation) bool {
+	return op.Type == batch.OperationTypeDeactivate && op.UniqueSuffix == "" && len(op.OperationBuffer) == 0
+}
+
+// expandCompactDeactivate populates the unique suffix, the recovery reveal value and the operation request of a
+// compact deactivate operation from its signed data
+func expandCompactDeactivate(op *batch.Operation) error {
+	if op.SignedData == nil {
+		return errors.New("missing signed data for compact deactivate operation")
+	}
+
+	payload, err := docutil.DecodeString(op.SignedData.Payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode signed data for compact deactivate operation")
+	}
+
+	signedData := &model.DeactivateSignedDataModel{}
+	if err := json.Unmarshal(payload, signedData); err != nil {
+		return errors.Wrap(err, "failed to unmarshal signed data for compact deactivate operation")
+	}
+
+	ns, err := namespaceFromDocID(op.ID)
+	if err != nil {
+		return err
+	}
+
+	if op.ID != ns+docutil.NamespaceDelimiter+signedData.DidSuffix {
+		return errors.Errorf("signed DID suffix [%s] doesn't match the ID [%s] of the compact deactivate operation",
+			signedData.DidSuffix, op.ID)
+	}
+
+	request, err := docutil.MarshalCanonical(&model.DeactivateRequest{
+		Operation:           model.OperationTypeDeactivate,
+		DidSuffix:           signedData.DidSuffix,
+		RecoveryRevealValue: signedData.RecoveryRevealValue,
+		SignedData:          op.SignedData,
+	})
+	if err != nil {
+		return err
+	}
+
+	op.UniqueSuffix = signedData.DidSuffix
+	op.RecoveryRevealValue = signedData.RecoveryRevealValue
+	op.OperationBuffer = request
+
+	return nil
+}
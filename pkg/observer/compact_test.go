@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/batch/filehandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+)
+
+func TestProcessBatchFile_CompactDeactivate(t *testing.T) {
+	const namespace = "did:sidetree"
+
+	deactivateOp, err := getDeactivateOperation(namespace, "suffix")
+	require.NoError(t, err)
+
+	createReq, err := getCreateRequest()
+	require.NoError(t, err)
+
+	createOp, err := operation.NewParser(getProtocol()).Parse(namespace, createReq)
+	require.NoError(t, err)
+
+	var ops [][]byte
+	for _, op := range []*batch.Operation{createOp, deactivateOp} {
+		opBytes, e := docutil.MarshalCanonical(op)
+		require.NoError(t, e)
+
+		ops = append(ops, opBytes)
+	}
+
+	fullBatchFile, err := filehandler.New().CreateBatchFile(ops)
+	require.NoError(t, err)
+
+	batchFile, err := filehandler.New(filehandler.WithCompactDeactivate()).CreateBatchFile(ops)
+	require.NoError(t, err)
+	require.Less(t, len(batchFile), len(fullBatchFile))
+
+	dcas := mockDCAS{readFunc: func(key string) ([]byte, error) {
+		return batchFile, nil
+	}}
+
+	for _, pcProvider := range []ProtocolClientProvider{nil, &mockProtocolClientProvider{}} {
+		var stored []*batch.Operation
+
+		providers := &Providers{
+			DCASClient: dcas,
+			OpStoreProvider: &mockOperationStoreProvider{opStore: &mockOperationStore{putFunc: func(ops []*batch.Operation) error {
+				stored = append(stored, ops...)
+				return nil
+			}}},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+			PcProvider:       pcProvider,
+		}
+
+		err = NewTxnProcessor(providers).processBatchFile("batch", "", SidetreeTxn{TransactionTime: 20, TransactionNumber: 2})
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+
+		var deactivated *batch.Operation
+		for _, op := range stored {
+			if op.Type == batch.OperationTypeDeactivate {
+				deactivated = op
+			}
+		}
+
+		require.NotNil(t, deactivated)
+		require.Equal(t, deactivateOp.ID, deactivated.ID)
+		require.Equal(t, deactivateOp.UniqueSuffix, deactivated.UniqueSuffix)
+		require.Equal(t, deactivateOp.RecoveryRevealValue, deactivated.RecoveryRevealValue)
+		require.Equal(t, deactivateOp.SignedData, deactivated.SignedData)
+		require.Equal(t, deactivateOp.OperationBuffer, deactivated.OperationBuffer)
+		require.Equal(t, uint64(20), deactivated.TransactionTime)
+	}
+}
+
+func TestExpandCompactDeactivate(t *testing.T) {
+	const namespace = "did:sidetree"
+
+	deactivateOp, err := getDeactivateOperation(namespace, "suffix")
+	require.NoError(t, err)
+
+	t.Run("not compact", func(t *testing.T) {
+		require.False(t, isCompactDeactivate(deactivateOp))
+		require.False(t, isCompactDeactivate(&batch.Operation{Type: batch.OperationTypeUpdate, ID: namespace + ":suffix"}))
+		require.True(t, isCompactDeactivate(&batch.Operation{Type: batch.OperationTypeDeactivate, ID: namespace + ":suffix"}))
+	})
+
+	t.Run("missing signed data", func(t *testing.T) {
+		err := expandCompactDeactivate(&batch.Operation{Type: batch.OperationTypeDeactivate, ID: namespace + ":suffix"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing signed data")
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		err := expandCompactDeactivate(&batch.Operation{
+			Type:       batch.OperationTypeDeactivate,
+			ID:         namespace + ":suffix",
+			SignedData: &model.JWS{Payload: docutil.EncodeToString([]byte("payload"))},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal signed data")
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		err := expandCompactDeactivate(&batch.Operation{
+			Type:       batch.OperationTypeDeactivate,
+			ID:         "suffix",
+			SignedData: deactivateOp.SignedData,
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid ID")
+	})
+
+	t.Run("DID suffix mismatch", func(t *testing.T) {
+		op := &batch.Operation{
+			Type:       batch.OperationTypeDeactivate,
+			ID:         namespace + ":other",
+			SignedData: deactivateOp.SignedData,
+		}
+
+		_, err := NewTxnProcessor(&Providers{}).updateOperation(encodeOperation(t, op), 0, SidetreeTxn{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to expand compact deactivate operation")
+		require.Contains(t, err.Error(), "signed DID suffix [suffix] doesn't match the ID")
+	})
+}
+
+func getDeactivateOperation(namespace, suffix string) (*batch.Operation, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := helper.NewDeactivateRequest(&helper.DeactivateRequestInfo{
+		DidSuffix:           suffix,
+		RecoveryRevealValue: []byte("recoveryReveal"),
+		Signer:              ecsigner.New(privateKey, "ES256", ""),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return operation.NewParser(getProtocol()).Parse(namespace, request)
+}
+
+func encodeOperation(t *testing.T, op *batch.Operation) string {
+	opBytes, err := docutil.MarshalCanonical(op)
+	require.NoError(t, err)
+
+	return docutil.EncodeToString(opBytes)
+}
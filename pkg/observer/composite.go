@@ -0,0 +1,79 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CompositeAnchorPrefix is the prefix of an anchor string that carries the anchors of multiple namespaces
+// in a single ledger transaction
+const CompositeAnchorPrefix = "composite:"
+
+// NamespaceAnchor is the anchor (address of the anchor file) written for a single namespace
+type NamespaceAnchor struct {
+	// Namespace is the namespace of all of the operations referenced by the anchor
+	Namespace string `json:"ns"`
+
+	// Anchor is the address of the anchor file in CAS
+	Anchor string `json:"anchor"`
+}
+
+// EncodeCompositeAnchor encodes the given namespace anchors into a single anchor string. If only one anchor
+// is provided then the plain anchor is returned so that the transaction may be processed by observers
+// that don't support composite anchors.
+func EncodeCompositeAnchor(anchors []NamespaceAnchor) (string, error) {
+	if len(anchors) == 0 {
+		return "", errors.New("at least one anchor is required")
+	}
+
+	for _, a := range anchors {
+		if a.Namespace == "" || a.Anchor == "" {
+			return "", errors.Errorf("namespace and anchor are required: %+v", a)
+		}
+	}
+
+	if len(anchors) == 1 {
+		return anchors[0].Anchor, nil
+	}
+
+	bytes, err := json.Marshal(anchors)
+	if err != nil {
+		return "", err
+	}
+
+	return CompositeAnchorPrefix + string(bytes), nil
+}
+
+// DecodeAnchor demultiplexes the given anchor string into namespace anchors. A plain (non-composite)
+// anchor is returned as a single namespace anchor with an empty namespace, meaning that its operations
+// may belong to any namespace.
+func DecodeAnchor(anchor string) ([]NamespaceAnchor, error) {
+	if !strings.HasPrefix(anchor, CompositeAnchorPrefix) {
+		return []NamespaceAnchor{{Anchor: anchor}}, nil
+	}
+
+	var anchors []NamespaceAnchor
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(anchor, CompositeAnchorPrefix)), &anchors); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal composite anchor")
+	}
+
+	if len(anchors) == 0 {
+		return nil, errors.New("composite anchor contains no anchors")
+	}
+
+	for _, a := range anchors {
+		if a.Namespace == "" || a.Anchor == "" {
+			return nil, errors.Errorf("composite anchor contains an invalid entry: %+v", a)
+		}
+	}
+
+	return anchors, nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+)
+
+func TestEncodeCompositeAnchor(t *testing.T) {
+	t.Run("single anchor", func(t *testing.T) {
+		anchor, err := EncodeCompositeAnchor([]NamespaceAnchor{{Namespace: "did:ns1", Anchor: "anchor1"}})
+		require.NoError(t, err)
+		require.Equal(t, "anchor1", anchor)
+	})
+
+	t.Run("multiple anchors", func(t *testing.T) {
+		anchors := []NamespaceAnchor{
+			{Namespace: "did:ns1", Anchor: "anchor1"},
+			{Namespace: "did:ns2", Anchor: "anchor2"},
+		}
+
+		anchor, err := EncodeCompositeAnchor(anchors)
+		require.NoError(t, err)
+		require.Contains(t, anchor, CompositeAnchorPrefix)
+
+		decoded, err := DecodeAnchor(anchor)
+		require.NoError(t, err)
+		require.Equal(t, anchors, decoded)
+	})
+
+	t.Run("no anchors", func(t *testing.T) {
+		anchor, err := EncodeCompositeAnchor(nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "at least one anchor is required")
+		require.Empty(t, anchor)
+	})
+
+	t.Run("missing namespace", func(t *testing.T) {
+		anchor, err := EncodeCompositeAnchor([]NamespaceAnchor{{Anchor: "anchor1"}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "namespace and anchor are required")
+		require.Empty(t, anchor)
+	})
+}
+
+func TestDecodeAnchor(t *testing.T) {
+	t.Run("plain anchor", func(t *testing.T) {
+		anchors, err := DecodeAnchor("anchor1")
+		require.NoError(t, err)
+		require.Equal(t, []NamespaceAnchor{{Anchor: "anchor1"}}, anchors)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		anchors, err := DecodeAnchor(CompositeAnchorPrefix + "{")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal composite anchor")
+		require.Nil(t, anchors)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		anchors, err := DecodeAnchor(CompositeAnchorPrefix + "[]")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "composite anchor contains no anchors")
+		require.Nil(t, anchors)
+	})
+
+	t.Run("invalid entry", func(t *testing.T) {
+		anchors, err := DecodeAnchor(CompositeAnchorPrefix + `[{"ns":"did:ns1"}]`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "composite anchor contains an invalid entry")
+		require.Nil(t, anchors)
+	})
+}
+
+func TestTxnProcessor_ProcessCompositeAnchor(t *testing.T) {
+	anchor, err := EncodeCompositeAnchor([]NamespaceAnchor{
+		{Namespace: "did:ns1", Anchor: "anchor1"},
+		{Namespace: "did:ns2", Anchor: "anchor2"},
+	})
+	require.NoError(t, err)
+
+	files := map[string][]byte{
+		"anchor1": marshalAnchorFile(t, "batch1"),
+		"anchor2": marshalAnchorFile(t, "batch2"),
+		"batch1":  marshalBatchFile(t, "did:ns1:suffix1"),
+		// batch2 contains an operation for a namespace other than the anchored namespace
+		"batch2": marshalBatchFile(t, "did:ns2:suffix2", "did:ns1:suffix3"),
+	}
+
+	dcas := mockDCAS{readFunc: func(key string) ([]byte, error) {
+		content, ok := files[key]
+		if !ok {
+			return nil, fmt.Errorf("not found: %s", key)
+		}
+
+		return content, nil
+	}}
+
+	t.Run("success", func(t *testing.T) {
+		var mutex sync.Mutex
+		var stored []string
+
+		opStore := &mockOperationStore{putFunc: func(ops []*batch.Operation) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			for _, op := range ops {
+				stored = append(stored, op.ID)
+			}
+
+			return nil
+		}}
+
+		providers := &Providers{
+			DCASClient:       dcas,
+			OpStoreProvider:  &mockOperationStoreProvider{opStore: opStore},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+		}
+
+		err := NewTxnProcessor(providers).Process(SidetreeTxn{TransactionTime: 10, TransactionNumber: 1, AnchorAddress: anchor})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"did:ns1:suffix1", "did:ns2:suffix2"}, stored)
+	})
+
+	t.Run("invalid composite anchor", func(t *testing.T) {
+		providers := &Providers{
+			DCASClient:       dcas,
+			OpFilterProvider: &NoopOperationFilterProvider{},
+		}
+
+		err := NewTxnProcessor(providers).Process(SidetreeTxn{AnchorAddress: CompositeAnchorPrefix + "[]"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid anchor")
+	})
+
+	t.Run("missing anchor file", func(t *testing.T) {
+		providers := &Providers{
+			DCASClient:       dcas,
+			OpStoreProvider:  &mockOperationStoreProvider{opStore: &mockOperationStore{}},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+		}
+
+		a, err := EncodeCompositeAnchor([]NamespaceAnchor{
+			{Namespace: "did:ns1", Anchor: "anchor1"},
+			{Namespace: "did:ns2", Anchor: "anchor3"},
+		})
+		require.NoError(t, err)
+
+		err = NewTxnProcessor(providers).Process(SidetreeTxn{AnchorAddress: a})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to retrieve content for anchor: key[anchor3]")
+	})
+}
+
+func marshalAnchorFile(t *testing.T, batchAddress string) []byte {
+	bytes, err := docutil.MarshalCanonical(&AnchorFile{BatchFileHash: batchAddress})
+	require.NoError(t, err)
+
+	return bytes
+}
+
+func marshalBatchFile(t *testing.T, ids ...string) []byte {
+	var ops []string
+	for _, id := range ids {
+		b, err := docutil.MarshalCanonical(batch.Operation{ID: id, UniqueSuffix: id})
+		require.NoError(t, err)
+
+		ops = append(ops, docutil.EncodeToString(b))
+	}
+
+	bytes, err := docutil.MarshalCanonical(&BatchFile{Operations: ops})
+	require.NoError(t, err)
+
+	return bytes
+}
@@ -0,0 +1,126 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FailedTxn is a Sidetree transaction that could not be processed
+type FailedTxn struct {
+	SidetreeTxn
+
+	Error    string
+	Attempts int
+	FailedAt time.Time
+}
+
+// DeadLetterStore persists the transactions that could not be processed after all retries were exhausted
+type DeadLetterStore interface {
+	// Put adds the failed transaction to the store (replacing a previous failure of the same transaction)
+	Put(txn *FailedTxn) error
+
+	// Get returns all failed transactions ordered by transaction number
+	Get() ([]*FailedTxn, error)
+
+	// Delete removes the transaction from the store
+	Delete(txn SidetreeTxn) error
+}
+
+// Redrive re-processes all of the transactions in the dead-letter store. Transactions that are successfully
+// processed are removed from the store; the others remain in the store with the latest error. Returns the
+// number of transactions that were successfully re-processed.
+func (o *Observer) Redrive() (int, error) {
+	failed, err := o.deadLetterStore.Get()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get transactions from dead-letter store")
+	}
+
+	var n int
+
+	for _, f := range failed {
+		logger := o.processor.txnLogger(f.SidetreeTxn)
+
+		err := o.processor.Process(f.SidetreeTxn)
+		if err != nil {
+			logger.Warnf("Failed to re-drive anchor[%s]: %s", f.AnchorAddress, err)
+
+			if e := o.deadLetterStore.Put(&FailedTxn{
+				SidetreeTxn: f.SidetreeTxn,
+				Error:       err.Error(),
+				Attempts:    f.Attempts + 1,
+				FailedAt:    time.Now(),
+			}); e != nil {
+				return n, errors.Wrapf(e, "failed to update anchor[%s] in dead-letter store", f.AnchorAddress)
+			}
+
+			continue
+		}
+
+		if err := o.deadLetterStore.Delete(f.SidetreeTxn); err != nil {
+			return n, errors.Wrapf(err, "failed to delete anchor[%s] from dead-letter store", f.AnchorAddress)
+		}
+
+		logger.Infof("Successfully re-drove anchor[%s]", f.AnchorAddress)
+
+		n++
+	}
+
+	return n, nil
+}
+
+// MemDeadLetterStore is an in-memory dead-letter store
+type MemDeadLetterStore struct {
+	mutex sync.RWMutex
+	txns  map[SidetreeTxn]*FailedTxn
+}
+
+// NewMemDeadLetterStore returns a new in-memory dead-letter store
+func NewMemDeadLetterStore() *MemDeadLetterStore {
+	return &MemDeadLetterStore{txns: make(map[SidetreeTxn]*FailedTxn)}
+}
+
+// Put adds the failed transaction to the store
+func (s *MemDeadLetterStore) Put(txn *FailedTxn) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.txns[txn.SidetreeTxn] = txn
+
+	return nil
+}
+
+// Get returns all failed transactions ordered by transaction number
+func (s *MemDeadLetterStore) Get() ([]*FailedTxn, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	txns := make([]*FailedTxn, 0, len(s.txns))
+	for _, txn := range s.txns {
+		txns = append(txns, txn)
+	}
+
+	sort.Slice(txns, func(i, j int) bool {
+		return txns[i].TransactionNumber < txns[j].TransactionNumber
+	})
+
+	return txns, nil
+}
+
+// Delete removes the transaction from the store
+func (s *MemDeadLetterStore) Delete(txn SidetreeTxn) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.txns, txn)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserver_Redrive(t *testing.T) {
+	txn1 := SidetreeTxn{TransactionTime: 20, TransactionNumber: 2, AnchorAddress: anchorAddressKey}
+	txn2 := SidetreeTxn{TransactionTime: 21, TransactionNumber: 3, AnchorAddress: anchorAddressKey}
+
+	t.Run("success", func(t *testing.T) {
+		dcas := &flakyDCAS{failures: 10}
+		dlq := NewMemDeadLetterStore()
+
+		o := New(newRetryProviders(dcas, dlq), WithMaxRetries(0))
+
+		require.False(t, o.process([]SidetreeTxn{txn2, txn1}))
+
+		failed, err := dlq.Get()
+		require.NoError(t, err)
+		require.Len(t, failed, 2)
+		require.Equal(t, txn1, failed[0].SidetreeTxn)
+		require.Equal(t, txn2, failed[1].SidetreeTxn)
+
+		// the first re-drive attempt fails
+		dcas.setFailures(1)
+
+		n, err := o.Redrive()
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		failed, err = dlq.Get()
+		require.NoError(t, err)
+		require.Len(t, failed, 1)
+		require.Equal(t, txn1, failed[0].SidetreeTxn)
+		require.Equal(t, 2, failed[0].Attempts)
+
+		n, err = o.Redrive()
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		failed, err = dlq.Get()
+		require.NoError(t, err)
+		require.Empty(t, failed)
+	})
+	t.Run("no dead-letter store", func(t *testing.T) {
+		dcas := &flakyDCAS{failures: 10}
+
+		o := New(newRetryProviders(dcas, nil), WithMaxRetries(0))
+
+		require.False(t, o.process([]SidetreeTxn{txn1}))
+
+		dcas.setFailures(0)
+
+		n, err := o.Redrive()
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+	})
+	t.Run("store errors", func(t *testing.T) {
+		errExpected := errors.New("injected DLQ error")
+
+		o := New(newRetryProviders(&flakyDCAS{}, &mockDeadLetterStore{getErr: errExpected}))
+		_, err := o.Redrive()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+
+		o = New(newRetryProviders(&flakyDCAS{}, &mockDeadLetterStore{
+			txns:   []*FailedTxn{{SidetreeTxn: txn1}},
+			delErr: errExpected,
+		}))
+		_, err = o.Redrive()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to delete anchor")
+
+		o = New(newRetryProviders(&flakyDCAS{failures: 1}, &mockDeadLetterStore{
+			txns: []*FailedTxn{{SidetreeTxn: txn1}},
+			err:  errExpected,
+		}))
+		_, err = o.Redrive()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to update anchor")
+	})
+}
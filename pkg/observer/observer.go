@@ -9,16 +9,17 @@ package observer
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/metrics"
 )
 
-var logger = logrus.New()
-
 // SidetreeTxn defines info about sidetree transaction
 type SidetreeTxn struct {
 	TransactionTime   uint64
@@ -39,6 +40,10 @@ type DCAS interface {
 // OperationStore interface to access operation store
 type OperationStore interface {
 	Put(ops []*batch.Operation) error
+
+	// Get returns the stored operations of the document with the given unique suffix (or an error that
+	// contains "not found" if no operations are stored)
+	Get(uniqueSuffix string) ([]*batch.Operation, error)
 }
 
 // OperationStoreProvider returns an operation store for the given namespace
@@ -56,29 +61,68 @@ type OperationFilterProvider interface {
 	Get(namespace string) (OperationFilter, error)
 }
 
+// ProtocolClientProvider returns a protocol client for the given namespace
+type ProtocolClientProvider interface {
+	ForNamespace(namespace string) (protocol.Client, error)
+}
+
 // Providers contains all of the providers required by the TxnProcessor
 type Providers struct {
 	Ledger           Ledger
 	DCASClient       DCAS
 	OpStoreProvider  OperationStoreProvider
 	OpFilterProvider OperationFilterProvider
+
+	// PcProvider is optional. If set, the anchored operation requests are re-parsed using
+	// the operation parser of the protocol version in effect at the transaction time.
+	PcProvider ProtocolClientProvider
+
+	// Metrics is optional. If not set, no-op metrics are used.
+	Metrics metrics.Metrics
+
+	// Logger is optional. If not set, the default logger is used.
+	Logger log.Logger
+
+	// DeadLetterStore is optional. Transactions that could not be processed after all retries were exhausted
+	// are persisted to the store so that they may be re-driven (see Observer.Redrive). If not set, an in-memory
+	// dead-letter store is used.
+	DeadLetterStore DeadLetterStore
 }
 
 // Observer receives transactions over a channel and processes them by storing them to an operation store
 type Observer struct {
 	*Providers
 
-	processor *TxnProcessor
-	stopCh    chan struct{}
+	processor       *TxnProcessor
+	deadLetterStore DeadLetterStore
+	stopCh          chan struct{}
+	maxRetries      int
+	initialBackoff  time.Duration
+	maxBackoff      time.Duration
 }
 
 // New returns a new observer
-func New(providers *Providers) *Observer {
-	return &Observer{
-		Providers: providers,
-		stopCh:    make(chan struct{}, 1),
-		processor: NewTxnProcessor(providers),
+func New(providers *Providers, opts ...Option) *Observer {
+	dls := providers.DeadLetterStore
+	if dls == nil {
+		dls = NewMemDeadLetterStore()
 	}
+
+	o := &Observer{
+		Providers:       providers,
+		stopCh:          make(chan struct{}, 1),
+		processor:       NewTxnProcessor(providers),
+		deadLetterStore: dls,
+		maxRetries:      defaultMaxRetries,
+		initialBackoff:  defaultInitialBackoff,
+		maxBackoff:      defaultMaxBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
 }
 
 // Start starts observer routines
@@ -95,63 +139,118 @@ func (o *Observer) listen(txnsCh <-chan []SidetreeTxn) {
 	for {
 		select {
 		case <-o.stopCh:
-			logger.Infof("The observer has been stopped. Exiting.")
+			o.processor.logger.Infof("The observer has been stopped. Exiting.")
 			return
 
 		case txns, ok := <-txnsCh:
 			if !ok {
-				logger.Warnf("Notification channel was closed. Exiting.")
+				o.processor.logger.Warnf("Notification channel was closed. Exiting.")
 				return
 			}
 
-			o.process(txns)
+			if stopped := o.process(txns); stopped {
+				o.processor.logger.Infof("The observer has been stopped. Exiting.")
+				return
+			}
 		}
 	}
 }
 
-func (o *Observer) process(txns []SidetreeTxn) {
+// process processes the given transactions and returns true if the observer was stopped while processing
+func (o *Observer) process(txns []SidetreeTxn) bool {
 	for _, txn := range txns {
-		err := o.processor.Process(txn)
-		if err != nil {
-			logger.Warnf("Failed to process anchor[%s]: %s", txn.AnchorAddress, err.Error())
-			continue
+		if stopped := o.processWithRetry(txn); stopped {
+			return true
 		}
-		logger.Debugf("Successfully processed anchor[%s]", txn.AnchorAddress)
 	}
+
+	return false
 }
 
 // TxnProcessor processes Sidetree transactions by persisting them to an operation store
 type TxnProcessor struct {
 	*Providers
+
+	metrics metrics.Metrics
+	logger  log.Logger
 }
 
 // NewTxnProcessor returns a new document operation processor
 func NewTxnProcessor(providers *Providers) *TxnProcessor {
+	m := providers.Metrics
+	if m == nil {
+		m = metrics.NewNoop()
+	}
+
+	l := providers.Logger
+	if l == nil {
+		l = log.Default()
+	}
+
 	return &TxnProcessor{
 		Providers: providers,
+		metrics:   m,
+		logger:    l,
 	}
 }
 
 // Process persists all of the operations for the given anchor
 func (p *TxnProcessor) Process(sidetreeTxn SidetreeTxn) error {
+	startTime := time.Now()
+
+	err := p.process(sidetreeTxn)
+	if err != nil {
+		p.metrics.TxnProcessFailure()
+		return err
+	}
+
+	p.metrics.TxnProcessTime(time.Since(startTime))
+
+	return nil
+}
+
+func (p *TxnProcessor) process(sidetreeTxn SidetreeTxn) error {
+	logger := p.txnLogger(sidetreeTxn)
+
 	logger.Debugf("processing sidetree txn:%+v", sidetreeTxn)
 
-	content, err := p.DCASClient.Read(sidetreeTxn.AnchorAddress)
+	anchors, err := DecodeAnchor(sidetreeTxn.AnchorAddress)
 	if err != nil {
-		return errors.Wrapf(err, "failed to retrieve content for anchor: key[%s]", sidetreeTxn.AnchorAddress)
+		return errors.Wrapf(err, "invalid anchor[%s]", sidetreeTxn.AnchorAddress)
 	}
 
-	logger.Debugf("cas content for anchor[%s]: %s", sidetreeTxn.AnchorAddress, string(content))
+	for _, anchor := range anchors {
+		if err := p.processAnchor(anchor, sidetreeTxn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *TxnProcessor) processAnchor(anchor NamespaceAnchor, sidetreeTxn SidetreeTxn) error {
+	logger := p.txnLogger(sidetreeTxn)
+
+	content, err := p.DCASClient.Read(anchor.Anchor)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve content for anchor: key[%s]", anchor.Anchor)
+	}
+
+	logger.Debugf("cas content for anchor[%s]: %s", anchor.Anchor, string(content))
 
 	af, err := getAnchorFile(content)
 	if err != nil {
-		return errors.Wrapf(err, "failed to unmarshal anchor[%s]", sidetreeTxn.AnchorAddress)
+		return errors.Wrapf(err, "failed to unmarshal anchor[%s]", anchor.Anchor)
 	}
 
-	return p.processBatchFile(af.BatchFileHash, sidetreeTxn)
+	return p.processBatchFile(af.BatchFileHash, anchor.Namespace, sidetreeTxn)
 }
 
-func (p *TxnProcessor) processBatchFile(batchFileAddress string, sidetreeTxn SidetreeTxn) error {
+// processBatchFile persists the operations in the given batch file. If namespace is not empty then
+// operations that don't belong to the namespace are skipped.
+func (p *TxnProcessor) processBatchFile(batchFileAddress, namespace string, sidetreeTxn SidetreeTxn) error {
+	logger := p.txnLogger(sidetreeTxn)
+
 	content, err := p.DCASClient.Read(batchFileAddress)
 	if err != nil {
 		return errors.Wrapf(err, "failed to retrieve content for batch: key[%s]", batchFileAddress)
@@ -165,8 +264,14 @@ func (p *TxnProcessor) processBatchFile(batchFileAddress string, sidetreeTxn Sid
 	logger.Debugf("batch file operations: %s", bf.Operations)
 	var ops []*batch.Operation
 	for index, op := range bf.Operations {
-		updatedOp, errUpdateOps := updateOperation(op, uint(index), sidetreeTxn)
+		updatedOp, errUpdateOps := p.updateOperation(op, uint(index), sidetreeTxn)
 		if errUpdateOps != nil {
+			if isInvalidOperation(errUpdateOps) {
+				// the transaction is anchored (immutable) so the remaining operations are still processed
+				logger.Warnf("Skipping invalid operation at index [%d] of batch [%s]: %s", index, batchFileAddress, errUpdateOps)
+				continue
+			}
+
 			return errors.Wrapf(errUpdateOps, "failed to update operation with blockchain metadata")
 		}
 
@@ -174,17 +279,37 @@ func (p *TxnProcessor) processBatchFile(batchFileAddress string, sidetreeTxn Sid
 		ops = append(ops, updatedOp)
 	}
 
-	for suffix, mapping := range mapOperationsByUniqueSuffix(ops) {
-		logger.Debugf("Filtering operations for namespace [%s] and suffix [%s]", mapping.namespace, suffix)
+	mappings := mapOperationsByUniqueSuffix(ops, logger)
 
-		opFilter, err := p.OpFilterProvider.Get(mapping.namespace)
-		if err != nil {
-			return errors.Wrapf(err, "error getting operation filter for namespace [%s]", mapping.namespace)
+	// the size of the batch file is validated before any of its operations are stored. Since the transaction
+	// is anchored (immutable), the operations of a namespace whose max batch file size is exceeded are skipped.
+	validated := make(map[string]bool)
+	oversized := make(map[string]bool)
+	for _, mapping := range mappings {
+		if validated[mapping.namespace] || (namespace != "" && mapping.namespace != namespace) {
+			continue
 		}
 
-		validOps, err := opFilter.Filter(suffix, mapping.operations)
-		if err != nil {
-			return errors.Wrap(err, "error filtering invalid operations")
+		if err := p.validateBatchFileSize(mapping.namespace, len(content), sidetreeTxn); err != nil {
+			if !isInvalidOperation(err) {
+				return errors.Wrapf(err, "invalid batch[%s]", batchFileAddress)
+			}
+
+			logger.Warnf("Skipping operations for namespace [%s] in batch [%s]: %s", mapping.namespace, batchFileAddress, err)
+			oversized[mapping.namespace] = true
+		}
+
+		validated[mapping.namespace] = true
+	}
+
+	for suffix, mapping := range mappings {
+		if namespace != "" && mapping.namespace != namespace {
+			logger.Warnf("Skipping operations for suffix [%s] since namespace [%s] doesn't match the anchored namespace [%s]", suffix, mapping.namespace, namespace)
+			continue
+		}
+
+		if oversized[mapping.namespace] {
+			continue
 		}
 
 		opStore, err := p.OpStoreProvider.ForNamespace(mapping.namespace)
@@ -192,6 +317,28 @@ func (p *TxnProcessor) processBatchFile(batchFileAddress string, sidetreeTxn Sid
 			return errors.Wrapf(err, "error getting operation store for namespace [%s]", mapping.namespace)
 		}
 
+		newOps, err := skipStored(opStore, suffix, mapping.operations)
+		if err != nil {
+			return err
+		}
+
+		if len(newOps) == 0 {
+			logger.Infof("Skipping operations for suffix [%s] in batch [%s] since they were already stored", suffix, batchFileAddress)
+			continue
+		}
+
+		logger.WithFields(log.Fields{log.FieldNamespace: mapping.namespace, log.FieldSuffix: suffix}).Debugf("Filtering operations")
+
+		opFilter, err := p.OpFilterProvider.Get(mapping.namespace)
+		if err != nil {
+			return errors.Wrapf(err, "error getting operation filter for namespace [%s]", mapping.namespace)
+		}
+
+		validOps, err := opFilter.Filter(suffix, newOps)
+		if err != nil {
+			return errors.Wrap(err, "error filtering invalid operations")
+		}
+
 		err = opStore.Put(validOps)
 		if err != nil {
 			return errors.Wrapf(err, "failed to store operation from batch[%s]", batchFileAddress)
@@ -201,15 +348,67 @@ func (p *TxnProcessor) processBatchFile(batchFileAddress string, sidetreeTxn Sid
 	return nil
 }
 
-func updateOperation(encodedOp string, index uint, sidetreeTxn SidetreeTxn) (*batch.Operation, error) {
+// skipStored returns the operations that are not stored yet. An operation is already stored if an operation with
+// the same transaction time, transaction number and operation index is stored for the suffix, e.g. if the transaction is processed
+// again after a partial failure (see Observer.Redrive).
+func skipStored(opStore OperationStore, suffix string, ops []*batch.Operation) ([]*batch.Operation, error) {
+	stored, err := opStore.Get(suffix)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return ops, nil
+		}
+
+		return nil, errors.Wrapf(err, "failed to read stored operations for suffix [%s]", suffix)
+	}
+
+	type anchoredKey struct {
+		transactionTime   uint64
+		transactionNumber uint64
+		operationIndex    uint
+	}
+
+	existing := make(map[anchoredKey]struct{}, len(stored))
+	for _, op := range stored {
+		existing[anchoredKey{op.TransactionTime, op.TransactionNumber, op.OperationIndex}] = struct{}{}
+	}
+
+	var newOps []*batch.Operation
+
+	for _, op := range ops {
+		if _, ok := existing[anchoredKey{op.TransactionTime, op.TransactionNumber, op.OperationIndex}]; ok {
+			continue
+		}
+
+		newOps = append(newOps, op)
+	}
+
+	return newOps, nil
+}
+
+func (p *TxnProcessor) updateOperation(encodedOp string, index uint, sidetreeTxn SidetreeTxn) (*batch.Operation, error) {
 	decodedOp, err := docutil.DecodeString(encodedOp)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to decode ops")
+		return nil, newInvalidOperationError(errors.Wrapf(err, "failed to decode ops"))
 	}
 	var op batch.Operation
 	err = json.Unmarshal(decodedOp, &op)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to unmarshal decoded ops")
+		return nil, newInvalidOperationError(errors.Wrapf(err, "failed to unmarshal decoded ops"))
+	}
+
+	if isCompactDeactivate(&op) {
+		if err := expandCompactDeactivate(&op); err != nil {
+			return nil, newInvalidOperationError(errors.Wrapf(err, "failed to expand compact deactivate operation [%s]", op.ID))
+		}
+	}
+
+	if p.PcProvider != nil {
+		parsedOp, err := p.parseOperation(&op, sidetreeTxn)
+		if err != nil {
+			return nil, wrapInvalidOperation(err, "failed to parse operation [%s]", op.ID)
+		}
+
+		op = *parsedOp
 	}
 
 	//  The logical blockchain time that this operation was anchored on the blockchain
@@ -222,6 +421,94 @@ func updateOperation(encodedOp string, index uint, sidetreeTxn SidetreeTxn) (*ba
 	return &op, nil
 }
 
+// validateBatchFileSize checks the size of the batch file against the max batch file size of the protocol version
+// that was in effect at the time of the transaction
+func (p *TxnProcessor) validateBatchFileSize(ns string, size int, sidetreeTxn SidetreeTxn) error {
+	if p.PcProvider == nil {
+		return nil
+	}
+
+	pc, err := p.PcProvider.ForNamespace(ns)
+	if err != nil {
+		return errors.Wrapf(err, "error getting protocol client for namespace [%s]", ns)
+	}
+
+	pv, err := pc.Get(sidetreeTxn.TransactionTime)
+	if err != nil {
+		return errors.Wrapf(err, "error getting protocol version for transaction time [%d]", sidetreeTxn.TransactionTime)
+	}
+
+	maxSize := pv.Protocol().MaxBatchFileSize
+	if maxSize > 0 && size > int(maxSize) {
+		return newInvalidOperationError(errors.Errorf("batch file size [%d] exceeds protocol max batch file size [%d]", size, maxSize))
+	}
+
+	return nil
+}
+
+// parseOperation parses the original operation request using the operation parser of the protocol version
+// that was in effect at the time of the transaction
+func (p *TxnProcessor) parseOperation(op *batch.Operation, sidetreeTxn SidetreeTxn) (*batch.Operation, error) {
+	ns, err := namespaceFromDocID(op.ID)
+	if err != nil {
+		return nil, newInvalidOperationError(err)
+	}
+
+	pc, err := p.PcProvider.ForNamespace(ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting protocol client for namespace [%s]", ns)
+	}
+
+	pv, err := pc.Get(sidetreeTxn.TransactionTime)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting protocol version for transaction time [%d]", sidetreeTxn.TransactionTime)
+	}
+
+	parsedOp, err := pv.OperationParser().Parse(ns, op.OperationBuffer)
+	if err != nil {
+		return nil, newInvalidOperationError(err)
+	}
+
+	return parsedOp, nil
+}
+
+// invalidOperationError indicates that an anchored operation is invalid (as opposed to an error that occurred
+// while processing the operation). Invalid operations are skipped.
+type invalidOperationError struct {
+	cause error
+}
+
+func newInvalidOperationError(cause error) error {
+	return &invalidOperationError{cause: cause}
+}
+
+func (e *invalidOperationError) Error() string {
+	return e.cause.Error()
+}
+
+// wrapInvalidOperation wraps the error with the given message and keeps it an invalid operation error
+// if the error is one
+func wrapInvalidOperation(err error, format string, args ...interface{}) error {
+	if isInvalidOperation(err) {
+		return newInvalidOperationError(errors.Wrapf(err, format, args...))
+	}
+
+	return errors.Wrapf(err, format, args...)
+}
+
+func isInvalidOperation(err error) bool {
+	_, ok := errors.Cause(err).(*invalidOperationError)
+	return ok
+}
+
+func (p *TxnProcessor) txnLogger(sidetreeTxn SidetreeTxn) log.Logger {
+	return p.logger.WithFields(log.Fields{
+		log.FieldTxnTime:       sidetreeTxn.TransactionTime,
+		log.FieldTxnNumber:     sidetreeTxn.TransactionNumber,
+		log.FieldAnchorAddress: sidetreeTxn.AnchorAddress,
+	})
+}
+
 // AnchorFile defines the schema of a Anchor File
 type AnchorFile struct {
 	// BatchFileHash is encoded hash of the batch file
@@ -274,7 +561,7 @@ type operationsMapping struct {
 	operations []*batch.Operation
 }
 
-func mapOperationsByUniqueSuffix(ops []*batch.Operation) map[string]*operationsMapping {
+func mapOperationsByUniqueSuffix(ops []*batch.Operation, logger log.Logger) map[string]*operationsMapping {
 	m := make(map[string]*operationsMapping)
 
 	for _, op := range ops {
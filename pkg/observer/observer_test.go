@@ -7,19 +7,34 @@ SPDX-License-Identifier: Apache-2.0
 package observer
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/metrics"
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
 )
 
-const anchorAddressKey = "anchorAddress"
+const (
+	anchorAddressKey = "anchorAddress"
+
+	sha2_256 = 18
+)
 
 func TestStartObserver(t *testing.T) {
 	t.Run("test error from ProcessSidetreeTxn", func(t *testing.T) {
@@ -153,6 +168,63 @@ func TestTxnProcessor_Process(t *testing.T) {
 	})
 }
 
+func TestTxnProcessor_Metrics(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		m := &mockMetrics{}
+		providers := &Providers{
+			DCASClient: mockDCAS{readFunc: func(key string) ([]byte, error) {
+				if key == anchorAddressKey {
+					return docutil.MarshalCanonical(&AnchorFile{BatchFileHash: "batchAddress"})
+				}
+				return docutil.MarshalCanonical(&BatchFile{})
+			}},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+			Metrics:          m,
+		}
+
+		p := NewTxnProcessor(providers)
+		err := p.Process(SidetreeTxn{AnchorAddress: anchorAddressKey})
+		require.NoError(t, err)
+		require.Equal(t, 1, m.txnProcessed)
+		require.Equal(t, 0, m.txnFailed)
+	})
+	t.Run("failure", func(t *testing.T) {
+		m := &mockMetrics{}
+		providers := &Providers{
+			DCASClient:       mockDCAS{readFunc: func(key string) ([]byte, error) { return nil, fmt.Errorf("read error") }},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+			Metrics:          m,
+		}
+
+		p := NewTxnProcessor(providers)
+		err := p.Process(SidetreeTxn{})
+		require.Error(t, err)
+		require.Equal(t, 0, m.txnProcessed)
+		require.Equal(t, 1, m.txnFailed)
+	})
+}
+
+func TestTxnProcessor_WithLogger(t *testing.T) {
+	l, hook := logtest.NewNullLogger()
+	l.SetLevel(logrus.DebugLevel)
+
+	providers := &Providers{
+		DCASClient:       mockDCAS{readFunc: func(key string) ([]byte, error) { return nil, fmt.Errorf("read error") }},
+		OpFilterProvider: &NoopOperationFilterProvider{},
+		Logger:           log.New(l),
+	}
+
+	p := NewTxnProcessor(providers)
+	err := p.Process(SidetreeTxn{TransactionTime: 10, TransactionNumber: 2, AnchorAddress: anchorAddressKey})
+	require.Error(t, err)
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	require.Equal(t, uint64(10), entry.Data[log.FieldTxnTime])
+	require.Equal(t, uint64(2), entry.Data[log.FieldTxnNumber])
+	require.Equal(t, anchorAddressKey, entry.Data[log.FieldAnchorAddress])
+}
+
 func TestProcessBatchFile(t *testing.T) {
 	t.Run("test error from getBatchFile", func(t *testing.T) {
 		providers := &Providers{
@@ -166,27 +238,70 @@ func TestProcessBatchFile(t *testing.T) {
 		}
 
 		p := NewTxnProcessor(providers)
-		err := p.processBatchFile("", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		err := p.processBatchFile("", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to unmarshal batch")
 	})
 
 	t.Run("test error from updateOperation", func(t *testing.T) {
+		errExpected := errors.New("injected protocol client provider error")
+
 		providers := &Providers{
 			DCASClient: mockDCAS{readFunc: func(key string) ([]byte, error) {
 				if key == anchorAddressKey {
 					return docutil.MarshalCanonical(&AnchorFile{})
 				}
-
-				return docutil.MarshalCanonical(&BatchFile{Operations: []string{"1"}})
+				b, err := docutil.MarshalCanonical(batch.Operation{ID: "did:sideteree:123456"})
+				require.NoError(t, err)
+				return docutil.MarshalCanonical(&BatchFile{Operations: []string{docutil.EncodeToString(b)}})
 			}},
 			OpFilterProvider: &NoopOperationFilterProvider{},
+			PcProvider:       &mockProtocolClientProvider{err: errExpected},
 		}
 
 		p := NewTxnProcessor(providers)
-		err := p.processBatchFile("", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		err := p.processBatchFile("", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to update operation with blockchain metadata")
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("invalid operations are skipped", func(t *testing.T) {
+		const namespace = "did:sidetree"
+
+		createReq, err := getCreateRequest()
+		require.NoError(t, err)
+
+		parsedOp, err := operation.NewParser(getProtocol()).Parse(namespace, createReq)
+		require.NoError(t, err)
+
+		valid, err := docutil.MarshalCanonical(parsedOp)
+		require.NoError(t, err)
+
+		unparseable, err := docutil.MarshalCanonical(batch.Operation{ID: namespace + ":123456"})
+		require.NoError(t, err)
+
+		var stored []*batch.Operation
+
+		providers := &Providers{
+			DCASClient: mockDCAS{readFunc: func(key string) ([]byte, error) {
+				return docutil.MarshalCanonical(&BatchFile{Operations: []string{
+					"1", docutil.EncodeToString(unparseable), docutil.EncodeToString(valid),
+				}})
+			}},
+			OpStoreProvider: &mockOperationStoreProvider{opStore: &mockOperationStore{putFunc: func(ops []*batch.Operation) error {
+				stored = append(stored, ops...)
+				return nil
+			}}},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+			PcProvider:       &mockProtocolClientProvider{},
+		}
+
+		err = NewTxnProcessor(providers).processBatchFile("batch", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		require.NoError(t, err)
+		require.Len(t, stored, 1)
+		require.Equal(t, parsedOp.UniqueSuffix, stored[0].UniqueSuffix)
+		require.Equal(t, uint(2), stored[0].OperationIndex)
 	})
 
 	t.Run("test error from operationStoreProvider ForNamespace", func(t *testing.T) {
@@ -206,7 +321,7 @@ func TestProcessBatchFile(t *testing.T) {
 		}
 
 		p := NewTxnProcessor(providers)
-		err := p.processBatchFile("", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		err := p.processBatchFile("", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), errExpected.Error())
 	})
@@ -230,7 +345,7 @@ func TestProcessBatchFile(t *testing.T) {
 		}
 
 		p := NewTxnProcessor(providers)
-		err := p.processBatchFile("", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		err := p.processBatchFile("", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to store operation from batch")
 	})
@@ -250,14 +365,187 @@ func TestProcessBatchFile(t *testing.T) {
 		}
 
 		p := NewTxnProcessor(providers)
-		err := p.processBatchFile("", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		err := p.processBatchFile("", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		require.NoError(t, err)
+	})
+
+	t.Run("operations that were already stored are skipped", func(t *testing.T) {
+		txn := SidetreeTxn{TransactionTime: 20, TransactionNumber: 5, AnchorAddress: anchorAddressKey}
+
+		var stored []*batch.Operation
+
+		opStore := &mockOperationStore{
+			putFunc: func(ops []*batch.Operation) error {
+				stored = append(stored, ops...)
+				return nil
+			},
+			getFunc: func(suffix string) ([]*batch.Operation, error) {
+				// the first operation was stored by a previous (partial) attempt
+				return []*batch.Operation{
+					{UniqueSuffix: suffix, TransactionTime: 20, TransactionNumber: 4, OperationIndex: 1},
+					{UniqueSuffix: suffix, TransactionTime: 19, TransactionNumber: 5, OperationIndex: 1},
+					{UniqueSuffix: suffix, TransactionTime: 20, TransactionNumber: 5, OperationIndex: 0},
+				}, nil
+			},
+		}
+
+		providers := &Providers{
+			DCASClient:       mockDCAS{readFunc: readBatchFile(t, 2)},
+			OpStoreProvider:  &mockOperationStoreProvider{opStore: opStore},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+		}
+
+		p := NewTxnProcessor(providers)
+		require.NoError(t, p.processBatchFile("batch", "", txn))
+		require.Len(t, stored, 1)
+		require.Equal(t, uint(1), stored[0].OperationIndex)
+
+		// all operations were already stored
+		stored = nil
+		opStore.getFunc = func(suffix string) ([]*batch.Operation, error) {
+			return []*batch.Operation{
+				{UniqueSuffix: suffix, TransactionTime: 20, TransactionNumber: 5, OperationIndex: 0},
+				{UniqueSuffix: suffix, TransactionTime: 20, TransactionNumber: 5, OperationIndex: 1},
+			}, nil
+		}
+
+		require.NoError(t, p.processBatchFile("batch", "", txn))
+		require.Empty(t, stored)
+	})
+
+	t.Run("test error from operationStore Get", func(t *testing.T) {
+		opStore := &mockOperationStore{getFunc: func(suffix string) ([]*batch.Operation, error) {
+			return nil, errors.New("injected get error")
+		}}
+
+		providers := &Providers{
+			DCASClient:       mockDCAS{readFunc: readBatchFile(t, 1)},
+			OpStoreProvider:  &mockOperationStoreProvider{opStore: opStore},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+		}
+
+		p := NewTxnProcessor(providers)
+		err := p.processBatchFile("batch", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read stored operations for suffix [123456]: injected get error")
+
+		// a "not found" error means that no operations are stored for the suffix
+		opStore.getFunc = func(suffix string) ([]*batch.Operation, error) {
+			return nil, errors.New("suffix not found")
+		}
+
+		require.NoError(t, p.processBatchFile("batch", "", SidetreeTxn{AnchorAddress: anchorAddressKey}))
+	})
+}
+
+// readBatchFile returns a DCAS read function that returns a batch file with the given number of
+// operations for the same suffix
+func readBatchFile(t *testing.T, n int) func(key string) ([]byte, error) {
+	return func(key string) ([]byte, error) {
+		var ops []string
+
+		for i := 0; i < n; i++ {
+			b, err := docutil.MarshalCanonical(batch.Operation{ID: "did:sidetree:123456", UniqueSuffix: "123456"})
+			require.NoError(t, err)
+
+			ops = append(ops, docutil.EncodeToString(b))
+		}
+
+		return docutil.MarshalCanonical(&BatchFile{Operations: ops})
+	}
+}
+
+func TestProcessBatchFile_MaxBatchFileSize(t *testing.T) {
+	const namespace = "did:sidetree"
+
+	createReq, err := getCreateRequest()
+	require.NoError(t, err)
+
+	parsedOp, err := operation.NewParser(getProtocol()).Parse(namespace, createReq)
+	require.NoError(t, err)
+
+	b, err := docutil.MarshalCanonical(parsedOp)
+	require.NoError(t, err)
+
+	batchFile, err := docutil.MarshalCanonical(&BatchFile{Operations: []string{docutil.EncodeToString(b)}})
+	require.NoError(t, err)
+
+	dcas := mockDCAS{readFunc: func(key string) ([]byte, error) {
+		return batchFile, nil
+	}}
+
+	t.Run("batch file exceeds max size", func(t *testing.T) {
+		p := getProtocol()
+		p.MaxBatchFileSize = uint(len(batchFile) - 1)
+
+		providers := &Providers{
+			DCASClient:       dcas,
+			OpStoreProvider:  &mockOperationStoreProvider{opStore: &mockOperationStore{}},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+			PcProvider:       &mockProtocolClientProvider{client: &mockProtocolClient{protocol: &p}},
+		}
+
+		// the batch is skipped rather than failing (and dead-lettering) the transaction
+		err := NewTxnProcessor(providers).processBatchFile("batch", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		require.NoError(t, err)
+	})
+
+	t.Run("no operations are stored when the batch file exceeds max size", func(t *testing.T) {
+		createReq2, err := getCreateRequest()
+		require.NoError(t, err)
+
+		parsedOp2, err := operation.NewParser(getProtocol()).Parse(namespace, createReq2)
+		require.NoError(t, err)
+
+		b2, err := docutil.MarshalCanonical(parsedOp2)
+		require.NoError(t, err)
+
+		largeBatchFile, err := docutil.MarshalCanonical(&BatchFile{Operations: []string{docutil.EncodeToString(b), docutil.EncodeToString(b2)}})
+		require.NoError(t, err)
+
+		p := getProtocol()
+		p.MaxBatchFileSize = uint(len(largeBatchFile) - 1)
+
+		puts := 0
+
+		providers := &Providers{
+			DCASClient: mockDCAS{readFunc: func(key string) ([]byte, error) {
+				return largeBatchFile, nil
+			}},
+			OpStoreProvider: &mockOperationStoreProvider{opStore: &mockOperationStore{putFunc: func(ops []*batch.Operation) error {
+				puts++
+				return nil
+			}}},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+			PcProvider:       &mockProtocolClientProvider{client: &mockProtocolClient{protocol: &p}},
+		}
+
+		err = NewTxnProcessor(providers).processBatchFile("batch", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
+		require.NoError(t, err)
+		require.Zero(t, puts)
+	})
+
+	t.Run("batch file within max size", func(t *testing.T) {
+		p := getProtocol()
+		p.MaxBatchFileSize = uint(len(batchFile))
+
+		providers := &Providers{
+			DCASClient:       dcas,
+			OpStoreProvider:  &mockOperationStoreProvider{opStore: &mockOperationStore{}},
+			OpFilterProvider: &NoopOperationFilterProvider{},
+			PcProvider:       &mockProtocolClientProvider{client: &mockProtocolClient{protocol: &p}},
+		}
+
+		err := NewTxnProcessor(providers).processBatchFile("batch", "", SidetreeTxn{AnchorAddress: anchorAddressKey})
 		require.NoError(t, err)
 	})
 }
 
 func TestUpdateOperation(t *testing.T) {
+	p := NewTxnProcessor(&Providers{})
+
 	t.Run("test error from unmarshal decoded ops", func(t *testing.T) {
-		_, err := updateOperation(docutil.EncodeToString([]byte("ops")), 1, SidetreeTxn{AnchorAddress: anchorAddressKey})
+		_, err := p.updateOperation(docutil.EncodeToString([]byte("ops")), 1, SidetreeTxn{AnchorAddress: anchorAddressKey})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to unmarshal decoded ops")
 	})
@@ -265,7 +553,7 @@ func TestUpdateOperation(t *testing.T) {
 	t.Run("test success", func(t *testing.T) {
 		b, err := docutil.MarshalCanonical(batch.Operation{ID: "did:sideteree:123456"})
 		require.NoError(t, err)
-		updatedOps, err := updateOperation(docutil.EncodeToString(b), 1, SidetreeTxn{TransactionTime: 20, TransactionNumber: 2})
+		updatedOps, err := p.updateOperation(docutil.EncodeToString(b), 1, SidetreeTxn{TransactionTime: 20, TransactionNumber: 2})
 		require.NoError(t, err)
 		require.Equal(t, uint64(20), updatedOps.TransactionTime)
 		require.Equal(t, uint64(2), updatedOps.TransactionNumber)
@@ -273,6 +561,76 @@ func TestUpdateOperation(t *testing.T) {
 	})
 }
 
+func TestUpdateOperation_WithOperationParser(t *testing.T) {
+	const namespace = "did:sidetree"
+
+	createReq, err := getCreateRequest()
+	require.NoError(t, err)
+
+	parsedOp, err := operation.NewParser(getProtocol()).Parse(namespace, createReq)
+	require.NoError(t, err)
+
+	b, err := docutil.MarshalCanonical(parsedOp)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		p := NewTxnProcessor(&Providers{PcProvider: &mockProtocolClientProvider{}})
+
+		updatedOp, err := p.updateOperation(docutil.EncodeToString(b), 1, SidetreeTxn{TransactionTime: 20, TransactionNumber: 2})
+		require.NoError(t, err)
+		require.Equal(t, parsedOp.ID, updatedOp.ID)
+		require.Equal(t, parsedOp.UniqueSuffix, updatedOp.UniqueSuffix)
+		require.Equal(t, batch.OperationTypeCreate, updatedOp.Type)
+		require.Equal(t, uint64(20), updatedOp.TransactionTime)
+	})
+
+	t.Run("invalid namespace", func(t *testing.T) {
+		p := NewTxnProcessor(&Providers{PcProvider: &mockProtocolClientProvider{}})
+
+		invalid, err := docutil.MarshalCanonical(batch.Operation{ID: "invalid"})
+		require.NoError(t, err)
+
+		updatedOp, err := p.updateOperation(docutil.EncodeToString(invalid), 1, SidetreeTxn{})
+		require.Error(t, err)
+		require.Nil(t, updatedOp)
+		require.Contains(t, err.Error(), "invalid ID")
+	})
+
+	t.Run("protocol client provider error", func(t *testing.T) {
+		errExpected := errors.New("injected protocol client provider error")
+		p := NewTxnProcessor(&Providers{PcProvider: &mockProtocolClientProvider{err: errExpected}})
+
+		updatedOp, err := p.updateOperation(docutil.EncodeToString(b), 1, SidetreeTxn{})
+		require.Error(t, err)
+		require.Nil(t, updatedOp)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.False(t, isInvalidOperation(err))
+	})
+
+	t.Run("protocol version error", func(t *testing.T) {
+		errExpected := errors.New("injected protocol version error")
+		p := NewTxnProcessor(&Providers{PcProvider: &mockProtocolClientProvider{client: &mockProtocolClient{err: errExpected}}})
+
+		updatedOp, err := p.updateOperation(docutil.EncodeToString(b), 1, SidetreeTxn{})
+		require.Error(t, err)
+		require.Nil(t, updatedOp)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		p := NewTxnProcessor(&Providers{PcProvider: &mockProtocolClientProvider{}})
+
+		invalid, err := docutil.MarshalCanonical(batch.Operation{ID: namespace + ":123456"})
+		require.NoError(t, err)
+
+		updatedOp, err := p.updateOperation(docutil.EncodeToString(invalid), 1, SidetreeTxn{})
+		require.Error(t, err)
+		require.Nil(t, updatedOp)
+		require.Contains(t, err.Error(), "failed to parse operation")
+		require.True(t, isInvalidOperation(err))
+	})
+}
+
 func TestGetNamespace(t *testing.T) {
 	const namespace = "did:sidetree"
 	const suffix = "123456"
@@ -329,6 +687,99 @@ func (m *mockOperationStore) Get(suffix string) ([]*batch.Operation, error) {
 	return nil, nil
 }
 
+type mockMetrics struct {
+	metrics.Noop
+
+	txnProcessed int
+	txnFailed    int
+}
+
+func (m *mockMetrics) TxnProcessTime(time.Duration) {
+	m.txnProcessed++
+}
+
+func (m *mockMetrics) TxnProcessFailure() {
+	m.txnFailed++
+}
+
+type mockProtocolClientProvider struct {
+	client protocol.Client
+	err    error
+}
+
+func (m *mockProtocolClientProvider) ForNamespace(string) (protocol.Client, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if m.client != nil {
+		return m.client, nil
+	}
+
+	return &mockProtocolClient{}, nil
+}
+
+type mockProtocolClient struct {
+	err      error
+	protocol *protocol.Protocol
+}
+
+func (m *mockProtocolClient) Current() (protocol.Version, error) {
+	return m.Get(0)
+}
+
+func (m *mockProtocolClient) Get(uint64) (protocol.Version, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &mockProtocolVersion{p: m.protocol}, nil
+}
+
+type mockProtocolVersion struct {
+	p *protocol.Protocol
+}
+
+func (m *mockProtocolVersion) Protocol() protocol.Protocol {
+	if m.p != nil {
+		return *m.p
+	}
+
+	return getProtocol()
+}
+
+func (m *mockProtocolVersion) OperationParser() protocol.OperationParser {
+	return operation.NewParser(getProtocol())
+}
+
+func getProtocol() protocol.Protocol {
+	return protocol.Protocol{
+		HashAlgorithmInMultiHashCode: sha2_256,
+		MaxOperationsPerBatch:        2,
+		MaxDeltaByteSize:             2000,
+	}
+}
+
+func getCreateRequest() ([]byte, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryKey, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.NewCreateRequest(&helper.CreateRequestInfo{
+		OpaqueDocument:          `{"name": "value"}`,
+		RecoveryKey:             recoveryKey,
+		NextRecoveryRevealValue: []byte("recoveryReveal"),
+		NextUpdateRevealValue:   []byte("updateReveal"),
+		MultihashCode:           sha2_256,
+	})
+}
+
 type mockOperationStoreProvider struct {
 	opStore OperationStore
 	err     error
@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"time"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// Option is an observer option
+type Option func(o *Observer)
+
+// WithMaxRetries sets the number of times that the processing of a transaction is retried before
+// it is sent to the dead-letter store (default 3). Subsequent transactions are not processed while
+// the observer waits to retry, so a value of 0 sends a failed transaction to the dead-letter store
+// (see Observer.Redrive) right away.
+func WithMaxRetries(maxRetries int) Option {
+	return func(o *Observer) {
+		o.maxRetries = maxRetries
+	}
+}
+
+// WithBackoff sets the backoff before the first retry. The backoff is doubled for every subsequent retry
+// up to the given maximum.
+func WithBackoff(initial, max time.Duration) Option {
+	return func(o *Observer) {
+		o.initialBackoff = initial
+		o.maxBackoff = max
+	}
+}
+
+// processWithRetry processes the transaction, retrying with exponential backoff on failure. If all retries
+// are exhausted then the transaction is sent to the dead-letter store. Returns true if the observer was
+// stopped while waiting to retry.
+func (o *Observer) processWithRetry(txn SidetreeTxn) bool {
+	logger := o.processor.txnLogger(txn)
+
+	backoff := o.initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := o.processor.Process(txn)
+		if err == nil {
+			logger.Debugf("Successfully processed anchor[%s]", txn.AnchorAddress)
+			return false
+		}
+
+		if attempt > o.maxRetries {
+			logger.Warnf("Failed to process anchor[%s] after %d attempt(s): %s", txn.AnchorAddress, attempt, err)
+			o.deadLetter(txn, err, attempt)
+			return false
+		}
+
+		logger.Infof("Failed to process anchor[%s] on attempt %d: %s. Retrying in %s", txn.AnchorAddress, attempt, err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-o.stopCh:
+			return true
+		}
+
+		backoff *= 2
+		if backoff > o.maxBackoff {
+			backoff = o.maxBackoff
+		}
+	}
+}
+
+func (o *Observer) deadLetter(txn SidetreeTxn, err error, attempts int) {
+	logger := o.processor.txnLogger(txn)
+
+	e := o.deadLetterStore.Put(&FailedTxn{
+		SidetreeTxn: txn,
+		Error:       err.Error(),
+		Attempts:    attempts,
+		FailedAt:    time.Now(),
+	})
+	if e != nil {
+		logger.Errorf("Unable to store anchor[%s] in dead-letter store: %s", txn.AnchorAddress, e)
+		return
+	}
+
+	logger.Infof("Anchor[%s] was sent to the dead-letter store", txn.AnchorAddress)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+)
+
+func TestObserver_Retry(t *testing.T) {
+	txn := SidetreeTxn{TransactionTime: 20, TransactionNumber: 2, AnchorAddress: anchorAddressKey}
+
+	t.Run("success after retry", func(t *testing.T) {
+		dcas := &flakyDCAS{failures: 2}
+		dlq := NewMemDeadLetterStore()
+
+		o := New(newRetryProviders(dcas, dlq), WithMaxRetries(3), WithBackoff(5*time.Millisecond, 10*time.Millisecond))
+
+		require.False(t, o.process([]SidetreeTxn{txn}))
+		require.Equal(t, 3, dcas.anchorReads())
+
+		failed, err := dlq.Get()
+		require.NoError(t, err)
+		require.Empty(t, failed)
+	})
+	t.Run("retries exhausted -> dead-letter store", func(t *testing.T) {
+		dcas := &flakyDCAS{failures: 10}
+		dlq := NewMemDeadLetterStore()
+
+		o := New(newRetryProviders(dcas, dlq), WithMaxRetries(2), WithBackoff(5*time.Millisecond, 5*time.Millisecond))
+
+		require.False(t, o.process([]SidetreeTxn{txn}))
+		require.Equal(t, 3, dcas.anchorReads())
+
+		failed, err := dlq.Get()
+		require.NoError(t, err)
+		require.Len(t, failed, 1)
+		require.Equal(t, txn, failed[0].SidetreeTxn)
+		require.Equal(t, 3, failed[0].Attempts)
+		require.Contains(t, failed[0].Error, "injected CAS error")
+	})
+	t.Run("retries enabled by default", func(t *testing.T) {
+		dcas := &flakyDCAS{failures: 1}
+		dlq := NewMemDeadLetterStore()
+
+		o := New(newRetryProviders(dcas, dlq))
+		require.Equal(t, defaultMaxRetries, o.maxRetries)
+
+		WithBackoff(5*time.Millisecond, 5*time.Millisecond)(o)
+
+		require.False(t, o.process([]SidetreeTxn{txn}))
+		require.Equal(t, 2, dcas.anchorReads())
+
+		failed, err := dlq.Get()
+		require.NoError(t, err)
+		require.Empty(t, failed)
+	})
+	t.Run("no dead-letter store -> in-memory dead-letter store", func(t *testing.T) {
+		dcas := &flakyDCAS{failures: 10}
+
+		o := New(newRetryProviders(dcas, nil), WithMaxRetries(0))
+
+		require.False(t, o.process([]SidetreeTxn{txn}))
+		require.Equal(t, 1, dcas.anchorReads())
+
+		failed, err := o.deadLetterStore.Get()
+		require.NoError(t, err)
+		require.Len(t, failed, 1)
+		require.Equal(t, txn, failed[0].SidetreeTxn)
+	})
+	t.Run("dead-letter store error", func(t *testing.T) {
+		dcas := &flakyDCAS{failures: 10}
+
+		o := New(newRetryProviders(dcas, &mockDeadLetterStore{err: errors.New("injected DLQ error")}), WithMaxRetries(0))
+
+		require.False(t, o.process([]SidetreeTxn{txn}))
+	})
+	t.Run("stopped while waiting to retry", func(t *testing.T) {
+		dcas := &flakyDCAS{failures: 10}
+
+		o := New(newRetryProviders(dcas, nil), WithMaxRetries(3), WithBackoff(time.Minute, time.Minute))
+		o.Stop()
+
+		require.True(t, o.process([]SidetreeTxn{txn}))
+		require.Equal(t, 1, dcas.anchorReads())
+	})
+}
+
+func newRetryProviders(dcas DCAS, dlq DeadLetterStore) *Providers {
+	return &Providers{
+		DCASClient:       dcas,
+		OpStoreProvider:  &mockOperationStoreProvider{opStore: &mockOperationStore{}},
+		OpFilterProvider: &NoopOperationFilterProvider{},
+		DeadLetterStore:  dlq,
+	}
+}
+
+// flakyDCAS fails to read the anchor file the given number of times
+type flakyDCAS struct {
+	mutex    sync.Mutex
+	failures int
+	reads    int
+}
+
+func (m *flakyDCAS) Read(key string) ([]byte, error) {
+	if key == anchorAddressKey {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+
+		m.reads++
+		if m.reads <= m.failures {
+			return nil, errors.New("injected CAS error")
+		}
+
+		return docutil.MarshalCanonical(&AnchorFile{BatchFileHash: "batch"})
+	}
+
+	b, err := docutil.MarshalCanonical(batch.Operation{ID: "did:sidetree:123456"})
+	if err != nil {
+		return nil, err
+	}
+
+	return docutil.MarshalCanonical(&BatchFile{Operations: []string{docutil.EncodeToString(b)}})
+}
+
+func (m *flakyDCAS) anchorReads() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.reads
+}
+
+func (m *flakyDCAS) setFailures(failures int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.failures = failures
+	m.reads = 0
+}
+
+type mockDeadLetterStore struct {
+	err    error
+	getErr error
+	delErr error
+	txns   []*FailedTxn
+}
+
+func (m *mockDeadLetterStore) Put(txn *FailedTxn) error {
+	return m.err
+}
+
+func (m *mockDeadLetterStore) Get() ([]*FailedTxn, error) {
+	return m.txns, m.getErr
+}
+
+func (m *mockDeadLetterStore) Delete(SidetreeTxn) error {
+	return m.delErr
+}
@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+// validateAnchorWindow checks that the anchor window (if any) in the signed data isn't empty. Whether the operation
+// was actually anchored within the window is checked by the processor.
+func validateAnchorWindow(w model.AnchorWindow) error {
+	if w.AnchorFrom > 0 && w.AnchorUntil > 0 && w.AnchorFrom > w.AnchorUntil {
+		return fmt.Errorf("anchor from [%d] is greater than anchor until [%d]", w.AnchorFrom, w.AnchorUntil)
+	}
+
+	return nil
+}
+
+func parseSignedDataForUpdate(encoded string) (*model.UpdateSignedDataModel, error) {
+	bytes, err := docutil.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &model.UpdateSignedDataModel{}
+	if err := json.Unmarshal(bytes, schema); err != nil {
+		return nil, err
+	}
+
+	if err := validateAnchorWindow(schema.AnchorWindow); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
@@ -13,13 +13,19 @@ import (
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 )
 
-// ParseCreateOperation will parse create operation
-func ParseCreateOperation(request []byte, protocol protocol.Protocol) (*batch.Operation, error) {
+// ParseCreateOperation will parse create operation. The patches are validated using the given options.
+func ParseCreateOperation(request []byte, protocol protocol.Protocol, opts ...document.ValidationOpt) (*batch.Operation, error) {
+	return parseCreateOperation(request, protocol, docutil.MultihashSuffixDeriver{}, opts...)
+}
+
+func parseCreateOperation(request []byte, protocol protocol.Protocol, deriver docutil.SuffixDeriver, opts ...document.ValidationOpt) (*batch.Operation, error) {
 	schema, err := parseCreateRequest(request)
 	if err != nil {
 		return nil, err
@@ -27,17 +33,17 @@ func ParseCreateOperation(request []byte, protocol protocol.Protocol) (*batch.Op
 
 	code := protocol.HashAlgorithmInMultiHashCode
 
-	suffixData, err := parseSuffixData(schema.SuffixData, code)
+	suffixData, err := parseSuffixData(schema.SuffixData, protocol)
 	if err != nil {
 		return nil, err
 	}
 
-	delta, err := parseCreateDelta(schema.Delta, code)
+	delta, err := parseCreateDelta(schema.Delta, protocol, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	uniqueSuffix, err := docutil.CalculateUniqueSuffix(schema.SuffixData, code)
+	uniqueSuffix, err := deriver.DeriveSuffix(schema.SuffixData, code)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +75,7 @@ func parseCreateRequest(payload []byte) (*model.CreateRequest, error) {
 	return schema, nil
 }
 
-func parseCreateDelta(encoded string, code uint) (*model.DeltaModel, error) {
+func parseCreateDelta(encoded string, p protocol.Protocol, opts ...document.ValidationOpt) (*model.DeltaModel, error) {
 	bytes, err := docutil.DecodeString(encoded)
 	if err != nil {
 		return nil, err
@@ -81,14 +87,18 @@ func parseCreateDelta(encoded string, code uint) (*model.DeltaModel, error) {
 		return nil, err
 	}
 
-	if err := validateDelta(schema, code); err != nil {
+	if err := translateLegacyDelta(schema, p); err != nil {
+		return nil, err
+	}
+
+	if err := validateDelta(schema, p.HashAlgorithmInMultiHashCode, opts...); err != nil {
 		return nil, err
 	}
 
 	return schema, nil
 }
 
-func parseSuffixData(encoded string, code uint) (*model.SuffixDataModel, error) {
+func parseSuffixData(encoded string, p protocol.Protocol) (*model.SuffixDataModel, error) {
 	bytes, err := docutil.DecodeString(encoded)
 	if err != nil {
 		return nil, err
@@ -100,22 +110,28 @@ func parseSuffixData(encoded string, code uint) (*model.SuffixDataModel, error)
 		return nil, err
 	}
 
-	if err := validateSuffixData(schema, code); err != nil {
+	if err := translateLegacySuffixData(schema, p); err != nil {
+		return nil, err
+	}
+
+	if err := validateSuffixData(schema, p.HashAlgorithmInMultiHashCode); err != nil {
+		return nil, err
+	}
+
+	if err := validateDIDType(schema.Type, p.DIDTypes); err != nil {
 		return nil, err
 	}
 
 	return schema, nil
 }
 
-func validateDelta(delta *model.DeltaModel, code uint) error {
+func validateDelta(delta *model.DeltaModel, code uint, opts ...document.ValidationOpt) error {
 	if len(delta.Patches) == 0 {
 		return errors.New("missing patches")
 	}
 
-	for _, p := range delta.Patches {
-		if err := p.Validate(); err != nil {
-			return err
-		}
+	if err := patch.ValidatePatches(delta.Patches, opts...); err != nil {
+		return err
 	}
 
 	if !docutil.IsComputedUsingHashAlgorithm(delta.UpdateCommitment, uint64(code)) {
@@ -141,6 +157,22 @@ func validateSuffixData(suffixData *model.SuffixDataModel, code uint) error {
 	return nil
 }
 
+// validateDIDType validates that the DID type (if set) is in the list of allowed types.
+// All types are allowed if the list of allowed types is empty.
+func validateDIDType(didType uint, allowed []uint) error {
+	if didType == 0 || len(allowed) == 0 {
+		return nil
+	}
+
+	for _, t := range allowed {
+		if t == didType {
+			return nil
+		}
+	}
+
+	return errors.Errorf("DID type [%d] is not allowed", didType)
+}
+
 func validateRecoveryKey(key *jws.JWK) error {
 	if key == nil {
 		return errors.New("missing recovery key")
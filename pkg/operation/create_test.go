@@ -70,8 +70,55 @@ func TestParseCreateOperation(t *testing.T) {
 	})
 }
 
+func TestParseCreateOperation_DIDType(t *testing.T) {
+	getRequest := func(didType uint) []byte {
+		create, err := getCreateRequest()
+		require.NoError(t, err)
+
+		suffixData := getSuffixData()
+		suffixData.Type = didType
+
+		suffixDataBytes, err := canonicalizer.MarshalCanonical(suffixData)
+		require.NoError(t, err)
+
+		create.SuffixData = docutil.EncodeToString(suffixDataBytes)
+
+		request, err := json.Marshal(create)
+		require.NoError(t, err)
+
+		return request
+	}
+
+	p := protocol.Protocol{
+		HashAlgorithmInMultiHashCode: sha2_256,
+		DIDTypes:                     []uint{1, 2},
+	}
+
+	t.Run("success - allowed type", func(t *testing.T) {
+		op, err := ParseCreateOperation(getRequest(2), p)
+		require.NoError(t, err)
+		require.Equal(t, uint(2), op.SuffixData.Type)
+	})
+	t.Run("success - no type", func(t *testing.T) {
+		op, err := ParseCreateOperation(getRequest(0), p)
+		require.NoError(t, err)
+		require.Zero(t, op.SuffixData.Type)
+	})
+	t.Run("success - all types allowed", func(t *testing.T) {
+		op, err := ParseCreateOperation(getRequest(3), protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256})
+		require.NoError(t, err)
+		require.Equal(t, uint(3), op.SuffixData.Type)
+	})
+	t.Run("error - type not allowed", func(t *testing.T) {
+		op, err := ParseCreateOperation(getRequest(3), p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "DID type [3] is not allowed")
+		require.Nil(t, op)
+	})
+}
+
 func TestParseSuffixData(t *testing.T) {
-	suffixData, err := parseSuffixData(refEncodedSuffixData, sha2_256)
+	suffixData, err := parseSuffixData(refEncodedSuffixData, protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256})
 	require.NoError(t, err)
 	require.NotNil(t, suffixData)
 }
@@ -114,7 +161,7 @@ func TestValidateSuffixData(t *testing.T) {
 
 func TestParseDelta(t *testing.T) {
 	// reference encoded delta fails because it contains 'replace' patch
-	delta, err := parseDelta(refEncodedDelta, sha2_256)
+	delta, err := parseDelta(refEncodedDelta, protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256})
 	require.Error(t, err)
 	require.Nil(t, delta)
 	require.Contains(t, err.Error(), "action 'replace' is not supported")
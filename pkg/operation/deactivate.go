@@ -76,6 +76,16 @@ func parseSignedDataForDeactivate(req *model.DeactivateRequest) (*model.Deactiva
 		return nil, err
 	}
 
+	// the signed data has to bind the deactivate operation to the DID and the recovery reveal value so that
+	// the signed request can't be replayed for another DID
+	if signedData.DidSuffix == "" {
+		return nil, errors.New("signed data for deactivate is missing did suffix")
+	}
+
+	if signedData.RecoveryRevealValue == "" {
+		return nil, errors.New("signed data for deactivate is missing recovery reveal value")
+	}
+
 	if signedData.RecoveryRevealValue != req.RecoveryRevealValue {
 		return nil, errors.New("signed recovery reveal mismatch for deactivate")
 	}
@@ -84,5 +94,9 @@ func parseSignedDataForDeactivate(req *model.DeactivateRequest) (*model.Deactiva
 		return nil, errors.New("signed did suffix mismatch for deactivate")
 	}
 
+	if err := validateAnchorWindow(signedData.AnchorWindow); err != nil {
+		return nil, err
+	}
+
 	return signedData, nil
 }
@@ -81,6 +81,36 @@ func TestParseDeactivateOperation(t *testing.T) {
 		require.Contains(t, err.Error(), "invalid character")
 		require.Nil(t, op)
 	})
+	t.Run("validate signed data error - missing did suffix", func(t *testing.T) {
+		signedData := getSignedDataForDeactivate()
+		signedData.DidSuffix = ""
+
+		deactivateRequest, err := getDeactivateRequest(signedData)
+		require.NoError(t, err)
+
+		request, err := json.Marshal(deactivateRequest)
+		require.NoError(t, err)
+
+		op, err := ParseDeactivateOperation(request, p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signed data for deactivate is missing did suffix")
+		require.Nil(t, op)
+	})
+	t.Run("validate signed data error - missing recovery reveal value", func(t *testing.T) {
+		signedData := getSignedDataForDeactivate()
+		signedData.RecoveryRevealValue = ""
+
+		deactivateRequest, err := getDeactivateRequest(signedData)
+		require.NoError(t, err)
+
+		request, err := json.Marshal(deactivateRequest)
+		require.NoError(t, err)
+
+		op, err := ParseDeactivateOperation(request, p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signed data for deactivate is missing recovery reveal value")
+		require.Nil(t, op)
+	})
 	t.Run("validate signed data error - did suffix mismatch", func(t *testing.T) {
 		signedData := getSignedDataForDeactivate()
 		signedData.DidSuffix = "different"
@@ -111,6 +141,22 @@ func TestParseDeactivateOperation(t *testing.T) {
 		require.Contains(t, err.Error(), "signed recovery reveal mismatch for deactivate")
 		require.Nil(t, op)
 	})
+	t.Run("validate signed data error - invalid anchor window", func(t *testing.T) {
+		signedData := getSignedDataForDeactivate()
+		signedData.AnchorFrom = 20
+		signedData.AnchorUntil = 10
+
+		deactivateRequest, err := getDeactivateRequest(signedData)
+		require.NoError(t, err)
+
+		request, err := json.Marshal(deactivateRequest)
+		require.NoError(t, err)
+
+		op, err := ParseDeactivateOperation(request, p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "anchor from [20] is greater than anchor until [10]")
+		require.Nil(t, op)
+	})
 }
 
 func getDeactivateRequest(signedData *model.DeactivateSignedDataModel) (*model.DeactivateRequest, error) {
@@ -0,0 +1,124 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+// translateLegacyDelta translates the next update reveal value sent by a legacy client into the update commitment
+func translateLegacyDelta(delta *model.DeltaModel, p protocol.Protocol) error {
+	commitment, err := legacyCommitment("update", delta.NextUpdateRevealValue, delta.UpdateCommitment, p)
+	if err != nil {
+		return err
+	}
+
+	delta.UpdateCommitment = commitment
+
+	return nil
+}
+
+// translateLegacySuffixData translates the next recovery reveal value sent by a legacy client into the recovery commitment
+func translateLegacySuffixData(suffixData *model.SuffixDataModel, p protocol.Protocol) error {
+	commitment, err := legacyCommitment("recovery", suffixData.NextRecoveryRevealValue, suffixData.RecoveryCommitment, p)
+	if err != nil {
+		return err
+	}
+
+	suffixData.RecoveryCommitment = commitment
+
+	return nil
+}
+
+// translateLegacySignedDataForRecovery translates the next recovery reveal value sent by a legacy client
+// into the recovery commitment
+func translateLegacySignedDataForRecovery(signedData *model.RecoverSignedDataModel, p protocol.Protocol) error {
+	commitment, err := legacyCommitment("recovery", signedData.NextRecoveryRevealValue, signedData.RecoveryCommitment, p)
+	if err != nil {
+		return err
+	}
+
+	signedData.RecoveryCommitment = commitment
+
+	return nil
+}
+
+// legacyCommitment returns the commitment for the given legacy reveal value. The commitment is returned
+// unchanged if a legacy reveal value was not provided.
+func legacyCommitment(name, revealValue, commitment string, p protocol.Protocol) (string, error) {
+	if revealValue == "" {
+		return commitment, nil
+	}
+
+	if !p.LegacyRevealValues {
+		return "", fmt.Errorf("next %s reveal value is no longer supported: provide the %s commitment instead", name, name)
+	}
+
+	if commitment != "" {
+		return "", fmt.Errorf("either the next %s reveal value or the %s commitment must be provided but not both", name, name)
+	}
+
+	value, err := docutil.DecodeString(revealValue)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode next %s reveal value: %s", name, err.Error())
+	}
+
+	if err := docutil.ValidateRevealValue(value, p.MinRevealValueLength, p.MaxRevealValueLength); err != nil {
+		return "", err
+	}
+
+	return CommitmentFromRevealValue(revealValue, p.HashAlgorithmInMultiHashCode)
+}
+
+// UsesLegacyRevealValues returns true if the parsed operation was sent by a legacy client, i.e. next reveal values
+// were provided instead of the update/recovery commitments
+func UsesLegacyRevealValues(op *batch.Operation) bool {
+	if op.Delta != nil && op.Delta.NextUpdateRevealValue != "" {
+		return true
+	}
+
+	if op.SuffixData != nil && op.SuffixData.NextRecoveryRevealValue != "" {
+		return true
+	}
+
+	if op.Type != batch.OperationTypeRecover || op.SignedData == nil {
+		return false
+	}
+
+	payload, err := docutil.DecodeString(op.SignedData.Payload)
+	if err != nil {
+		return false
+	}
+
+	signedData := &model.RecoverSignedDataModel{}
+	if err := json.Unmarshal(payload, signedData); err != nil {
+		return false
+	}
+
+	return signedData.NextRecoveryRevealValue != ""
+}
+
+// CommitmentFromRevealValue returns the commitment (the encoded multihash) of the given encoded reveal value
+func CommitmentFromRevealValue(revealValue string, multihashCode uint) (string, error) {
+	value, err := docutil.DecodeString(revealValue)
+	if err != nil {
+		return "", err
+	}
+
+	mh, err := docutil.ComputeMultihash(multihashCode, value)
+	if err != nil {
+		return "", err
+	}
+
+	return docutil.EncodeToString(mh), nil
+}
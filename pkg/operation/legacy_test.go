@@ -0,0 +1,223 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/canonicalizer"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+func TestLegacyRevealValues(t *testing.T) {
+	legacy := protocol.Protocol{
+		HashAlgorithmInMultiHashCode: sha2_256,
+		LegacyRevealValues:           true,
+	}
+
+	updateReveal := docutil.EncodeToString([]byte("updateReveal"))
+	recoveryReveal := docutil.EncodeToString([]byte("recoveryReveal"))
+
+	t.Run("create", func(t *testing.T) {
+		delta, err := getDelta()
+		require.NoError(t, err)
+		delta.UpdateCommitment = ""
+		delta.NextUpdateRevealValue = updateReveal
+
+		suffixData := getSuffixData()
+		suffixData.RecoveryCommitment = ""
+		suffixData.NextRecoveryRevealValue = recoveryReveal
+
+		request, err := getLegacyCreateRequestBytes(delta, suffixData)
+		require.NoError(t, err)
+
+		op, err := ParseCreateOperation(request, legacy)
+		require.NoError(t, err)
+		require.Equal(t, computeMultihash("updateReveal"), op.UpdateCommitment)
+		require.Equal(t, computeMultihash("recoveryReveal"), op.RecoveryCommitment)
+		require.True(t, UsesLegacyRevealValues(op))
+
+		op, err = ParseCreateOperation(request, protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256})
+		require.Error(t, err)
+		require.Nil(t, op)
+		require.Contains(t, err.Error(), "next recovery reveal value is no longer supported")
+	})
+	t.Run("update", func(t *testing.T) {
+		delta, err := getUpdateDelta()
+		require.NoError(t, err)
+		delta.UpdateCommitment = ""
+		delta.NextUpdateRevealValue = updateReveal
+
+		req, err := getUpdateRequest(delta)
+		require.NoError(t, err)
+
+		request, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		op, err := ParseUpdateOperation(request, legacy)
+		require.NoError(t, err)
+		require.Equal(t, computeMultihash("updateReveal"), op.UpdateCommitment)
+		require.True(t, UsesLegacyRevealValues(op))
+
+		op, err = ParseUpdateOperation(request, protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256})
+		require.Error(t, err)
+		require.Nil(t, op)
+		require.Contains(t, err.Error(), "next update reveal value is no longer supported")
+	})
+	t.Run("recover", func(t *testing.T) {
+		delta, err := getDelta()
+		require.NoError(t, err)
+		delta.UpdateCommitment = ""
+		delta.NextUpdateRevealValue = updateReveal
+
+		signedData := getSignedDataForRecovery()
+		signedData.RecoveryCommitment = ""
+		signedData.NextRecoveryRevealValue = recoveryReveal
+
+		req, err := getRecoverRequest(delta, signedData)
+		require.NoError(t, err)
+
+		request, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		op, err := ParseRecoverOperation(request, legacy)
+		require.NoError(t, err)
+		require.Equal(t, computeMultihash("updateReveal"), op.UpdateCommitment)
+		require.Equal(t, computeMultihash("recoveryReveal"), op.RecoveryCommitment)
+		require.True(t, UsesLegacyRevealValues(op))
+
+		// only the next recovery reveal value is provided by a legacy client
+		op.Delta.NextUpdateRevealValue = ""
+		require.True(t, UsesLegacyRevealValues(op))
+
+		op, err = ParseRecoverOperation(request, protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256})
+		require.Error(t, err)
+		require.Nil(t, op)
+		require.Contains(t, err.Error(), "next update reveal value is no longer supported")
+	})
+	t.Run("both reveal value and commitment provided", func(t *testing.T) {
+		delta, err := getUpdateDelta()
+		require.NoError(t, err)
+		delta.NextUpdateRevealValue = updateReveal
+
+		err = translateLegacyDelta(delta, legacy)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "either the next update reveal value or the update commitment must be provided but not both")
+	})
+	t.Run("invalid reveal value", func(t *testing.T) {
+		_, err := legacyCommitment("update", "!!", "", legacy)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode next update reveal value")
+	})
+	t.Run("reveal value too short", func(t *testing.T) {
+		p := legacy
+		p.MinRevealValueLength = 32
+
+		_, err := legacyCommitment("update", updateReveal, "", p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is less than the minimum length [32]")
+	})
+	t.Run("hash algorithm not supported", func(t *testing.T) {
+		p := legacy
+		p.HashAlgorithmInMultiHashCode = 55
+
+		_, err := legacyCommitment("update", updateReveal, "", p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "algorithm not supported")
+	})
+	t.Run("commitment is returned if reveal value is not provided", func(t *testing.T) {
+		commitment, err := legacyCommitment("update", "", "commitment", protocol.Protocol{})
+		require.NoError(t, err)
+		require.Equal(t, "commitment", commitment)
+	})
+}
+
+func TestParser_LegacyRevealValues(t *testing.T) {
+	p := protocol.Default()
+	p.HashAlgorithmInMultiHashCode = sha2_256
+	p.LegacyRevealValues = true
+
+	delta, err := getUpdateDelta()
+	require.NoError(t, err)
+	delta.UpdateCommitment = ""
+	delta.NextUpdateRevealValue = docutil.EncodeToString([]byte("updateReveal"))
+
+	req, err := getUpdateRequest(delta)
+	require.NoError(t, err)
+
+	request, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	l := logrus.New()
+	l.Out = &buf
+
+	op, err := NewParser(p, WithLogger(log.New(l))).Parse(namespace, request)
+	require.NoError(t, err)
+	require.True(t, UsesLegacyRevealValues(op))
+	require.Contains(t, buf.String(), "Deprecated: next reveal values were provided instead of commitments")
+}
+
+func TestUsesLegacyRevealValues(t *testing.T) {
+	require.False(t, UsesLegacyRevealValues(&batch.Operation{Type: batch.OperationTypeDeactivate}))
+	require.False(t, UsesLegacyRevealValues(&batch.Operation{
+		Type:       batch.OperationTypeRecover,
+		SignedData: &model.JWS{Payload: "="},
+	}))
+	require.False(t, UsesLegacyRevealValues(&batch.Operation{
+		Type:       batch.OperationTypeRecover,
+		SignedData: &model.JWS{Payload: docutil.EncodeToString([]byte("{"))},
+	}))
+}
+
+func TestCommitmentFromRevealValue(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		commitment, err := CommitmentFromRevealValue(docutil.EncodeToString([]byte("reveal")), sha2_256)
+		require.NoError(t, err)
+
+		mh, err := docutil.ComputeMultihash(sha2_256, []byte("reveal"))
+		require.NoError(t, err)
+		require.Equal(t, docutil.EncodeToString(mh), commitment)
+	})
+	t.Run("invalid reveal value", func(t *testing.T) {
+		_, err := CommitmentFromRevealValue("=", sha2_256)
+		require.Error(t, err)
+	})
+	t.Run("unsupported multihash code", func(t *testing.T) {
+		_, err := CommitmentFromRevealValue(docutil.EncodeToString([]byte("reveal")), 55)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "algorithm not supported")
+	})
+}
+
+func getLegacyCreateRequestBytes(delta *model.DeltaModel, suffixData *model.SuffixDataModel) ([]byte, error) {
+	deltaBytes, err := canonicalizer.MarshalCanonical(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	suffixDataBytes, err := canonicalizer.MarshalCanonical(suffixData)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&model.CreateRequest{
+		Operation:  model.OperationTypeCreate,
+		Delta:      docutil.EncodeToString(deltaBytes),
+		SuffixData: docutil.EncodeToString(suffixDataBytes),
+	})
+}
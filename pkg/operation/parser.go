@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+// Parser parses and validates operation requests according to the protocol parameters of a protocol version
+type Parser struct {
+	protocol.Protocol
+	suffixDeriver docutil.SuffixDeriver
+	logger        log.Logger
+}
+
+// ParserOption is an operation parser option
+type ParserOption func(p *Parser)
+
+// WithSuffixDeriver sets the function that derives the unique suffix of a DID from the suffix data of its create
+// operation (the multihash of the suffix data is used by default)
+func WithSuffixDeriver(deriver docutil.SuffixDeriver) ParserOption {
+	return func(p *Parser) {
+		p.suffixDeriver = deriver
+	}
+}
+
+// WithLogger sets the logger (the standard logrus logger is used by default)
+func WithLogger(logger log.Logger) ParserOption {
+	return func(p *Parser) {
+		p.logger = logger
+	}
+}
+
+// NewParser returns a new operation parser for the given protocol parameters
+func NewParser(p protocol.Protocol, opts ...ParserOption) *Parser {
+	parser := &Parser{
+		Protocol:      p,
+		suffixDeriver: docutil.MultihashSuffixDeriver{},
+		logger:        log.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(parser)
+	}
+
+	return parser
+}
+
+// Parse parses and validates the operation request and returns the operation for the given namespace
+func (p *Parser) Parse(namespace string, operationBuffer []byte) (*batch.Operation, error) {
+	schema := &operationSchema{}
+	err := json.Unmarshal(operationBuffer, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := document.WithCharacterPolicy(p.CharacterPolicy)
+
+	var op *batch.Operation
+	var parseErr error
+	switch schema.Operation {
+	case model.OperationTypeCreate:
+		op, parseErr = parseCreateOperation(operationBuffer, p.Protocol, p.suffixDeriver, policy)
+	case model.OperationTypeUpdate:
+		op, parseErr = ParseUpdateOperation(operationBuffer, p.Protocol, policy)
+	case model.OperationTypeDeactivate:
+		op, parseErr = ParseDeactivateOperation(operationBuffer, p.Protocol)
+	case model.OperationTypeRecover:
+		op, parseErr = ParseRecoverOperation(operationBuffer, p.Protocol, policy)
+	default:
+		return nil, fmt.Errorf("operation type [%s] not implemented", schema.Operation)
+	}
+
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if op.Delta != nil {
+		if err := patch.ValidateActions(op.Delta.Patches, p.Patches); err != nil {
+			return nil, err
+		}
+
+		if err := patch.ValidateProtectedProperties(op.Delta.Patches, p.JSONPatchProtectedProperties); err != nil {
+			return nil, err
+		}
+	}
+
+	if UsesLegacyRevealValues(op) {
+		p.logger.WithFields(log.Fields{
+			log.FieldSuffix:        op.UniqueSuffix,
+			log.FieldOperationType: op.Type,
+		}).Warnf("Deprecated: next reveal values were provided instead of commitments. " +
+			"Support for legacy reveal values will be removed in a future protocol version.")
+	}
+
+	op.ID = namespace + docutil.NamespaceDelimiter + op.UniqueSuffix
+
+	return op, nil
+}
+
+// operationSchema is used to get operation type
+type operationSchema struct {
+
+	// operation
+	Operation model.OperationType `json:"type"`
+}
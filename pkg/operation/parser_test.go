@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+const namespace = "did:sidetree"
+
+func TestParser_Parse(t *testing.T) {
+	parser := NewParser(protocol.Protocol{
+		HashAlgorithmInMultiHashCode: sha2_256,
+	})
+
+	t.Run("create", func(t *testing.T) {
+		request, err := getCreateRequestBytes()
+		require.NoError(t, err)
+
+		op, err := parser.Parse(namespace, request)
+		require.NoError(t, err)
+		require.Equal(t, batch.OperationTypeCreate, op.Type)
+		require.Equal(t, namespace+docutil.NamespaceDelimiter+op.UniqueSuffix, op.ID)
+	})
+	t.Run("create with suffix deriver", func(t *testing.T) {
+		deriver, err := docutil.NewUUIDSuffixDeriver("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		require.NoError(t, err)
+
+		request, err := getCreateRequestBytes()
+		require.NoError(t, err)
+
+		op, err := NewParser(protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256},
+			WithSuffixDeriver(deriver)).Parse(namespace, request)
+		require.NoError(t, err)
+		require.NoError(t, deriver.ValidateSuffix(op.UniqueSuffix))
+		require.Equal(t, namespace+docutil.NamespaceDelimiter+op.UniqueSuffix, op.ID)
+	})
+	t.Run("character policy", func(t *testing.T) {
+		services, err := patch.NewAddServiceEndpointsPatch(`[{"id": "hub", "type": "IdentityHub", "serviceEndpoint": "https://example.com/hub"}]`)
+		require.NoError(t, err)
+
+		// non-ASCII service type
+		services[patch.ServiceEndpointsKey].([]interface{})[0].(map[string]interface{})["type"] = "IdentitätHub"
+
+		req, err := getUpdateRequest(&model.DeltaModel{
+			UpdateCommitment: computeMultihash("updateReveal"),
+			Patches:          []patch.Patch{services},
+		})
+		require.NoError(t, err)
+
+		request, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		op, err := parser.Parse(namespace, request)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "non-ASCII character U+00E4 is not allowed")
+		require.Nil(t, op)
+
+		op, err = NewParser(protocol.Protocol{
+			HashAlgorithmInMultiHashCode: sha2_256,
+			CharacterPolicy:              document.CharacterPolicy{AllowNonASCII: true},
+		}).Parse(namespace, request)
+		require.NoError(t, err)
+		require.Equal(t, batch.OperationTypeUpdate, op.Type)
+	})
+	t.Run("update", func(t *testing.T) {
+		request, err := getUpdateRequestBytes()
+		require.NoError(t, err)
+
+		op, err := parser.Parse(namespace, request)
+		require.NoError(t, err)
+		require.Equal(t, batch.OperationTypeUpdate, op.Type)
+		require.Equal(t, namespace+docutil.NamespaceDelimiter+op.UniqueSuffix, op.ID)
+	})
+	t.Run("deactivate", func(t *testing.T) {
+		request, err := getDeactivateRequestBytes()
+		require.NoError(t, err)
+
+		op, err := parser.Parse(namespace, request)
+		require.NoError(t, err)
+		require.Equal(t, batch.OperationTypeDeactivate, op.Type)
+	})
+	t.Run("recover", func(t *testing.T) {
+		request, err := getRecoverRequestBytes()
+		require.NoError(t, err)
+
+		op, err := parser.Parse(namespace, request)
+		require.NoError(t, err)
+		require.Equal(t, batch.OperationTypeRecover, op.Type)
+	})
+	t.Run("operation parsing error", func(t *testing.T) {
+		// set-up invalid hash algorithm in protocol configuration
+		parserWithErr := NewParser(protocol.Protocol{
+			HashAlgorithmInMultiHashCode: 55,
+		})
+
+		request, err := getRecoverRequestBytes()
+		require.NoError(t, err)
+
+		op, err := parserWithErr.Parse(namespace, request)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "next update commitment hash is not computed with the latest supported hash algorithm")
+		require.Nil(t, op)
+	})
+	t.Run("patch action not allowed error", func(t *testing.T) {
+		parserWithPolicy := NewParser(protocol.Protocol{
+			HashAlgorithmInMultiHashCode: sha2_256,
+			Patches:                      []string{"add-public-keys"},
+		})
+
+		request, err := getUpdateRequestBytes()
+		require.NoError(t, err)
+
+		op, err := parserWithPolicy.Parse(namespace, request)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "action 'ietf-json-patch' is not allowed")
+		require.Nil(t, op)
+	})
+	t.Run("protected property error", func(t *testing.T) {
+		parserWithProtected := NewParser(protocol.Protocol{
+			HashAlgorithmInMultiHashCode: sha2_256,
+			JSONPatchProtectedProperties: []string{"name"},
+		})
+
+		request, err := getUpdateRequestBytes()
+		require.NoError(t, err)
+
+		op, err := parserWithProtected.Parse(namespace, request)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "patch[0]: path /patches/0/path: ietf-json-patch: cannot modify name")
+		require.Nil(t, op)
+	})
+	t.Run("reveal value length is not checked", func(t *testing.T) {
+		// the commitment of the reveal value was anchored under the bounds of an earlier protocol version
+		request, err := getDeactivateRequestBytes()
+		require.NoError(t, err)
+
+		op, err := NewParser(protocol.Protocol{
+			HashAlgorithmInMultiHashCode: sha2_256,
+			MinRevealValueLength:         32,
+			MaxRevealValueLength:         64,
+		}).Parse(namespace, request)
+		require.NoError(t, err)
+		require.NotNil(t, op)
+	})
+	t.Run("unsupported operation type error", func(t *testing.T) {
+		request, err := json.Marshal(&operationSchema{Operation: "unsupported"})
+		require.NoError(t, err)
+
+		op, err := parser.Parse(namespace, request)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not implemented")
+		require.Nil(t, op)
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		op, err := parser.Parse(namespace, []byte(invalid))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid character")
+		require.Nil(t, op)
+	})
+}
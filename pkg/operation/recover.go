@@ -13,12 +13,13 @@ import (
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 )
 
-// ParseRecoverOperation will parse recover operation
-func ParseRecoverOperation(request []byte, protocol protocol.Protocol) (*batch.Operation, error) {
+// ParseRecoverOperation will parse recover operation. The patches are validated using the given options.
+func ParseRecoverOperation(request []byte, protocol protocol.Protocol, opts ...document.ValidationOpt) (*batch.Operation, error) {
 	schema, err := parseRecoverRequest(request)
 	if err != nil {
 		return nil, err
@@ -26,12 +27,12 @@ func ParseRecoverOperation(request []byte, protocol protocol.Protocol) (*batch.O
 
 	code := protocol.HashAlgorithmInMultiHashCode
 
-	delta, err := parseDelta(schema.Delta, code)
+	delta, err := parseDelta(schema.Delta, protocol, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	signedData, err := parseSignedDataForRecovery(schema.SignedData.Payload, code)
+	signedData, err := parseSignedDataForRecovery(schema.SignedData.Payload, protocol)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +65,7 @@ func parseRecoverRequest(payload []byte) (*model.RecoverRequest, error) {
 	return schema, nil
 }
 
-func parseDelta(encoded string, code uint) (*model.DeltaModel, error) {
+func parseDelta(encoded string, p protocol.Protocol, opts ...document.ValidationOpt) (*model.DeltaModel, error) {
 	bytes, err := docutil.DecodeString(encoded)
 	if err != nil {
 		return nil, err
@@ -76,14 +77,18 @@ func parseDelta(encoded string, code uint) (*model.DeltaModel, error) {
 		return nil, err
 	}
 
-	if err := validateDelta(schema, code); err != nil {
+	if err := translateLegacyDelta(schema, p); err != nil {
+		return nil, err
+	}
+
+	if err := validateDelta(schema, p.HashAlgorithmInMultiHashCode, opts...); err != nil {
 		return nil, err
 	}
 
 	return schema, nil
 }
 
-func parseSignedDataForRecovery(encoded string, code uint) (*model.RecoverSignedDataModel, error) {
+func parseSignedDataForRecovery(encoded string, p protocol.Protocol) (*model.RecoverSignedDataModel, error) {
 	bytes, err := docutil.DecodeString(encoded)
 	if err != nil {
 		return nil, err
@@ -95,7 +100,11 @@ func parseSignedDataForRecovery(encoded string, code uint) (*model.RecoverSigned
 		return nil, err
 	}
 
-	if err := validateSignedDataForRecovery(schema, code); err != nil {
+	if err := translateLegacySignedDataForRecovery(schema, p); err != nil {
+		return nil, err
+	}
+
+	if err := validateSignedDataForRecovery(schema, p.HashAlgorithmInMultiHashCode); err != nil {
 		return nil, err
 	}
 
@@ -107,6 +116,10 @@ func validateSignedDataForRecovery(signedData *model.RecoverSignedDataModel, cod
 		return err
 	}
 
+	if err := validateAnchorWindow(signedData.AnchorWindow); err != nil {
+		return err
+	}
+
 	if !docutil.IsComputedUsingHashAlgorithm(signedData.RecoveryCommitment, uint64(code)) {
 		return errors.New("next recovery commitment hash is not computed with the latest supported hash algorithm")
 	}
@@ -138,6 +138,23 @@ func TestValidateSignedDataForRecovery(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "next recovery commitment hash is not computed with the latest supported hash algorithm")
 	})
+	t.Run("invalid anchor window", func(t *testing.T) {
+		signed := getSignedDataForRecovery()
+		signed.AnchorFrom = 20
+		signed.AnchorUntil = 10
+		err := validateSignedDataForRecovery(signed, sha2_256)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "anchor from [20] is greater than anchor until [10]")
+	})
+	t.Run("valid anchor window", func(t *testing.T) {
+		signed := getSignedDataForRecovery()
+		signed.AnchorFrom = 10
+		signed.AnchorUntil = 10
+		require.NoError(t, validateSignedDataForRecovery(signed, sha2_256))
+
+		signed.AnchorFrom = 0
+		require.NoError(t, validateSignedDataForRecovery(signed, sha2_256))
+	})
 }
 
 func TestValidateSignedData(t *testing.T) {
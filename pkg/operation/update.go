@@ -12,22 +12,27 @@ import (
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 )
 
-// ParseUpdateOperation will parse update operation
-func ParseUpdateOperation(request []byte, protocol protocol.Protocol) (*batch.Operation, error) {
+// ParseUpdateOperation will parse update operation. The patches are validated using the given options.
+func ParseUpdateOperation(request []byte, protocol protocol.Protocol, opts ...document.ValidationOpt) (*batch.Operation, error) {
 	schema, err := parseUpdateRequest(request)
 	if err != nil {
 		return nil, err
 	}
 
-	delta, err := parseUpdateDelta(schema.Delta, protocol.HashAlgorithmInMultiHashCode)
+	delta, err := parseUpdateDelta(schema.Delta, protocol, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	if _, err := parseSignedDataForUpdate(schema.SignedData.Payload); err != nil {
+		return nil, err
+	}
+
 	return &batch.Operation{
 		Type:                         batch.OperationTypeUpdate,
 		OperationBuffer:              request,
@@ -55,7 +60,7 @@ func parseUpdateRequest(payload []byte) (*model.UpdateRequest, error) {
 	return schema, nil
 }
 
-func parseUpdateDelta(encoded string, code uint) (*model.DeltaModel, error) {
+func parseUpdateDelta(encoded string, p protocol.Protocol, opts ...document.ValidationOpt) (*model.DeltaModel, error) {
 	bytes, err := docutil.DecodeString(encoded)
 	if err != nil {
 		return nil, err
@@ -67,7 +72,11 @@ func parseUpdateDelta(encoded string, code uint) (*model.DeltaModel, error) {
 		return nil, err
 	}
 
-	if err := validateDelta(schema, code); err != nil {
+	if err := translateLegacyDelta(schema, p); err != nil {
+		return nil, err
+	}
+
+	if err := validateDelta(schema, p.HashAlgorithmInMultiHashCode, opts...); err != nil {
 		return nil, err
 	}
 
@@ -31,6 +31,34 @@ func TestParseUpdateOperation(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, batch.OperationTypeUpdate, op.Type)
 	})
+	t.Run("invalid anchor window", func(t *testing.T) {
+		req, err := getDefaultUpdateRequest()
+		require.NoError(t, err)
+
+		req.SignedData.Payload = docutil.EncodeToString([]byte(`{"delta_hash":"hash","anchor_from":20,"anchor_until":10}`))
+
+		payload, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		op, err := ParseUpdateOperation(payload, p)
+		require.Error(t, err)
+		require.Nil(t, op)
+		require.Contains(t, err.Error(), "anchor from [20] is greater than anchor until [10]")
+	})
+	t.Run("invalid signed data payload", func(t *testing.T) {
+		req, err := getDefaultUpdateRequest()
+		require.NoError(t, err)
+
+		req.SignedData.Payload = docutil.EncodeToString([]byte("payload"))
+
+		payload, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		op, err := ParseUpdateOperation(payload, p)
+		require.Error(t, err)
+		require.Nil(t, op)
+		require.Contains(t, err.Error(), "invalid character")
+	})
 	t.Run("invalid json", func(t *testing.T) {
 		schema, err := ParseUpdateOperation([]byte(""), p)
 		require.Error(t, err)
@@ -90,14 +118,14 @@ func TestParseUpdateDelta(t *testing.T) {
 		deltaBytes, err := json.Marshal(delta)
 		require.NoError(t, err)
 
-		parsed, err := parseUpdateDelta(docutil.EncodeToString(deltaBytes), sha2_256)
+		parsed, err := parseUpdateDelta(docutil.EncodeToString(deltaBytes), protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256})
 		require.Error(t, err)
 		require.Nil(t, parsed)
 		require.Contains(t, err.Error(),
 			"next update commitment hash is not computed with the latest supported hash algorithm")
 	})
 	t.Run("invalid bytes", func(t *testing.T) {
-		parsed, err := parseUpdateDelta("invalid", sha2_256)
+		parsed, err := parseUpdateDelta("invalid", protocol.Protocol{HashAlgorithmInMultiHashCode: sha2_256})
 		require.Error(t, err)
 		require.Nil(t, parsed)
 		require.Contains(t, err.Error(), "invalid character")
@@ -154,7 +182,7 @@ func getUpdateRequest(delta *model.DeltaModel) (*model.UpdateRequest, error) {
 				Alg: "alg",
 				Kid: "kid",
 			},
-			Payload:   "payload",
+			Payload:   docutil.EncodeToString([]byte(`{"delta_hash":"hash"}`)),
 			Signature: "signature",
 		},
 		Operation: model.OperationTypeUpdate,
@@ -174,9 +174,10 @@ func NewRemovePublicKeysPatch(publicKeyIds string) (Patch, error) {
 	return patch, nil
 }
 
-// NewAddServiceEndpointsPatch creates new patch for adding service endpoints
-func NewAddServiceEndpointsPatch(serviceEndpoints string) (Patch, error) {
-	services, err := getServices(serviceEndpoints)
+// NewAddServiceEndpointsPatch creates new patch for adding service endpoints. The services are validated
+// using the given options (e.g. the character policy).
+func NewAddServiceEndpointsPatch(serviceEndpoints string, opts ...document.ValidationOpt) (Patch, error) {
+	services, err := getServices(serviceEndpoints, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -231,27 +232,162 @@ func (p Patch) Bytes() ([]byte, error) {
 	return docutil.MarshalCanonical(p)
 }
 
-// Validate validates patch
-func (p Patch) Validate() error {
+// ValidationError is returned when a patch is invalid. Index is the index of the invalid patch within the
+// patches of an operation (-1 if the patch was validated on its own) and Path is the JSON Pointer (RFC 6901)
+// of the offending value within the patch, e.g. /public_keys/0/jwk.
+type ValidationError struct {
+	Index int
+	Path  string
+	Err   error
+}
+
+// Error returns the error message including the index of the patch and the path of the offending value
+func (e *ValidationError) Error() string {
+	msg := e.Err.Error()
+
+	if e.Path != "" {
+		msg = fmt.Sprintf("path %s: %s", e.Path, msg)
+	}
+
+	if e.Index >= 0 {
+		msg = fmt.Sprintf("patch[%d]: %s", e.Index, msg)
+	}
+
+	return msg
+}
+
+// Unwrap returns the underlying error
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// newValidationError returns a validation error for the value of the given key. If the error is a
+// document.PropertyError then its path (relative to the value) is appended to the path of the key.
+func newValidationError(key Key, err error) *ValidationError {
+	path := "/" + string(key)
+
+	var propErr *document.PropertyError
+	if errors.As(err, &propErr) {
+		return &ValidationError{Index: -1, Path: path + propErr.Path, Err: propErr.Err}
+	}
+
+	return &ValidationError{Index: -1, Path: path, Err: err}
+}
+
+// withIndex returns a copy of the validation error with the given patch index
+func withIndex(err error, index int) error {
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+
+	return &ValidationError{Index: index, Path: ve.Path, Err: ve.Err}
+}
+
+// Validate validates patch using the given options (e.g. the character policy of services).
+// A ValidationError is returned if the patch is invalid.
+func (p Patch) Validate(opts ...document.ValidationOpt) error {
 	action, err := p.parseAction()
 	if err != nil {
-		return err
+		return newValidationError(ActionKey, err)
 	}
 
+	var key Key
+
 	switch action {
 	case JSONPatch:
-		return p.validateJSON()
+		key, err = PatchesKey, p.validateJSON()
 	case AddPublicKeys:
-		return p.validateAddPublicKeys()
+		key, err = PublicKeys, p.validateAddPublicKeys()
 	case RemovePublicKeys:
-		return p.validateRemovePublicKeys()
+		key, err = PublicKeys, p.validateRemovePublicKeys()
 	case AddServiceEndpoints:
-		return p.validateAddServiceEndpoints()
+		key, err = ServiceEndpointsKey, p.validateAddServiceEndpoints(opts...)
 	case RemoveServiceEndpoints:
-		return p.validateRemoveServiceEndpoints()
+		key, err = ServiceEndpointIdsKey, p.validateRemoveServiceEndpoints()
+	default:
+		return newValidationError(ActionKey, fmt.Errorf("action '%s' is not supported", action))
+	}
+
+	if err != nil {
+		return newValidationError(key, err)
+	}
+
+	return nil
+}
+
+// ValidatePatches validates the given patches using the given options. The ValidationError that is returned
+// for an invalid patch contains the index of the patch.
+func ValidatePatches(patches []Patch, opts ...document.ValidationOpt) error {
+	for i, p := range patches {
+		if err := p.Validate(opts...); err != nil {
+			return withIndex(err, i)
+		}
+	}
+
+	return nil
+}
+
+// ValidateActions validates that the actions of the given patches are in the list of allowed actions.
+// All actions are allowed if the list of allowed actions is empty.
+func ValidateActions(patches []Patch, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for i, p := range patches {
+		action, err := p.parseAction()
+		if err != nil {
+			return withIndex(newValidationError(ActionKey, err), i)
+		}
+
+		if !containsAction(allowed, action) {
+			return withIndex(newValidationError(ActionKey, fmt.Errorf("action '%s' is not allowed", action)), i)
+		}
+	}
+
+	return nil
+}
+
+// ValidateProtectedProperties validates that the ietf-json-patch patches of the given patches don't modify
+// any of the given top-level document properties (in addition to id, controller, public keys and services which
+// are always protected)
+func ValidateProtectedProperties(patches []Patch, protected []string) error {
+	if len(protected) == 0 {
+		return nil
+	}
+
+	for i, p := range patches {
+		action, err := p.parseAction()
+		if err != nil {
+			return withIndex(newValidationError(ActionKey, err), i)
+		}
+
+		if action != JSONPatch {
+			continue
+		}
+
+		patchesBytes, err := json.Marshal(p.GetValue(PatchesKey))
+		if err != nil {
+			return err
+		}
+
+		if err := validateJSONPatches(patchesBytes, protected...); err != nil {
+			return withIndex(newValidationError(PatchesKey, err), i)
+		}
+	}
+
+	return nil
+}
+
+func containsAction(actions []string, action Action) bool {
+	for _, a := range actions {
+		if Action(a) == action {
+			return true
+		}
 	}
 
-	return fmt.Errorf("action '%s' is not supported", action)
+	return false
 }
 
 // JSONLdObject returns map that represents JSON LD Object
@@ -293,35 +429,98 @@ func validateDocument(doc document.Document) error {
 	return nil
 }
 
-func validateJSONPatches(patches []byte) error {
+// protectedProperties are the top-level document properties that may not be modified by ietf-json-patch patches.
+// The id and controller identify the document and public keys and services may only be modified using
+// their dedicated patch actions.
+var protectedProperties = []string{
+	document.IDProperty,
+	document.ControllerProperty,
+	document.PublicKeyProperty,
+	document.ServiceProperty,
+}
+
+// protectedPropertyNames contains the names of protected properties used in error messages
+var protectedPropertyNames = map[string]string{
+	document.PublicKeyProperty: "public keys",
+	document.ServiceProperty:   "services",
+}
+
+func validateJSONPatches(patches []byte, additionalProtected ...string) error {
 	jsonPatches, err := jsonpatch.DecodePatch(patches)
 	if err != nil {
 		return fmt.Errorf("%s: %s", JSONPatch, err.Error())
 	}
 
-	for _, p := range jsonPatches {
+	for i, p := range jsonPatches {
 		pathMsg, ok := p["path"]
 		if !ok {
-			return fmt.Errorf("%s: path not found", JSONPatch)
+			return newPropertyError(fmt.Sprintf("/%d", i), fmt.Errorf("%s: path not found", JSONPatch))
 		}
 
 		var path string
 		if err := json.Unmarshal(*pathMsg, &path); err != nil {
-			return fmt.Errorf("%s: invalid path", JSONPatch)
+			return newPropertyError(fmt.Sprintf("/%d/path", i), fmt.Errorf("%s: invalid path", JSONPatch))
 		}
 
-		if strings.HasPrefix(path, "/"+document.ServiceProperty) {
-			return fmt.Errorf("%s: cannot modify services", JSONPatch)
+		if err := validateJSONPatchPath(path, additionalProtected); err != nil {
+			return newPropertyError(fmt.Sprintf("/%d/path", i), err)
 		}
 
-		if strings.HasPrefix(path, "/"+document.PublicKeyProperty) {
-			return fmt.Errorf("%s: cannot modify public keys", JSONPatch)
+		// a move operation removes the value at the 'from' location
+		var op string
+		if opMsg, ok := p["op"]; !ok || json.Unmarshal(*opMsg, &op) != nil || op != "move" {
+			continue
+		}
+
+		var from string
+		if fromMsg, ok := p["from"]; !ok || json.Unmarshal(*fromMsg, &from) != nil {
+			return newPropertyError(fmt.Sprintf("/%d/from", i), fmt.Errorf("%s: invalid from", JSONPatch))
+		}
+
+		if err := validateJSONPatchPath(from, additionalProtected); err != nil {
+			return newPropertyError(fmt.Sprintf("/%d/from", i), err)
 		}
 	}
 
 	return nil
 }
 
+// validateJSONPatchPath checks that the given JSON Pointer doesn't refer to the whole document or to
+// (a value within) a protected top-level property
+func validateJSONPatchPath(path string, additionalProtected []string) error {
+	if path == "" {
+		return fmt.Errorf("%s: cannot replace the document", JSONPatch)
+	}
+
+	property := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+	property = strings.NewReplacer("~1", "/", "~0", "~").Replace(property)
+
+	if contains(protectedProperties, property) || contains(additionalProtected, property) {
+		name, ok := protectedPropertyNames[property]
+		if !ok {
+			name = property
+		}
+
+		return fmt.Errorf("%s: cannot modify %s", JSONPatch, name)
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newPropertyError(path string, err error) error {
+	return &document.PropertyError{Path: path, Err: err}
+}
+
 func getPublicKeys(publicKeys string) (interface{}, error) {
 	// create an empty did document with public keys
 	pkDoc, err := document.DidDocumentFromBytes([]byte(fmt.Sprintf(`{"%s":%s}`, document.PublicKeyProperty, publicKeys)))
@@ -338,7 +537,7 @@ func getPublicKeys(publicKeys string) (interface{}, error) {
 	return pkDoc[document.PublicKeyProperty], nil
 }
 
-func getServices(serviceEndpoints string) (interface{}, error) {
+func getServices(serviceEndpoints string, opts ...document.ValidationOpt) (interface{}, error) {
 	// create an empty did document with service endpoints
 	svcDocStr := fmt.Sprintf(`{"%s":%s}`, document.ServiceProperty, serviceEndpoints)
 	svcDoc, err := document.DidDocumentFromBytes([]byte(svcDocStr))
@@ -347,7 +546,7 @@ func getServices(serviceEndpoints string) (interface{}, error) {
 	}
 
 	services := svcDoc.Services()
-	err = document.ValidateServices(services)
+	err = document.ValidateServices(services, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -422,14 +621,14 @@ func (p Patch) validateRemovePublicKeys() error {
 	return validateIds(document.StringArray(genericArr))
 }
 
-func (p Patch) validateAddServiceEndpoints() error {
+func (p Patch) validateAddServiceEndpoints(opts ...document.ValidationOpt) error {
 	_, err := p.getRequiredArray(ServiceEndpointsKey)
 	if err != nil {
 		return err
 	}
 
 	services := document.ParseServices(p.GetValue(ServiceEndpointsKey))
-	return document.ValidateServices(services)
+	return document.ValidateServices(services, opts...)
 }
 
 func (p Patch) validateRemoveServiceEndpoints() error {
@@ -442,9 +641,9 @@ func (p Patch) validateRemoveServiceEndpoints() error {
 }
 
 func validateIds(ids []string) error {
-	for _, id := range ids {
+	for i, id := range ids {
 		if err := document.ValidateID(id); err != nil {
-			return err
+			return newPropertyError(fmt.Sprintf("/%d", i), err)
 		}
 	}
 
@@ -7,9 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package patch
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
 )
 
 func TestFromBytes(t *testing.T) {
@@ -51,7 +56,7 @@ func TestActionValidation(t *testing.T) {
 		patch, err := FromBytes([]byte(`{"action": "invalid"}`))
 		require.Error(t, err)
 		require.Nil(t, patch)
-		require.Equal(t, err.Error(), "action 'invalid' is not supported")
+		require.Equal(t, err.Error(), "path /action: action 'invalid' is not supported")
 	})
 	t.Run("action not supported", func(t *testing.T) {
 		patch, err := FromBytes([]byte(`{"action": 0}`))
@@ -61,6 +66,55 @@ func TestActionValidation(t *testing.T) {
 	})
 }
 
+func TestValidateActions(t *testing.T) {
+	addKeys, err := FromBytes([]byte(addPublicKeysPatch))
+	require.NoError(t, err)
+
+	jsonPatch, err := NewJSONPatch(patches)
+	require.NoError(t, err)
+
+	t.Run("success - no restrictions", func(t *testing.T) {
+		err := ValidateActions([]Patch{addKeys, jsonPatch}, nil)
+		require.NoError(t, err)
+	})
+	t.Run("success - allowed actions", func(t *testing.T) {
+		err := ValidateActions([]Patch{addKeys}, []string{string(AddPublicKeys)})
+		require.NoError(t, err)
+	})
+	t.Run("error - action not allowed", func(t *testing.T) {
+		err := ValidateActions([]Patch{addKeys, jsonPatch}, []string{string(AddPublicKeys)})
+		require.Error(t, err)
+		require.Equal(t, "patch[1]: path /action: action 'ietf-json-patch' is not allowed", err.Error())
+	})
+}
+
+func TestValidateProtectedProperties(t *testing.T) {
+	addKeys, err := FromBytes([]byte(addPublicKeysPatch))
+	require.NoError(t, err)
+
+	jsonPatch, err := NewJSONPatch(`[{"op": "replace", "path": "/authentication", "value": []}]`)
+	require.NoError(t, err)
+
+	t.Run("success - no additional protected properties", func(t *testing.T) {
+		err := ValidateProtectedProperties([]Patch{addKeys, jsonPatch}, nil)
+		require.NoError(t, err)
+	})
+	t.Run("success - property not modified", func(t *testing.T) {
+		err := ValidateProtectedProperties([]Patch{addKeys, jsonPatch}, []string{"@context"})
+		require.NoError(t, err)
+	})
+	t.Run("error - protected property modified", func(t *testing.T) {
+		err := ValidateProtectedProperties([]Patch{addKeys, jsonPatch}, []string{"authentication"})
+		require.Error(t, err)
+		require.Equal(t, "patch[1]: path /patches/0/path: ietf-json-patch: cannot modify authentication", err.Error())
+	})
+	t.Run("error - missing action", func(t *testing.T) {
+		err := ValidateProtectedProperties([]Patch{{}}, []string{"authentication"})
+		require.Error(t, err)
+		require.Equal(t, "patch[0]: path /action: patch is missing action property", err.Error())
+	})
+}
+
 func TestPatchesFromDocument(t *testing.T) {
 	t.Run("success from new", func(t *testing.T) {
 		patches, err := PatchesFromDocument(replaceDoc)
@@ -87,6 +141,45 @@ func TestPatchesFromDocument(t *testing.T) {
 	})
 }
 
+func TestValidatePatches(t *testing.T) {
+	addKeys, err := FromBytes([]byte(addPublicKeysPatch))
+	require.NoError(t, err)
+
+	invalidKeys := make(Patch)
+	require.NoError(t, json.Unmarshal([]byte(strings.Replace(addPublicKeysPatch, `"P-256K"`, `""`, 1)), &invalidKeys))
+
+	invalidIDs := make(Patch)
+	require.NoError(t, json.Unmarshal([]byte(`{"action": "remove-service-endpoints", "ids": ["sds1", "a123*b456"]}`), &invalidIDs))
+
+	invalidJSONPatch := make(Patch)
+	require.NoError(t, json.Unmarshal([]byte(ietfServicesPatch), &invalidJSONPatch))
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, ValidatePatches([]Patch{addKeys}))
+	})
+	t.Run("invalid public key", func(t *testing.T) {
+		err := ValidatePatches([]Patch{addKeys, invalidKeys})
+		require.EqualError(t, err, "patch[1]: path /public_keys/0/jwk: JWK crv is missing")
+
+		var ve *ValidationError
+		require.True(t, errors.As(err, &ve))
+		require.Equal(t, 1, ve.Index)
+		require.Equal(t, "/public_keys/0/jwk", ve.Path)
+	})
+	t.Run("invalid id", func(t *testing.T) {
+		err := ValidatePatches([]Patch{invalidIDs})
+		require.EqualError(t, err, "patch[0]: path /ids/1: id contains invalid characters")
+	})
+	t.Run("invalid JSON patch", func(t *testing.T) {
+		err := ValidatePatches([]Patch{addKeys, addKeys, invalidJSONPatch})
+		require.EqualError(t, err, "patch[2]: path /patches/0/path: ietf-json-patch: cannot modify services")
+	})
+	t.Run("missing action", func(t *testing.T) {
+		err := ValidatePatches([]Patch{{}})
+		require.EqualError(t, err, "patch[0]: path /action: patch is missing action property")
+	})
+}
+
 func TestIETFPatch(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		patch, err := FromBytes([]byte(ietfPatch))
@@ -98,20 +191,63 @@ func TestIETFPatch(t *testing.T) {
 		patch, err := FromBytes([]byte(ietfPatchNoPath))
 		require.Error(t, err)
 		require.Nil(t, patch)
-		require.Equal(t, err.Error(), "ietf-json-patch: path not found")
+		require.Equal(t, err.Error(), "path /patches/0: ietf-json-patch: path not found")
 	})
 	t.Run("error - cannot update services", func(t *testing.T) {
 		patch, err := FromBytes([]byte(ietfServicesPatch))
 		require.Error(t, err)
 		require.Nil(t, patch)
-		require.Equal(t, err.Error(), "ietf-json-patch: cannot modify services")
+		require.Equal(t, err.Error(), "path /patches/0/path: ietf-json-patch: cannot modify services")
 	})
 	t.Run("error - cannot update public keys", func(t *testing.T) {
 		patch, err := FromBytes([]byte(ietfPublicKeysPatch))
 		require.Error(t, err)
 		require.Nil(t, patch)
+		require.Equal(t, err.Error(), "path /patches/0/path: ietf-json-patch: cannot modify public keys")
+	})
+	t.Run("error - cannot update id", func(t *testing.T) {
+		p, err := NewJSONPatch(`[{"op": "replace", "path": "/id", "value": "did:other:123"}]`)
+		require.Error(t, err)
+		require.Nil(t, p)
+		require.Equal(t, err.Error(), "ietf-json-patch: cannot modify id")
+	})
+	t.Run("error - cannot update controller", func(t *testing.T) {
+		p, err := NewJSONPatch(`[{"op": "add", "path": "/name", "value": "x"}, {"op": "remove", "path": "/controller"}]`)
+		require.Error(t, err)
+		require.Nil(t, p)
+		require.Equal(t, err.Error(), "ietf-json-patch: cannot modify controller")
+
+		var propErr *document.PropertyError
+		require.True(t, errors.As(err, &propErr))
+		require.Equal(t, "/1/path", propErr.Path)
+	})
+	t.Run("error - cannot update value within public keys", func(t *testing.T) {
+		p, err := NewJSONPatch(`[{"op": "replace", "path": "/publicKey/0/type", "value": "x"}]`)
+		require.Error(t, err)
+		require.Nil(t, p)
 		require.Equal(t, err.Error(), "ietf-json-patch: cannot modify public keys")
 	})
+	t.Run("error - cannot move from service", func(t *testing.T) {
+		p, err := NewJSONPatch(`[{"op": "move", "from": "/service", "path": "/other"}]`)
+		require.Error(t, err)
+		require.Nil(t, p)
+		require.Equal(t, err.Error(), "ietf-json-patch: cannot modify services")
+
+		var propErr *document.PropertyError
+		require.True(t, errors.As(err, &propErr))
+		require.Equal(t, "/0/from", propErr.Path)
+	})
+	t.Run("error - cannot replace document", func(t *testing.T) {
+		p, err := NewJSONPatch(`[{"op": "replace", "path": "", "value": {}}]`)
+		require.Error(t, err)
+		require.Nil(t, p)
+		require.Equal(t, err.Error(), "ietf-json-patch: cannot replace the document")
+	})
+	t.Run("success - property with protected prefix", func(t *testing.T) {
+		p, err := NewJSONPatch(`[{"op": "add", "path": "/identity", "value": "x"}]`)
+		require.NoError(t, err)
+		require.NotNil(t, p)
+	})
 	t.Run("missing patches", func(t *testing.T) {
 		patch, err := FromBytes([]byte(`{"action": "ietf-json-patch"}`))
 		require.Error(t, err)
@@ -218,6 +354,27 @@ func TestAddServiceEndpointsPatch(t *testing.T) {
 		require.Nil(t, p)
 		require.Contains(t, err.Error(), "service id is missing")
 	})
+	t.Run("character policy", func(t *testing.T) {
+		services := `[{"id": "hub", "type": "IdentitätHub", "serviceEndpoint": "https://example.com/hub"}]`
+
+		p, err := NewAddServiceEndpointsPatch(services)
+		require.Error(t, err)
+		require.Nil(t, p)
+		require.Contains(t, err.Error(), "non-ASCII character U+00E4 is not allowed")
+
+		policy := document.WithCharacterPolicy(document.CharacterPolicy{AllowNonASCII: true})
+
+		p, err = NewAddServiceEndpointsPatch(services, policy)
+		require.NoError(t, err)
+		require.NotNil(t, p)
+
+		err = p.Validate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "non-ASCII character U+00E4 is not allowed")
+
+		require.NoError(t, p.Validate(policy))
+		require.NoError(t, ValidatePatches([]Patch{p}, policy))
+	})
 	t.Run("success from new", func(t *testing.T) {
 		p, err := NewAddServiceEndpointsPatch(testAddServiceEndpoints)
 		require.NoError(t, err)
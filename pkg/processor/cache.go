@@ -0,0 +1,146 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+// CacheKey identifies the state of a document. The state changes (and the cached result no longer applies)
+// as soon as a new operation is stored for the document.
+type CacheKey struct {
+	// UniqueSuffix is the unique suffix of the document
+	UniqueSuffix string
+	// UpdateCommitment is the update commitment of the latest operation of the document
+	UpdateCommitment string
+	// OperationCount is the number of operations of the document. (The update commitment alone doesn't identify
+	// the state of the document since an update operation may reuse the previous commitment.)
+	OperationCount int
+}
+
+// Cache caches the resolution results of documents
+type Cache interface {
+	// Get returns the resolution result for the given key or false if the result isn't cached
+	Get(key CacheKey) (*document.ResolutionResult, bool)
+
+	// Put caches the resolution result for the given key
+	Put(key CacheKey, result *document.ResolutionResult)
+}
+
+// LRUCache is a Cache which holds the latest resolution result of at most the given number of documents.
+// The least recently used document is evicted when the cache is full.
+type LRUCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type cacheEntry struct {
+	key    CacheKey
+	result *document.ResolutionResult
+}
+
+// NewLRUCache returns a new cache that holds the resolution results of at most maxEntries documents
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get returns a copy of the cached resolution result for the given key. False is returned if the document
+// isn't cached or if the cached result is for a different state of the document.
+func (c *LRUCache) Get(key CacheKey) (*document.ResolutionResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[key.UniqueSuffix]
+	if !ok {
+		return nil, false
+	}
+
+	entry := e.Value.(*cacheEntry)
+	if entry.key != key {
+		// the document has changed since the result was cached
+		c.lru.Remove(e)
+		delete(c.entries, key.UniqueSuffix)
+
+		return nil, false
+	}
+
+	c.lru.MoveToFront(e)
+
+	result, err := copyResult(entry.result)
+	if err != nil {
+		return nil, false
+	}
+
+	return result, true
+}
+
+// Put caches a copy of the resolution result for the given key, replacing the result of any other
+// state of the document
+func (c *LRUCache) Put(key CacheKey, result *document.ResolutionResult) {
+	if c.maxEntries <= 0 {
+		return
+	}
+
+	result, err := copyResult(result)
+	if err != nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if e, ok := c.entries[key.UniqueSuffix]; ok {
+		e.Value = &cacheEntry{key: key, result: result}
+		c.lru.MoveToFront(e)
+
+		return
+	}
+
+	c.entries[key.UniqueSuffix] = c.lru.PushFront(&cacheEntry{key: key, result: result})
+
+	if c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key.UniqueSuffix)
+	}
+}
+
+// Len returns the number of cached documents
+func (c *LRUCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lru.Len()
+}
+
+// copyResult returns a deep copy of the document of the resolution result since callers may modify
+// the returned document
+func copyResult(result *document.ResolutionResult) (*document.ResolutionResult, error) {
+	docBytes, err := result.Document.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := document.FromBytes(docBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	resultCopy := *result
+	resultCopy.Document = doc
+
+	return &resultCopy, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+func TestLRUCache(t *testing.T) {
+	key1 := CacheKey{UniqueSuffix: "suffix1", UpdateCommitment: "commitment1", OperationCount: 1}
+	key2 := CacheKey{UniqueSuffix: "suffix2", UpdateCommitment: "commitment1", OperationCount: 1}
+	key3 := CacheKey{UniqueSuffix: "suffix3", UpdateCommitment: "commitment1", OperationCount: 1}
+
+	t.Run("get and put", func(t *testing.T) {
+		c := NewLRUCache(2)
+
+		result, ok := c.Get(key1)
+		require.False(t, ok)
+		require.Nil(t, result)
+
+		c.Put(key1, newResult("value1"))
+
+		result, ok = c.Get(key1)
+		require.True(t, ok)
+		require.Equal(t, "value1", result.Document["name"])
+	})
+
+	t.Run("changed document", func(t *testing.T) {
+		c := NewLRUCache(2)
+		c.Put(key1, newResult("value1"))
+
+		changed := key1
+		changed.UpdateCommitment = "commitment2"
+
+		result, ok := c.Get(changed)
+		require.False(t, ok)
+		require.Nil(t, result)
+		require.Equal(t, 0, c.Len())
+
+		// the operation count is also part of the state of the document
+		c.Put(key1, newResult("value1"))
+
+		changed = key1
+		changed.OperationCount = 2
+
+		result, ok = c.Get(changed)
+		require.False(t, ok)
+		require.Nil(t, result)
+
+		c.Put(changed, newResult("value2"))
+		require.Equal(t, 1, c.Len())
+
+		result, ok = c.Get(changed)
+		require.True(t, ok)
+		require.Equal(t, "value2", result.Document["name"])
+	})
+
+	t.Run("eviction", func(t *testing.T) {
+		c := NewLRUCache(2)
+		c.Put(key1, newResult("value1"))
+		c.Put(key2, newResult("value2"))
+
+		// key1 becomes the most recently used
+		_, ok := c.Get(key1)
+		require.True(t, ok)
+
+		c.Put(key3, newResult("value3"))
+		require.Equal(t, 2, c.Len())
+
+		_, ok = c.Get(key2)
+		require.False(t, ok)
+
+		_, ok = c.Get(key1)
+		require.True(t, ok)
+
+		_, ok = c.Get(key3)
+		require.True(t, ok)
+	})
+
+	t.Run("copies", func(t *testing.T) {
+		c := NewLRUCache(2)
+
+		r := newResult("value1")
+		c.Put(key1, r)
+
+		r.Document["name"] = "modified"
+
+		result, ok := c.Get(key1)
+		require.True(t, ok)
+		require.Equal(t, "value1", result.Document["name"])
+
+		result.Document["name"] = "modified"
+
+		result, ok = c.Get(key1)
+		require.True(t, ok)
+		require.Equal(t, "value1", result.Document["name"])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c := NewLRUCache(0)
+		c.Put(key1, newResult("value1"))
+
+		_, ok := c.Get(key1)
+		require.False(t, ok)
+		require.Equal(t, 0, c.Len())
+	})
+}
+
+func newResult(value string) *document.ResolutionResult {
+	return &document.ResolutionResult{
+		Document: document.Document{"name": value},
+	}
+}
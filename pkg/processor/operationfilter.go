@@ -7,12 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package processor
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
 )
 
 // OperationValidationFilter filters out invalid operations.
@@ -21,17 +23,20 @@ type OperationValidationFilter struct {
 }
 
 // NewOperationFilter returns new operation filter with the given name. (Note that name is only used for logging.)
-func NewOperationFilter(name string, store OperationStoreClient) *OperationValidationFilter {
+func NewOperationFilter(name string, store OperationStoreClient, opts ...Option) *OperationValidationFilter {
 	return &OperationValidationFilter{
-		OperationProcessor: New(name, store),
+		OperationProcessor: New(name, store, opts...),
 	}
 }
 
 // Filter filters out the invalid operations and returns only the valid ones
 func (s *OperationValidationFilter) Filter(uniqueSuffix string, newOps []*batch.Operation) ([]*batch.Operation, error) {
-	log.Debugf("[%s] Validating operations for unique suffix [%s]...", s.name, uniqueSuffix)
+	s.logger.Debugf("Validating operations for unique suffix [%s]...", uniqueSuffix)
 
-	newOps = s.filterInvalidSuffix(uniqueSuffix, newOps)
+	// the reasons why operations were rejected
+	rejected := make(map[*batch.Operation]string)
+
+	validSuffixOps := s.filterInvalidSuffix(uniqueSuffix, newOps, rejected)
 
 	ops, err := s.store.Get(uniqueSuffix)
 	if err != nil {
@@ -39,16 +44,16 @@ func (s *OperationValidationFilter) Filter(uniqueSuffix string, newOps []*batch.
 			return nil, err
 		}
 
-		log.Debugf("[%s] Unique suffix not found in the store [%s]", s.name, uniqueSuffix)
+		s.logger.Debugf("Unique suffix not found in the store [%s]", uniqueSuffix)
 	}
 
 	// Combine the existing (persistet) operations with the new operations
-	ops = append(ops, newOps...)
+	ops = append(ops, validSuffixOps...)
 
 	// Sort the operations by transaction time/number
 	sortOperations(ops)
 
-	log.Debugf("[%s] Found %d operations for unique suffix [%s]: %+v", s.name, len(ops), uniqueSuffix, ops)
+	s.logger.Debugf("Found %d operations for unique suffix [%s]: %+v", len(ops), uniqueSuffix, ops)
 
 	// split operations info 'full' and 'update' operations
 	fullOps, updateOps := splitOperations(ops)
@@ -57,14 +62,14 @@ func (s *OperationValidationFilter) Filter(uniqueSuffix string, newOps []*batch.
 	}
 
 	// apply 'full' operations first
-	validFullOps, rm := s.getValidOperations(fullOps, &resolutionModel{})
+	validFullOps, rm := s.getValidOperations(fullOps, &resolutionModel{}, rejected)
 
 	var validUpdateOps []*batch.Operation
 	if rm.Doc == nil {
-		log.Debugf("[%s] Document was deactivated [%s]", s.name, uniqueSuffix)
+		s.logger.Debugf("Document was deactivated [%s]", uniqueSuffix)
 	} else {
 		// next apply update ops since last 'full' transaction
-		validUpdateOps, _ = s.getValidOperations(getOpsWithTxnGreaterThan(updateOps, rm.LastOperationTransactionTime, rm.LastOperationTransactionNumber), rm)
+		validUpdateOps, _ = s.getValidOperations(getOpsWithTxnGreaterThan(updateOps, rm.LastOperationTransactionTime, rm.LastOperationTransactionNumber), rm, rejected)
 	}
 
 	var validNewOps []*batch.Operation
@@ -74,32 +79,66 @@ func (s *OperationValidationFilter) Filter(uniqueSuffix string, newOps []*batch.
 		}
 	}
 
+	if s.tracksSkipped() {
+		s.recordSkipped(uniqueSuffix, skippedOperations(newOps, validNewOps, rejected, validFullOps, rm))
+	}
+
 	return validNewOps, nil
 }
 
-func (s *OperationValidationFilter) getValidOperations(ops []*batch.Operation, rm *resolutionModel) ([]*batch.Operation, *resolutionModel) {
+// skippedOperations returns the new operations which are not valid along with the reason why they were rejected.
+// Operations which were not rejected explicitly were either anchored before the last valid create/recover operation
+// or after the document was deactivated.
+func skippedOperations(newOps, validNewOps []*batch.Operation, rejected map[*batch.Operation]string, validFullOps []*batch.Operation, rm *resolutionModel) []document.SkippedOperation {
+	var skipped []document.SkippedOperation
+
+	for _, op := range newOps {
+		if contains(validNewOps, op) {
+			continue
+		}
+
+		reason, ok := rejected[op]
+		if !ok {
+			if rm.Doc == nil {
+				reason = "document was deactivated"
+			} else {
+				reason = supersededReason(validFullOps[len(validFullOps)-1])
+			}
+		}
+
+		skipped = append(skipped, newSkippedOperation(op, reason))
+	}
+
+	return skipped
+}
+
+func (s *OperationValidationFilter) getValidOperations(ops []*batch.Operation, rm *resolutionModel, rejected map[*batch.Operation]string) ([]*batch.Operation, *resolutionModel) {
 	var validOps []*batch.Operation
 	for _, op := range ops {
 		m, err := s.applyOperation(op, rm)
 		if err != nil {
-			log.Infof("[%s] Rejecting invalid operation {ID: %s, UniqueSuffix: %s, Type: %s, TransactionTime: %d, TransactionNumber: %d}. Reason: %s", s.name, op.ID, op.UniqueSuffix, op.Type, op.TransactionTime, op.TransactionNumber, err)
+			s.operationLogger(op).Infof("Rejecting invalid operation [%s]. Reason: %s", op.ID, err)
+			rejected[op] = err.Error()
+
 			continue
 		}
 
 		validOps = append(validOps, op)
 		rm = m
 
-		log.Debugf("[%s] After applying op %+v, New doc: %s", s.name, op, rm.Doc)
+		s.logger.Debugf("After applying op %+v, New doc: %s", op, rm.Doc)
 	}
 
 	return validOps, rm
 }
 
-func (s *OperationValidationFilter) filterInvalidSuffix(uniqueSuffix string, ops []*batch.Operation) []*batch.Operation {
+func (s *OperationValidationFilter) filterInvalidSuffix(uniqueSuffix string, ops []*batch.Operation, rejected map[*batch.Operation]string) []*batch.Operation {
 	var filtered []*batch.Operation
 	for _, op := range ops {
 		if op.UniqueSuffix != uniqueSuffix {
-			log.Infof("[%s] Rejecting invalid operation {ID: %s, UniqueSuffix: %s Type: %s, TransactionTime: %d, TransactionNumber: %d}. Reason: operation's unique suffix is not set to [%s]", s.name, op.ID, op.UniqueSuffix, op.Type, op.TransactionTime, op.TransactionNumber, uniqueSuffix)
+			s.operationLogger(op).Infof("Rejecting invalid operation [%s]. Reason: operation's unique suffix is not set to [%s]", op.ID, uniqueSuffix)
+			rejected[op] = fmt.Sprintf("operation's unique suffix is not set to [%s]", uniqueSuffix)
+
 			continue
 		}
 
@@ -109,6 +148,15 @@ func (s *OperationValidationFilter) filterInvalidSuffix(uniqueSuffix string, ops
 	return filtered
 }
 
+func (s *OperationValidationFilter) operationLogger(op *batch.Operation) log.Logger {
+	return s.logger.WithFields(log.Fields{
+		log.FieldSuffix:        op.UniqueSuffix,
+		log.FieldOperationType: op.Type,
+		log.FieldTxnTime:       op.TransactionTime,
+		log.FieldTxnNumber:     op.TransactionNumber,
+	})
+}
+
 func contains(ops []*batch.Operation, op *batch.Operation) bool {
 	for _, o := range ops {
 		if o == op {
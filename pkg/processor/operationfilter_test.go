@@ -13,9 +13,13 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
 )
 
@@ -136,4 +140,76 @@ func TestOperationFilter_Filter(t *testing.T) {
 		require.Len(t, validOps, 1)
 		require.True(t, validOps[0] == deactivateOp)
 	})
+
+	t.Run("With logger", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		store := mocks.NewMockOperationStore(nil)
+		store.Validate = false
+
+		createOp, err := getCreateOperation(privateKey)
+		require.NoError(t, err)
+
+		updateOp, err := getUpdateOperation(privateKey, "123456", 1)
+		require.NoError(t, err)
+
+		l, hook := logtest.NewNullLogger()
+
+		filter := NewOperationFilter("test", store, WithLogger(log.New(l)))
+		validOps, err := filter.Filter(createOp.UniqueSuffix, []*batch.Operation{createOp, updateOp})
+		require.NoError(t, err)
+		require.Len(t, validOps, 1)
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		require.Equal(t, logrus.InfoLevel, entry.Level)
+		require.Contains(t, entry.Message, "Rejecting invalid operation")
+		require.Equal(t, "test", entry.Data[log.FieldProcessor])
+		require.Equal(t, "123456", entry.Data[log.FieldSuffix])
+	})
+
+	t.Run("Rejected operations are recorded", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		store := mocks.NewMockOperationStore(nil)
+		store.Validate = false
+
+		createOp, err := getCreateOperation(privateKey)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(createOp))
+
+		updateOp1, err := getUpdateOperation(privateKey, "123456", 1)
+		require.NoError(t, err)
+		updateOp2, err := getUpdateOperation(privateKey, createOp.UniqueSuffix, 1)
+		require.NoError(t, err)
+		updateOp3, err := getUpdateOperation(privateKey, createOp.UniqueSuffix, 3)
+		require.NoError(t, err)
+
+		recorded := make(map[uint64]document.SkippedOperation)
+
+		filter := NewOperationFilter("test", store, WithSkippedOperationRecorder(SkippedOperationRecorderFunc(
+			func(suffix string, skipped []document.SkippedOperation) error {
+				require.Equal(t, createOp.UniqueSuffix, suffix)
+
+				for _, op := range skipped {
+					recorded[op.TransactionNumber] = op
+				}
+
+				return nil
+			},
+		)))
+
+		validOps, err := filter.Filter(createOp.UniqueSuffix, []*batch.Operation{updateOp1, updateOp2, updateOp3})
+		require.NoError(t, err)
+		require.Len(t, validOps, 1)
+		require.True(t, validOps[0] == updateOp2)
+
+		require.Len(t, recorded, 2)
+		require.Equal(t, "update", recorded[1].Type)
+		require.Equal(t, "operation's unique suffix is not set to ["+createOp.UniqueSuffix+"]", recorded[1].Reason)
+		require.Equal(t, "update", recorded[3].Type)
+		require.Contains(t, recorded[3].Reason, "commitment")
+	})
 }
@@ -11,23 +11,38 @@ import (
 	"errors"
 	"fmt"
 	"sort"
-
-	log "github.com/sirupsen/logrus"
+	"sync/atomic"
+	"time"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/composer"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	internal "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/metrics"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/verifier"
 )
 
 // OperationProcessor will process document operations in chronological order and create final document during resolution.
 // It uses operation store client to retrieve all operations that are related to requested document.
 type OperationProcessor struct {
-	name  string
-	store OperationStoreClient
+	name          string
+	store         OperationStoreClient
+	metrics       metrics.Metrics
+	logger        log.Logger
+	pc            protocol.Client
+	slowThreshold time.Duration
+	sampleRate    uint64
+	resolutions   uint64
+	cache         Cache
+
+	skippedRecorder   SkippedOperationRecorder
+	skippedInMetadata bool
 }
 
 // OperationStoreClient defines interface for retrieving all operations related to document
@@ -36,24 +51,133 @@ type OperationStoreClient interface {
 	Get(uniqueSuffix string) ([]*batch.Operation, error)
 }
 
+// Option is an operation processor option
+type Option func(opts *OperationProcessor)
+
+// WithMetrics sets the metrics hooks (no-op metrics are used by default)
+func WithMetrics(m metrics.Metrics) Option {
+	return func(opts *OperationProcessor) {
+		opts.metrics = m
+	}
+}
+
+// WithLogger sets the logger (the standard logrus logger is used by default)
+func WithLogger(logger log.Logger) Option {
+	return func(opts *OperationProcessor) {
+		opts.logger = logger
+	}
+}
+
+// WithProtocolClient sets the protocol client that is used to validate operations against the protocol version
+// that was in effect at the transaction time of the operation (e.g. allowed patch actions and character policy).
+// If not set, the default character policy is used.
+func WithProtocolClient(pc protocol.Client) Option {
+	return func(opts *OperationProcessor) {
+		opts.pc = pc
+	}
+}
+
+// WithSlowResolutionThreshold logs a warning (with the number of operations and a timing breakdown) for every
+// resolution that takes at least the given duration. Slow resolutions are not logged if zero (the default).
+func WithSlowResolutionThreshold(threshold time.Duration) Option {
+	return func(opts *OperationProcessor) {
+		opts.slowThreshold = threshold
+	}
+}
+
+// WithMetricsSampleRate reports the resolution metrics for one out of every n resolutions
+// (the metrics of every resolution are reported by default)
+func WithMetricsSampleRate(n uint) Option {
+	return func(opts *OperationProcessor) {
+		opts.sampleRate = uint64(n)
+	}
+}
+
+// WithCache caches the resolution results so that repeated resolutions of unchanged documents skip
+// the application of the document's operations (results aren't cached by default)
+func WithCache(cache Cache) Option {
+	return func(opts *OperationProcessor) {
+		opts.cache = cache
+	}
+}
+
 // New returns new operation processor with the given name. (Note that name is only used for logging.)
-func New(name string, store OperationStoreClient) *OperationProcessor {
-	return &OperationProcessor{name: name, store: store}
+func New(name string, store OperationStoreClient, opts ...Option) *OperationProcessor {
+	s := &OperationProcessor{
+		name:    name,
+		store:   store,
+		metrics: metrics.NewNoop(),
+		logger:  log.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.logger = s.logger.WithFields(log.Fields{log.FieldProcessor: name})
+
+	return s
 }
 
 // Resolve document based on the given unique suffix
 // Parameters:
 // uniqueSuffix - unique portion of ID to resolve. for example "abc123" in "did:sidetree:abc123"
 func (s *OperationProcessor) Resolve(uniqueSuffix string) (*document.ResolutionResult, error) {
+	startTime := time.Now()
+
+	var numOps int
+
+	var storeTime time.Duration
+
+	defer func() {
+		s.recordResolution(uniqueSuffix, numOps, storeTime, time.Since(startTime))
+	}()
+
 	ops, err := s.store.Get(uniqueSuffix)
+
+	storeTime = time.Since(startTime)
+
 	if err != nil {
 		return nil, err
 	}
 
+	numOps = len(ops)
+
 	sortOperations(ops)
 
-	log.Debugf("[%s] Found %d operations for unique suffix [%s]: %+v", s.name, len(ops), uniqueSuffix, ops)
+	s.logger.Debugf("Found %d operations for unique suffix [%s]: %+v", len(ops), uniqueSuffix, ops)
+
+	if s.cache == nil {
+		result, err := s.resolve(ops)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.reportSkipped(uniqueSuffix, result), nil
+	}
+
+	key := cacheKey(uniqueSuffix, ops)
 
+	if result, ok := s.cache.Get(key); ok {
+		s.metrics.ResolveCacheHit()
+		return s.reportSkipped(uniqueSuffix, result), nil
+	}
+
+	s.metrics.ResolveCacheMiss()
+
+	result, err := s.resolve(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Put(key, result)
+
+	return s.reportSkipped(uniqueSuffix, result), nil
+}
+
+// resolve applies the given (sorted) operations and returns the resolution result. The skipped operations
+// are included in the method metadata if they are tracked.
+func (s *OperationProcessor) resolve(ops []*batch.Operation) (*document.ResolutionResult, error) {
 	rm := &resolutionModel{}
 
 	// split operations info 'full' and 'update' operations
@@ -63,7 +187,7 @@ func (s *OperationProcessor) Resolve(uniqueSuffix string) (*document.ResolutionR
 	}
 
 	// apply 'full' operations first
-	rm, err = s.applyOperations(fullOps, rm)
+	rm, err := s.applyOperations(fullOps, rm)
 	if err != nil {
 		return nil, err
 	}
@@ -73,17 +197,59 @@ func (s *OperationProcessor) Resolve(uniqueSuffix string) (*document.ResolutionR
 	}
 
 	// next apply update ops since last 'full' transaction
-	rm, err = s.applyOperations(getOpsWithTxnGreaterThan(updateOps, rm.LastOperationTransactionTime, rm.LastOperationTransactionNumber), rm)
+	appliedOps := getOpsWithTxnGreaterThan(updateOps, rm.LastOperationTransactionTime, rm.LastOperationTransactionNumber)
+
+	rm, err = s.applyOperations(appliedOps, rm)
 	if err != nil {
 		return nil, err
 	}
 
-	return &document.ResolutionResult{
+	result := &document.ResolutionResult{
 		Document: rm.Doc,
 		MethodMetadata: document.MethodMetadata{
 			RecoveryKey: rm.RecoveryKey,
+			Type:        rm.DIDType,
+			Integrity:   rm.Integrity,
 		},
-	}, nil
+	}
+
+	if s.tracksSkipped() {
+		result.MethodMetadata.SkippedOperations = supersededOperations(updateOps, appliedOps, fullOps[len(fullOps)-1])
+	}
+
+	return result, nil
+}
+
+// recordResolution reports the (sampled) resolution metrics and logs the resolution if it was slow
+func (s *OperationProcessor) recordResolution(uniqueSuffix string, numOps int, storeTime, total time.Duration) {
+	if s.sampled() {
+		s.metrics.ResolveTime(total)
+		s.metrics.ResolveOperationCount(numOps)
+	}
+
+	if s.slowThreshold > 0 && total >= s.slowThreshold {
+		s.logger.WithFields(log.Fields{log.FieldSuffix: uniqueSuffix, log.FieldOperationCount: numOps}).Warnf(
+			"Slow resolution of %d operation(s) took %s (store: %s, apply: %s)", numOps, total, storeTime, total-storeTime)
+	}
+}
+
+func (s *OperationProcessor) sampled() bool {
+	if s.sampleRate <= 1 {
+		return true
+	}
+
+	return (atomic.AddUint64(&s.resolutions, 1)-1)%s.sampleRate == 0
+}
+
+// cacheKey returns the cache key for the document with the given (sorted) operations
+func cacheKey(uniqueSuffix string, ops []*batch.Operation) CacheKey {
+	key := CacheKey{UniqueSuffix: uniqueSuffix, OperationCount: len(ops)}
+
+	if len(ops) > 0 {
+		key.UpdateCommitment = ops[len(ops)-1].UpdateCommitment
+	}
+
+	return key
 }
 
 func splitOperations(ops []*batch.Operation) (fullOps, updateOps []*batch.Operation) {
@@ -125,7 +291,7 @@ func (s *OperationProcessor) applyOperations(ops []*batch.Operation, rm *resolut
 			return nil, err
 		}
 
-		log.Debugf("[%s] After applying op %+v, New doc: %s", s.name, op, rm.Doc)
+		s.logger.Debugf("After applying op %+v, New doc: %s", op, rm.Doc)
 	}
 
 	return rm, nil
@@ -138,9 +304,15 @@ type resolutionModel struct {
 	UpdateCommitment               string
 	RecoveryCommitment             string
 	RecoveryKey                    *jws.JWK
+	DIDType                        uint
+	Integrity                      *document.Integrity
 }
 
 func (s *OperationProcessor) applyOperation(operation *batch.Operation, rm *resolutionModel) (*resolutionModel, error) {
+	if err := s.validateProtocol(operation); err != nil {
+		return nil, err
+	}
+
 	switch operation.Type {
 	case batch.OperationTypeCreate:
 		return s.applyCreateOperation(operation, rm)
@@ -155,14 +327,48 @@ func (s *OperationProcessor) applyOperation(operation *batch.Operation, rm *reso
 	}
 }
 
+// validateProtocol validates the operation against the protocol version in effect at the transaction time
+func (s *OperationProcessor) validateProtocol(operation *batch.Operation) error {
+	if s.pc == nil || operation.Delta == nil {
+		return nil
+	}
+
+	pv, err := s.pc.Get(operation.TransactionTime)
+	if err != nil {
+		return err
+	}
+
+	return patch.ValidateActions(operation.Delta.Patches, pv.Protocol().Patches)
+}
+
+// characterPolicy returns the character policy of the protocol version in effect at the transaction time
+// (the default policy if the protocol client isn't set)
+func (s *OperationProcessor) characterPolicy(operation *batch.Operation) (document.CharacterPolicy, error) {
+	if s.pc == nil {
+		return document.DefaultCharacterPolicy(), nil
+	}
+
+	pv, err := s.pc.Get(operation.TransactionTime)
+	if err != nil {
+		return document.CharacterPolicy{}, err
+	}
+
+	return pv.Protocol().CharacterPolicy, nil
+}
+
 func (s *OperationProcessor) applyCreateOperation(operation *batch.Operation, rm *resolutionModel) (*resolutionModel, error) {
-	log.Debugf("[%s] Applying create operation: %+v", s.name, operation)
+	s.logger.Debugf("Applying create operation: %+v", operation)
 
 	if rm.Doc != nil {
 		return nil, errors.New("create has to be the first operation")
 	}
 
-	doc, err := composer.ApplyPatches(make(document.Document), operation.Delta.Patches)
+	policy, err := s.characterPolicy(operation)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := composer.ApplyPatches(make(document.Document), operation.Delta.Patches, document.WithCharacterPolicy(policy))
 	if err != nil {
 		return nil, err
 	}
@@ -174,11 +380,13 @@ func (s *OperationProcessor) applyCreateOperation(operation *batch.Operation, rm
 		UpdateCommitment:               operation.UpdateCommitment,
 		RecoveryCommitment:             operation.RecoveryCommitment,
 		RecoveryKey:                    operation.SuffixData.RecoveryKey,
+		DIDType:                        operation.SuffixData.Type,
+		Integrity:                      createIntegrity(operation),
 	}, nil
 }
 
 func (s *OperationProcessor) applyUpdateOperation(operation *batch.Operation, rm *resolutionModel) (*resolutionModel, error) { //nolint:dupl
-	log.Debugf("[%s] Applying update operation: %+v", s.name, operation)
+	s.logger.Debugf("Applying update operation: %+v", operation)
 
 	if rm.Doc == nil {
 		return nil, errors.New("update cannot be first operation")
@@ -193,7 +401,7 @@ func (s *OperationProcessor) applyUpdateOperation(operation *batch.Operation, rm
 		return nil, fmt.Errorf("update reveal value doesn't match update commitment: %s", err.Error())
 	}
 
-	signingPublicKey, err := getSigningPublicKeyFromDoc(rm.Doc, operation.SignedData.Protected.Kid)
+	signingPublicKey, err := document.GetOperationsKey(rm.Doc, operation.SignedData.Protected.Kid)
 	if err != nil {
 		return nil, err
 	}
@@ -214,13 +422,22 @@ func (s *OperationProcessor) applyUpdateOperation(operation *batch.Operation, rm
 		return nil, err
 	}
 
+	if err := checkAnchorWindow(signedDataModel.AnchorWindow, operation); err != nil {
+		return nil, err
+	}
+
 	// verify the delta against the signed delta hash
 	err = isValidHash(operation.EncodedDelta, signedDataModel.DeltaHash)
 	if err != nil {
 		return nil, fmt.Errorf("update delta doesn't match delta hash: %s", err.Error())
 	}
 
-	doc, err := composer.ApplyPatches(rm.Doc, operation.Delta.Patches)
+	policy, err := s.characterPolicy(operation)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := composer.ApplyPatches(rm.Doc, operation.Delta.Patches, document.WithCharacterPolicy(policy))
 	if err != nil {
 		return nil, err
 	}
@@ -231,7 +448,9 @@ func (s *OperationProcessor) applyUpdateOperation(operation *batch.Operation, rm
 		LastOperationTransactionNumber: operation.TransactionNumber,
 		UpdateCommitment:               operation.UpdateCommitment,
 		RecoveryCommitment:             rm.RecoveryCommitment,
-		RecoveryKey:                    rm.RecoveryKey}, nil
+		RecoveryKey:                    rm.RecoveryKey,
+		DIDType:                        rm.DIDType,
+		Integrity:                      appendIntegrity(rm.Integrity, operation)}, nil
 }
 
 func checkSignedData(signedData *model.JWS) error {
@@ -246,39 +465,8 @@ func checkSignedData(signedData *model.JWS) error {
 	return nil
 }
 
-func getSigningPublicKeyFromDoc(doc document.Document, kid string) (*jws.JWK, error) {
-	pk, err := findPublicKey(doc, kid)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := document.ValidateOperationsKey(pk); err != nil {
-		return nil, err
-	}
-
-	jwk := pk.JWK()
-
-	return &jws.JWK{
-		Kty: jwk.Kty(),
-		Crv: jwk.Crv(),
-		X:   jwk.X(),
-		Y:   jwk.Y(),
-	}, nil
-}
-
-func findPublicKey(doc document.Document, kid string) (document.PublicKey, error) {
-	didDoc := document.DidDocumentFromJSONLDObject(doc.JSONLdObject())
-	for _, pk := range didDoc.PublicKeys() {
-		if pk.ID() == kid {
-			return pk, nil
-		}
-	}
-
-	return nil, errors.New("signing public key not found in the document")
-}
-
 func (s *OperationProcessor) applyDeactivateOperation(operation *batch.Operation, rm *resolutionModel) (*resolutionModel, error) {
-	log.Debugf("[%s] Applying deactivate operation: %+v", s.name, operation)
+	s.logger.Debugf("Applying deactivate operation: %+v", operation)
 
 	if rm.Doc == nil {
 		return nil, errors.New("deactivate can only be applied to an existing document")
@@ -293,12 +481,12 @@ func (s *OperationProcessor) applyDeactivateOperation(operation *batch.Operation
 		return nil, fmt.Errorf("deactivate recovery reveal value doesn't match recovery commitment: %s", err.Error())
 	}
 
-	jwsParts, err := internal.ParseJWS(operation.SignedData.Signature, rm.RecoveryKey)
+	payload, err := verifier.VerifyJWS(operation.SignedData.Signature, rm.RecoveryKey)
 	if err != nil {
 		return nil, err
 	}
 
-	decoded, err := docutil.DecodeString(string(jwsParts.Payload))
+	decoded, err := docutil.DecodeString(string(payload))
 	if err != nil {
 		return nil, err
 	}
@@ -309,6 +497,10 @@ func (s *OperationProcessor) applyDeactivateOperation(operation *batch.Operation
 		return nil, err
 	}
 
+	if err := checkAnchorWindow(signedDataModel.AnchorWindow, operation); err != nil {
+		return nil, err
+	}
+
 	// verify signed did suffix against actual did suffix
 	if operation.UniqueSuffix != signedDataModel.DidSuffix {
 		return nil, errors.New("did suffix doesn't match signed value")
@@ -327,7 +519,7 @@ func (s *OperationProcessor) applyDeactivateOperation(operation *batch.Operation
 }
 
 func (s *OperationProcessor) applyRecoverOperation(operation *batch.Operation, rm *resolutionModel) (*resolutionModel, error) { //nolint:dupl
-	log.Debugf("[%s] Applying recover operation: %+v", s.name, operation)
+	s.logger.Debugf("Applying recover operation: %+v", operation)
 
 	if rm.Doc == nil {
 		return nil, errors.New("recover can only be applied to an existing document")
@@ -342,12 +534,12 @@ func (s *OperationProcessor) applyRecoverOperation(operation *batch.Operation, r
 		return nil, fmt.Errorf("recovery reveal value doesn't match recovery commitment: %s", err.Error())
 	}
 
-	jwsParts, err := internal.ParseJWS(operation.SignedData.Signature, rm.RecoveryKey)
+	payload, err := verifier.VerifyJWS(operation.SignedData.Signature, rm.RecoveryKey)
 	if err != nil {
 		return nil, err
 	}
 
-	decoded, err := docutil.DecodeString(string(jwsParts.Payload))
+	decoded, err := docutil.DecodeString(string(payload))
 	if err != nil {
 		return nil, err
 	}
@@ -358,13 +550,28 @@ func (s *OperationProcessor) applyRecoverOperation(operation *batch.Operation, r
 		return nil, err
 	}
 
+	if err := checkAnchorWindow(signedDataModel.AnchorWindow, operation); err != nil {
+		return nil, err
+	}
+
+	// the new recovery key may be of a different key type than the current recovery key (against which the
+	// signature was verified) so it is validated on its own
+	if err := validateRecoveryKey(signedDataModel.RecoveryKey); err != nil {
+		return nil, fmt.Errorf("invalid recovery key: %s", err.Error())
+	}
+
 	// verify the delta against the signed delta hash
 	err = isValidHash(operation.EncodedDelta, signedDataModel.DeltaHash)
 	if err != nil {
 		return nil, fmt.Errorf("recover delta doesn't match delta hash: %s", err.Error())
 	}
 
-	doc, err := composer.ApplyPatches(make(document.Document), operation.Delta.Patches)
+	policy, err := s.characterPolicy(operation)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := composer.ApplyPatches(make(document.Document), operation.Delta.Patches, document.WithCharacterPolicy(policy))
 	if err != nil {
 		return nil, err
 	}
@@ -375,7 +582,74 @@ func (s *OperationProcessor) applyRecoverOperation(operation *batch.Operation, r
 		LastOperationTransactionNumber: operation.TransactionNumber,
 		UpdateCommitment:               operation.UpdateCommitment,
 		RecoveryCommitment:             operation.RecoveryCommitment,
-		RecoveryKey:                    signedDataModel.RecoveryKey}, nil
+		RecoveryKey:                    signedDataModel.RecoveryKey,
+		DIDType:                        rm.DIDType,
+		Integrity:                      appendIntegrity(rm.Integrity, operation)}, nil
+}
+
+// createIntegrity returns the integrity data of the create operation or nil if the original request
+// (which contains the encoded suffix data) isn't available
+func createIntegrity(operation *batch.Operation) *document.Integrity {
+	if len(operation.OperationBuffer) == 0 {
+		return nil
+	}
+
+	var request model.CreateRequest
+	if err := json.Unmarshal(operation.OperationBuffer, &request); err != nil || request.SuffixData == "" {
+		return nil
+	}
+
+	return &document.Integrity{
+		SuffixData: request.SuffixData,
+		Operations: []document.IntegrityOperation{{
+			Type:            string(operation.Type),
+			Delta:           operation.EncodedDelta,
+			TransactionTime: operation.TransactionTime,
+		}},
+	}
+}
+
+// appendIntegrity returns the integrity data with the given (update or recover) operation appended
+func appendIntegrity(integrity *document.Integrity, operation *batch.Operation) *document.Integrity {
+	if integrity == nil {
+		return nil
+	}
+
+	ops := make([]document.IntegrityOperation, len(integrity.Operations), len(integrity.Operations)+1)
+	copy(ops, integrity.Operations)
+
+	return &document.Integrity{
+		SuffixData: integrity.SuffixData,
+		Operations: append(ops, document.IntegrityOperation{
+			Type:            string(operation.Type),
+			SignedData:      operation.SignedData.Signature,
+			Delta:           operation.EncodedDelta,
+			TransactionTime: operation.TransactionTime,
+		}),
+	}
+}
+
+// checkAnchorWindow checks that the operation was anchored within the anchor window declared in its signed data
+func checkAnchorWindow(w model.AnchorWindow, operation *batch.Operation) error {
+	if w.AnchorFrom > 0 && operation.TransactionTime < w.AnchorFrom {
+		return fmt.Errorf("%s operation was anchored at transaction time [%d] which is before anchor from [%d]",
+			operation.Type, operation.TransactionTime, w.AnchorFrom)
+	}
+
+	if w.AnchorUntil > 0 && operation.TransactionTime > w.AnchorUntil {
+		return fmt.Errorf("%s operation was anchored at transaction time [%d] which is after anchor until [%d]",
+			operation.Type, operation.TransactionTime, w.AnchorUntil)
+	}
+
+	return nil
+}
+
+func validateRecoveryKey(key *jws.JWK) error {
+	if key == nil {
+		return errors.New("missing recovery key")
+	}
+
+	return internal.ValidatePublicKey(key)
 }
 
 func isValidHash(encodedContent, encodedMultihash string) error {
@@ -8,6 +8,7 @@ package processor
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/json"
@@ -15,7 +16,11 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
@@ -23,11 +28,14 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/canonicalizer"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/signutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
 )
 
@@ -54,6 +62,151 @@ func TestResolve(t *testing.T) {
 		require.NotNil(t, doc)
 	})
 
+	t.Run("success - integrity", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+
+		result, err := New("test", store).Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.NotNil(t, result.MethodMetadata.Integrity)
+		requireIntegrity(t, result.MethodMetadata.Integrity, batch.OperationTypeCreate)
+	})
+
+	t.Run("success - no integrity without operation request", func(t *testing.T) {
+		createOp, err := getCreateOperation(privateKey)
+		require.NoError(t, err)
+
+		createOp.OperationBuffer = nil
+
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(createOp))
+
+		result, err := New("test", store).Resolve(createOp.UniqueSuffix)
+		require.NoError(t, err)
+		require.Nil(t, result.MethodMetadata.Integrity)
+	})
+
+	t.Run("success - with metrics", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+		m := mocks.NewMockMetrics()
+		op := New("test", store, WithMetrics(m))
+
+		doc, err := op.Resolve(uniqueSuffix)
+		require.Nil(t, err)
+		require.NotNil(t, doc)
+		require.Len(t, m.ResolveTimes(), 1)
+		require.Equal(t, []int{1}, m.ResolveOperationCounts())
+	})
+
+	t.Run("success - with metrics sampling", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+		m := mocks.NewMockMetrics()
+		op := New("test", store, WithMetrics(m), WithMetricsSampleRate(2))
+
+		for i := 0; i < 3; i++ {
+			doc, err := op.Resolve(uniqueSuffix)
+			require.Nil(t, err)
+			require.NotNil(t, doc)
+		}
+
+		require.Len(t, m.ResolveTimes(), 2)
+		require.Equal(t, []int{1, 1}, m.ResolveOperationCounts())
+	})
+
+	t.Run("success - DID type", func(t *testing.T) {
+		store := mocks.NewMockOperationStore(nil)
+
+		createOp, err := getCreateOperation(privateKey)
+		require.NoError(t, err)
+		createOp.SuffixData.Type = 5
+		require.NoError(t, store.Put(createOp))
+
+		updateOp, err := getUpdateOperation(privateKey, createOp.UniqueSuffix, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(updateOp))
+
+		result, err := New("test", store).Resolve(createOp.UniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, uint(5), result.MethodMetadata.Type)
+	})
+
+	t.Run("success - with cache", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+		m := mocks.NewMockMetrics()
+		op := New("test", store, WithMetrics(m), WithCache(NewLRUCache(10)))
+
+		result, err := op.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, 0, m.ResolveCacheHits())
+		require.Equal(t, 1, m.ResolveCacheMisses())
+
+		// modifying the returned document must not affect the cached document
+		result.Document["id"] = "modified"
+
+		cached, err := op.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.NotNil(t, cached)
+		require.Empty(t, cached.Document.ID())
+		require.Equal(t, 1, m.ResolveCacheHits())
+		require.Equal(t, 1, m.ResolveCacheMisses())
+
+		// storing a new operation invalidates the cached result
+		updateOp, err := getUpdateOperation(privateKey, uniqueSuffix, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(updateOp))
+
+		result, err = op.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "special1", result.Document["test"])
+		require.Equal(t, 1, m.ResolveCacheHits())
+		require.Equal(t, 2, m.ResolveCacheMisses())
+	})
+
+	t.Run("success - slow resolution", func(t *testing.T) {
+		l, hook := logtest.NewNullLogger()
+
+		store, uniqueSuffix := getDefaultStore(privateKey)
+		op := New("test", store, WithLogger(log.New(l)), WithSlowResolutionThreshold(time.Nanosecond))
+
+		doc, err := op.Resolve(uniqueSuffix)
+		require.Nil(t, err)
+		require.NotNil(t, doc)
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		require.Equal(t, logrus.WarnLevel, entry.Level)
+		require.Contains(t, entry.Message, "Slow resolution of 1 operation(s)")
+		require.Equal(t, uniqueSuffix, entry.Data[log.FieldSuffix])
+		require.Equal(t, 1, entry.Data[log.FieldOperationCount])
+	})
+
+	t.Run("success - resolution not slow", func(t *testing.T) {
+		l, hook := logtest.NewNullLogger()
+
+		store, uniqueSuffix := getDefaultStore(privateKey)
+		op := New("test", store, WithLogger(log.New(l)), WithSlowResolutionThreshold(time.Minute))
+
+		doc, err := op.Resolve(uniqueSuffix)
+		require.Nil(t, err)
+		require.NotNil(t, doc)
+		require.Empty(t, hook.AllEntries())
+	})
+
+	t.Run("success - with logger", func(t *testing.T) {
+		l, hook := logtest.NewNullLogger()
+		l.SetLevel(logrus.DebugLevel)
+
+		store, uniqueSuffix := getDefaultStore(privateKey)
+		op := New("test", store, WithLogger(log.New(l)))
+
+		doc, err := op.Resolve(uniqueSuffix)
+		require.Nil(t, err)
+		require.NotNil(t, doc)
+
+		require.NotEmpty(t, hook.AllEntries())
+		require.Equal(t, "test", hook.LastEntry().Data[log.FieldProcessor])
+	})
+
 	t.Run("document not found error", func(t *testing.T) {
 		store, _ := getDefaultStore(privateKey)
 
@@ -131,6 +284,71 @@ func TestUpdateDocument(t *testing.T) {
 		// check if service type value is updated again (done via json patch)
 		didDoc = document.DidDocumentFromJSONLDObject(result.Document)
 		require.Equal(t, "special2", didDoc["test"])
+
+		// integrity data contains all of the applied operations
+		integrity := result.MethodMetadata.Integrity
+		requireIntegrity(t, integrity, batch.OperationTypeCreate, batch.OperationTypeUpdate, batch.OperationTypeUpdate)
+		require.Equal(t, updateOp.EncodedDelta, integrity.Operations[2].Delta)
+		require.Equal(t, updateOp.SignedData.Signature, integrity.Operations[2].SignedData)
+	})
+
+	t.Run("patch action not allowed by protocol", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+
+		pc := mocks.NewMockProtocolClient()
+		pc.Protocol.Patches = []string{string(patch.AddPublicKeys)}
+
+		p := New("test", store, WithProtocolClient(pc))
+		result, err := p.Resolve(uniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "is not allowed")
+	})
+
+	t.Run("character policy of protocol", func(t *testing.T) {
+		publicKey, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		publicKeyBytes, err := json.Marshal(publicKey)
+		require.NoError(t, err)
+
+		createOp, err := getCreateOperationWithDoc(privateKey, fmt.Sprintf(docTemplate, updateKey, string(publicKeyBytes)))
+		require.NoError(t, err)
+
+		// non-ASCII service type
+		for _, p := range createOp.Delta.Patches {
+			if p.GetAction() == patch.AddServiceEndpoints {
+				p[patch.ServiceEndpointsKey].([]interface{})[0].(map[string]interface{})["type"] = "IdentitätHub"
+			}
+		}
+
+		store := mocks.NewMockOperationStore(nil)
+		require.NoError(t, store.Put(createOp))
+
+		// only printable ASCII characters are permitted by default
+		result, err := New("test", store).Resolve(createOp.UniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "non-ASCII character U+00E4 is not allowed")
+
+		pc := mocks.NewMockProtocolClient()
+		pc.Protocol.CharacterPolicy = document.CharacterPolicy{AllowNonASCII: true}
+
+		result, err = New("test", store, WithProtocolClient(pc)).Resolve(createOp.UniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "IdentitätHub", document.DidDocumentFromJSONLDObject(result.Document).Services()[0].Type())
+
+		// the policy of the protocol version in effect at the transaction time applies
+		allowed := pc.Protocol
+		allowed.StartingBlockChainTime = 100
+
+		pc, err = mocks.NewMockProtocolClientWithVersions(mocks.NewMockProtocolClient().Protocol, allowed)
+		require.NoError(t, err)
+
+		result, err = New("test", store, WithProtocolClient(pc)).Resolve(createOp.UniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "non-ASCII character U+00E4 is not allowed")
 	})
 
 	t.Run("missing signed data error", func(t *testing.T) {
@@ -217,7 +435,7 @@ func TestUpdateDocument(t *testing.T) {
 		doc, err := p.Resolve(uniqueSuffix)
 		require.NotNil(t, err)
 		require.Nil(t, doc)
-		require.Contains(t, err.Error(), "signing public key not found in the document")
+		require.Contains(t, err.Error(), "signing public key [some-key] not found in the document")
 	})
 
 	t.Run("delta hash doesn't match delta error", func(t *testing.T) {
@@ -439,6 +657,30 @@ func TestDeactivate(t *testing.T) {
 		require.Nil(t, doc)
 	})
 
+	t.Run("signed data is missing did suffix", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+
+		deactivateOp, err := getDeactivateOperation(privateKey, uniqueSuffix, 1)
+		require.NoError(t, err)
+
+		jws, err := signutil.SignModel(&model.DeactivateSignedDataModel{
+			RecoveryRevealValue: docutil.EncodeToString([]byte(recoveryReveal)),
+		}, ecsigner.New(privateKey, "ES256", ""))
+		require.NoError(t, err)
+
+		deactivateOp.SignedData = jws
+
+		err = store.Put(deactivateOp)
+		require.NoError(t, err)
+
+		p := New("test", store)
+		doc, err := p.Resolve(uniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, doc)
+		// the signed data must be bound to the DID
+		require.Contains(t, err.Error(), "did suffix doesn't match signed value")
+	})
+
 	t.Run("did suffix doesn't match signed value error", func(t *testing.T) {
 		store, uniqueSuffix := getDefaultStore(privateKey)
 
@@ -513,6 +755,12 @@ func TestRecover(t *testing.T) {
 		require.NoError(t, err)
 		require.Contains(t, string(docBytes), "recovered")
 
+		// integrity data contains the recover operation
+		integrity := result.MethodMetadata.Integrity
+		requireIntegrity(t, integrity, batch.OperationTypeCreate, batch.OperationTypeRecover)
+		require.Equal(t, recoverOp.EncodedDelta, integrity.Operations[1].Delta)
+		require.Equal(t, recoverOp.SignedData.Signature, integrity.Operations[1].SignedData)
+
 		// apply recover again - consecutive recoveries are valid
 		recoverOp, err = getRecoverOperation(recoveryKey, uniqueSuffix, 2)
 		require.NoError(t, err)
@@ -598,6 +846,98 @@ func TestRecover(t *testing.T) {
 	})
 }
 
+func TestRecover_RecoveryKeyRotation(t *testing.T) {
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p256JWK, err := pubkey.GetPublicKeyJWK(&p256Key.PublicKey)
+	require.NoError(t, err)
+
+	ed25519PubKey, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ed25519JWK, err := pubkey.GetPublicKeyJWK(ed25519PubKey)
+	require.NoError(t, err)
+
+	secp256k1Key, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+	require.NoError(t, err)
+
+	secp256k1JWK, err := pubkey.GetPublicKeyJWK(&secp256k1Key.PublicKey)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(p256Key)
+
+		// rotate from P-256 to Ed25519
+		recoverOp, err := getRecoverOperationWithSigner(ecsigner.New(p256Key, "ES256", ""), ed25519JWK, uniqueSuffix, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(recoverOp))
+
+		// rotate from Ed25519 to secp256k1
+		recoverOp, err = getRecoverOperationWithSigner(edsigner.New(ed25519Key, "EdDSA", ""), secp256k1JWK, uniqueSuffix, 2)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(recoverOp))
+
+		// rotate from secp256k1 back to P-256
+		recoverOp, err = getRecoverOperationWithSigner(ecsigner.New(secp256k1Key, "ES256K", ""), p256JWK, uniqueSuffix, 3)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(recoverOp))
+
+		p := New("test", store)
+		result, err := p.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, p256JWK, result.MethodMetadata.RecoveryKey)
+	})
+
+	t.Run("signed with previous recovery key", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(p256Key)
+
+		recoverOp, err := getRecoverOperationWithSigner(ecsigner.New(p256Key, "ES256", ""), ed25519JWK, uniqueSuffix, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(recoverOp))
+
+		recoverOp, err = getRecoverOperationWithSigner(ecsigner.New(p256Key, "ES256", ""), p256JWK, uniqueSuffix, 2)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(recoverOp))
+
+		p := New("test", store)
+		result, err := p.Resolve(uniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "ed25519: invalid signature")
+	})
+
+	t.Run("algorithm doesn't match recovery key", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(p256Key)
+
+		recoverOp, err := getRecoverOperationWithSigner(ecsigner.New(p256Key, "ES256K", ""), ed25519JWK, uniqueSuffix, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(recoverOp))
+
+		p := New("test", store)
+		result, err := p.Resolve(uniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "key type 'EC' with curve 'P-256' cannot be used with algorithm 'ES256K'")
+	})
+
+	t.Run("invalid new recovery key", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(p256Key)
+
+		invalidKey := &jws.JWK{Kty: "OKP", Crv: "Ed25519", X: "invalid"}
+
+		recoverOp, err := getRecoverOperationWithSigner(ecsigner.New(p256Key, "ES256", ""), invalidKey, uniqueSuffix, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(recoverOp))
+
+		p := New("test", store)
+		result, err := p.Resolve(uniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "invalid recovery key: ed25519: invalid key")
+	})
+}
+
 func TestOpsWithTxnGreaterThan(t *testing.T) {
 	op1 := &batch.Operation{
 		TransactionTime:   1,
@@ -621,6 +961,108 @@ func TestOpsWithTxnGreaterThan(t *testing.T) {
 	require.Equal(t, 1, len(txns))
 }
 
+func TestAnchorWindow(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	window := model.AnchorWindow{AnchorFrom: 10, AnchorUntil: 20}
+
+	t.Run("deactivate - anchored within window", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+
+		deactivateOp, err := getDeactivateOperationWithAnchorWindow(privateKey, uniqueSuffix, 0, window)
+		require.NoError(t, err)
+
+		deactivateOp.TransactionTime = 20
+		require.NoError(t, store.Put(deactivateOp))
+
+		result, err := New("test", store).Resolve(uniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "document was deactivated")
+	})
+
+	t.Run("deactivate - anchored before window", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+
+		deactivateOp, err := getDeactivateOperationWithAnchorWindow(privateKey, uniqueSuffix, 0, window)
+		require.NoError(t, err)
+
+		deactivateOp.TransactionTime = 9
+		require.NoError(t, store.Put(deactivateOp))
+
+		result, err := New("test", store).Resolve(uniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "deactivate operation was anchored at transaction time [9] which is before anchor from [10]")
+	})
+
+	t.Run("recover - anchored after window", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+
+		recoveryKey, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		delta, err := getReplaceDelta(recoveredDoc)
+		require.NoError(t, err)
+
+		recoverRequest, err := getRecoverRequest(ecsigner.New(privateKey, "ES256", ""), delta, &model.RecoverSignedDataModel{
+			AnchorWindow:       window,
+			RecoveryKey:        recoveryKey,
+			RecoveryCommitment: getEncodedMultihash([]byte("recoveryReveal")),
+		})
+		require.NoError(t, err)
+
+		recoverOp, err := getRecoverOperation(privateKey, uniqueSuffix, 0)
+		require.NoError(t, err)
+
+		recoverOp.SignedData = recoverRequest.SignedData
+		recoverOp.EncodedDelta = recoverRequest.Delta
+		recoverOp.TransactionTime = 21
+		require.NoError(t, store.Put(recoverOp))
+
+		result, err := New("test", store).Resolve(uniqueSuffix)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "recover operation was anchored at transaction time [21] which is after anchor until [20]")
+	})
+
+	t.Run("check anchor window", func(t *testing.T) {
+		op := &batch.Operation{Type: batch.OperationTypeUpdate}
+
+		for _, txnTime := range []uint64{10, 15, 20} {
+			op.TransactionTime = txnTime
+			require.NoError(t, checkAnchorWindow(window, op))
+		}
+
+		op.TransactionTime = 21
+		require.EqualError(t, checkAnchorWindow(window, op),
+			"update operation was anchored at transaction time [21] which is after anchor until [20]")
+
+		require.NoError(t, checkAnchorWindow(model.AnchorWindow{AnchorFrom: 10}, op))
+		require.NoError(t, checkAnchorWindow(model.AnchorWindow{AnchorUntil: 30}, op))
+		require.NoError(t, checkAnchorWindow(model.AnchorWindow{}, op))
+	})
+}
+
+// requireIntegrity checks that the integrity data contains operations of the given types (in order)
+func requireIntegrity(t *testing.T, integrity *document.Integrity, types ...batch.OperationType) {
+	require.NotNil(t, integrity)
+	require.NotEmpty(t, integrity.SuffixData)
+	require.Len(t, integrity.Operations, len(types))
+
+	for i, op := range integrity.Operations {
+		require.Equal(t, string(types[i]), op.Type)
+		require.NotEmpty(t, op.Delta)
+
+		if types[i] == batch.OperationTypeCreate {
+			require.Empty(t, op.SignedData)
+		} else {
+			require.NotEmpty(t, op.SignedData)
+		}
+	}
+}
+
 func getUpdateOperationWithSigner(s helper.Signer, uniqueSuffix string, operationNumber uint) (*batch.Operation, error) {
 	p := map[string]interface{}{
 		"op":    "replace",
@@ -684,7 +1126,13 @@ func getUpdateOperation(privateKey *ecdsa.PrivateKey, uniqueSuffix string, opera
 }
 
 func getDeactivateOperation(privateKey *ecdsa.PrivateKey, uniqueSuffix string, operationNumber uint) (*batch.Operation, error) {
+	return getDeactivateOperationWithAnchorWindow(privateKey, uniqueSuffix, operationNumber, model.AnchorWindow{})
+}
+
+func getDeactivateOperationWithAnchorWindow(privateKey *ecdsa.PrivateKey, uniqueSuffix string, operationNumber uint,
+	window model.AnchorWindow) (*batch.Operation, error) {
 	signedDataModel := model.DeactivateSignedDataModel{
+		AnchorWindow:        window,
 		DidSuffix:           uniqueSuffix,
 		RecoveryRevealValue: docutil.EncodeToString([]byte(recoveryReveal)),
 	}
@@ -708,7 +1156,18 @@ func getDeactivateOperation(privateKey *ecdsa.PrivateKey, uniqueSuffix string, o
 }
 
 func getRecoverOperation(privateKey *ecdsa.PrivateKey, uniqueSuffix string, operationNumber uint) (*batch.Operation, error) {
-	recoverRequest, err := getDefaultRecoverRequest(privateKey)
+	jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return getRecoverOperationWithSigner(ecsigner.New(privateKey, "ES256", ""), jwk, uniqueSuffix, operationNumber)
+}
+
+// getRecoverOperationWithSigner returns a recover operation that is signed with the given (current) recovery key
+// signer and that sets the given new recovery key
+func getRecoverOperationWithSigner(signer signutil.Signer, recoveryKey *jws.JWK, uniqueSuffix string, operationNumber uint) (*batch.Operation, error) {
+	recoverRequest, err := getDefaultRecoverRequest(signer, recoveryKey)
 	if err != nil {
 		return nil, err
 	}
@@ -742,7 +1201,7 @@ func getRecoverOperation(privateKey *ecdsa.PrivateKey, uniqueSuffix string, oper
 	}, nil
 }
 
-func getRecoverRequest(privateKey *ecdsa.PrivateKey, deltaModel *model.DeltaModel, signedDataModel *model.RecoverSignedDataModel) (*model.RecoverRequest, error) {
+func getRecoverRequest(signer signutil.Signer, deltaModel *model.DeltaModel, signedDataModel *model.RecoverSignedDataModel) (*model.RecoverRequest, error) {
 	deltaBytes, err := canonicalizer.MarshalCanonical(deltaModel)
 	if err != nil {
 		return nil, err
@@ -750,7 +1209,7 @@ func getRecoverRequest(privateKey *ecdsa.PrivateKey, deltaModel *model.DeltaMode
 
 	signedDataModel.DeltaHash = getEncodedMultihash(deltaBytes)
 
-	jws, err := signutil.SignModel(signedDataModel, ecsigner.New(privateKey, "ES256", ""))
+	jws, err := signutil.SignModel(signedDataModel, signer)
 	if err != nil {
 		return nil, err
 	}
@@ -763,7 +1222,7 @@ func getRecoverRequest(privateKey *ecdsa.PrivateKey, deltaModel *model.DeltaMode
 	}, nil
 }
 
-func getDefaultRecoverRequest(privateKey *ecdsa.PrivateKey) (*model.RecoverRequest, error) {
+func getDefaultRecoverRequest(signer signutil.Signer, recoveryKey *jws.JWK) (*model.RecoverRequest, error) {
 	delta, err := getReplaceDelta(recoveredDoc)
 	if err != nil {
 		return nil, err
@@ -774,18 +1233,13 @@ func getDefaultRecoverRequest(privateKey *ecdsa.PrivateKey) (*model.RecoverReque
 		return nil, err
 	}
 
-	jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
-	if err != nil {
-		return nil, err
-	}
-
 	recoverSignedData := &model.RecoverSignedDataModel{
-		RecoveryKey:        jwk,
+		RecoveryKey:        recoveryKey,
 		RecoveryCommitment: getEncodedMultihash([]byte("recoveryReveal")),
 		DeltaHash:          getEncodedMultihash(deltaBytes),
 	}
 
-	return getRecoverRequest(privateKey, delta, recoverSignedData)
+	return getRecoverRequest(signer, delta, recoverSignedData)
 }
 
 func getDefaultStore(recoveryKey *ecdsa.PrivateKey) (*mocks.MockOperationStore, string) {
@@ -830,11 +1284,16 @@ func getCreateOperationWithDoc(privateKey *ecdsa.PrivateKey, doc string) (*batch
 		return nil, err
 	}
 
-	suffixData, err := getSuffixData(privateKey)
+	suffixDataBytes, err := docutil.DecodeString(createRequest.SuffixData)
 	if err != nil {
 		return nil, err
 	}
 
+	suffixData := &model.SuffixDataModel{}
+	if err := json.Unmarshal(suffixDataBytes, suffixData); err != nil {
+		return nil, err
+	}
+
 	return &batch.Operation{
 		HashAlgorithmInMultiHashCode: sha2_256,
 		ID:                           "did:sidetree:" + uniqueSuffix,
@@ -883,6 +1342,8 @@ func getCreateRequest(privateKey *ecdsa.PrivateKey) (*model.CreateRequest, error
 		return nil, err
 	}
 
+	suffixData.DeltaHash = getEncodedMultihash(deltaBytes)
+
 	suffixDataBytes, err := canonicalizer.MarshalCanonical(suffixData)
 	if err != nil {
 		return nil, err
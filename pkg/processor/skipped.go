@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+// SkippedOperationRecorder records the operations of a document that were not applied (e.g. in a debug store) so
+// that it is possible to find out why an operation didn't take effect. Operations are recorded when they are
+// rejected by the operation filter (e.g. due to an invalid commitment or signature) and when anchored operations
+// are skipped during resolution, so the recorder may be invoked more than once for the same unique suffix.
+type SkippedOperationRecorder interface {
+	RecordSkipped(uniqueSuffix string, skipped []document.SkippedOperation) error
+}
+
+// SkippedOperationRecorderFunc is a function that implements SkippedOperationRecorder
+type SkippedOperationRecorderFunc func(uniqueSuffix string, skipped []document.SkippedOperation) error
+
+// RecordSkipped records the skipped operations of the document with the given unique suffix
+func (f SkippedOperationRecorderFunc) RecordSkipped(uniqueSuffix string, skipped []document.SkippedOperation) error {
+	return f(uniqueSuffix, skipped)
+}
+
+// WithSkippedOperationRecorder sets the recorder of the operations that are skipped.
+// Skipped operations are not recorded by default.
+func WithSkippedOperationRecorder(recorder SkippedOperationRecorder) Option {
+	return func(opts *OperationProcessor) {
+		opts.skippedRecorder = recorder
+	}
+}
+
+// WithSkippedOperationsInMetadata includes the operations that are skipped during resolution in the method
+// metadata of the resolution result. (The REST resolve handler only returns them to authorized requesters.)
+func WithSkippedOperationsInMetadata() Option {
+	return func(opts *OperationProcessor) {
+		opts.skippedInMetadata = true
+	}
+}
+
+// supersededOperations returns the update operations that were skipped since they were anchored before
+// the given (last applied) create/recover operation
+func supersededOperations(updateOps, appliedOps []*batch.Operation, lastFullOp *batch.Operation) []document.SkippedOperation {
+	superseded := updateOps[:len(updateOps)-len(appliedOps)]
+	if len(superseded) == 0 {
+		return nil
+	}
+
+	skipped := make([]document.SkippedOperation, len(superseded))
+
+	for i, op := range superseded {
+		skipped[i] = newSkippedOperation(op, supersededReason(lastFullOp))
+	}
+
+	return skipped
+}
+
+func supersededReason(lastFullOp *batch.Operation) string {
+	return fmt.Sprintf("superseded by %s operation at transaction time %d (transaction number %d)",
+		lastFullOp.Type, lastFullOp.TransactionTime, lastFullOp.TransactionNumber)
+}
+
+func newSkippedOperation(op *batch.Operation, reason string) document.SkippedOperation {
+	return document.SkippedOperation{
+		Type:              string(op.Type),
+		TransactionTime:   op.TransactionTime,
+		TransactionNumber: op.TransactionNumber,
+		UpdateCommitment:  op.UpdateCommitment,
+		Reason:            reason,
+	}
+}
+
+// tracksSkipped returns true if the skipped operations are either recorded or returned in the metadata
+func (s *OperationProcessor) tracksSkipped() bool {
+	return s.skippedRecorder != nil || s.skippedInMetadata
+}
+
+// reportSkipped records the skipped operations of the given (possibly cached) resolution result and returns
+// the result that is returned to the caller. The skipped operations are always kept in the resolution
+// result that is cached so that a cache hit reports the same skipped operations as a cache miss.
+func (s *OperationProcessor) reportSkipped(uniqueSuffix string, result *document.ResolutionResult) *document.ResolutionResult {
+	skipped := result.MethodMetadata.SkippedOperations
+	if len(skipped) == 0 {
+		return result
+	}
+
+	s.recordSkipped(uniqueSuffix, skipped)
+
+	if s.skippedInMetadata {
+		return result
+	}
+
+	r := *result
+	r.MethodMetadata.SkippedOperations = nil
+
+	return &r
+}
+
+// recordSkipped reports the skipped operations to the recorder (if any). Errors are only logged since
+// the skipped operations are recorded for debugging purposes.
+func (s *OperationProcessor) recordSkipped(uniqueSuffix string, skipped []document.SkippedOperation) {
+	if s.skippedRecorder == nil || len(skipped) == 0 {
+		return
+	}
+
+	if err := s.skippedRecorder.RecordSkipped(uniqueSuffix, skipped); err != nil {
+		s.logger.Warnf("Failed to record %d skipped operation(s) for unique suffix [%s]: %s",
+			len(skipped), uniqueSuffix, err)
+	}
+}
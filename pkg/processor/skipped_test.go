@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package processor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+func TestResolve_SkippedOperations(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// the update is anchored before the recover operation and is therefore skipped
+	newStore := func(t *testing.T) (*mocks.MockOperationStore, string) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+
+		updateOp, err := getUpdateOperation(privateKey, uniqueSuffix, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(updateOp))
+
+		recoverOp, err := getRecoverOperation(privateKey, uniqueSuffix, 2)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(recoverOp))
+
+		return store, uniqueSuffix
+	}
+
+	t.Run("not recorded by default", func(t *testing.T) {
+		store, uniqueSuffix := newStore(t)
+
+		result, err := New("test", store).Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Empty(t, result.MethodMetadata.SkippedOperations)
+	})
+
+	t.Run("recorder", func(t *testing.T) {
+		store, uniqueSuffix := newStore(t)
+
+		var recorded []document.SkippedOperation
+
+		p := New("test", store, WithSkippedOperationRecorder(SkippedOperationRecorderFunc(
+			func(suffix string, skipped []document.SkippedOperation) error {
+				require.Equal(t, uniqueSuffix, suffix)
+				recorded = append(recorded, skipped...)
+
+				return nil
+			},
+		)))
+
+		result, err := p.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Empty(t, result.MethodMetadata.SkippedOperations)
+
+		require.Len(t, recorded, 1)
+		require.Equal(t, "update", recorded[0].Type)
+		require.Equal(t, uint64(1), recorded[0].TransactionNumber)
+		require.NotEmpty(t, recorded[0].UpdateCommitment)
+		require.Equal(t, "superseded by recover operation at transaction time 0 (transaction number 2)", recorded[0].Reason)
+	})
+
+	t.Run("recorder error", func(t *testing.T) {
+		store, uniqueSuffix := newStore(t)
+
+		p := New("test", store, WithSkippedOperationRecorder(SkippedOperationRecorderFunc(
+			func(string, []document.SkippedOperation) error {
+				return errors.New("injected recorder error")
+			},
+		)))
+
+		// errors of the recorder don't fail the resolution
+		result, err := p.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+	})
+
+	t.Run("in metadata", func(t *testing.T) {
+		store, uniqueSuffix := newStore(t)
+
+		result, err := New("test", store, WithSkippedOperationsInMetadata()).Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, result.MethodMetadata.SkippedOperations, 1)
+		require.Equal(t, "update", result.MethodMetadata.SkippedOperations[0].Type)
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		store, uniqueSuffix := newStore(t)
+
+		var recorded [][]document.SkippedOperation
+
+		recorder := SkippedOperationRecorderFunc(func(suffix string, skipped []document.SkippedOperation) error {
+			recorded = append(recorded, skipped)
+			return nil
+		})
+
+		p := New("test", store, WithCache(NewLRUCache(10)), WithSkippedOperationRecorder(recorder))
+
+		// the second resolution is a cache hit and reports the same skipped operations
+		for i := 0; i < 2; i++ {
+			result, err := p.Resolve(uniqueSuffix)
+			require.NoError(t, err)
+			require.Empty(t, result.MethodMetadata.SkippedOperations)
+		}
+
+		require.Len(t, recorded, 2)
+		require.Len(t, recorded[0], 1)
+		require.Equal(t, recorded[0], recorded[1])
+
+		p = New("test", store, WithCache(NewLRUCache(10)), WithSkippedOperationsInMetadata())
+
+		result, err := p.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Len(t, result.MethodMetadata.SkippedOperations, 1)
+
+		cached, err := p.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, result, cached)
+	})
+
+	t.Run("nothing skipped", func(t *testing.T) {
+		store, uniqueSuffix := getDefaultStore(privateKey)
+
+		updateOp, err := getUpdateOperation(privateKey, uniqueSuffix, 1)
+		require.NoError(t, err)
+		require.NoError(t, store.Put(updateOp))
+
+		recorded := false
+
+		p := New("test", store, WithSkippedOperationsInMetadata(), WithSkippedOperationRecorder(
+			SkippedOperationRecorderFunc(func(string, []document.SkippedOperation) error {
+				recorded = true
+				return nil
+			}),
+		))
+
+		result, err := p.Resolve(uniqueSuffix)
+		require.NoError(t, err)
+		require.Empty(t, result.MethodMetadata.SkippedOperations)
+		require.False(t, recorded)
+	})
+}
@@ -0,0 +1,344 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package protocolclient provides a protocol client whose protocol versions may be reloaded at runtime.
+//
+// The protocol versions of the client are swapped atomically on reload, so that protocol parameters (e.g. batch
+// sizes) may be tweaked without restarting nodes that hold in-memory operation queues. Callers that obtained a
+// protocol version before the reload keep using the previous parameters; subsequent calls to Current and Get
+// return the reloaded parameters. Versions may be reloaded by the file Watcher or by the protocol admin
+// endpoint of the REST API.
+//
+// Since anchored operations are validated with the protocol version in effect at the transaction time, a reload
+// may not change the history: versions that are already in effect may not be removed and only their
+// non-consensus parameters (see ErrVersionInEffect) may be changed, and new versions must start in the future.
+package protocolclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+)
+
+// ErrVersionInEffect is returned (wrapped) by Reload if the reloaded versions change the history, i.e. if a version
+// that is already in effect is removed or if one of its consensus parameters is changed, or if a new version starts
+// before the current blockchain time. Only the parameters that don't affect the validation of anchored operations
+// (MaxOperationsPerBatch, MaxOperationsPerDID, RateLimitWindow and DeactivateConfirmation) may be changed in
+// versions that are in effect.
+var ErrVersionInEffect = errors.New("protocol version is already in effect")
+
+// BlockchainTime returns the current logical blockchain time
+type BlockchainTime func() (uint64, error)
+
+// Client is a protocol client whose protocol versions may be replaced at runtime
+type Client struct {
+	versions       atomic.Value // []*version sorted by starting blockchain time
+	parserOptions  []operation.ParserOption
+	blockchainTime BlockchainTime
+	logger         log.Logger
+	mutex          sync.Mutex // serializes reloads
+}
+
+// Option is a protocol client option
+type Option func(c *Client)
+
+// WithParserOptions sets the options of the operation parsers that are created for the protocol versions
+func WithParserOptions(opts ...operation.ParserOption) Option {
+	return func(c *Client) {
+		c.parserOptions = append(c.parserOptions, opts...)
+	}
+}
+
+// WithBlockchainTime sets the function that returns the current blockchain time. Versions whose starting
+// blockchain time is after the current time may be changed or removed on reload. If not set, all of the
+// existing versions are considered to be in effect and new versions must start after the latest version.
+func WithBlockchainTime(blockchainTime BlockchainTime) Option {
+	return func(c *Client) {
+		c.blockchainTime = blockchainTime
+	}
+}
+
+// WithLogger sets the logger of the protocol client (and of its watchers)
+func WithLogger(logger log.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// New returns a new protocol client with the given protocol versions
+func New(versions []protocol.Protocol, opts ...Option) (*Client, error) {
+	c := &Client{logger: log.Default()}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := c.Reload(versions...); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Current returns the latest protocol version
+func (c *Client) Current() (protocol.Version, error) {
+	versions := c.load()
+
+	return versions[len(versions)-1], nil
+}
+
+// Get returns the protocol version that applies to the given transaction time
+func (c *Client) Get(transactionTime uint64) (protocol.Version, error) {
+	versions := c.load()
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if uint64(versions[i].p.StartingBlockChainTime) <= transactionTime {
+			return versions[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("protocol parameters are not defined for blockchain time [%d]", transactionTime)
+}
+
+// Versions returns the protocol parameters of all versions, sorted by starting blockchain time
+func (c *Client) Versions() []protocol.Protocol {
+	versions := c.load()
+
+	protocols := make([]protocol.Protocol, len(versions))
+	for i, v := range versions {
+		protocols[i] = v.p
+	}
+
+	return protocols
+}
+
+// Reload validates the given protocol versions and atomically replaces the protocol versions of the client.
+// The existing versions are kept if validation fails. The returned error wraps ErrVersionInEffect if the
+// given versions change a version that is already in effect.
+func (c *Client) Reload(protocols ...protocol.Protocol) error {
+	if len(protocols) == 0 {
+		return fmt.Errorf("at least one protocol version is required")
+	}
+
+	sorted := append([]protocol.Protocol(nil), protocols...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartingBlockChainTime < sorted[j].StartingBlockChainTime
+	})
+
+	versions := make([]*version, len(sorted))
+
+	for i, p := range sorted {
+		if i > 0 && p.StartingBlockChainTime == sorted[i-1].StartingBlockChainTime {
+			return fmt.Errorf("duplicate protocol version for starting blockchain time [%d]", p.StartingBlockChainTime)
+		}
+
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("invalid protocol version for starting blockchain time [%d]: %s", p.StartingBlockChainTime, err)
+		}
+
+		versions[i] = &version{p: p, parser: operation.NewParser(p, c.parserOptions...)}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	reload := c.versions.Load() != nil
+
+	if reload {
+		if err := c.checkHistory(sorted); err != nil {
+			return err
+		}
+	}
+
+	c.versions.Store(versions)
+
+	if reload {
+		c.logger.Infof("Reloaded %d protocol version(s)", len(versions))
+	}
+
+	return nil
+}
+
+// ReloadFromFile loads the protocol versions from the given file (see ParseVersions) and reloads the client
+func (c *Client) ReloadFromFile(path string, opts ...protocol.LoadOption) error {
+	data, err := ioutil.ReadFile(path) //nolint:gosec // path is provided by the operator
+	if err != nil {
+		return fmt.Errorf("failed to read protocol file: %s", err)
+	}
+
+	versions, err := ParseVersions(data, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to load protocol versions from [%s]: %s", path, err)
+	}
+
+	return c.Reload(versions...)
+}
+
+// checkHistory checks that the given (sorted) versions don't change the versions that are already in effect
+func (c *Client) checkHistory(protocols []protocol.Protocol) error {
+	current := c.load()
+
+	now, err := c.currentTime(current)
+	if err != nil {
+		return err
+	}
+
+	reloaded := make(map[uint]protocol.Protocol, len(protocols))
+	for _, p := range protocols {
+		reloaded[p.StartingBlockChainTime] = p
+	}
+
+	inEffect := make(map[uint]bool)
+
+	for _, v := range current {
+		start := v.p.StartingBlockChainTime
+		if uint64(start) > now {
+			continue
+		}
+
+		inEffect[start] = true
+
+		p, ok := reloaded[start]
+		if !ok {
+			return fmt.Errorf("%w: version for starting blockchain time [%d] may not be removed", ErrVersionInEffect, start)
+		}
+
+		if !reflect.DeepEqual(consensusParameters(p), consensusParameters(v.p)) {
+			return fmt.Errorf("%w: only non-consensus parameters of version for starting blockchain time [%d] may be changed",
+				ErrVersionInEffect, start)
+		}
+	}
+
+	for _, p := range protocols {
+		if uint64(p.StartingBlockChainTime) <= now && !inEffect[p.StartingBlockChainTime] {
+			return fmt.Errorf("%w: new version for starting blockchain time [%d] must start after blockchain time [%d]",
+				ErrVersionInEffect, p.StartingBlockChainTime, now)
+		}
+	}
+
+	return nil
+}
+
+// currentTime returns the current blockchain time or, if the blockchain time is not available, the starting
+// blockchain time of the latest version (so that all of the existing versions are in effect)
+func (c *Client) currentTime(current []*version) (uint64, error) {
+	if c.blockchainTime == nil {
+		return uint64(current[len(current)-1].p.StartingBlockChainTime), nil
+	}
+
+	now, err := c.blockchainTime()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current blockchain time: %s", err)
+	}
+
+	return now, nil
+}
+
+// consensusParameters returns the protocol parameters without the parameters that don't affect the validation
+// of anchored operations
+func consensusParameters(p protocol.Protocol) protocol.Protocol {
+	p.MaxOperationsPerBatch = 0
+	p.MaxOperationsPerDID = 0
+	p.RateLimitWindow = 0
+	p.DeactivateConfirmation = false
+
+	return p
+}
+
+func (c *Client) load() []*version {
+	return c.versions.Load().([]*version)
+}
+
+// ParseVersions parses the JSON-encoded protocol versions, which may either be a single protocol (object) or
+// a list of protocols (array). Each version is loaded with the defaults and the given options (see protocol.FromJSON).
+func ParseVersions(data []byte, opts ...protocol.LoadOption) ([]protocol.Protocol, error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) == 0 || data[0] != '[' {
+		p, err := protocol.FromJSON(data, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return []protocol.Protocol{p}, nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal protocol versions: %s", err)
+	}
+
+	versions := make([]protocol.Protocol, len(raw))
+
+	for i, r := range raw {
+		p, err := protocol.FromJSON(r, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("protocol version [%d]: %s", i, err)
+		}
+
+		versions[i] = p
+	}
+
+	return versions, nil
+}
+
+type version struct {
+	p      protocol.Protocol
+	parser *operation.Parser
+}
+
+// Protocol returns the protocol parameters
+func (v *version) Protocol() protocol.Protocol {
+	return v.p
+}
+
+// OperationParser returns the operation parser
+func (v *version) OperationParser() protocol.OperationParser {
+	return v.parser
+}
+
+// Provider provides the protocol clients of namespaces (see observer.ProtocolClientProvider)
+type Provider struct {
+	mutex   sync.RWMutex
+	clients map[string]*Client
+}
+
+// NewProvider returns a new protocol client provider
+func NewProvider() *Provider {
+	return &Provider{clients: make(map[string]*Client)}
+}
+
+// Add sets the protocol client of the given namespace
+func (p *Provider) Add(namespace string, client *Client) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.clients[namespace] = client
+}
+
+// ForNamespace returns the protocol client of the given namespace
+func (p *Provider) ForNamespace(namespace string) (protocol.Client, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	client, ok := p.clients[namespace]
+	if !ok {
+		return nil, fmt.Errorf("protocol client not found for namespace [%s]", namespace)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,276 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocolclient
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+const namespace = "did:sidetree"
+
+func TestNew(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		c, err := New([]protocol.Protocol{newProtocol(100, 20), newProtocol(0, 10)})
+		require.NoError(t, err)
+
+		pv, err := c.Current()
+		require.NoError(t, err)
+		require.Equal(t, uint(20), pv.Protocol().MaxOperationsPerBatch)
+		require.NotNil(t, pv.OperationParser())
+
+		pv, err = c.Get(99)
+		require.NoError(t, err)
+		require.Equal(t, uint(10), pv.Protocol().MaxOperationsPerBatch)
+
+		pv, err = c.Get(100)
+		require.NoError(t, err)
+		require.Equal(t, uint(20), pv.Protocol().MaxOperationsPerBatch)
+
+		versions := c.Versions()
+		require.Len(t, versions, 2)
+		require.Equal(t, uint(0), versions[0].StartingBlockChainTime)
+		require.Equal(t, uint(100), versions[1].StartingBlockChainTime)
+	})
+
+	t.Run("no versions", func(t *testing.T) {
+		c, err := New(nil)
+		require.Error(t, err)
+		require.Nil(t, c)
+		require.Contains(t, err.Error(), "at least one protocol version is required")
+	})
+
+	t.Run("no version for transaction time", func(t *testing.T) {
+		c, err := New([]protocol.Protocol{newProtocol(100, 10)})
+		require.NoError(t, err)
+
+		pv, err := c.Get(99)
+		require.Error(t, err)
+		require.Nil(t, pv)
+		require.Contains(t, err.Error(), "protocol parameters are not defined for blockchain time [99]")
+	})
+}
+
+func TestClient_Reload(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		c, err := New([]protocol.Protocol{newProtocol(0, 10)})
+		require.NoError(t, err)
+
+		before, err := c.Current()
+		require.NoError(t, err)
+
+		require.NoError(t, c.Reload(newProtocol(0, 50)))
+
+		after, err := c.Current()
+		require.NoError(t, err)
+		require.Equal(t, uint(50), after.Protocol().MaxOperationsPerBatch)
+
+		// versions that were obtained before the reload are not modified
+		require.Equal(t, uint(10), before.Protocol().MaxOperationsPerBatch)
+	})
+
+	t.Run("with logger", func(t *testing.T) {
+		l, hook := logtest.NewNullLogger()
+
+		c, err := New([]protocol.Protocol{newProtocol(0, 10)}, WithLogger(log.New(l)))
+		require.NoError(t, err)
+		require.Empty(t, hook.AllEntries())
+
+		require.NoError(t, c.Reload(newProtocol(0, 50)))
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		require.Equal(t, "Reloaded 1 protocol version(s)", entry.Message)
+	})
+
+	t.Run("invalid version - keeps existing versions", func(t *testing.T) {
+		c, err := New([]protocol.Protocol{newProtocol(0, 10)})
+		require.NoError(t, err)
+
+		invalid := newProtocol(100, 20)
+		invalid.HashAlgorithmInMultiHashCode = 999
+
+		err = c.Reload(newProtocol(0, 50), invalid)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid protocol version for starting blockchain time [100]")
+
+		pv, err := c.Current()
+		require.NoError(t, err)
+		require.Equal(t, uint(10), pv.Protocol().MaxOperationsPerBatch)
+	})
+
+	t.Run("duplicate starting time", func(t *testing.T) {
+		c, err := New([]protocol.Protocol{newProtocol(0, 10)})
+		require.NoError(t, err)
+
+		err = c.Reload(newProtocol(0, 50), newProtocol(0, 60))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "duplicate protocol version for starting blockchain time [0]")
+	})
+
+	t.Run("versions in effect", func(t *testing.T) {
+		c, err := New([]protocol.Protocol{newProtocol(0, 10), newProtocol(100, 10)})
+		require.NoError(t, err)
+
+		// without the blockchain time all of the existing versions are in effect
+		err = c.Reload(newProtocol(0, 10))
+		require.True(t, errors.Is(err, ErrVersionInEffect))
+		require.Contains(t, err.Error(), "version for starting blockchain time [100] may not be removed")
+
+		changed := newProtocol(100, 10)
+		changed.MaxDeltaByteSize++
+
+		err = c.Reload(newProtocol(0, 10), changed)
+		require.True(t, errors.Is(err, ErrVersionInEffect))
+		require.Contains(t, err.Error(), "only non-consensus parameters of version for starting blockchain time [100] may be changed")
+
+		err = c.Reload(newProtocol(0, 10), newProtocol(50, 10), newProtocol(100, 10))
+		require.True(t, errors.Is(err, ErrVersionInEffect))
+		require.Contains(t, err.Error(), "new version for starting blockchain time [50] must start after blockchain time [100]")
+
+		require.Len(t, c.Versions(), 2)
+
+		// non-consensus parameters may be changed
+		changed = newProtocol(100, 20)
+		changed.MaxOperationsPerDID = 5
+		changed.RateLimitWindow = 60
+		changed.DeactivateConfirmation = true
+
+		require.NoError(t, c.Reload(newProtocol(0, 10), changed, newProtocol(200, 10)))
+		require.Len(t, c.Versions(), 3)
+	})
+
+	t.Run("future versions", func(t *testing.T) {
+		var now uint64 = 150
+
+		c, err := New([]protocol.Protocol{newProtocol(0, 10), newProtocol(200, 10)},
+			WithBlockchainTime(func() (uint64, error) { return now, nil }))
+		require.NoError(t, err)
+
+		// the version that starts at 200 is not in effect yet so it may be changed or removed
+		changed := newProtocol(200, 10)
+		changed.MaxDeltaByteSize++
+
+		require.NoError(t, c.Reload(newProtocol(0, 10), changed))
+		require.NoError(t, c.Reload(newProtocol(0, 10), newProtocol(300, 10)))
+		require.Len(t, c.Versions(), 2)
+
+		err = c.Reload(newProtocol(0, 10), newProtocol(100, 10))
+		require.True(t, errors.Is(err, ErrVersionInEffect))
+		require.Contains(t, err.Error(), "new version for starting blockchain time [100] must start after blockchain time [150]")
+
+		now = 300
+
+		err = c.Reload(newProtocol(0, 10))
+		require.True(t, errors.Is(err, ErrVersionInEffect))
+	})
+
+	t.Run("blockchain time error", func(t *testing.T) {
+		c, err := New([]protocol.Protocol{newProtocol(0, 10)},
+			WithBlockchainTime(func() (uint64, error) { return 0, errors.New("injected ledger error") }))
+		require.NoError(t, err)
+
+		err = c.Reload(newProtocol(0, 20))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get current blockchain time: injected ledger error")
+	})
+
+	t.Run("concurrent reloads and reads", func(t *testing.T) {
+		c, err := New([]protocol.Protocol{newProtocol(0, 10)})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+
+		for i := 1; i <= 10; i++ {
+			wg.Add(2)
+
+			go func(size uint) {
+				defer wg.Done()
+				require.NoError(t, c.Reload(newProtocol(0, size)))
+			}(uint(i))
+
+			go func() {
+				defer wg.Done()
+				_, err := c.Current()
+				require.NoError(t, err)
+			}()
+		}
+
+		wg.Wait()
+	})
+}
+
+func TestParseVersions(t *testing.T) {
+	t.Run("single version", func(t *testing.T) {
+		versions, err := ParseVersions([]byte(` {"maxOperationsPerBatch": 7} `))
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		require.Equal(t, uint(7), versions[0].MaxOperationsPerBatch)
+		require.Equal(t, protocol.Default().MaxDeltaByteSize, versions[0].MaxDeltaByteSize)
+	})
+
+	t.Run("multiple versions", func(t *testing.T) {
+		versions, err := ParseVersions([]byte(`[{"maxOperationsPerBatch": 7},{"startingBlockchainTime": 100}]`))
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.Equal(t, uint(7), versions[0].MaxOperationsPerBatch)
+		require.Equal(t, uint(100), versions[1].StartingBlockChainTime)
+	})
+
+	t.Run("invalid array", func(t *testing.T) {
+		versions, err := ParseVersions([]byte(`[{`))
+		require.Error(t, err)
+		require.Nil(t, versions)
+		require.Contains(t, err.Error(), "failed to unmarshal protocol versions")
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		versions, err := ParseVersions([]byte(`[{},{"unknown": 1}]`))
+		require.Error(t, err)
+		require.Nil(t, versions)
+		require.Contains(t, err.Error(), "protocol version [1]")
+	})
+
+	t.Run("invalid object", func(t *testing.T) {
+		versions, err := ParseVersions([]byte(`{"unknown": 1}`))
+		require.Error(t, err)
+		require.Nil(t, versions)
+		require.Contains(t, err.Error(), "failed to unmarshal protocol")
+	})
+}
+
+func TestProvider(t *testing.T) {
+	c, err := New([]protocol.Protocol{newProtocol(0, 10)})
+	require.NoError(t, err)
+
+	p := NewProvider()
+	p.Add(namespace, c)
+
+	pc, err := p.ForNamespace(namespace)
+	require.NoError(t, err)
+	require.Equal(t, c, pc)
+
+	pc, err = p.ForNamespace("other")
+	require.Error(t, err)
+	require.Nil(t, pc)
+	require.Contains(t, err.Error(), "protocol client not found for namespace [other]")
+}
+
+func newProtocol(startingTime, maxOperationsPerBatch uint) protocol.Protocol {
+	p := protocol.Default()
+	p.StartingBlockChainTime = startingTime
+	p.MaxOperationsPerBatch = maxOperationsPerBatch
+
+	return p
+}
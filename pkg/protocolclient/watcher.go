@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocolclient
+
+import (
+	"os"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+)
+
+const defaultWatchInterval = 10 * time.Second
+
+// Watcher reloads the protocol versions of a client when the protocol file changes
+type Watcher struct {
+	client      *Client
+	path        string
+	interval    time.Duration
+	loadOptions []protocol.LoadOption
+	modTime     time.Time
+	size        int64
+	stopCh      chan struct{}
+}
+
+// WatcherOption is a watcher option
+type WatcherOption func(w *Watcher)
+
+// WithInterval sets the interval at which the protocol file is checked for changes (default 10s)
+func WithInterval(interval time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.interval = interval
+	}
+}
+
+// WithLoadOptions sets the options that are used to load the protocol versions (e.g. environment overrides)
+func WithLoadOptions(opts ...protocol.LoadOption) WatcherOption {
+	return func(w *Watcher) {
+		w.loadOptions = append(w.loadOptions, opts...)
+	}
+}
+
+// NewWatcher returns a new watcher that reloads the protocol versions of the given client from the given
+// file (see ParseVersions) when the modification time or the size of the file changes. If the changed file
+// is invalid then the error is logged and the client keeps its protocol versions.
+func NewWatcher(client *Client, path string, opts ...WatcherOption) *Watcher {
+	w := &Watcher{
+		client:   client,
+		path:     path,
+		interval: defaultWatchInterval,
+		stopCh:   make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		w.modTime = info.ModTime()
+		w.size = info.Size()
+	} else {
+		w.client.logger.Warnf("Unable to stat protocol file [%s]: %s", path, err)
+	}
+
+	return w
+}
+
+// Start starts watching the protocol file
+func (w *Watcher) Start() {
+	go w.watch()
+}
+
+// Stop stops watching the protocol file
+func (w *Watcher) Stop() {
+	w.stopCh <- struct{}{}
+}
+
+func (w *Watcher) watch() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.check()
+		case <-w.stopCh:
+			w.client.logger.Infof("Stopped watching protocol file [%s]", w.path)
+			return
+		}
+	}
+}
+
+// check reloads the protocol versions if the protocol file has changed and returns true if the
+// versions were reloaded
+func (w *Watcher) check() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		w.client.logger.Warnf("Unable to stat protocol file [%s]: %s", w.path, err)
+		return false
+	}
+
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false
+	}
+
+	// remember the change even if the reload fails so that an invalid file is only reported once
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+
+	if err := w.client.ReloadFromFile(w.path, w.loadOptions...); err != nil {
+		w.client.logger.Errorf("Protocol file [%s] changed but the protocol versions were not reloaded: %s", w.path, err)
+		return false
+	}
+
+	w.client.logger.Infof("Reloaded protocol versions from [%s]", w.path)
+
+	return true
+}
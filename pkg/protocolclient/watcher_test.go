@@ -0,0 +1,94 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocolclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+)
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protocol")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	path := filepath.Join(dir, "protocol.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"maxOperationsPerBatch": 10}`), 0600))
+
+	c, err := New([]protocol.Protocol{newProtocol(0, 10)}, WithBlockchainTime(func() (uint64, error) { return 50, nil }))
+	require.NoError(t, err)
+
+	t.Run("unchanged", func(t *testing.T) {
+		w := NewWatcher(c, path)
+		require.False(t, w.check())
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		w := NewWatcher(c, path)
+
+		require.NoError(t, ioutil.WriteFile(path, []byte(`[{"maxOperationsPerBatch": 20},{"startingBlockchainTime": 100}]`), 0600))
+		require.True(t, w.check())
+		require.Len(t, c.Versions(), 2)
+
+		pv, err := c.Get(0)
+		require.NoError(t, err)
+		require.Equal(t, uint(20), pv.Protocol().MaxOperationsPerBatch)
+
+		// no further changes
+		require.False(t, w.check())
+	})
+
+	t.Run("invalid file - keeps existing versions", func(t *testing.T) {
+		w := NewWatcher(c, path)
+
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{"maxOperationsPerBatch": "invalid"}`), 0600))
+		require.False(t, w.check())
+		require.Len(t, c.Versions(), 2)
+	})
+
+	t.Run("env overrides", func(t *testing.T) {
+		require.NoError(t, os.Setenv("WATCHER_TEST_MAX_OPERATIONS_PER_BATCH", "123"))
+		defer func() { require.NoError(t, os.Unsetenv("WATCHER_TEST_MAX_OPERATIONS_PER_BATCH")) }()
+
+		w := NewWatcher(c, path, WithLoadOptions(protocol.WithEnvOverrides("WATCHER_TEST_")))
+
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{}`), 0600))
+		require.True(t, w.check())
+
+		pv, err := c.Current()
+		require.NoError(t, err)
+		require.Equal(t, uint(123), pv.Protocol().MaxOperationsPerBatch)
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		w := NewWatcher(c, filepath.Join(dir, "missing.json"))
+		require.False(t, w.check())
+	})
+
+	t.Run("start and stop", func(t *testing.T) {
+		w := NewWatcher(c, path, WithInterval(10*time.Millisecond))
+		w.Start()
+
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{"maxOperationsPerBatch": 30}`), 0600))
+
+		require.Eventually(t, func() bool {
+			pv, err := c.Current()
+			require.NoError(t, err)
+
+			return pv.Protocol().MaxOperationsPerBatch == 30
+		}, time.Second, 10*time.Millisecond)
+
+		w.Stop()
+	})
+}
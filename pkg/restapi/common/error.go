@@ -10,6 +10,7 @@ package common
 type HTTPError struct {
 	err    error
 	status int
+	code   string
 }
 
 // NewHTTPError returns a new HTTPError
@@ -20,6 +21,16 @@ func NewHTTPError(status int, err error) *HTTPError {
 	}
 }
 
+// NewHTTPErrorWithCode returns a new HTTPError with the given error code. Errors that have a code
+// are returned to the client as a structured (JSON) error response.
+func NewHTTPErrorWithCode(status int, code string, err error) *HTTPError {
+	return &HTTPError{
+		err:    err,
+		status: status,
+		code:   code,
+	}
+}
+
 // Error returns the error string
 func (e *HTTPError) Error() string {
 	return e.err.Error()
@@ -29,3 +40,14 @@ func (e *HTTPError) Error() string {
 func (e *HTTPError) Status() int {
 	return e.status
 }
+
+// Code returns the error code (empty if the error is not structured)
+func (e *HTTPError) Code() string {
+	return e.code
+}
+
+// ErrorResponse is the structured error that is returned to the client
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
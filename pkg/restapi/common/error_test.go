@@ -21,3 +21,13 @@ func TestNewHTTPError(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, err.Status())
 	require.Equal(t, errExpected.Error(), err.Error())
 }
+
+func TestNewHTTPErrorWithCode(t *testing.T) {
+	errExpected := errors.New("expected error")
+	err := NewHTTPErrorWithCode(http.StatusForbidden, "forbidden", errExpected)
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusForbidden, err.Status())
+	require.Equal(t, "forbidden", err.Code())
+	require.Equal(t, errExpected.Error(), err.Error())
+	require.Empty(t, NewHTTPError(http.StatusBadRequest, errExpected).Code())
+}
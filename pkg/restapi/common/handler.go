@@ -23,3 +23,35 @@ type HTTPHandler interface {
 	Method() string
 	Handler() HTTPRequestHandler
 }
+
+// Handler implements HTTPHandler
+type Handler struct {
+	path       string
+	method     string
+	reqHandler HTTPRequestHandler
+}
+
+// NewHandler returns a new HTTP handler descriptor. The given middleware is applied to the request handler
+// in the order provided, i.e. the first middleware is the outermost.
+func NewHandler(path, method string, reqHandler HTTPRequestHandler, middleware ...Middleware) *Handler {
+	return &Handler{
+		path:       path,
+		method:     method,
+		reqHandler: Chain(reqHandler, middleware...),
+	}
+}
+
+// Path returns the context path
+func (h *Handler) Path() string {
+	return h.path
+}
+
+// Method returns the HTTP method
+func (h *Handler) Method() string {
+	return h.method
+}
+
+// Handler returns the handler
+func (h *Handler) Handler() HTTPRequestHandler {
+	return h.reqHandler
+}
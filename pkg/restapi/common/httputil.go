@@ -11,9 +11,22 @@ import (
 	"net/http"
 )
 
+const (
+	// DIDContentType is the content type of the responses for DID documents
+	DIDContentType = "application/did+ld+json"
+
+	// JSONContentType is the content type of the responses for generic (non-DID) documents
+	JSONContentType = "application/json"
+)
+
 // WriteResponse writes a response to the response writer
 func WriteResponse(rw http.ResponseWriter, status int, v interface{}) {
-	rw.Header().Set("Content-Type", "application/did+ld+json")
+	WriteResponseWithContentType(rw, status, DIDContentType, v)
+}
+
+// WriteResponseWithContentType writes a response with the given content type to the response writer
+func WriteResponseWithContentType(rw http.ResponseWriter, status int, contentType string, v interface{}) {
+	rw.Header().Set("Content-Type", contentType)
 	rw.WriteHeader(status)
 	err := json.NewEncoder(rw).Encode(v)
 	if err != nil {
@@ -32,3 +45,15 @@ func WriteError(rw http.ResponseWriter, status int, err error) {
 		logger.Errorf("Unable to write response: %s", e)
 	}
 }
+
+// WriteJSONError writes a structured (JSON) error to the response writer
+func WriteJSONError(rw http.ResponseWriter, status int, code string, err error) {
+	logger.Warnf("returning error status: %d, code: %s, message: %s", status, code, err.Error())
+
+	rw.Header().Set("Content-Type", JSONContentType)
+	rw.WriteHeader(status)
+	e := json.NewEncoder(rw).Encode(&ErrorResponse{Code: code, Message: err.Error()})
+	if e != nil {
+		logger.Errorf("Unable to write response: %s", e)
+	}
+}
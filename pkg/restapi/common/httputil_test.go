@@ -23,6 +23,14 @@ func TestWriteResponse(t *testing.T) {
 	require.Equal(t, "application/did+ld+json", rw.Header().Get("content-type"))
 }
 
+func TestWriteResponseWithContentType(t *testing.T) {
+	rw := httptest.NewRecorder()
+	WriteResponseWithContentType(rw, http.StatusOK, JSONContentType, "content")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "\"content\"\n", rw.Body.String())
+	require.Equal(t, "application/json", rw.Header().Get("content-type"))
+}
+
 func TestWriteError(t *testing.T) {
 	rw := httptest.NewRecorder()
 	errExpected := errors.New("some error")
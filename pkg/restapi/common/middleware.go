@@ -0,0 +1,132 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	// RequestIDHeader is the default header that carries the request ID
+	RequestIDHeader = "X-Request-ID"
+
+	authHeader   = "Authorization"
+	bearerPrefix = "Bearer "
+
+	requestIDLength = 16
+)
+
+type contextKey string
+
+const requestIDKey contextKey = "requestID"
+
+// Middleware wraps a request handler with additional behavior (e.g. authentication, request tracing)
+type Middleware func(next HTTPRequestHandler) HTTPRequestHandler
+
+// ErrorMapper maps the error returned by a handler (which contains the default HTTP status)
+// to the error that is returned to the client
+type ErrorMapper func(err *HTTPError) *HTTPError
+
+// TokenValidator validates the bearer token of a request
+type TokenValidator func(token string) error
+
+// Chain applies the given middleware to the request handler. The first middleware is the outermost.
+func Chain(handler HTTPRequestHandler, middleware ...Middleware) HTTPRequestHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
+
+// DefaultErrorMapper returns the error unchanged
+func DefaultErrorMapper(err *HTTPError) *HTTPError {
+	return err
+}
+
+// NewRequestIDMiddleware returns middleware that extracts the request ID from the given header (X-Request-ID if empty)
+// or generates one if the header is not present. The request ID is added to the request context (see RequestID)
+// and is returned in the same response header.
+func NewRequestIDMiddleware(header string) Middleware {
+	if header == "" {
+		header = RequestIDHeader
+	}
+
+	return func(next HTTPRequestHandler) HTTPRequestHandler {
+		return func(rw http.ResponseWriter, req *http.Request) {
+			id := req.Header.Get(header)
+			if id == "" {
+				id = newRequestID()
+			}
+
+			rw.Header().Set(header, id)
+
+			next(rw, req.WithContext(context.WithValue(req.Context(), requestIDKey, id)))
+		}
+	}
+}
+
+// RequestID returns the request ID from the given context or empty string if the request ID is not set
+func RequestID(ctx context.Context) string {
+	id, ok := ctx.Value(requestIDKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return id
+}
+
+// NewAuthTokenMiddleware returns middleware that validates the bearer token in the Authorization header
+// using the given validator. If the token is missing or invalid then 401 (Unauthorized) is returned.
+func NewAuthTokenMiddleware(validate TokenValidator) Middleware {
+	return func(next HTTPRequestHandler) HTTPRequestHandler {
+		return func(rw http.ResponseWriter, req *http.Request) {
+			token, err := BearerToken(req)
+			if err != nil {
+				WriteError(rw, http.StatusUnauthorized, err)
+				return
+			}
+
+			if err := validate(token); err != nil {
+				logger.Debugf("Invalid bearer token: %s", err)
+				WriteError(rw, http.StatusUnauthorized, errors.New("invalid token"))
+				return
+			}
+
+			next(rw, req)
+		}
+	}
+}
+
+// BearerToken returns the bearer token from the Authorization header of the request
+func BearerToken(req *http.Request) (string, error) {
+	header := req.Header.Get(authHeader)
+	if header == "" {
+		return "", errors.New("missing authorization header")
+	}
+
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return "", errors.New("invalid authorization header")
+	}
+
+	return strings.TrimPrefix(header, bearerPrefix), nil
+}
+
+func newRequestID() string {
+	b := make([]byte, requestIDLength)
+	if _, err := rand.Read(b); err != nil {
+		logger.Warnf("Unable to generate request ID: %s", err)
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
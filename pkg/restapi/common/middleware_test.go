@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHandler(t *testing.T) {
+	var calls []string
+
+	newMiddleware := func(name string) Middleware {
+		return func(next HTTPRequestHandler) HTTPRequestHandler {
+			return func(rw http.ResponseWriter, req *http.Request) {
+				calls = append(calls, name)
+				next(rw, req)
+			}
+		}
+	}
+
+	h := NewHandler("/path", http.MethodGet, func(rw http.ResponseWriter, req *http.Request) {
+		calls = append(calls, "handler")
+		rw.WriteHeader(http.StatusOK)
+	}, newMiddleware("first"), newMiddleware("second"))
+
+	require.Equal(t, "/path", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+
+	rw := httptest.NewRecorder()
+	h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/path", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var requestID string
+
+	handler := NewRequestIDMiddleware("")(func(rw http.ResponseWriter, req *http.Request) {
+		requestID = RequestID(req.Context())
+	})
+
+	t.Run("from header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/path", nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+
+		rw := httptest.NewRecorder()
+		handler(rw, req)
+		require.Equal(t, "req-1", requestID)
+		require.Equal(t, "req-1", rw.Header().Get(RequestIDHeader))
+	})
+	t.Run("generated", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		handler(rw, httptest.NewRequest(http.MethodGet, "/path", nil))
+		require.NotEmpty(t, requestID)
+		require.Equal(t, requestID, rw.Header().Get(RequestIDHeader))
+	})
+	t.Run("custom header", func(t *testing.T) {
+		handler := NewRequestIDMiddleware("X-Correlation-ID")(func(rw http.ResponseWriter, req *http.Request) {
+			requestID = RequestID(req.Context())
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/path", nil)
+		req.Header.Set("X-Correlation-ID", "corr-1")
+
+		rw := httptest.NewRecorder()
+		handler(rw, req)
+		require.Equal(t, "corr-1", requestID)
+		require.Equal(t, "corr-1", rw.Header().Get("X-Correlation-ID"))
+	})
+	t.Run("not set", func(t *testing.T) {
+		require.Empty(t, RequestID(httptest.NewRequest(http.MethodGet, "/path", nil).Context()))
+	})
+}
+
+func TestAuthTokenMiddleware(t *testing.T) {
+	handler := NewAuthTokenMiddleware(func(token string) error {
+		if token != "secret" {
+			return errors.New("invalid token")
+		}
+		return nil
+	})(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/path", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+
+		rw := httptest.NewRecorder()
+		handler(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+	})
+	t.Run("missing header", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		handler(rw, httptest.NewRequest(http.MethodGet, "/path", nil))
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+		require.Contains(t, rw.Body.String(), "missing authorization header")
+	})
+	t.Run("not a bearer token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/path", nil)
+		req.Header.Set("Authorization", "Basic abc")
+
+		rw := httptest.NewRecorder()
+		handler(rw, req)
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+		require.Contains(t, rw.Body.String(), "invalid authorization header")
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/path", nil)
+		req.Header.Set("Authorization", "Bearer wrong")
+
+		rw := httptest.NewRecorder()
+		handler(rw, req)
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+		require.Contains(t, rw.Body.String(), "invalid token")
+	})
+}
+
+func TestDefaultErrorMapper(t *testing.T) {
+	err := NewHTTPError(http.StatusBadRequest, errors.New("bad request"))
+	require.Equal(t, err, DefaultErrorMapper(err))
+}
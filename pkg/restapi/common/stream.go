@@ -0,0 +1,172 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+const (
+	didDocumentProperty     = "didDocument"
+	genericDocumentProperty = "document"
+)
+
+// WriteResolutionResult streams the resolution result to the response writer. The document is written
+// property by property (and arrays element by element) so that the full JSON of large documents is never
+// held in memory. The output is the same as the output of WriteResponse.
+func WriteResolutionResult(rw http.ResponseWriter, status int, result *document.ResolutionResult) {
+	writeResolutionResult(rw, status, DIDContentType, didDocumentProperty, result)
+}
+
+// WriteGenericResolutionResult streams the resolution result of a generic (non-DID) document to the response
+// writer. The result is written as plain JSON (application/json) with the document in the "document" property.
+func WriteGenericResolutionResult(rw http.ResponseWriter, status int, result *document.ResolutionResult) {
+	writeResolutionResult(rw, status, JSONContentType, genericDocumentProperty, result)
+}
+
+func writeResolutionResult(rw http.ResponseWriter, status int, contentType, docProperty string,
+	result *document.ResolutionResult) {
+	rw.Header().Set("Content-Type", contentType)
+	rw.WriteHeader(status)
+
+	if err := encodeResolutionResult(rw, docProperty, result); err != nil {
+		logger.Errorf("Unable to write response: %s", err)
+	}
+}
+
+// EncodeResolutionResult streams the JSON encoding of the resolution result (followed by a newline)
+// to the given writer. Many small writes are made so the writer should be buffered (as is the case
+// for the http.ResponseWriter).
+func EncodeResolutionResult(w io.Writer, result *document.ResolutionResult) error {
+	return encodeResolutionResult(w, didDocumentProperty, result)
+}
+
+// EncodeGenericResolutionResult is the same as EncodeResolutionResult except that the document is written
+// in the "document" property (instead of "didDocument")
+func EncodeGenericResolutionResult(w io.Writer, result *document.ResolutionResult) error {
+	return encodeResolutionResult(w, genericDocumentProperty, result)
+}
+
+func encodeResolutionResult(w io.Writer, docProperty string, result *document.ResolutionResult) error {
+	s := &streamWriter{w: w, enc: json.NewEncoder(&valueWriter{w: w})}
+
+	if result == nil {
+		s.value(nil)
+	} else {
+		s.raw(`{"@context":`)
+		s.value(result.Context)
+		s.raw(`,"` + docProperty + `":`)
+		s.document(result.Document)
+		s.raw(`,"methodMetadata":`)
+		s.value(result.MethodMetadata)
+		s.raw("}")
+	}
+
+	s.raw("\n")
+
+	return s.err
+}
+
+// streamWriter writes JSON to the underlying writer and retains the first error
+type streamWriter struct {
+	w   io.Writer
+	enc *json.Encoder
+	err error
+}
+
+// valueWriter strips the newline that the JSON encoder appends to each value. (Values are encoded
+// with a single encoder rather than json.Marshal since the encoder reuses its internal buffers.)
+type valueWriter struct {
+	w io.Writer
+}
+
+func (w *valueWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if n > 0 && p[n-1] == '\n' {
+		p = p[:n-1]
+	}
+
+	if _, err := w.w.Write(p); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (s *streamWriter) raw(str string) {
+	if s.err != nil {
+		return
+	}
+
+	_, s.err = io.WriteString(s.w, str)
+}
+
+func (s *streamWriter) value(v interface{}) {
+	if s.err != nil {
+		return
+	}
+
+	s.err = s.enc.Encode(v)
+}
+
+func (s *streamWriter) document(doc document.Document) {
+	if doc == nil {
+		s.raw("null")
+		return
+	}
+
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+
+	// keys are sorted to produce the same output as encoding/json
+	sort.Strings(keys)
+
+	s.raw("{")
+
+	for i, k := range keys {
+		if i > 0 {
+			s.raw(",")
+		}
+
+		s.value(k)
+		s.raw(":")
+		s.array(doc[k])
+	}
+
+	s.raw("}")
+}
+
+// array writes slices element by element. Other values are written as a whole.
+func (s *streamWriter) array(v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.IsNil() || rv.Type().Elem().Kind() == reflect.Uint8 || rv.Type().Implements(marshalerType) {
+		s.value(v)
+		return
+	}
+
+	s.raw("[")
+
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			s.raw(",")
+		}
+
+		s.value(rv.Index(i).Interface())
+	}
+
+	s.raw("]")
+}
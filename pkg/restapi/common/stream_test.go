@@ -0,0 +1,142 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+func TestEncodeResolutionResult(t *testing.T) {
+	t.Run("same output as encoding/json", func(t *testing.T) {
+		for _, result := range []*document.ResolutionResult{
+			nil,
+			{Context: "https://w3id.org/did-resolution/v1"},
+			newResolutionResult(100),
+		} {
+			expected := &bytes.Buffer{}
+			require.NoError(t, json.NewEncoder(expected).Encode(result))
+
+			actual := &bytes.Buffer{}
+			require.NoError(t, EncodeResolutionResult(actual, result))
+
+			require.Equal(t, expected.String(), actual.String())
+		}
+	})
+	t.Run("marshal error", func(t *testing.T) {
+		result := newResolutionResult(1)
+		result.Document["invalid"] = make(chan int)
+
+		err := EncodeResolutionResult(&bytes.Buffer{}, result)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported type")
+	})
+	t.Run("write error", func(t *testing.T) {
+		err := EncodeResolutionResult(&failingWriter{err: errors.New("write error")}, newResolutionResult(10))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "write error")
+	})
+}
+
+func TestWriteResolutionResult(t *testing.T) {
+	result := newResolutionResult(5)
+
+	rw := httptest.NewRecorder()
+	WriteResolutionResult(rw, http.StatusOK, result)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "application/did+ld+json", rw.Header().Get("Content-Type"))
+
+	var r document.ResolutionResult
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &r))
+	require.Equal(t, result.Document.ID(), r.Document.ID())
+	require.Len(t, r.Document.PublicKeys(), 5)
+}
+
+func TestWriteGenericResolutionResult(t *testing.T) {
+	result := newResolutionResult(5)
+
+	rw := httptest.NewRecorder()
+	WriteGenericResolutionResult(rw, http.StatusOK, result)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+
+	var r map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &r))
+	require.NotContains(t, r, "didDocument")
+	require.Contains(t, r, "methodMetadata")
+
+	doc, err := document.FromBytes(r["document"])
+	require.NoError(t, err)
+	require.Equal(t, result.Document.ID(), doc.ID())
+
+	t.Run("encode", func(t *testing.T) {
+		actual := &bytes.Buffer{}
+		require.NoError(t, EncodeGenericResolutionResult(actual, result))
+		require.Equal(t, rw.Body.String(), actual.String())
+	})
+}
+
+func newResolutionResult(n int) *document.ResolutionResult {
+	var keys []document.PublicKey
+	var services []document.Service
+
+	for i := 0; i < n; i++ {
+		keys = append(keys, document.PublicKey{
+			"id":         fmt.Sprintf("did:example:123#key-%d", i),
+			"type":       "JwsVerificationKey2020",
+			"controller": "did:example:123",
+			"publicKeyJwk": map[string]interface{}{
+				"kty": "EC",
+				"crv": "P-256",
+				"x":   "PUymIqdtF_qxaAqPABSw-C-owT1KYYQbsMKFM-L9fJA",
+				"y":   "nM84jDHCMOTGTh_ZdHq4dBBdo4Z5PkEOW9jA8z8IsGc",
+			},
+		})
+
+		services = append(services, document.Service{
+			"id":              fmt.Sprintf("did:example:123#svc-%d", i),
+			"type":            "<Service & Type>",
+			"serviceEndpoint": "https://example.com/?a=1&b=2",
+		})
+	}
+
+	return &document.ResolutionResult{
+		Context: "https://w3id.org/did-resolution/v1",
+		Document: document.Document{
+			"@context":       []interface{}{"https://w3id.org/did/v1"},
+			"id":             "did:example:123",
+			"publicKey":      keys,
+			"authentication": []interface{}{"did:example:123#key-0"},
+			"service":        services,
+			"empty":          []string(nil),
+			"bytes":          []byte("value"),
+			"number":         1.5,
+		},
+		MethodMetadata: document.MethodMetadata{
+			Published:   true,
+			RecoveryKey: &jws.JWK{Kty: "EC", Crv: "P-256", X: "x", Y: "y"},
+		},
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
@@ -0,0 +1,49 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// ArchiveExportHandler exports the operation history of the namespace (admin endpoint)
+type ArchiveExportHandler struct {
+	common.HTTPHandler
+}
+
+// NewArchiveExportHandler returns a new archive export handler
+func NewArchiveExportHandler(basePath string, exporter dochandler.OperationExporter, authorizer dochandler.AdminAuthorizer,
+	opts ...dochandler.Option) *ArchiveExportHandler {
+	return &ArchiveExportHandler{
+		HTTPHandler: common.NewHandler(
+			fmt.Sprintf("%s/admin/archive", basePath),
+			http.MethodGet,
+			dochandler.NewArchiveHandler(exporter, nil, authorizer, opts...).Export,
+		),
+	}
+}
+
+// ArchiveImportHandler imports an operation history archive into the namespace (admin endpoint)
+type ArchiveImportHandler struct {
+	common.HTTPHandler
+}
+
+// NewArchiveImportHandler returns a new archive import handler
+func NewArchiveImportHandler(basePath string, importer dochandler.OperationImporter, authorizer dochandler.AdminAuthorizer,
+	opts ...dochandler.Option) *ArchiveImportHandler {
+	return &ArchiveImportHandler{
+		HTTPHandler: common.NewHandler(
+			fmt.Sprintf("%s/admin/archive", basePath),
+			http.MethodPost,
+			dochandler.NewArchiveHandler(nil, importer, authorizer, opts...).Import,
+		),
+	}
+}
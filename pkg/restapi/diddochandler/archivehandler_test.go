@@ -0,0 +1,70 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/store/archive"
+)
+
+func TestArchiveHandlers(t *testing.T) {
+	store := &mockArchiveStore{ops: []*batch.Operation{
+		{UniqueSuffix: "suffix", Type: batch.OperationTypeCreate, TransactionTime: 1, OperationBuffer: []byte("{}")},
+	}}
+
+	exportHandler := NewArchiveExportHandler(basePath, archive.NewExporter(namespace, store), allowAdmin)
+	require.Equal(t, basePath+"/admin/archive", exportHandler.Path())
+	require.Equal(t, http.MethodGet, exportHandler.Method())
+	require.NotNil(t, exportHandler.Handler())
+
+	rw := httptest.NewRecorder()
+	exportHandler.Handler()(rw, httptest.NewRequest(http.MethodGet, basePath+"/admin/archive", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Contains(t, rw.Body.String(), `"uniqueSuffix":"suffix"`)
+
+	importHandler := NewArchiveImportHandler(basePath, archive.NewImporter("did:other", nil, store), allowAdmin)
+	require.Equal(t, basePath+"/admin/archive", importHandler.Path())
+	require.Equal(t, http.MethodPost, importHandler.Method())
+	require.NotNil(t, importHandler.Handler())
+
+	// the archive was exported from another namespace
+	importRW := httptest.NewRecorder()
+	importHandler.Handler()(importRW, httptest.NewRequest(http.MethodPost, basePath+"/admin/archive", bytes.NewReader(rw.Body.Bytes())))
+	require.Equal(t, http.StatusBadRequest, importRW.Code)
+	require.Contains(t, importRW.Body.String(), "doesn't match namespace [did:other]")
+}
+
+type mockArchiveStore struct {
+	ops []*batch.Operation
+}
+
+func (m *mockArchiveStore) IterateOperations(fn func(op *batch.Operation) error) error {
+	for _, op := range m.ops {
+		if err := fn(op); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *mockArchiveStore) Get(string) ([]*batch.Operation, error) {
+	return nil, errors.New("not found")
+}
+
+func (m *mockArchiveStore) Put(ops []*batch.Operation) error {
+	m.ops = append(m.ops, ops...)
+	return nil
+}
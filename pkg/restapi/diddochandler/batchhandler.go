@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// BatchUpdateHandler handles the submission of multiple DID operations in a single request
+type BatchUpdateHandler struct {
+	common.HTTPHandler
+}
+
+// NewBatchUpdateHandler returns a new DID document batch update handler
+func NewBatchUpdateHandler(basePath string, processor dochandler.Processor, opts ...dochandler.Option) *BatchUpdateHandler {
+	return &BatchUpdateHandler{
+		HTTPHandler: common.NewHandler(
+			fmt.Sprintf("%s/operations/batch", basePath),
+			http.MethodPost,
+			dochandler.NewBatchUpdateHandler(processor, opts...).Update,
+		),
+	}
+}
@@ -0,0 +1,35 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// DIDConfigurationPath is the well-known path of the DID configuration resource. (Note that the path is
+// relative to the root of the domain rather than to the base path of the DID handlers.)
+const DIDConfigurationPath = "/.well-known/did-configuration.json"
+
+// DIDConfigurationHandler returns the DID configuration resource that links the domain of the node to
+// the DIDs of the node operator
+type DIDConfigurationHandler struct {
+	common.HTTPHandler
+}
+
+// NewDIDConfigurationHandler returns a new DID configuration handler for the given origin (e.g. https://example.com)
+func NewDIDConfigurationHandler(origin string, dids []dochandler.LinkedDID, opts ...dochandler.Option) *DIDConfigurationHandler {
+	return &DIDConfigurationHandler{
+		HTTPHandler: common.NewHandler(
+			DIDConfigurationPath,
+			http.MethodGet,
+			dochandler.NewDIDConfigurationHandler(origin, dids, opts...).DIDConfiguration,
+		),
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+)
+
+func TestDIDConfigurationHandler(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	dids := []dochandler.LinkedDID{
+		{DID: "did:sidetree:suffix", Signer: edsigner.New(privateKey, "EdDSA", "key-1")},
+	}
+
+	handler := NewDIDConfigurationHandler("https://example.com", dids)
+	require.Equal(t, "/.well-known/did-configuration.json", handler.Path())
+	require.Equal(t, http.MethodGet, handler.Method())
+	require.NotNil(t, handler.Handler())
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, DIDConfigurationPath, nil)
+	handler.Handler()(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	config := &dochandler.DIDConfiguration{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), config))
+	require.Equal(t, dochandler.DIDConfigurationContext, config.Context)
+	require.Len(t, config.LinkedDIDs, 1)
+}
@@ -0,0 +1,32 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// ProtocolReloadHandler reloads the protocol parameters (admin endpoint)
+type ProtocolReloadHandler struct {
+	common.HTTPHandler
+}
+
+// NewProtocolReloadHandler returns a new protocol reload handler
+func NewProtocolReloadHandler(basePath string, reloader dochandler.ProtocolReloader, authorizer dochandler.AdminAuthorizer,
+	opts ...dochandler.Option) *ProtocolReloadHandler {
+	return &ProtocolReloadHandler{
+		HTTPHandler: common.NewHandler(
+			fmt.Sprintf("%s/admin/protocol", basePath),
+			http.MethodPost,
+			dochandler.NewProtocolReloadHandler(reloader, authorizer, opts...).Reload,
+		),
+	}
+}
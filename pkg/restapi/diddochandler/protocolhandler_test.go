@@ -0,0 +1,40 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/protocolclient"
+)
+
+func TestProtocolReloadHandler(t *testing.T) {
+	c, err := protocolclient.New([]protocol.Protocol{protocol.Default()})
+	require.NoError(t, err)
+
+	handler := NewProtocolReloadHandler(basePath, c, allowAdmin)
+	require.Equal(t, basePath+"/admin/protocol", handler.Path())
+	require.Equal(t, http.MethodPost, handler.Method())
+	require.NotNil(t, handler.Handler())
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, basePath+"/admin/protocol", bytes.NewReader([]byte(`{"maxOperationsPerBatch": 5}`)))
+	handler.Handler()(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Contains(t, rw.Body.String(), `"maxOperationsPerBatch":5`)
+}
+
+// allowAdmin authorizes all admin requests
+func allowAdmin(*http.Request) error {
+	return nil
+}
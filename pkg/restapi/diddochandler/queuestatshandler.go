@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// QueueStatsHandler returns the statistics of the operation queue (an admin endpoint)
+type QueueStatsHandler struct {
+	common.HTTPHandler
+}
+
+// NewQueueStatsHandler returns a new queue statistics handler
+func NewQueueStatsHandler(basePath string, provider dochandler.QueueStatsProvider, opts ...dochandler.Option) *QueueStatsHandler {
+	return &QueueStatsHandler{
+		HTTPHandler: common.NewHandler(
+			fmt.Sprintf("%s/admin/queue", basePath),
+			http.MethodGet,
+			dochandler.NewQueueStatsHandler(provider, opts...).Stats,
+		),
+	}
+}
@@ -0,0 +1,36 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/batch"
+)
+
+func TestQueueStatsHandler_Stats(t *testing.T) {
+	handler := NewQueueStatsHandler(basePath, &mockQueueStatsProvider{})
+	require.Equal(t, basePath+"/admin/queue", handler.Path())
+	require.Equal(t, http.MethodGet, handler.Method())
+	require.NotNil(t, handler.Handler())
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, basePath+"/admin/queue", nil)
+	handler.Handler()(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Contains(t, rw.Body.String(), `"pending":3`)
+}
+
+type mockQueueStatsProvider struct{}
+
+func (m *mockQueueStatsProvider) QueueStats() (*batch.QueueStats, error) {
+	return &batch.QueueStats{Pending: 3}, nil
+}
@@ -10,21 +10,22 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
 )
 
 // ResolveHandler resolves DID documents
 type ResolveHandler struct {
-	*handler
+	common.HTTPHandler
 }
 
 // NewResolveHandler returns a new DID document resolve handler
-func NewResolveHandler(basePath string, resolver dochandler.Resolver) *ResolveHandler {
+func NewResolveHandler(basePath string, resolver dochandler.Resolver, opts ...dochandler.Option) *ResolveHandler {
 	return &ResolveHandler{
-		handler: newHandler(
+		HTTPHandler: common.NewHandler(
 			fmt.Sprintf("%s/identifiers/{id}", basePath),
 			http.MethodGet,
-			dochandler.NewResolveHandler(resolver).Resolve,
+			dochandler.NewResolveHandler(resolver, opts...).Resolve,
 		),
 	}
 }
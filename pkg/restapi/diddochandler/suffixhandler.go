@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// SuffixHandler checks whether a DID unique suffix is in use
+type SuffixHandler struct {
+	common.HTTPHandler
+}
+
+// NewSuffixHandler returns a new DID unique suffix handler
+func NewSuffixHandler(basePath string, checker dochandler.SuffixChecker, opts ...dochandler.Option) *SuffixHandler {
+	return &SuffixHandler{
+		HTTPHandler: common.NewHandler(
+			fmt.Sprintf("%s/suffixes/{suffix}", basePath),
+			http.MethodGet,
+			dochandler.NewSuffixHandler(checker, opts...).Check,
+		),
+	}
+}
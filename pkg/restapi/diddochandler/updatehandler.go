@@ -10,21 +10,22 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
 )
 
 // UpdateHandler handles the creation and update of DID documents
 type UpdateHandler struct {
-	*handler
+	common.HTTPHandler
 }
 
 // NewUpdateHandler returns a new DID document update handler
-func NewUpdateHandler(basePath string, processor dochandler.Processor) *UpdateHandler {
+func NewUpdateHandler(basePath string, processor dochandler.Processor, opts ...dochandler.Option) *UpdateHandler {
 	return &UpdateHandler{
-		handler: newHandler(
+		HTTPHandler: common.NewHandler(
 			fmt.Sprintf("%s/operations", basePath),
 			http.MethodPost,
-			dochandler.NewUpdateHandler(processor).Update,
+			dochandler.NewUpdateHandler(processor, opts...).Update,
 		),
 	}
 }
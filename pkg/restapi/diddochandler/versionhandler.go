@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// VersionHandler returns the current protocol parameters
+type VersionHandler struct {
+	common.HTTPHandler
+}
+
+// NewVersionHandler returns a new version handler
+func NewVersionHandler(basePath string, provider dochandler.ProtocolProvider, opts ...dochandler.Option) *VersionHandler {
+	return &VersionHandler{
+		HTTPHandler: common.NewHandler(
+			fmt.Sprintf("%s/version", basePath),
+			http.MethodGet,
+			dochandler.NewVersionHandler(provider, opts...).Version,
+		),
+	}
+}
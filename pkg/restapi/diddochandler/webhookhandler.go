@@ -0,0 +1,47 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+)
+
+// WebhookRegisterHandler registers the callback URL of a DID
+type WebhookRegisterHandler struct {
+	common.HTTPHandler
+}
+
+// NewWebhookRegisterHandler returns a new webhook registration handler
+func NewWebhookRegisterHandler(basePath string, registrar dochandler.WebhookRegistrar, opts ...dochandler.Option) *WebhookRegisterHandler {
+	return &WebhookRegisterHandler{
+		HTTPHandler: common.NewHandler(
+			fmt.Sprintf("%s/webhooks", basePath),
+			http.MethodPost,
+			dochandler.NewWebhookHandler(registrar, opts...).Register,
+		),
+	}
+}
+
+// WebhookUnregisterHandler removes the registered callback URL of a DID
+type WebhookUnregisterHandler struct {
+	common.HTTPHandler
+}
+
+// NewWebhookUnregisterHandler returns a new handler that removes webhook registrations
+func NewWebhookUnregisterHandler(basePath string, registrar dochandler.WebhookRegistrar, opts ...dochandler.Option) *WebhookUnregisterHandler {
+	return &WebhookUnregisterHandler{
+		HTTPHandler: common.NewHandler(
+			fmt.Sprintf("%s/webhooks", basePath),
+			http.MethodDelete,
+			dochandler.NewWebhookHandler(registrar, opts...).Unregister,
+		),
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package diddochandler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/webhook"
+)
+
+func TestWebhookRegisterHandler(t *testing.T) {
+	handler := NewWebhookRegisterHandler(basePath, &mockWebhookRegistrar{})
+	require.Equal(t, basePath+"/webhooks", handler.Path())
+	require.Equal(t, http.MethodPost, handler.Method())
+	require.NotNil(t, handler.Handler())
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, basePath+"/webhooks", bytes.NewReader([]byte("request")))
+	handler.Handler()(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Contains(t, rw.Body.String(), `"did_suffix":"suffix"`)
+}
+
+func TestWebhookUnregisterHandler(t *testing.T) {
+	handler := NewWebhookUnregisterHandler(basePath, &mockWebhookRegistrar{})
+	require.Equal(t, basePath+"/webhooks", handler.Path())
+	require.Equal(t, http.MethodDelete, handler.Method())
+	require.NotNil(t, handler.Handler())
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, basePath+"/webhooks", bytes.NewReader([]byte("request")))
+	handler.Handler()(rw, req)
+	require.Equal(t, http.StatusNoContent, rw.Code)
+}
+
+type mockWebhookRegistrar struct{}
+
+func (m *mockWebhookRegistrar) Register([]byte) (*webhook.Registration, error) {
+	return &webhook.Registration{UniqueSuffix: "suffix", CallbackURL: "https://example.com/callback"}, nil
+}
+
+func (m *mockWebhookRegistrar) Unregister([]byte) error {
+	return nil
+}
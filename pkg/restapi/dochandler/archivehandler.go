@@ -0,0 +1,133 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/store/archive"
+)
+
+const defaultMaxArchiveSize = 100 * 1024 * 1024
+
+// OperationExporter exports the stored operations of a namespace (implemented by archive.Exporter)
+type OperationExporter interface {
+	Export() (*archive.Archive, error)
+}
+
+// OperationImporter imports archived operations into the operation store of a namespace
+// (implemented by archive.Importer)
+type OperationImporter interface {
+	Import(a *archive.Archive) (*archive.ImportResult, error)
+}
+
+// ArchiveHandler exports the operation history of a namespace as a hash-verifiable archive and imports
+// an archive that was exported by another node.
+// These are admin endpoints so requests are only processed if they are authorized by the admin authorizer.
+type ArchiveHandler struct {
+	exporter      OperationExporter
+	importer      OperationImporter
+	authorizer    AdminAuthorizer
+	errorMapper   common.ErrorMapper
+	maxSize       int64
+	exportHandler common.HTTPRequestHandler
+	importHandler common.HTTPRequestHandler
+}
+
+// NewArchiveHandler returns a new archive handler. All requests are rejected if the authorizer is nil.
+func NewArchiveHandler(exporter OperationExporter, importer OperationImporter, authorizer AdminAuthorizer,
+	opts ...Option) *ArchiveHandler {
+	options := getOptions(opts...)
+
+	h := &ArchiveHandler{
+		exporter:    exporter,
+		importer:    importer,
+		authorizer:  authorizer,
+		errorMapper: options.ErrorMapper,
+		maxSize:     options.MaxArchiveSize,
+	}
+
+	if h.maxSize <= 0 {
+		h.maxSize = defaultMaxArchiveSize
+	}
+
+	h.exportHandler = common.Chain(h.doExport, options.Middleware...)
+	h.importHandler = common.Chain(h.doImport, options.Middleware...)
+
+	return h
+}
+
+// Export returns an archive of all of the stored operations of the namespace
+func (h *ArchiveHandler) Export(rw http.ResponseWriter, req *http.Request) {
+	h.exportHandler(rw, req)
+}
+
+// Import imports the archive in the request body
+func (h *ArchiveHandler) Import(rw http.ResponseWriter, req *http.Request) {
+	h.importHandler(rw, req)
+}
+
+func (h *ArchiveHandler) doExport(rw http.ResponseWriter, req *http.Request) {
+	if err := authorizeAdmin(h.authorizer, req); err != nil {
+		writeError(rw, h.errorMapper, err)
+		return
+	}
+
+	a, err := h.exporter.Export()
+	if err != nil {
+		logger.Errorf("Failed to export operations: %s", err)
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusInternalServerError, err))
+		return
+	}
+
+	common.WriteResponseWithContentType(rw, http.StatusOK, common.JSONContentType, a)
+}
+
+func (h *ArchiveHandler) doImport(rw http.ResponseWriter, req *http.Request) {
+	if err := authorizeAdmin(h.authorizer, req); err != nil {
+		writeError(rw, h.errorMapper, err)
+		return
+	}
+
+	a, err := archive.Read(http.MaxBytesReader(rw, req.Body, h.maxSize))
+	if err != nil {
+		status := http.StatusBadRequest
+		if isTooLarge(err) {
+			status = http.StatusRequestEntityTooLarge
+		}
+
+		writeError(rw, h.errorMapper, common.NewHTTPError(status, err))
+		return
+	}
+
+	result, err := h.importer.Import(a)
+	if err != nil {
+		logger.Warnf("Failed to import operations: %s", err)
+
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, archive.ErrInvalidArchive) || errors.Is(err, archive.ErrHashMismatch):
+			status = http.StatusBadRequest
+		case errors.Is(err, archive.ErrUntrusted):
+			status = http.StatusForbidden
+		}
+
+		writeError(rw, h.errorMapper, common.NewHTTPError(status, err))
+		return
+	}
+
+	common.WriteResponseWithContentType(rw, http.StatusOK, common.JSONContentType, result)
+}
+
+// isTooLarge returns true if the error was returned by the reader of http.MaxBytesReader
+func isTooLarge(err error) bool {
+	return strings.Contains(err.Error(), "http: request body too large")
+}
@@ -0,0 +1,210 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/store/archive"
+)
+
+func TestArchiveHandler_Export(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		a := newArchive(t)
+
+		rw := httptest.NewRecorder()
+		NewArchiveHandler(&mockExporter{archive: a}, nil, allowAdmin).Export(rw, httptest.NewRequest(http.MethodGet, "/admin/archive", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("content-type"))
+
+		exported, err := archive.Read(rw.Body)
+		require.NoError(t, err)
+		require.Equal(t, a.Hash, exported.Hash)
+	})
+
+	t.Run("export error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		NewArchiveHandler(&mockExporter{err: errors.New("export error")}, nil, allowAdmin).
+			Export(rw, httptest.NewRequest(http.MethodGet, "/admin/archive", nil))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		require.Contains(t, rw.Body.String(), "export error")
+	})
+}
+
+func TestArchiveHandler_Import(t *testing.T) {
+	a := newArchive(t)
+
+	archiveBytes, err := json.Marshal(a)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		importer := &mockImporter{}
+
+		rw := httptest.NewRecorder()
+		NewArchiveHandler(nil, importer, allowAdmin).Import(rw, httptest.NewRequest(http.MethodPost, "/admin/archive", bytes.NewReader(archiveBytes)))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("content-type"))
+		require.Equal(t, a.Hash, importer.archive.Hash)
+
+		result := &archive.ImportResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+		require.Equal(t, 1, result.Operations)
+	})
+
+	t.Run("invalid archive", func(t *testing.T) {
+		importer := &mockImporter{}
+
+		tampered := strings.Replace(string(archiveBytes), `"transactionTime":1`, `"transactionTime":2`, 1)
+
+		rw := httptest.NewRecorder()
+		NewArchiveHandler(nil, importer, allowAdmin).Import(rw, httptest.NewRequest(http.MethodPost, "/admin/archive", strings.NewReader(tampered)))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "archive hash doesn't match")
+		require.Nil(t, importer.archive)
+	})
+
+	t.Run("archive too large", func(t *testing.T) {
+		importer := &mockImporter{}
+
+		rw := httptest.NewRecorder()
+		NewArchiveHandler(nil, importer, allowAdmin, WithMaxArchiveSize(int64(len(archiveBytes)-1))).
+			Import(rw, httptest.NewRequest(http.MethodPost, "/admin/archive", bytes.NewReader(archiveBytes)))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+		require.Nil(t, importer.archive)
+	})
+
+	t.Run("import error", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+		}{
+			{err: fmt.Errorf("%w: operation [0]", archive.ErrInvalidArchive), status: http.StatusBadRequest},
+			{err: archive.ErrHashMismatch, status: http.StatusBadRequest},
+			{err: fmt.Errorf("%w: archive is not signed", archive.ErrUntrusted), status: http.StatusForbidden},
+			{err: errors.New("store error"), status: http.StatusInternalServerError},
+		}
+
+		for _, tc := range tests {
+			rw := httptest.NewRecorder()
+			NewArchiveHandler(nil, &mockImporter{err: tc.err}, allowAdmin).
+				Import(rw, httptest.NewRequest(http.MethodPost, "/admin/archive", bytes.NewReader(archiveBytes)))
+			require.Equal(t, tc.status, rw.Code)
+			require.Contains(t, rw.Body.String(), tc.err.Error())
+		}
+	})
+}
+
+func TestArchiveHandler_Authorization(t *testing.T) {
+	authorizer := NewTokenAdminAuthorizer(func(token string) error {
+		if token != "admin" {
+			return errors.New("invalid token")
+		}
+
+		return nil
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		exporter := &mockExporter{archive: newArchive(t)}
+		importer := &mockImporter{}
+
+		h := NewArchiveHandler(exporter, importer, authorizer)
+
+		rw := httptest.NewRecorder()
+		h.Export(rw, httptest.NewRequest(http.MethodGet, "/admin/archive", nil))
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+		require.Contains(t, rw.Body.String(), unauthorizedCode)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/archive", strings.NewReader("{}"))
+		req.Header.Set("Authorization", "Bearer other")
+
+		rw = httptest.NewRecorder()
+		h.Import(rw, req)
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+		require.Nil(t, importer.archive)
+	})
+
+	t.Run("authorized", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/archive", nil)
+		req.Header.Set("Authorization", "Bearer admin")
+
+		rw := httptest.NewRecorder()
+		NewArchiveHandler(&mockExporter{archive: newArchive(t)}, nil, authorizer).Export(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+	})
+
+	t.Run("no authorizer", func(t *testing.T) {
+		importer := &mockImporter{}
+
+		rw := httptest.NewRecorder()
+		NewArchiveHandler(nil, importer, nil).Import(rw, httptest.NewRequest(http.MethodPost, "/admin/archive", strings.NewReader("{}")))
+		require.Equal(t, http.StatusForbidden, rw.Code)
+		require.Contains(t, rw.Body.String(), "admin authorizer is not configured")
+		require.Nil(t, importer.archive)
+	})
+}
+
+// allowAdmin authorizes all admin requests
+func allowAdmin(*http.Request) error {
+	return nil
+}
+
+func newArchive(t *testing.T) *archive.Archive {
+	a, err := archive.NewExporter(namespace, &mockOperationIterator{ops: []*batch.Operation{
+		{UniqueSuffix: "suffix", Type: batch.OperationTypeCreate, TransactionTime: 1, OperationBuffer: []byte("{}")},
+	}}).Export()
+	require.NoError(t, err)
+
+	return a
+}
+
+type mockOperationIterator struct {
+	ops []*batch.Operation
+}
+
+func (m *mockOperationIterator) IterateOperations(fn func(op *batch.Operation) error) error {
+	for _, op := range m.ops {
+		if err := fn(op); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type mockExporter struct {
+	archive *archive.Archive
+	err     error
+}
+
+func (m *mockExporter) Export() (*archive.Archive, error) {
+	return m.archive, m.err
+}
+
+type mockImporter struct {
+	archive *archive.Archive
+	err     error
+}
+
+func (m *mockImporter) Import(a *archive.Archive) (*archive.ImportResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.archive = a
+
+	return &archive.ImportResult{Operations: len(a.Operations), Documents: 1}, nil
+}
@@ -0,0 +1,280 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/verifier"
+)
+
+const (
+	// RequesterDIDHeader is the header that carries the DID of the requester
+	RequesterDIDHeader = "X-Requester-DID"
+
+	// RequesterProofHeader is the header that carries the requester's proof of control of its DID
+	RequesterProofHeader = "X-Requester-Proof"
+)
+
+const (
+	unauthorizedCode = "unauthorized"
+	forbiddenCode    = "forbidden"
+)
+
+var (
+	// ErrUnauthorized is returned by an authorizer if the requester could not be authenticated (401)
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden is returned by an authorizer if the requester is not allowed to submit the operation (403)
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Authorizer is consulted by the update handler before a create/update/recover/deactivate operation is accepted.
+// Authorize should return an error that wraps ErrUnauthorized or ErrForbidden if the request is rejected;
+// any other error results in 500 (Internal Server Error).
+type Authorizer interface {
+	Authorize(req *http.Request, operation *batch.Operation) error
+}
+
+// AuthorizerFunc is a function that implements Authorizer
+type AuthorizerFunc func(req *http.Request, operation *batch.Operation) error
+
+// Authorize authorizes the operation
+func (f AuthorizerFunc) Authorize(req *http.Request, operation *batch.Operation) error {
+	return f(req, operation)
+}
+
+// NewTokenAuthorizer returns an authorizer that validates the bearer token in the Authorization header
+func NewTokenAuthorizer(validate common.TokenValidator) Authorizer {
+	return AuthorizerFunc(func(req *http.Request, _ *batch.Operation) error {
+		token, err := common.BearerToken(req)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrUnauthorized, err)
+		}
+
+		if err := validate(token); err != nil {
+			return fmt.Errorf("%w: invalid token", ErrUnauthorized)
+		}
+
+		return nil
+	})
+}
+
+// DIDResolver resolves the DID document of a requester
+type DIDResolver interface {
+	ResolveDocument(id string) (*document.ResolutionResult, error)
+}
+
+// RequesterProof is the signed payload that proves that the requester controls its DID
+type RequesterProof struct {
+	// DID is the DID of the requester
+	DID string `json:"did"`
+
+	// OperationHash is the encoded multihash of the operation request. For operations that are submitted
+	// in a batch it is the multihash of the entire batch request body.
+	OperationHash string `json:"operationHash"`
+}
+
+// NewDIDAllowlistAuthorizer returns an authorizer that only accepts operations from the given DIDs.
+//
+// The requester provides its DID in the X-Requester-DID header and proves that it controls the DID in the
+// X-Requester-Proof header. The proof is a compact JWS of a RequesterProof that is signed by an operations key
+// of the requester's DID document (the 'kid' protected header is the ID of the key). Since the proof contains
+// the hash of the operation request, it can't be used to submit any other operation. A batch submission carries
+// a single proof over the entire batch request body which authorizes each of the operations in the batch.
+func NewDIDAllowlistAuthorizer(resolver DIDResolver, allowed ...string) Authorizer {
+	allowlist := make(map[string]struct{}, len(allowed))
+	for _, did := range allowed {
+		allowlist[did] = struct{}{}
+	}
+
+	return AuthorizerFunc(func(req *http.Request, operation *batch.Operation) error {
+		did := req.Header.Get(RequesterDIDHeader)
+		if did == "" {
+			return fmt.Errorf("%w: missing requester DID", ErrUnauthorized)
+		}
+
+		if _, ok := allowlist[did]; !ok {
+			return fmt.Errorf("%w: requester [%s] is not allowed to submit %s operations", ErrForbidden, did, operation.Type)
+		}
+
+		return verifyRequesterProof(resolver, did, req.Header.Get(RequesterProofHeader), signedRequest(req, operation))
+	})
+}
+
+type batchRequestKey struct{}
+
+// withBatchRequest returns a copy of the request whose context holds the body of the batch submission
+func withBatchRequest(req *http.Request, body []byte) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), batchRequestKey{}, body))
+}
+
+// signedRequest returns the content that is covered by the requester proof: the batch request body if the
+// operation was submitted in a batch, otherwise the operation request
+func signedRequest(req *http.Request, operation *batch.Operation) []byte {
+	if body, ok := req.Context().Value(batchRequestKey{}).([]byte); ok {
+		return body
+	}
+
+	return operation.OperationBuffer
+}
+
+// verifyRequesterProof verifies that the proof was signed by an operations key of the DID and covers the request
+func verifyRequesterProof(resolver DIDResolver, did, proof string, request []byte) error {
+	if proof == "" {
+		return fmt.Errorf("%w: missing proof of control of requester DID", ErrUnauthorized)
+	}
+
+	kid, err := keyID(proof)
+	if err != nil {
+		return fmt.Errorf("%w: invalid requester proof: %s", ErrUnauthorized, err)
+	}
+
+	result, err := resolver.ResolveDocument(did)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return fmt.Errorf("%w: requester DID [%s] not found", ErrUnauthorized, did)
+		}
+
+		return fmt.Errorf("failed to resolve requester DID [%s]: %w", did, err)
+	}
+
+	jwk, err := document.GetOperationsKey(result.Document, kid)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnauthorized, err)
+	}
+
+	payload, err := verifier.VerifyJWS(proof, jwk)
+	if err != nil {
+		return fmt.Errorf("%w: invalid requester proof: %s", ErrUnauthorized, err)
+	}
+
+	decoded, err := docutil.DecodeString(string(payload))
+	if err != nil {
+		return fmt.Errorf("%w: invalid requester proof payload: %s", ErrUnauthorized, err)
+	}
+
+	p := &RequesterProof{}
+	if err := json.Unmarshal(decoded, p); err != nil {
+		return fmt.Errorf("%w: invalid requester proof payload: %s", ErrUnauthorized, err)
+	}
+
+	if p.DID != did {
+		return fmt.Errorf("%w: requester proof was issued for a different DID", ErrUnauthorized)
+	}
+
+	code, err := docutil.GetMultihashCode(p.OperationHash)
+	if err != nil {
+		return fmt.Errorf("%w: invalid operation hash in requester proof: %s", ErrUnauthorized, err)
+	}
+
+	hash, err := docutil.ComputeMultihash(uint(code), request)
+	if err != nil {
+		return fmt.Errorf("%w: invalid operation hash in requester proof: %s", ErrUnauthorized, err)
+	}
+
+	if docutil.EncodeToString(hash) != p.OperationHash {
+		return fmt.Errorf("%w: requester proof doesn't match the operation request", ErrUnauthorized)
+	}
+
+	return nil
+}
+
+// keyID returns the 'kid' protected header of the given compact JWS
+func keyID(compactJWS string) (string, error) {
+	parts := strings.Split(compactJWS, ".")
+	if len(parts) != 3 {
+		return "", errors.New("not a compact JWS")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("decode protected header: %s", err)
+	}
+
+	var headers jws.Headers
+	if err := json.Unmarshal(headerBytes, &headers); err != nil {
+		return "", fmt.Errorf("unmarshal protected header: %s", err)
+	}
+
+	kid, ok := headers.KeyID()
+	if !ok || kid == "" {
+		return "", errors.New("missing kid in protected header")
+	}
+
+	return kid, nil
+}
+
+// AdminAuthorizer is consulted by the admin handlers (protocol reload, archive export and import) before the
+// request is processed. It should return an error that wraps ErrUnauthorized or ErrForbidden if the request
+// is rejected; any other error results in 500 (Internal Server Error).
+type AdminAuthorizer func(req *http.Request) error
+
+// NewTokenAdminAuthorizer returns an admin authorizer that validates the bearer token in the Authorization header
+func NewTokenAdminAuthorizer(validate common.TokenValidator) AdminAuthorizer {
+	authorizer := NewTokenAuthorizer(validate)
+
+	return func(req *http.Request) error {
+		return authorizer.Authorize(req, nil)
+	}
+}
+
+// authorizeAdmin authorizes an admin request. All requests are rejected if the authorizer is nil.
+func authorizeAdmin(authorizer AdminAuthorizer, req *http.Request) *common.HTTPError {
+	if authorizer == nil {
+		return common.NewHTTPErrorWithCode(http.StatusForbidden, forbiddenCode,
+			fmt.Errorf("%w: admin authorizer is not configured", ErrForbidden))
+	}
+
+	err := authorizer(req)
+	if err == nil {
+		return nil
+	}
+
+	logger.Infof("Admin request [%s %s] not authorized: %s", req.Method, req.URL.Path, err)
+
+	return authorizationError(err)
+}
+
+// authorizationError maps the error returned by an authorizer to the HTTP error
+func authorizationError(err error) *common.HTTPError {
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return common.NewHTTPErrorWithCode(http.StatusUnauthorized, unauthorizedCode, err)
+	case errors.Is(err, ErrForbidden):
+		return common.NewHTTPErrorWithCode(http.StatusForbidden, forbiddenCode, err)
+	default:
+		return common.NewHTTPError(http.StatusInternalServerError, err)
+	}
+}
+
+// DebugAuthorizer decides whether the requester (typically an administrator) may see debugging information
+// such as the operations that were skipped during resolution
+type DebugAuthorizer func(req *http.Request) bool
+
+// NewTokenDebugAuthorizer returns a debug authorizer that validates the bearer token in the Authorization header
+func NewTokenDebugAuthorizer(validate common.TokenValidator) DebugAuthorizer {
+	return func(req *http.Request) bool {
+		token, err := common.BearerToken(req)
+		if err != nil {
+			return false
+		}
+
+		return validate(token) == nil
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/signutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+func TestNewTokenAuthorizer(t *testing.T) {
+	authorizer := NewTokenAuthorizer(func(token string) error {
+		if token != "secret" {
+			return errors.New("invalid token")
+		}
+		return nil
+	})
+
+	op := &batch.Operation{Type: batch.OperationTypeUpdate}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/document", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		require.NoError(t, authorizer.Authorize(req, op))
+	})
+	t.Run("missing token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/document", nil)
+		err := authorizer.Authorize(req, op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "missing authorization header")
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/document", nil)
+		req.Header.Set("Authorization", "Bearer other")
+		err := authorizer.Authorize(req, op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "invalid token")
+	})
+}
+
+func TestNewDIDAllowlistAuthorizer(t *testing.T) {
+	const (
+		did = "did:example:123"
+		kid = "key-1"
+	)
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	resolver := newMockDIDResolver(t, privateKey, kid, "ops")
+	authorizer := NewDIDAllowlistAuthorizer(resolver, did)
+
+	request := []byte(`{"type":"create"}`)
+	op := &batch.Operation{Type: batch.OperationTypeCreate, OperationBuffer: request}
+
+	newReq := func(did, proof string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/document", nil)
+		req.Header.Set(RequesterDIDHeader, did)
+		req.Header.Set(RequesterProofHeader, proof)
+
+		return req
+	}
+
+	t.Run("success", func(t *testing.T) {
+		proof := newRequesterProof(t, privateKey, kid, did, request)
+		require.NoError(t, authorizer.Authorize(newReq(did, proof), op))
+	})
+	t.Run("missing DID", func(t *testing.T) {
+		err := authorizer.Authorize(newReq("", ""), op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "missing requester DID")
+	})
+	t.Run("DID not allowed", func(t *testing.T) {
+		err := authorizer.Authorize(newReq("did:example:456", ""), op)
+		require.True(t, errors.Is(err, ErrForbidden))
+		require.Contains(t, err.Error(), "requester [did:example:456] is not allowed to submit create operations")
+	})
+	t.Run("missing proof", func(t *testing.T) {
+		err := authorizer.Authorize(newReq(did, ""), op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "missing proof of control of requester DID")
+	})
+	t.Run("invalid proof", func(t *testing.T) {
+		err := authorizer.Authorize(newReq(did, "proof"), op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "invalid requester proof: not a compact JWS")
+	})
+	t.Run("signed by another key", func(t *testing.T) {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		proof := newRequesterProof(t, otherKey, kid, did, request)
+
+		err = authorizer.Authorize(newReq(did, proof), op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "invalid requester proof")
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		proof := newRequesterProof(t, privateKey, "key-2", did, request)
+
+		err := authorizer.Authorize(newReq(did, proof), op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "signing public key [key-2] not found")
+	})
+	t.Run("not an operations key", func(t *testing.T) {
+		proof := newRequesterProof(t, privateKey, kid, did, request)
+
+		err := NewDIDAllowlistAuthorizer(newMockDIDResolver(t, privateKey, kid, "general"), did).Authorize(newReq(did, proof), op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "is not an operations key")
+	})
+	t.Run("proof for another DID", func(t *testing.T) {
+		proof := newRequesterProof(t, privateKey, kid, "did:example:456", request)
+
+		err := authorizer.Authorize(newReq(did, proof), op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "requester proof was issued for a different DID")
+	})
+	t.Run("proof for another operation", func(t *testing.T) {
+		proof := newRequesterProof(t, privateKey, kid, did, []byte(`{"type":"update"}`))
+
+		err := authorizer.Authorize(newReq(did, proof), op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "requester proof doesn't match the operation request")
+	})
+	t.Run("DID not found", func(t *testing.T) {
+		proof := newRequesterProof(t, privateKey, kid, did, request)
+
+		err := NewDIDAllowlistAuthorizer(&mockDIDResolver{err: errors.New("not found")}, did).Authorize(newReq(did, proof), op)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "requester DID [did:example:123] not found")
+	})
+	t.Run("resolver error", func(t *testing.T) {
+		proof := newRequesterProof(t, privateKey, kid, did, request)
+
+		err := NewDIDAllowlistAuthorizer(&mockDIDResolver{err: errors.New("resolver error")}, did).Authorize(newReq(did, proof), op)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "failed to resolve requester DID [did:example:123]: resolver error")
+	})
+}
+
+func TestNewTokenDebugAuthorizer(t *testing.T) {
+	authorize := NewTokenDebugAuthorizer(func(token string) error {
+		if token != "secret" {
+			return errors.New("invalid token")
+		}
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/document", nil)
+	require.False(t, authorize(req))
+
+	req.Header.Set("Authorization", "Bearer other")
+	require.False(t, authorize(req))
+
+	req.Header.Set("Authorization", "Bearer secret")
+	require.True(t, authorize(req))
+}
+
+func newRequesterProof(t *testing.T, privateKey *ecdsa.PrivateKey, kid, did string, request []byte) string {
+	hash, err := docutil.ComputeMultihash(sha2_256, request)
+	require.NoError(t, err)
+
+	proof, err := signutil.SignModel(&RequesterProof{
+		DID:           did,
+		OperationHash: docutil.EncodeToString(hash),
+	}, ecsigner.New(privateKey, "ES256", kid))
+	require.NoError(t, err)
+
+	return proof.Signature
+}
+
+type mockDIDResolver struct {
+	doc document.Document
+	err error
+}
+
+func newMockDIDResolver(t *testing.T, privateKey *ecdsa.PrivateKey, kid, usage string) *mockDIDResolver {
+	jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	return &mockDIDResolver{
+		doc: document.Document{
+			"publicKey": []interface{}{
+				map[string]interface{}{
+					"id":    kid,
+					"type":  "JwsVerificationKey2020",
+					"usage": []interface{}{usage},
+					"jwk": map[string]interface{}{
+						"kty": jwk.Kty,
+						"crv": jwk.Crv,
+						"x":   jwk.X,
+						"y":   jwk.Y,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (m *mockDIDResolver) ResolveDocument(string) (*document.ResolutionResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &document.ResolutionResult{Document: m.doc}, nil
+}
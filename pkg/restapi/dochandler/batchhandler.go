@@ -0,0 +1,163 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+const (
+	defaultMaxBatchRequests = 1000
+
+	batchTooLargeCode = "batch_too_large"
+)
+
+// BatchResult is the result of a single operation request within a batch submission
+type BatchResult struct {
+	// Index is the index of the operation request within the batch
+	Index int `json:"index"`
+
+	// ID is the ID of the document if the operation was accepted
+	ID string `json:"id,omitempty"`
+
+	// UniqueSuffix is the unique suffix of the document if the operation was accepted
+	UniqueSuffix string `json:"uniqueSuffix,omitempty"`
+
+	// Receipt is the node-signed receipt for the accepted operation (only if receipts are enabled)
+	Receipt string `json:"receipt,omitempty"`
+
+	// Status is the HTTP status that would have been returned had the operation been submitted on its own
+	Status int `json:"status"`
+
+	// Warning is the warning that would have been returned in the Warning header had the operation been
+	// submitted on its own (e.g. if the operation uses deprecated features)
+	Warning string `json:"warning,omitempty"`
+
+	// Error is set if the operation was rejected
+	Error *common.ErrorResponse `json:"error,omitempty"`
+}
+
+// BatchUpdateHandler accepts an array of operation requests in a single request. Each operation is validated
+// and processed independently (exactly as if it had been submitted on its own) and a result is returned for
+// each operation, so that the rejection of one operation doesn't affect the others.
+type BatchUpdateHandler struct {
+	update      *UpdateHandler
+	errorMapper common.ErrorMapper
+	maxRequests int
+	generic     bool
+	handler     common.HTTPRequestHandler
+}
+
+// NewBatchUpdateHandler returns a new batch update handler
+func NewBatchUpdateHandler(processor Processor, opts ...Option) *BatchUpdateHandler {
+	options := getOptions(opts...)
+
+	maxRequests := options.MaxBatchRequests
+	if maxRequests <= 0 {
+		maxRequests = defaultMaxBatchRequests
+	}
+
+	h := &BatchUpdateHandler{
+		update:      NewUpdateHandler(processor, opts...),
+		errorMapper: options.ErrorMapper,
+		maxRequests: maxRequests,
+		generic:     options.GenericDocuments,
+	}
+
+	h.handler = common.Chain(h.updateBatch, options.Middleware...)
+
+	return h
+}
+
+// Update creates or updates the documents of the operations in the batch
+func (h *BatchUpdateHandler) Update(rw http.ResponseWriter, req *http.Request) {
+	h.handler(rw, req)
+}
+
+func (h *BatchUpdateHandler) updateBatch(rw http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusBadRequest, err))
+		return
+	}
+
+	var requests []json.RawMessage
+	if err := json.Unmarshal(body, &requests); err != nil {
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusBadRequest,
+			fmt.Errorf("expecting an array of operation requests: %s", err)))
+		return
+	}
+
+	if len(requests) == 0 {
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusBadRequest, errors.New("no operation requests")))
+		return
+	}
+
+	if len(requests) > h.maxRequests {
+		writeError(rw, h.errorMapper, common.NewHTTPErrorWithCode(http.StatusRequestEntityTooLarge, batchTooLargeCode,
+			fmt.Errorf("number of operation requests [%d] exceeds the maximum [%d]", len(requests), h.maxRequests)))
+		return
+	}
+
+	// a requester proof covers the entire batch
+	req = withBatchRequest(req, body)
+
+	results := make([]*BatchResult, len(requests))
+
+	for i, request := range requests {
+		results[i] = h.process(req, i, request)
+	}
+
+	writeResponse(rw, h.generic, results)
+}
+
+func (h *BatchUpdateHandler) process(req *http.Request, index int, request []byte) *BatchResult {
+	op, _, receipt, err := h.update.doUpdate(req, request)
+	if err != nil {
+		mappedErr := h.errorMapper(err.(*common.HTTPError))
+
+		logger.Infof("Operation request at index %d of batch was rejected: %s", index, mappedErr)
+
+		return &BatchResult{
+			Index:  index,
+			Status: mappedErr.Status(),
+			Error:  &common.ErrorResponse{Code: errorCode(mappedErr), Message: mappedErr.Error()},
+		}
+	}
+
+	result := &BatchResult{
+		Index:        index,
+		ID:           op.ID,
+		UniqueSuffix: op.UniqueSuffix,
+		Receipt:      receipt,
+		Status:       http.StatusOK,
+	}
+
+	if operation.UsesLegacyRevealValues(op) {
+		result.Warning = legacyRevealValuesWarning
+	}
+
+	return result
+}
+
+// errorCode returns the code of the error or, if the error doesn't have a code, a code derived from
+// the HTTP status (e.g. bad_request)
+func errorCode(err *common.HTTPError) string {
+	if err.Code() != "" {
+		return err.Code()
+	}
+
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(err.Status())), " ", "_")
+}
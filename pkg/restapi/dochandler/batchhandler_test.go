@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+)
+
+func TestBatchUpdateHandler_Update(t *testing.T) {
+	create1, err := helper.NewCreateRequest(getCreateRequestInfo())
+	require.NoError(t, err)
+
+	create2, err := helper.NewCreateRequest(getCreateRequestInfo())
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+		handler := NewBatchUpdateHandler(docHandler)
+
+		results := submitBatch(t, handler, http.StatusOK, fmt.Sprintf(`[%s,%s,%s]`, create1, getUnsupportedRequest(), create2))
+		require.Len(t, results, 3)
+
+		require.Equal(t, 0, results[0].Index)
+		require.Equal(t, http.StatusOK, results[0].Status)
+		require.NotEmpty(t, results[0].UniqueSuffix)
+		require.Equal(t, namespace+":"+results[0].UniqueSuffix, results[0].ID)
+		require.Nil(t, results[0].Error)
+
+		require.Equal(t, 1, results[1].Index)
+		require.Equal(t, http.StatusBadRequest, results[1].Status)
+		require.Empty(t, results[1].ID)
+		require.NotNil(t, results[1].Error)
+		require.Equal(t, "bad_request", results[1].Error.Code)
+		require.Contains(t, results[1].Error.Message, "not implemented")
+
+		require.Equal(t, 2, results[2].Index)
+		require.Equal(t, http.StatusOK, results[2].Status)
+		require.NotEmpty(t, results[2].UniqueSuffix)
+		require.NotEqual(t, results[0].UniqueSuffix, results[2].UniqueSuffix)
+	})
+
+	t.Run("structured error", func(t *testing.T) {
+		docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+		handler := NewBatchUpdateHandler(docHandler, WithAuthorizer(AuthorizerFunc(
+			func(*http.Request, *batch.Operation) error {
+				return ErrForbidden
+			})))
+
+		results := submitBatch(t, handler, http.StatusOK, fmt.Sprintf(`[%s]`, create1))
+		require.Len(t, results, 1)
+		require.Equal(t, http.StatusForbidden, results[0].Status)
+		require.Equal(t, forbiddenCode, results[0].Error.Code)
+	})
+
+	t.Run("DID allowlist", func(t *testing.T) {
+		const (
+			did = "did:example:123"
+			kid = "key-1"
+		)
+
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+		handler := NewBatchUpdateHandler(docHandler,
+			WithAuthorizer(NewDIDAllowlistAuthorizer(newMockDIDResolver(t, privateKey, kid, "ops"), did)))
+
+		body := []byte(fmt.Sprintf(`[%s,%s]`, create1, create2))
+
+		submit := func(proof string) []*BatchResult {
+			req := httptest.NewRequest(http.MethodPost, "/operations/batch", bytes.NewReader(body))
+			req.Header.Set(RequesterDIDHeader, did)
+			req.Header.Set(RequesterProofHeader, proof)
+
+			rw := httptest.NewRecorder()
+			handler.Update(rw, req)
+			require.Equal(t, http.StatusOK, rw.Code)
+
+			var results []*BatchResult
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &results))
+
+			return results
+		}
+
+		// the proof covers the entire batch
+		results := submit(newRequesterProof(t, privateKey, kid, did, body))
+		require.Len(t, results, 2)
+		require.Equal(t, http.StatusOK, results[0].Status)
+		require.Equal(t, http.StatusOK, results[1].Status)
+
+		// a proof for a single operation doesn't authorize the batch
+		results = submit(newRequesterProof(t, privateKey, kid, did, create1))
+		require.Len(t, results, 2)
+		require.Equal(t, http.StatusUnauthorized, results[0].Status)
+		require.Equal(t, unauthorizedCode, results[0].Error.Code)
+		require.Equal(t, http.StatusUnauthorized, results[1].Status)
+	})
+
+	t.Run("error mapper", func(t *testing.T) {
+		docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+		handler := NewBatchUpdateHandler(docHandler, WithErrorMapper(func(err *common.HTTPError) *common.HTTPError {
+			return common.NewHTTPErrorWithCode(http.StatusUnprocessableEntity, "custom", err)
+		}))
+
+		results := submitBatch(t, handler, http.StatusOK, fmt.Sprintf(`[%s]`, getUnsupportedRequest()))
+		require.Len(t, results, 1)
+		require.Equal(t, http.StatusUnprocessableEntity, results[0].Status)
+		require.Equal(t, "custom", results[0].Error.Code)
+	})
+
+	t.Run("legacy reveal values", func(t *testing.T) {
+		pc := mocks.NewMockProtocolClient()
+		pc.Protocol.LegacyRevealValues = true
+
+		handler := NewBatchUpdateHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(pc))
+
+		results := submitBatch(t, handler, http.StatusOK, fmt.Sprintf(`[%s,%s]`, getLegacyCreateRequest(t), create1))
+		require.Len(t, results, 2)
+		require.Equal(t, http.StatusOK, results[0].Status)
+		require.Equal(t, legacyRevealValuesWarning, results[0].Warning)
+		require.Equal(t, http.StatusOK, results[1].Status)
+		require.Empty(t, results[1].Warning)
+	})
+
+	t.Run("processor error", func(t *testing.T) {
+		docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace).WithError(errors.New("processor error"))
+		handler := NewBatchUpdateHandler(docHandler)
+
+		results := submitBatch(t, handler, http.StatusOK, fmt.Sprintf(`[%s]`, create1))
+		require.Len(t, results, 1)
+		require.Equal(t, http.StatusInternalServerError, results[0].Status)
+		require.Equal(t, "internal_server_error", results[0].Error.Code)
+	})
+
+	t.Run("not an array", func(t *testing.T) {
+		handler := NewBatchUpdateHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace))
+
+		rw := httptest.NewRecorder()
+		handler.Update(rw, httptest.NewRequest(http.MethodPost, "/operations/batch", bytes.NewReader(create1)))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "expecting an array of operation requests")
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		handler := NewBatchUpdateHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace))
+
+		rw := httptest.NewRecorder()
+		handler.Update(rw, httptest.NewRequest(http.MethodPost, "/operations/batch", bytes.NewReader([]byte("[]"))))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "no operation requests")
+	})
+
+	t.Run("batch too large", func(t *testing.T) {
+		handler := NewBatchUpdateHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace), WithMaxBatchRequests(1))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/operations/batch", bytes.NewReader([]byte(fmt.Sprintf(`[%s,%s]`, create1, create2))))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+
+		var errResp common.ErrorResponse
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &errResp))
+		require.Equal(t, batchTooLargeCode, errResp.Code)
+		require.Contains(t, errResp.Message, "exceeds the maximum [1]")
+	})
+}
+
+func submitBatch(t *testing.T, handler *BatchUpdateHandler, expectedStatus int, body string) []*BatchResult {
+	rw := httptest.NewRecorder()
+	handler.Update(rw, httptest.NewRequest(http.MethodPost, "/operations/batch", bytes.NewReader([]byte(body))))
+	require.Equal(t, expectedStatus, rw.Code)
+
+	var results []*BatchResult
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &results))
+
+	return results
+}
@@ -0,0 +1,52 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// DeactivateConfirmationHeader is the request header that confirms deactivate operations if the protocol requires
+// confirmation (see protocol.Protocol.DeactivateConfirmation). The header contains the DID suffix of the deactivated
+// document (or a comma-separated list of DID suffixes for batch submissions).
+const DeactivateConfirmationHeader = "Sidetree-Deactivate-Confirmation"
+
+// confirmationRequiredCode is the code of the structured error that is returned for deactivate operations that
+// were not confirmed
+const confirmationRequiredCode = "confirmation_required"
+
+func (h *UpdateHandler) checkDeactivateConfirmation(req *http.Request, operation *batch.Operation) *common.HTTPError {
+	if operation.Type != batch.OperationTypeDeactivate {
+		return nil
+	}
+
+	pv, err := h.processor.Protocol().Current()
+	if err != nil {
+		return common.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if !pv.Protocol().DeactivateConfirmation {
+		return nil
+	}
+
+	for _, suffix := range strings.Split(req.Header.Get(DeactivateConfirmationHeader), ",") {
+		if strings.TrimSpace(suffix) == operation.UniqueSuffix {
+			return nil
+		}
+	}
+
+	logger.Infof("deactivate operation for [%s] rejected since it wasn't confirmed", operation.UniqueSuffix)
+
+	return common.NewHTTPErrorWithCode(http.StatusPreconditionRequired, confirmationRequiredCode,
+		fmt.Errorf("deactivate operation for [%s] must be confirmed with the %s header",
+			operation.UniqueSuffix, DeactivateConfirmationHeader))
+}
@@ -0,0 +1,237 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	internaljws "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+const (
+	// DIDConfigurationContext is the JSON-LD context of the DID configuration resource and the domain linkage credential
+	DIDConfigurationContext = "https://identity.foundation/.well-known/did-configuration/v1"
+
+	credentialsContext      = "https://www.w3.org/2018/credentials/v1"
+	verifiableCredential    = "VerifiableCredential"
+	domainLinkageCredential = "DomainLinkageCredential"
+
+	didPrefix = "did:"
+
+	defaultDomainLinkageValidity = 365 * 24 * time.Hour
+)
+
+// LinkedDID is a DID controlled by the node operator that is linked to the domain of the node
+type LinkedDID struct {
+	DID string
+
+	// Signer signs the domain linkage credential of the DID. The signing key must be a key of the DID document.
+	// The key ID (kid) header should contain the DID URL of the key; a key ID that isn't a DID URL
+	// (e.g. "key-1" or "#key-1") is qualified with the DID.
+	Signer Signer
+}
+
+// DIDConfiguration is the DID configuration resource (/.well-known/did-configuration.json) of a domain
+type DIDConfiguration struct {
+	Context    string   `json:"@context"`
+	LinkedDIDs []string `json:"linked_dids"`
+}
+
+// DomainLinkageClaims contains the claims of a domain linkage credential (JWT)
+type DomainLinkageClaims struct {
+	Issuer     string               `json:"iss"`
+	Subject    string               `json:"sub"`
+	NotBefore  int64                `json:"nbf"`
+	Expiration int64                `json:"exp"`
+	Credential *DomainLinkageVCData `json:"vc"`
+}
+
+// DomainLinkageVCData is the verifiable credential of the domain linkage credential
+type DomainLinkageVCData struct {
+	Context           []string                  `json:"@context"`
+	Issuer            string                    `json:"issuer"`
+	IssuanceDate      string                    `json:"issuanceDate"`
+	ExpirationDate    string                    `json:"expirationDate"`
+	Type              []string                  `json:"type"`
+	CredentialSubject *DomainLinkageSubjectData `json:"credentialSubject"`
+}
+
+// DomainLinkageSubjectData is the subject of the domain linkage credential
+type DomainLinkageSubjectData struct {
+	ID     string `json:"id"`
+	Origin string `json:"origin"`
+}
+
+// VerifyDomainLinkage verifies the signature of the given domain linkage credential (JWT) using the public key
+// of the DID and returns the claims of the credential
+func VerifyDomainLinkage(credential string, jwk *jws.JWK) (*DomainLinkageClaims, error) {
+	signature, err := internaljws.ParseJWS(credential, jwk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain linkage credential: %s", err)
+	}
+
+	claims := &DomainLinkageClaims{}
+	if err := json.Unmarshal(signature.Payload, claims); err != nil {
+		return nil, fmt.Errorf("invalid domain linkage credential claims: %s", err)
+	}
+
+	return claims, nil
+}
+
+// DIDConfigurationHandler returns the DID configuration resource that links the origin (domain) of the node
+// to the DIDs that are controlled by the node operator. The domain linkage credentials are signed when they
+// are first requested and are signed again once half of their validity period has passed.
+type DIDConfigurationHandler struct {
+	origin      string
+	dids        []LinkedDID
+	validity    time.Duration
+	errorMapper common.ErrorMapper
+	handler     common.HTTPRequestHandler
+
+	mutex     sync.Mutex
+	config    *DIDConfiguration
+	refreshAt time.Time
+}
+
+// NewDIDConfigurationHandler returns a new DID configuration handler for the given origin (e.g. https://example.com)
+// and DIDs
+func NewDIDConfigurationHandler(origin string, dids []LinkedDID, opts ...Option) *DIDConfigurationHandler {
+	options := getOptions(opts...)
+
+	h := &DIDConfigurationHandler{
+		origin:      origin,
+		dids:        dids,
+		validity:    options.DomainLinkageValidity,
+		errorMapper: options.ErrorMapper,
+	}
+
+	if h.validity == 0 {
+		h.validity = defaultDomainLinkageValidity
+	}
+
+	h.handler = common.Chain(h.didConfiguration, options.Middleware...)
+
+	return h
+}
+
+// DIDConfiguration returns the DID configuration resource
+func (h *DIDConfigurationHandler) DIDConfiguration(rw http.ResponseWriter, req *http.Request) {
+	h.handler(rw, req)
+}
+
+func (h *DIDConfigurationHandler) didConfiguration(rw http.ResponseWriter, _ *http.Request) {
+	config, err := h.getConfiguration(time.Now())
+	if err != nil {
+		logger.Errorf("Unable to create DID configuration: %s", err)
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusInternalServerError, err))
+		return
+	}
+
+	common.WriteResponseWithContentType(rw, http.StatusOK, common.JSONContentType, config)
+}
+
+func (h *DIDConfigurationHandler) getConfiguration(now time.Time) (*DIDConfiguration, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.config != nil && now.Before(h.refreshAt) {
+		return h.config, nil
+	}
+
+	if err := validateOrigin(h.origin); err != nil {
+		return nil, err
+	}
+
+	config := &DIDConfiguration{
+		Context:    DIDConfigurationContext,
+		LinkedDIDs: make([]string, len(h.dids)),
+	}
+
+	for i, d := range h.dids {
+		credential, err := h.newDomainLinkageCredential(d, now)
+		if err != nil {
+			return nil, fmt.Errorf("domain linkage credential for [%s]: %s", d.DID, err)
+		}
+
+		config.LinkedDIDs[i] = credential
+	}
+
+	h.config = config
+	h.refreshAt = now.Add(h.validity / 2)
+
+	return config, nil
+}
+
+func (h *DIDConfigurationHandler) newDomainLinkageCredential(d LinkedDID, now time.Time) (string, error) {
+	if d.DID == "" {
+		return "", errors.New("missing DID")
+	}
+
+	if d.Signer == nil {
+		return "", errors.New("missing signer")
+	}
+
+	expiration := now.Add(h.validity)
+
+	claims := &DomainLinkageClaims{
+		Issuer:     d.DID,
+		Subject:    d.DID,
+		NotBefore:  now.Unix(),
+		Expiration: expiration.Unix(),
+		Credential: &DomainLinkageVCData{
+			Context:        []string{credentialsContext, DIDConfigurationContext},
+			Issuer:         d.DID,
+			IssuanceDate:   now.UTC().Format(time.RFC3339),
+			ExpirationDate: expiration.UTC().Format(time.RFC3339),
+			Type:           []string{verifiableCredential, domainLinkageCredential},
+			CredentialSubject: &DomainLinkageSubjectData{
+				ID:     d.DID,
+				Origin: h.origin,
+			},
+		},
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	headers := jws.Headers{jws.HeaderType: "JWT"}
+
+	if kid, ok := d.Signer.Headers().KeyID(); ok && kid != "" && !strings.HasPrefix(kid, didPrefix) {
+		headers[jws.HeaderKeyID] = d.DID + "#" + strings.TrimPrefix(kid, "#")
+	}
+
+	signature, err := internaljws.NewJWS(headers, nil, payload, d.Signer)
+	if err != nil {
+		return "", err
+	}
+
+	return signature.SerializeCompact(false)
+}
+
+func validateOrigin(origin string) error {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("invalid origin: %s", err)
+	}
+
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return fmt.Errorf("invalid origin [%s]: must be a scheme and host (e.g. https://example.com)", origin)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+const (
+	origin  = "https://example.com"
+	ecDID   = "did:sidetree:ec"
+	edDID   = "did:sidetree:ed"
+	ecKeyID = "did:sidetree:ec#key-1"
+	edKeyID = "did:sidetree:ed#key-2"
+)
+
+func TestDIDConfigurationHandler(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ecJWK, err := pubkey.GetPublicKeyJWK(&ecKey.PublicKey)
+	require.NoError(t, err)
+
+	edPublicKey, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	edJWK, err := pubkey.GetPublicKeyJWK(edPublicKey)
+	require.NoError(t, err)
+
+	dids := []LinkedDID{
+		{DID: ecDID, Signer: ecsigner.New(ecKey, "ES256", ecKeyID)},
+		{DID: edDID, Signer: edsigner.New(edKey, "EdDSA", "#key-2")},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		before := time.Now().Unix()
+
+		config := getDIDConfiguration(t, NewDIDConfigurationHandler(origin, dids), http.StatusOK)
+		require.Equal(t, DIDConfigurationContext, config.Context)
+		require.Len(t, config.LinkedDIDs, 2)
+
+		claims, err := VerifyDomainLinkage(config.LinkedDIDs[0], ecJWK)
+		require.NoError(t, err)
+		require.Equal(t, ecDID, claims.Issuer)
+		require.Equal(t, ecDID, claims.Subject)
+		require.GreaterOrEqual(t, claims.NotBefore, before)
+		require.Equal(t, claims.NotBefore+int64(defaultDomainLinkageValidity/time.Second), claims.Expiration)
+		require.Equal(t, ecDID, claims.Credential.Issuer)
+		require.Equal(t, []string{"VerifiableCredential", "DomainLinkageCredential"}, claims.Credential.Type)
+		require.Equal(t, ecDID, claims.Credential.CredentialSubject.ID)
+		require.Equal(t, origin, claims.Credential.CredentialSubject.Origin)
+		require.Equal(t, ecKeyID, getKeyID(t, config.LinkedDIDs[0]))
+
+		claims, err = VerifyDomainLinkage(config.LinkedDIDs[1], edJWK)
+		require.NoError(t, err)
+		require.Equal(t, edDID, claims.Subject)
+
+		// key ID is qualified with the DID
+		require.Equal(t, edKeyID, getKeyID(t, config.LinkedDIDs[1]))
+
+		_, err = VerifyDomainLinkage(config.LinkedDIDs[1], ecJWK)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid domain linkage credential")
+	})
+
+	t.Run("credentials are cached until half of the validity period has passed", func(t *testing.T) {
+		handler := NewDIDConfigurationHandler(origin, dids, WithDomainLinkageValidity(time.Hour))
+
+		now := time.Now()
+
+		config, err := handler.getConfiguration(now)
+		require.NoError(t, err)
+
+		cached, err := handler.getConfiguration(now.Add(29 * time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, config, cached)
+
+		refreshed, err := handler.getConfiguration(now.Add(30 * time.Minute))
+		require.NoError(t, err)
+		require.NotEqual(t, config, refreshed)
+
+		claims, err := VerifyDomainLinkage(refreshed.LinkedDIDs[0], ecJWK)
+		require.NoError(t, err)
+		require.Equal(t, now.Add(90*time.Minute).Unix(), claims.Expiration)
+	})
+
+	t.Run("invalid origin", func(t *testing.T) {
+		for _, o := range []string{"", "example.com", "ftp://example.com", "https://example.com/path", "https://%"} {
+			getDIDConfiguration(t, NewDIDConfigurationHandler(o, dids), http.StatusInternalServerError)
+		}
+	})
+
+	t.Run("invalid DIDs", func(t *testing.T) {
+		getDIDConfiguration(t, NewDIDConfigurationHandler(origin, []LinkedDID{{Signer: dids[0].Signer}}),
+			http.StatusInternalServerError)
+		getDIDConfiguration(t, NewDIDConfigurationHandler(origin, []LinkedDID{{DID: ecDID}}),
+			http.StatusInternalServerError)
+	})
+
+	t.Run("signer error", func(t *testing.T) {
+		handler := NewDIDConfigurationHandler(origin, []LinkedDID{
+			{DID: ecDID, Signer: &mockSigner{err: errors.New("signer error")}},
+		})
+
+		rw := httptest.NewRecorder()
+		handler.DIDConfiguration(rw, httptest.NewRequest(http.MethodGet, "/.well-known/did-configuration.json", nil))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		require.Contains(t, rw.Body.String(), "signer error")
+	})
+}
+
+func getDIDConfiguration(t *testing.T, handler *DIDConfigurationHandler, status int) *DIDConfiguration {
+	rw := httptest.NewRecorder()
+	handler.DIDConfiguration(rw, httptest.NewRequest(http.MethodGet, "/.well-known/did-configuration.json", nil))
+	require.Equal(t, status, rw.Code)
+
+	if status != http.StatusOK {
+		return nil
+	}
+
+	require.Equal(t, "application/json", rw.Header().Get("content-type"))
+
+	config := &DIDConfiguration{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), config))
+
+	return config
+}
+
+func getKeyID(t *testing.T, credential string) string {
+	headerBytes, err := docutil.DecodeString(strings.Split(credential, ".")[0])
+	require.NoError(t, err)
+
+	headers := jws.Headers{}
+	require.NoError(t, json.Unmarshal(headerBytes, &headers))
+	require.Equal(t, "JWT", headers[jws.HeaderType])
+
+	kid, ok := headers.KeyID()
+	require.True(t, ok)
+
+	return kid
+}
+
+type mockSigner struct {
+	err error
+}
+
+func (m *mockSigner) Sign([]byte) ([]byte, error) {
+	return nil, m.err
+}
+
+func (m *mockSigner) Headers() jws.Headers {
+	return jws.Headers{jws.HeaderAlgorithm: "ES256"}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/metrics"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// Options contains optional parameters for the document handlers
+type Options struct {
+	Metrics               metrics.Metrics
+	Middleware            []common.Middleware
+	ErrorMapper           common.ErrorMapper
+	Authorizer            Authorizer
+	ReceiptSigner         Signer
+	RateLimiter           RateLimiter
+	MaxBatchRequests      int
+	DebugAuthorizer       DebugAuthorizer
+	GenericDocuments      bool
+	DomainLinkageValidity time.Duration
+	MaxArchiveSize        int64
+}
+
+// Option is a document handler option
+type Option func(opts *Options)
+
+// WithMetrics sets the metrics hooks (no-op metrics are used by default)
+func WithMetrics(m metrics.Metrics) Option {
+	return func(opts *Options) {
+		opts.Metrics = m
+	}
+}
+
+// WithMiddleware adds request middleware (e.g. auth token validation, request ID extraction).
+// Middleware is applied in the order provided, i.e. the first middleware is the outermost.
+func WithMiddleware(middleware ...common.Middleware) Option {
+	return func(opts *Options) {
+		opts.Middleware = append(opts.Middleware, middleware...)
+	}
+}
+
+// WithErrorMapper sets a custom mapper for the errors that are returned to the client
+func WithErrorMapper(mapper common.ErrorMapper) Option {
+	return func(opts *Options) {
+		opts.ErrorMapper = mapper
+	}
+}
+
+// WithAuthorizer sets the authorizer that is consulted before a write operation is accepted
+func WithAuthorizer(authorizer Authorizer) Option {
+	return func(opts *Options) {
+		opts.Authorizer = authorizer
+	}
+}
+
+// WithReceiptSigner sets the signer of the receipts that are returned (in the Sidetree-Receipt header)
+// for accepted operations. Receipts are not returned if a signer is not provided.
+func WithReceiptSigner(signer Signer) Option {
+	return func(opts *Options) {
+		opts.ReceiptSigner = signer
+	}
+}
+
+// WithRateLimiter sets the rate limiter that is consulted before an operation is accepted
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(opts *Options) {
+		opts.RateLimiter = limiter
+	}
+}
+
+// WithMaxBatchRequests sets the maximum number of operation requests that may be submitted in a single batch
+// (1000 by default)
+func WithMaxBatchRequests(max int) Option {
+	return func(opts *Options) {
+		opts.MaxBatchRequests = max
+	}
+}
+
+// WithDebugAuthorizer sets the authorizer of requests for debugging information. The operations that were
+// skipped during resolution are only returned to authorized requesters (and never if an authorizer isn't provided).
+func WithDebugAuthorizer(authorizer DebugAuthorizer) Option {
+	return func(opts *Options) {
+		opts.DebugAuthorizer = authorizer
+	}
+}
+
+// WithGenericDocuments configures the handlers for a namespace of generic (non-DID) documents, e.g. schemas or
+// credential status documents. Responses are written as plain JSON (application/json) instead of
+// application/did+ld+json and the resolved document is returned in the "document" property of the resolution
+// result (instead of "didDocument"). The document handler of the namespace should be created with a generic
+// document validator (see the docvalidator package).
+func WithGenericDocuments() Option {
+	return func(opts *Options) {
+		opts.GenericDocuments = true
+	}
+}
+
+// WithDomainLinkageValidity sets the validity period of the domain linkage credentials that are returned by the
+// DID configuration handler (one year by default)
+func WithDomainLinkageValidity(validity time.Duration) Option {
+	return func(opts *Options) {
+		opts.DomainLinkageValidity = validity
+	}
+}
+
+// WithMaxArchiveSize sets the maximum size (in bytes) of an archive that is imported by the archive handler
+// (100 MiB by default)
+func WithMaxArchiveSize(size int64) Option {
+	return func(opts *Options) {
+		opts.MaxArchiveSize = size
+	}
+}
+
+func getOptions(opts ...Option) *Options {
+	options := &Options{
+		Metrics:     metrics.NewNoop(),
+		ErrorMapper: common.DefaultErrorMapper,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
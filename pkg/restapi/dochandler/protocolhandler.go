@@ -0,0 +1,89 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/protocolclient"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// ProtocolReloader replaces the protocol versions of a protocol client (implemented by protocolclient.Client)
+type ProtocolReloader interface {
+	Reload(versions ...protocol.Protocol) error
+	Versions() []protocol.Protocol
+}
+
+// ProtocolReloadHandler reloads the protocol parameters without restarting the node. The request contains
+// a protocol version (JSON object) or a list of protocol versions (JSON array) that replace all of the
+// current versions, and the response contains the reloaded versions.
+// This is an admin endpoint so requests are only processed if they are authorized by the admin authorizer.
+type ProtocolReloadHandler struct {
+	reloader    ProtocolReloader
+	authorizer  AdminAuthorizer
+	errorMapper common.ErrorMapper
+	handler     common.HTTPRequestHandler
+}
+
+// NewProtocolReloadHandler returns a new protocol reload handler. All requests are rejected if the authorizer is nil.
+func NewProtocolReloadHandler(reloader ProtocolReloader, authorizer AdminAuthorizer, opts ...Option) *ProtocolReloadHandler {
+	options := getOptions(opts...)
+
+	h := &ProtocolReloadHandler{
+		reloader:    reloader,
+		authorizer:  authorizer,
+		errorMapper: options.ErrorMapper,
+	}
+
+	h.handler = common.Chain(h.reload, options.Middleware...)
+
+	return h
+}
+
+// Reload reloads the protocol versions
+func (h *ProtocolReloadHandler) Reload(rw http.ResponseWriter, req *http.Request) {
+	h.handler(rw, req)
+}
+
+func (h *ProtocolReloadHandler) reload(rw http.ResponseWriter, req *http.Request) {
+	if err := authorizeAdmin(h.authorizer, req); err != nil {
+		writeError(rw, h.errorMapper, err)
+		return
+	}
+
+	request, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusBadRequest, err))
+		return
+	}
+
+	versions, err := protocolclient.ParseVersions(request)
+	if err != nil {
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusBadRequest, err))
+		return
+	}
+
+	if err := h.reloader.Reload(versions...); err != nil {
+		logger.Warnf("Protocol versions were not reloaded: %s", err)
+
+		status := http.StatusBadRequest
+		if errors.Is(err, protocolclient.ErrVersionInEffect) {
+			status = http.StatusConflict
+		}
+
+		writeError(rw, h.errorMapper, common.NewHTTPError(status, err))
+		return
+	}
+
+	logger.Infof("Reloaded %d protocol version(s) from admin request", len(versions))
+
+	common.WriteResponseWithContentType(rw, http.StatusOK, common.JSONContentType, h.reloader.Versions())
+}
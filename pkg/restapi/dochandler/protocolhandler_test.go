@@ -0,0 +1,103 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/protocolclient"
+)
+
+func TestProtocolReloadHandler_Reload(t *testing.T) {
+	newClient := func(t *testing.T) *protocolclient.Client {
+		c, err := protocolclient.New([]protocol.Protocol{protocol.Default()})
+		require.NoError(t, err)
+
+		return c
+	}
+
+	t.Run("success", func(t *testing.T) {
+		c := newClient(t)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/protocol",
+			bytes.NewReader([]byte(`[{"maxOperationsPerBatch": 50},{"startingBlockchainTime": 100}]`)))
+		NewProtocolReloadHandler(c, allowAdmin).Reload(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("content-type"))
+
+		var versions []protocol.Protocol
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &versions))
+		require.Len(t, versions, 2)
+
+		pv, err := c.Get(0)
+		require.NoError(t, err)
+		require.Equal(t, uint(50), pv.Protocol().MaxOperationsPerBatch)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		c := newClient(t)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/protocol", bytes.NewReader([]byte(`{"unknown": 1}`)))
+		NewProtocolReloadHandler(c, allowAdmin).Reload(rw, req)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Len(t, c.Versions(), 1)
+	})
+
+	t.Run("reload error", func(t *testing.T) {
+		c := newClient(t)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/protocol", bytes.NewReader([]byte(`[{},{}]`)))
+		NewProtocolReloadHandler(c, allowAdmin).Reload(rw, req)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "duplicate protocol version")
+		require.Len(t, c.Versions(), 1)
+	})
+
+	t.Run("version in effect", func(t *testing.T) {
+		c := newClient(t)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/protocol", bytes.NewReader([]byte(`{"maxDeltaByteSize": 1}`)))
+		NewProtocolReloadHandler(c, allowAdmin).Reload(rw, req)
+		require.Equal(t, http.StatusConflict, rw.Code)
+		require.Contains(t, rw.Body.String(), "protocol version is already in effect")
+		require.Len(t, c.Versions(), 1)
+	})
+
+	t.Run("not authorized", func(t *testing.T) {
+		c := newClient(t)
+
+		forbidden := func(*http.Request) error {
+			return fmt.Errorf("%w: not an administrator", ErrForbidden)
+		}
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/protocol", bytes.NewReader([]byte(`{"maxOperationsPerBatch": 50}`)))
+		NewProtocolReloadHandler(c, forbidden).Reload(rw, req)
+		require.Equal(t, http.StatusForbidden, rw.Code)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/admin/protocol", bytes.NewReader([]byte(`{"maxOperationsPerBatch": 50}`)))
+		NewProtocolReloadHandler(c, nil).Reload(rw, req)
+		require.Equal(t, http.StatusForbidden, rw.Code)
+
+		pv, err := c.Current()
+		require.NoError(t, err)
+		require.Equal(t, protocol.Default().MaxOperationsPerBatch, pv.Protocol().MaxOperationsPerBatch)
+	})
+}
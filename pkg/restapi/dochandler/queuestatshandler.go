@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"net/http"
+
+	batchwriter "github.com/trustbloc/sidetree-core-go/pkg/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// QueueStatsProvider provides the statistics of the operation queue (implemented by the batch writer)
+type QueueStatsProvider interface {
+	QueueStats() (*batchwriter.QueueStats, error)
+}
+
+// QueueStatsHandler returns the number of pending operations and the time that operations spend in the operation
+// queue (bucketed by time in queue) so that operators can tune the batch timers and detect anchoring slowdowns.
+// This is an admin endpoint so it should be protected (e.g. with auth token middleware).
+type QueueStatsHandler struct {
+	provider    QueueStatsProvider
+	errorMapper common.ErrorMapper
+	handler     common.HTTPRequestHandler
+}
+
+// NewQueueStatsHandler returns a new queue statistics handler
+func NewQueueStatsHandler(provider QueueStatsProvider, opts ...Option) *QueueStatsHandler {
+	options := getOptions(opts...)
+
+	h := &QueueStatsHandler{
+		provider:    provider,
+		errorMapper: options.ErrorMapper,
+	}
+
+	h.handler = common.Chain(h.stats, options.Middleware...)
+
+	return h
+}
+
+// Stats returns the statistics of the operation queue
+func (h *QueueStatsHandler) Stats(rw http.ResponseWriter, req *http.Request) {
+	h.handler(rw, req)
+}
+
+func (h *QueueStatsHandler) stats(rw http.ResponseWriter, _ *http.Request) {
+	stats, err := h.provider.QueueStats()
+	if err != nil {
+		logger.Errorf("Unable to get operation queue statistics: %s", err)
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusInternalServerError, err))
+		return
+	}
+
+	common.WriteResponseWithContentType(rw, http.StatusOK, common.JSONContentType, stats)
+}
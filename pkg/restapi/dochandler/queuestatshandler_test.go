@@ -0,0 +1,77 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	batchwriter "github.com/trustbloc/sidetree-core-go/pkg/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+func TestQueueStatsHandler_Stats(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := &mockQueueStatsProvider{stats: &batchwriter.QueueStats{
+			Pending:    2,
+			PendingAge: []batchwriter.Bucket{{LE: "1s", Count: 1}, {LE: "+Inf", Count: 1}},
+			WaitTime:   []batchwriter.Bucket{{LE: "1s", Count: 5}, {LE: "+Inf", Count: 0}},
+		}}
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin/queue", nil)
+		NewQueueStatsHandler(provider).Stats(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("content-type"))
+
+		var stats batchwriter.QueueStats
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &stats))
+		require.Equal(t, uint(2), stats.Pending)
+		require.Len(t, stats.PendingAge, 2)
+		require.Equal(t, uint64(5), stats.WaitTime[0].Count)
+	})
+	t.Run("auth middleware", func(t *testing.T) {
+		handler := NewQueueStatsHandler(&mockQueueStatsProvider{stats: &batchwriter.QueueStats{}},
+			WithMiddleware(common.NewAuthTokenMiddleware(func(token string) error {
+				if token != "admin" {
+					return errors.New("invalid token")
+				}
+				return nil
+			})))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin/queue", nil)
+		handler.Stats(rw, req)
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+		rw = httptest.NewRecorder()
+		req.Header.Set("Authorization", "Bearer admin")
+		handler.Stats(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+	})
+	t.Run("provider error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin/queue", nil)
+		NewQueueStatsHandler(&mockQueueStatsProvider{err: errors.New("queue error")}).Stats(rw, req)
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		require.Contains(t, rw.Body.String(), "queue error")
+	})
+}
+
+type mockQueueStatsProvider struct {
+	stats *batchwriter.QueueStats
+	err   error
+}
+
+func (m *mockQueueStatsProvider) QueueStats() (*batchwriter.QueueStats, error) {
+	return m.stats, m.err
+}
@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+)
+
+const (
+	rateLimitedCode = "rate_limited"
+
+	defaultRateLimitWindow = time.Minute
+)
+
+// RateLimiter is consulted by the update handler before an operation is accepted. If Allow returns false
+// then the request is rejected with 429 (Too Many Requests). If the operation is allowed but subsequently
+// rejected by the document handler then Release is invoked so that only accepted operations count toward the limit.
+type RateLimiter interface {
+	Allow(req *http.Request, operation *batch.Operation, p protocol.Protocol) bool
+	Release(req *http.Request, operation *batch.Operation, p protocol.Protocol)
+}
+
+// WindowRateLimiter limits the number of operations per DID (unique suffix) within a fixed time window.
+// The limit and the window are taken from the protocol parameters (MaxOperationsPerDID and RateLimitWindow).
+type WindowRateLimiter struct {
+	mutex     sync.Mutex
+	windows   map[string]*rateWindow
+	byIP      bool
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count uint
+}
+
+// RateLimiterOption is a rate limiter option
+type RateLimiterOption func(l *WindowRateLimiter)
+
+// WithClientIPKey also keys the limit by the client IP (i.e. each client may submit up to the maximum
+// number of operations for a DID within the window)
+func WithClientIPKey() RateLimiterOption {
+	return func(l *WindowRateLimiter) {
+		l.byIP = true
+	}
+}
+
+// NewWindowRateLimiter returns a new rate limiter
+func NewWindowRateLimiter(opts ...RateLimiterOption) *WindowRateLimiter {
+	l := &WindowRateLimiter{
+		windows: make(map[string]*rateWindow),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Allow returns true if the number of operations for the DID within the current window has not
+// exceeded the protocol limit
+func (l *WindowRateLimiter) Allow(req *http.Request, operation *batch.Operation, p protocol.Protocol) bool {
+	if p.MaxOperationsPerDID == 0 {
+		return true
+	}
+
+	window := defaultRateLimitWindow
+	if p.RateLimitWindow > 0 {
+		window = time.Duration(p.RateLimitWindow) * time.Second
+	}
+
+	key := l.key(req, operation)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+
+	l.sweep(now, window)
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+
+	if w.count >= p.MaxOperationsPerDID {
+		return false
+	}
+
+	w.count++
+
+	return true
+}
+
+// Release releases an operation that was allowed (by Allow) but was not accepted
+func (l *WindowRateLimiter) Release(req *http.Request, operation *batch.Operation, p protocol.Protocol) {
+	if p.MaxOperationsPerDID == 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if w, ok := l.windows[l.key(req, operation)]; ok && w.count > 0 {
+		w.count--
+	}
+}
+
+func (l *WindowRateLimiter) key(req *http.Request, operation *batch.Operation) string {
+	if l.byIP {
+		return operation.UniqueSuffix + "|" + clientIP(req)
+	}
+
+	return operation.UniqueSuffix
+}
+
+// sweep removes expired windows (at most once per window)
+func (l *WindowRateLimiter) sweep(now time.Time, window time.Duration) {
+	if now.Sub(l.lastSweep) < window {
+		return
+	}
+
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= window {
+			delete(l.windows, key)
+		}
+	}
+
+	l.lastSweep = now
+}
+
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,136 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+)
+
+func TestWindowRateLimiter_Allow(t *testing.T) {
+	op1 := &batch.Operation{UniqueSuffix: "suffix1"}
+	op2 := &batch.Operation{UniqueSuffix: "suffix2"}
+
+	t.Run("not limited", func(t *testing.T) {
+		l := NewWindowRateLimiter()
+
+		req := httptest.NewRequest(http.MethodPost, "/document", nil)
+
+		for i := 0; i < 10; i++ {
+			require.True(t, l.Allow(req, op1, protocol.Protocol{}))
+		}
+	})
+
+	t.Run("limited per DID", func(t *testing.T) {
+		now := time.Now()
+
+		l := NewWindowRateLimiter()
+		l.now = func() time.Time { return now }
+
+		p := protocol.Protocol{MaxOperationsPerDID: 2, RateLimitWindow: 10}
+		req := httptest.NewRequest(http.MethodPost, "/document", nil)
+
+		require.True(t, l.Allow(req, op1, p))
+		require.True(t, l.Allow(req, op1, p))
+		require.False(t, l.Allow(req, op1, p))
+
+		// other DIDs are not affected
+		require.True(t, l.Allow(req, op2, p))
+
+		now = now.Add(9 * time.Second)
+		require.False(t, l.Allow(req, op1, p))
+
+		// new window
+		now = now.Add(time.Second)
+		require.True(t, l.Allow(req, op1, p))
+	})
+
+	t.Run("default window", func(t *testing.T) {
+		now := time.Now()
+
+		l := NewWindowRateLimiter()
+		l.now = func() time.Time { return now }
+
+		p := protocol.Protocol{MaxOperationsPerDID: 1}
+		req := httptest.NewRequest(http.MethodPost, "/document", nil)
+
+		require.True(t, l.Allow(req, op1, p))
+		require.False(t, l.Allow(req, op1, p))
+
+		now = now.Add(defaultRateLimitWindow)
+		require.True(t, l.Allow(req, op1, p))
+	})
+
+	t.Run("keyed by client IP", func(t *testing.T) {
+		l := NewWindowRateLimiter(WithClientIPKey())
+
+		p := protocol.Protocol{MaxOperationsPerDID: 1}
+
+		req1 := httptest.NewRequest(http.MethodPost, "/document", nil)
+		req1.RemoteAddr = "10.0.0.1:1234"
+
+		req2 := httptest.NewRequest(http.MethodPost, "/document", nil)
+		req2.RemoteAddr = "10.0.0.2:1234"
+
+		require.True(t, l.Allow(req1, op1, p))
+		require.False(t, l.Allow(req1, op1, p))
+		require.True(t, l.Allow(req2, op1, p))
+	})
+
+	t.Run("release", func(t *testing.T) {
+		l := NewWindowRateLimiter(WithClientIPKey())
+
+		p := protocol.Protocol{MaxOperationsPerDID: 1}
+		req := httptest.NewRequest(http.MethodPost, "/document", nil)
+
+		require.True(t, l.Allow(req, op1, p))
+		require.False(t, l.Allow(req, op1, p))
+
+		l.Release(req, op1, p)
+		require.True(t, l.Allow(req, op1, p))
+
+		// releasing an unknown or unused window has no effect
+		l.Release(req, op2, p)
+		l.Release(req, op1, protocol.Protocol{})
+		require.False(t, l.Allow(req, op1, p))
+	})
+
+	t.Run("expired windows are removed", func(t *testing.T) {
+		now := time.Now()
+
+		l := NewWindowRateLimiter()
+		l.now = func() time.Time { return now }
+
+		p := protocol.Protocol{MaxOperationsPerDID: 1, RateLimitWindow: 1}
+		req := httptest.NewRequest(http.MethodPost, "/document", nil)
+
+		require.True(t, l.Allow(req, op1, p))
+		require.True(t, l.Allow(req, op2, p))
+		require.Len(t, l.windows, 2)
+
+		now = now.Add(time.Second)
+		require.True(t, l.Allow(req, op1, p))
+		require.Len(t, l.windows, 1)
+	})
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/document", nil)
+
+	req.RemoteAddr = "10.0.0.1:1234"
+	require.Equal(t, "10.0.0.1", clientIP(req))
+
+	req.RemoteAddr = "10.0.0.1"
+	require.Equal(t, "10.0.0.1", clientIP(req))
+}
@@ -0,0 +1,79 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	internaljws "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/signutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+// ReceiptHeader is the response header that contains the node-signed receipt (compact JWS)
+const ReceiptHeader = "Sidetree-Receipt"
+
+// Signer signs the operation receipts and the domain linkage credentials of the node
+type Signer interface {
+	// Sign signs data and returns signature value
+	Sign(data []byte) ([]byte, error)
+
+	// Headers provides required JWS protected headers. It provides information about signing key and algorithm.
+	Headers() jws.Headers
+}
+
+// Receipt is the evidence that an operation was accepted by the node
+type Receipt struct {
+	ID            string              `json:"id"`
+	Type          batch.OperationType `json:"type"`
+	OperationHash string              `json:"operationHash"`
+	AcceptedAt    int64               `json:"acceptedAt"`
+}
+
+// VerifyReceipt verifies the signature of the given receipt (compact JWS) using the node's public key
+// and returns the receipt
+func VerifyReceipt(receipt string, jwk *jws.JWK) (*Receipt, error) {
+	signature, err := internaljws.ParseJWS(receipt, jwk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt: %s", err)
+	}
+
+	payload, err := docutil.DecodeString(string(signature.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("invalid receipt payload: %s", err)
+	}
+
+	r := &Receipt{}
+	if err := json.Unmarshal(payload, r); err != nil {
+		return nil, fmt.Errorf("invalid receipt payload: %s", err)
+	}
+
+	return r, nil
+}
+
+func newReceipt(signer Signer, operation *batch.Operation, request []byte, hashAlgorithm uint, acceptedAt time.Time) (string, error) {
+	hash, err := docutil.ComputeMultihash(hashAlgorithm, request)
+	if err != nil {
+		return "", err
+	}
+
+	signed, err := signutil.SignModel(&Receipt{
+		ID:            operation.ID,
+		Type:          operation.Type,
+		OperationHash: docutil.EncodeToString(hash),
+		AcceptedAt:    acceptedAt.Unix(),
+	}, signer)
+	if err != nil {
+		return "", err
+	}
+
+	return signed.Signature, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+func TestUpdateHandler_Receipt(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	create, err := helper.NewCreateRequest(getCreateRequestInfo())
+	require.NoError(t, err)
+
+	docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+
+	t.Run("success", func(t *testing.T) {
+		handler := NewUpdateHandler(docHandler, WithReceiptSigner(ecsigner.New(privateKey, "ES256", "node-key")))
+
+		before := time.Now().Unix()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		receipt := rw.Header().Get(ReceiptHeader)
+		require.NotEmpty(t, receipt)
+
+		r, err := VerifyReceipt(receipt, jwk)
+		require.NoError(t, err)
+		require.Equal(t, batch.OperationTypeCreate, r.Type)
+		require.Contains(t, r.ID, namespace)
+		require.GreaterOrEqual(t, r.AcceptedAt, before)
+
+		hash, err := docutil.ComputeMultihash(sha2_256, create)
+		require.NoError(t, err)
+		require.Equal(t, docutil.EncodeToString(hash), r.OperationHash)
+	})
+	t.Run("no signer", func(t *testing.T) {
+		handler := NewUpdateHandler(docHandler)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Empty(t, rw.Header().Get(ReceiptHeader))
+	})
+	t.Run("signing error", func(t *testing.T) {
+		handler := NewUpdateHandler(docHandler, WithReceiptSigner(ecsigner.New(privateKey, "", "node-key")))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Empty(t, rw.Header().Get(ReceiptHeader))
+	})
+}
+
+func TestVerifyReceipt(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk, err := pubkey.GetPublicKeyJWK(&otherKey.PublicKey)
+	require.NoError(t, err)
+
+	receipt, err := newReceipt(ecsigner.New(privateKey, "ES256", "node-key"),
+		&batch.Operation{ID: "did:sidetree:abc", Type: batch.OperationTypeUpdate}, []byte("request"), sha2_256, time.Now())
+	require.NoError(t, err)
+
+	t.Run("invalid signature", func(t *testing.T) {
+		r, err := VerifyReceipt(receipt, jwk)
+		require.Error(t, err)
+		require.Nil(t, r)
+		require.Contains(t, err.Error(), "invalid receipt")
+	})
+	t.Run("invalid format", func(t *testing.T) {
+		r, err := VerifyReceipt("invalid", jwk)
+		require.Error(t, err)
+		require.Nil(t, r)
+		require.Contains(t, err.Error(), "invalid JWS compact format")
+	})
+	t.Run("invalid hash algorithm", func(t *testing.T) {
+		_, err := newReceipt(ecsigner.New(privateKey, "ES256", "node-key"),
+			&batch.Operation{}, []byte("request"), 55, time.Now())
+		require.Error(t, err)
+	})
+}
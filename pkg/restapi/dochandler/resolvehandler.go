@@ -7,13 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package dochandler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
-	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/request"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
@@ -21,6 +24,19 @@ import (
 
 var logger = logrus.New()
 
+// verifyParam is the query parameter with which a client requests the integrity check of the resolved document.
+// If set to true, the resolved document is re-computed from its anchored operations and compared with the resolved
+// document, and the integrity data is included in the method metadata so that the client may verify the document
+// as well (see dochandler.DocumentHandler.VerifyIntegrity).
+const verifyParam = "verify"
+
+const integrityUnavailableCode = "integrity_unavailable"
+
+// IntegrityVerifier is implemented by resolvers that are able to verify the integrity of a resolved document
+type IntegrityVerifier interface {
+	VerifyIntegrity(result *document.ResolutionResult) error
+}
+
 // Resolver resolves documents
 type Resolver interface {
 	Namespace() string
@@ -29,27 +45,85 @@ type Resolver interface {
 
 // ResolveHandler resolves generic documents
 type ResolveHandler struct {
-	resolver Resolver
+	resolver        Resolver
+	errorMapper     common.ErrorMapper
+	debugAuthorizer DebugAuthorizer
+	generic         bool
+	handler         common.HTTPRequestHandler
 }
 
 // NewResolveHandler returns a new document resolve handler
-func NewResolveHandler(resolver Resolver) *ResolveHandler {
-	return &ResolveHandler{
-		resolver: resolver,
+func NewResolveHandler(resolver Resolver, opts ...Option) *ResolveHandler {
+	options := getOptions(opts...)
+
+	o := &ResolveHandler{
+		resolver:        resolver,
+		errorMapper:     options.ErrorMapper,
+		debugAuthorizer: options.DebugAuthorizer,
+		generic:         options.GenericDocuments,
 	}
+
+	o.handler = common.Chain(o.resolve, options.Middleware...)
+
+	return o
 }
 
 // Resolve resolves a document
 func (o *ResolveHandler) Resolve(rw http.ResponseWriter, req *http.Request) {
+	o.handler(rw, req)
+}
+
+func (o *ResolveHandler) resolve(rw http.ResponseWriter, req *http.Request) {
+	verify, err := getVerify(req)
+	if err != nil {
+		writeError(rw, o.errorMapper, common.NewHTTPError(http.StatusBadRequest, err))
+		return
+	}
+
 	id := getID(o.resolver.Namespace(), req)
 	logger.Debugf("Resolving DID document for ID [%s]", id)
 	response, err := o.doResolve(id)
 	if err != nil {
-		common.WriteError(rw, err.(*common.HTTPError).Status(), err)
+		writeError(rw, o.errorMapper, err.(*common.HTTPError))
 		return
 	}
 	logger.Debugf("... resolved DID document for ID [%s]: %s", id, response.Document)
-	common.WriteResponse(rw, http.StatusOK, response)
+
+	if o.debugAuthorizer == nil || !o.debugAuthorizer(req) {
+		response.MethodMetadata.SkippedOperations = nil
+	}
+
+	if !verify {
+		response.MethodMetadata.Integrity = nil
+	} else if err := o.verifyIntegrity(response); err != nil {
+		logger.Errorf("Resolved document for ID [%s] failed integrity check: %s", id, err)
+		writeError(rw, o.errorMapper, err)
+		return
+	}
+
+	writeResolutionResult(rw, o.generic, response)
+}
+
+// verifyIntegrity checks that the resolved document is consistent with its anchored operations. If the resolver
+// isn't able to verify the document (or doesn't provide integrity data for the document) then 422 (Unprocessable
+// Entity) is returned.
+func (o *ResolveHandler) verifyIntegrity(result *document.ResolutionResult) *common.HTTPError {
+	v, ok := o.resolver.(IntegrityVerifier)
+	if !ok {
+		return common.NewHTTPErrorWithCode(http.StatusUnprocessableEntity, integrityUnavailableCode,
+			errors.New("the integrity of the resolved document can't be verified"))
+	}
+
+	err := v.VerifyIntegrity(result)
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, dochandler.ErrIntegrityUnavailable):
+		return common.NewHTTPErrorWithCode(http.StatusUnprocessableEntity, integrityUnavailableCode, err)
+	default:
+		return common.NewHTTPError(http.StatusInternalServerError, fmt.Errorf("integrity check failed: %s", err.Error()))
+	}
 }
 
 func (o *ResolveHandler) doResolve(id string) (*document.ResolutionResult, error) {
@@ -77,10 +151,55 @@ func (o *ResolveHandler) doResolve(id string) (*document.ResolutionResult, error
 	return doc, nil
 }
 
+func writeError(rw http.ResponseWriter, mapper common.ErrorMapper, err *common.HTTPError) {
+	mappedErr := mapper(err)
+	if mappedErr.Code() != "" {
+		common.WriteJSONError(rw, mappedErr.Status(), mappedErr.Code(), mappedErr)
+		return
+	}
+
+	common.WriteError(rw, mappedErr.Status(), mappedErr)
+}
+
+// writeResolutionResult writes the resolution result as a DID resolution result or,
+// for generic documents, as plain JSON
+func writeResolutionResult(rw http.ResponseWriter, generic bool, result *document.ResolutionResult) {
+	if generic {
+		common.WriteGenericResolutionResult(rw, http.StatusOK, result)
+		return
+	}
+
+	common.WriteResolutionResult(rw, http.StatusOK, result)
+}
+
+// writeResponse writes the response with the DID content type or, for generic documents, as plain JSON
+func writeResponse(rw http.ResponseWriter, generic bool, v interface{}) {
+	if generic {
+		common.WriteResponseWithContentType(rw, http.StatusOK, common.JSONContentType, v)
+		return
+	}
+
+	common.WriteResponse(rw, http.StatusOK, v)
+}
+
 var getID = func(namespace string, req *http.Request) string {
 	return mux.Vars(req)["id"] + getInitialState(namespace, req)
 }
 
+func getVerify(req *http.Request) (bool, error) {
+	value := req.URL.Query().Get(verifyParam)
+	if value == "" {
+		return false, nil
+	}
+
+	verify, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s parameter: %s", verifyParam, value)
+	}
+
+	return verify, nil
+}
+
 func getInitialState(namespace string, req *http.Request) string {
 	initialParam := request.GetInitialStateParam(namespace)
 	initialParamValue := req.URL.Query().Get(initialParam)
@@ -10,6 +10,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -19,11 +20,14 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/canonicalizer"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/request"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
 )
@@ -138,6 +142,36 @@ func TestResolveHandler_Resolve(t *testing.T) {
 		require.Equal(t, http.StatusInternalServerError, rw.Code)
 		require.Contains(t, rw.Body.String(), errExpected.Error())
 	})
+	t.Run("Error mapper and middleware", func(t *testing.T) {
+		getID = func(namespace string, req *http.Request) string {
+			return namespace + docutil.NamespaceDelimiter + "someid"
+		}
+		docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+
+		var requestID string
+		handler := NewResolveHandler(docHandler,
+			WithMiddleware(common.NewRequestIDMiddleware(""), func(next common.HTTPRequestHandler) common.HTTPRequestHandler {
+				return func(rw http.ResponseWriter, req *http.Request) {
+					requestID = common.RequestID(req.Context())
+					next(rw, req)
+				}
+			}),
+			WithErrorMapper(func(err *common.HTTPError) *common.HTTPError {
+				if err.Status() == http.StatusNotFound {
+					return common.NewHTTPError(http.StatusGone, errors.New("mapped error"))
+				}
+				return err
+			}),
+		)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set(common.RequestIDHeader, "req-1")
+		handler.Resolve(rw, req)
+		require.Equal(t, http.StatusGone, rw.Code)
+		require.Equal(t, "mapped error", rw.Body.String())
+		require.Equal(t, "req-1", requestID)
+	})
 	t.Run("Document is no longer available", func(t *testing.T) {
 		docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
 
@@ -174,6 +208,181 @@ func TestResolveHandler_Resolve(t *testing.T) {
 	})
 }
 
+func TestResolveHandler_SkippedOperations(t *testing.T) {
+	resolver := &stubResolver{}
+
+	getID = func(namespace string, req *http.Request) string {
+		return namespace + docutil.NamespaceDelimiter + "someid"
+	}
+
+	debugAuthorizer := func(req *http.Request) bool { return req.Header.Get("X-Admin") == "true" }
+
+	t.Run("no debug authorizer", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("X-Admin", "true")
+
+		NewResolveHandler(resolver).Resolve(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.NotContains(t, rw.Body.String(), "skippedOperations")
+	})
+
+	t.Run("not authorized", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+
+		NewResolveHandler(resolver, WithDebugAuthorizer(debugAuthorizer)).Resolve(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.NotContains(t, rw.Body.String(), "skippedOperations")
+	})
+
+	t.Run("authorized", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/document", nil)
+		req.Header.Set("X-Admin", "true")
+
+		NewResolveHandler(resolver, WithDebugAuthorizer(debugAuthorizer)).Resolve(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Contains(t, rw.Body.String(), "skippedOperations")
+		require.Contains(t, rw.Body.String(), "superseded")
+	})
+}
+
+func TestResolveHandler_GenericDocuments(t *testing.T) {
+	getID = func(namespace string, req *http.Request) string {
+		return namespace + docutil.NamespaceDelimiter + "someid"
+	}
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/document", nil)
+
+	NewResolveHandler(&stubResolver{}, WithGenericDocuments()).Resolve(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "application/json", rw.Header().Get("content-type"))
+
+	var result map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &result))
+	require.NotContains(t, result, "didDocument")
+
+	doc, err := document.FromBytes(result["document"])
+	require.NoError(t, err)
+	require.Equal(t, namespace+docutil.NamespaceDelimiter+"someid", doc.ID())
+}
+
+func TestResolveHandler_Verify(t *testing.T) {
+	integrity := &document.Integrity{
+		SuffixData: "suffixData",
+		Operations: []document.IntegrityOperation{{Type: "create", Delta: "delta"}},
+	}
+
+	getID = func(namespace string, req *http.Request) string { return namespace + ":suffix" }
+
+	resolve := func(resolver Resolver, query string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		NewResolveHandler(resolver).Resolve(rw, httptest.NewRequest(http.MethodGet, "/document"+query, nil))
+
+		return rw
+	}
+
+	t.Run("success", func(t *testing.T) {
+		resolver := &integrityResolver{integrity: integrity}
+
+		rw := resolve(resolver, "?verify=true")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.True(t, resolver.verified)
+
+		result := &document.ResolutionResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+		require.Equal(t, integrity, result.MethodMetadata.Integrity)
+	})
+
+	t.Run("integrity data is only included if requested", func(t *testing.T) {
+		for _, query := range []string{"", "?verify=false"} {
+			resolver := &integrityResolver{integrity: integrity}
+
+			rw := resolve(resolver, query)
+			require.Equal(t, http.StatusOK, rw.Code)
+			require.NotContains(t, rw.Body.String(), "integrity")
+			require.False(t, resolver.verified)
+		}
+	})
+
+	t.Run("invalid verify parameter", func(t *testing.T) {
+		rw := resolve(&integrityResolver{integrity: integrity}, "?verify=yes")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "invalid value for verify parameter: yes")
+	})
+
+	t.Run("integrity data not available", func(t *testing.T) {
+		rw := resolve(&integrityResolver{err: dochandler.ErrIntegrityUnavailable}, "?verify=true")
+		require.Equal(t, http.StatusUnprocessableEntity, rw.Code)
+
+		var errResp common.ErrorResponse
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &errResp))
+		require.Equal(t, "integrity_unavailable", errResp.Code)
+		require.Contains(t, errResp.Message, "integrity data is not available")
+	})
+
+	t.Run("resolver can't verify integrity", func(t *testing.T) {
+		rw := resolve(&stubResolver{}, "?verify=true")
+		require.Equal(t, http.StatusUnprocessableEntity, rw.Code)
+
+		var errResp common.ErrorResponse
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &errResp))
+		require.Equal(t, "integrity_unavailable", errResp.Code)
+	})
+
+	t.Run("integrity check failed", func(t *testing.T) {
+		rw := resolve(&integrityResolver{integrity: integrity, err: errors.New("delta doesn't match delta hash")}, "?verify=true")
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		require.Contains(t, rw.Body.String(), "integrity check failed: delta doesn't match delta hash")
+	})
+}
+
+// integrityResolver returns a resolution result with the given integrity data for any ID and
+// verifies the integrity of the result with the given error
+type integrityResolver struct {
+	integrity *document.Integrity
+	err       error
+	verified  bool
+}
+
+func (r *integrityResolver) Namespace() string {
+	return namespace
+}
+
+func (r *integrityResolver) ResolveDocument(id string) (*document.ResolutionResult, error) {
+	return &document.ResolutionResult{
+		Document:       document.Document{"id": id},
+		MethodMetadata: document.MethodMetadata{Published: true, Integrity: r.integrity},
+	}, nil
+}
+
+func (r *integrityResolver) VerifyIntegrity(*document.ResolutionResult) error {
+	r.verified = true
+
+	return r.err
+}
+
+// stubResolver returns a resolution result (including skipped operations) for any ID
+type stubResolver struct{}
+
+func (r *stubResolver) Namespace() string {
+	return namespace
+}
+
+func (r *stubResolver) ResolveDocument(id string) (*document.ResolutionResult, error) {
+	return &document.ResolutionResult{
+		Document: document.Document{"id": id},
+		MethodMetadata: document.MethodMetadata{
+			Published: true,
+			SkippedOperations: []document.SkippedOperation{
+				{Type: "update", TransactionTime: 1, TransactionNumber: 2, Reason: "superseded"},
+			},
+		},
+	}, nil
+}
+
 func TestGetInitialState(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/document", nil)
 	initialState := getInitialState(namespace, req)
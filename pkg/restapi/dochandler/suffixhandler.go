@@ -0,0 +1,76 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// SuffixChecker checks whether a unique suffix is in use
+type SuffixChecker interface {
+	CheckSuffix(uniqueSuffix string) (*document.SuffixCheck, error)
+}
+
+// SuffixHandler checks whether a unique suffix is in use so that clients can detect collisions (or duplicate
+// registrations) before submitting a create operation
+type SuffixHandler struct {
+	checker     SuffixChecker
+	errorMapper common.ErrorMapper
+	generic     bool
+	handler     common.HTTPRequestHandler
+}
+
+// NewSuffixHandler returns a new suffix handler
+func NewSuffixHandler(checker SuffixChecker, opts ...Option) *SuffixHandler {
+	options := getOptions(opts...)
+
+	h := &SuffixHandler{
+		checker:     checker,
+		errorMapper: options.ErrorMapper,
+		generic:     options.GenericDocuments,
+	}
+
+	h.handler = common.Chain(h.check, options.Middleware...)
+
+	return h
+}
+
+// Check returns the status of the unique suffix
+func (h *SuffixHandler) Check(rw http.ResponseWriter, req *http.Request) {
+	h.handler(rw, req)
+}
+
+func (h *SuffixHandler) check(rw http.ResponseWriter, req *http.Request) {
+	suffix := getSuffix(req)
+
+	logger.Debugf("Checking unique suffix [%s]", suffix)
+
+	result, err := h.checker.CheckSuffix(suffix)
+	if err != nil {
+		if strings.Contains(err.Error(), "bad request") {
+			writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusBadRequest, err))
+			return
+		}
+
+		logger.Errorf("Unable to check unique suffix [%s]: %s", suffix, err)
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusInternalServerError, err))
+
+		return
+	}
+
+	writeResponse(rw, h.generic, result)
+}
+
+var getSuffix = func(req *http.Request) string {
+	return mux.Vars(req)["suffix"]
+}
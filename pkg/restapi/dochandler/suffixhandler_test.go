@@ -0,0 +1,62 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+func TestSuffixHandler_Check(t *testing.T) {
+	const suffix = "abc"
+
+	getSuffix = func(req *http.Request) string { return suffix }
+
+	t.Run("available", func(t *testing.T) {
+		handler := NewSuffixHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/suffixes/"+suffix, nil)
+		handler.Check(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		var check document.SuffixCheck
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &check))
+		require.Equal(t, suffix, check.UniqueSuffix)
+		require.Equal(t, document.SuffixAvailable, check.Status)
+	})
+	t.Run("bad request", func(t *testing.T) {
+		handler := NewSuffixHandler(mocks.NewMockDocumentHandler().
+			WithNamespace(namespace).
+			WithError(errors.New("bad request: invalid suffix")))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/suffixes/"+suffix, nil)
+		handler.Check(rw, req)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "invalid suffix")
+	})
+	t.Run("internal error", func(t *testing.T) {
+		handler := NewSuffixHandler(mocks.NewMockDocumentHandler().
+			WithNamespace(namespace).
+			WithError(errors.New("store error")))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/suffixes/"+suffix, nil)
+		handler.Check(rw, req)
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		require.Contains(t, rw.Body.String(), "store error")
+	})
+}
@@ -7,18 +7,42 @@ SPDX-License-Identifier: Apache-2.0
 package dochandler
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
-	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/metrics"
 	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
-	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+const (
+	// invalidPatchCode is the code of the structured error that is returned for operations with an invalid patch.
+	// The message contains the index of the patch and the JSON Pointer of the offending value.
+	invalidPatchCode = "invalid_patch"
+
+	// suffixDeniedCode is returned for create operations whose suffix is in the deny-list
+	suffixDeniedCode = "suffix_denied"
+
+	// suffixCollisionCode is returned for create operations whose suffix collides with an existing create operation
+	suffixCollisionCode = "suffix_collision"
+
+	// operationTooLargeCode is returned for operations that exceed the size limits of the protocol
+	operationTooLargeCode = "operation_too_large"
+
+	// WarningHeader is set on the response to operation requests that use deprecated features
+	WarningHeader = "Warning"
+
+	// legacyRevealValuesWarning is returned for operation requests that provide next reveal values instead of
+	// the update/recovery commitments
+	legacyRevealValuesWarning = `299 - "next reveal values are deprecated: provide the commitments instead"`
 )
 
 // Processor processes document operations
@@ -30,85 +54,202 @@ type Processor interface {
 
 // UpdateHandler handles the creation and update of documents
 type UpdateHandler struct {
-	processor Processor
+	processor   Processor
+	metrics     metrics.Metrics
+	errorMapper common.ErrorMapper
+	authorizer  Authorizer
+	signer      Signer
+	limiter     RateLimiter
+	generic     bool
+	handler     common.HTTPRequestHandler
 }
 
 // NewUpdateHandler returns a new document update handler
-func NewUpdateHandler(processor Processor) *UpdateHandler {
-	return &UpdateHandler{
-		processor: processor,
+func NewUpdateHandler(processor Processor, opts ...Option) *UpdateHandler {
+	options := getOptions(opts...)
+
+	h := &UpdateHandler{
+		processor:   processor,
+		metrics:     options.Metrics,
+		errorMapper: options.ErrorMapper,
+		authorizer:  options.Authorizer,
+		signer:      options.ReceiptSigner,
+		limiter:     options.RateLimiter,
+		generic:     options.GenericDocuments,
 	}
+
+	h.handler = common.Chain(h.update, options.Middleware...)
+
+	return h
 }
 
 // Update creates or updates a document
 func (h *UpdateHandler) Update(rw http.ResponseWriter, req *http.Request) {
+	h.handler(rw, req)
+}
+
+func (h *UpdateHandler) update(rw http.ResponseWriter, req *http.Request) {
 	request, err := ioutil.ReadAll(req.Body)
 	if err != nil {
-		common.WriteError(rw, http.StatusBadRequest, err)
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusBadRequest, err))
 		return
 	}
 
-	response, err := h.doUpdate(request)
+	op, response, receipt, err := h.doUpdate(req, request)
 	if err != nil {
-		common.WriteError(rw, err.(*common.HTTPError).Status(), err)
+		writeError(rw, h.errorMapper, err.(*common.HTTPError))
 		return
 	}
-	common.WriteResponse(rw, http.StatusOK, response)
+
+	if operation.UsesLegacyRevealValues(op) {
+		rw.Header().Set(WarningHeader, legacyRevealValuesWarning)
+	}
+
+	if receipt != "" {
+		rw.Header().Set(ReceiptHeader, receipt)
+	}
+
+	writeResolutionResult(rw, h.generic, response)
 }
 
-func (h *UpdateHandler) doUpdate(request []byte) (*document.ResolutionResult, error) {
+// doUpdate validates and processes the operation request and returns the operation, the resolution result
+// and the receipt (empty if receipts are not enabled). The returned error is always an *common.HTTPError.
+func (h *UpdateHandler) doUpdate(req *http.Request, request []byte) (*batch.Operation, *document.ResolutionResult, string, error) {
+	acceptedAt := time.Now()
+
 	operation, err := h.getOperation(request)
 	if err != nil {
 		logger.Warnf("operation validation error: %s", err.Error())
-		return nil, common.NewHTTPError(http.StatusBadRequest, err)
+		h.metrics.OperationParseFailure()
+
+		var patchErr *patch.ValidationError
+		if errors.As(err, &patchErr) {
+			return nil, nil, "", common.NewHTTPErrorWithCode(http.StatusBadRequest, invalidPatchCode, err)
+		}
+
+		return nil, nil, "", common.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	h.metrics.OperationRequest(operation.Type)
+
+	if err := h.checkDeactivateConfirmation(req, operation); err != nil {
+		return nil, nil, "", err
+	}
+
+	if err := h.authorize(req, operation); err != nil {
+		return nil, nil, "", err
+	}
+
+	if err := h.checkRateLimit(req, operation); err != nil {
+		return nil, nil, "", err
 	}
 
 	// operation has been validated, now process it
 	result, err := h.processor.ProcessOperation(operation)
 	if err != nil {
-		logger.Errorf("internal server error:  %s", err.Error())
-		return nil, common.NewHTTPError(http.StatusInternalServerError, err)
+		h.releaseRateLimit(req, operation)
+
+		return nil, nil, "", processError(err)
 	}
 
-	return result, nil
+	receipt, err := h.getReceipt(operation, request, acceptedAt)
+	if err != nil {
+		// the operation has already been accepted so don't fail the request
+		logger.Errorf("Unable to create receipt for %s operation [%s]: %s", operation.Type, operation.ID, err)
+	}
+
+	return operation, result, receipt, nil
 }
 
-func (h *UpdateHandler) getOperation(operationBuffer []byte) (*batch.Operation, error) {
-	schema := &operationSchema{}
-	err := json.Unmarshal(operationBuffer, schema)
+func (h *UpdateHandler) getReceipt(operation *batch.Operation, request []byte, acceptedAt time.Time) (string, error) {
+	if h.signer == nil {
+		return "", nil
+	}
+
+	pv, err := h.processor.Protocol().Current()
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+
+	return newReceipt(h.signer, operation, request, pv.Protocol().HashAlgorithmInMultiHashCode, acceptedAt)
+}
+
+func (h *UpdateHandler) authorize(req *http.Request, operation *batch.Operation) *common.HTTPError {
+	if h.authorizer == nil {
+		return nil
+	}
+
+	err := h.authorizer.Authorize(req, operation)
+	if err == nil {
+		return nil
+	}
+
+	logger.Infof("%s operation for [%s] not authorized: %s", operation.Type, operation.ID, err)
+
+	return authorizationError(err)
+}
+
+func (h *UpdateHandler) checkRateLimit(req *http.Request, operation *batch.Operation) *common.HTTPError {
+	if h.limiter == nil {
+		return nil
 	}
 
-	protocol := h.processor.Protocol().Current()
+	pv, err := h.processor.Protocol().Current()
+	if err != nil {
+		return common.NewHTTPError(http.StatusInternalServerError, err)
+	}
 
-	var op *batch.Operation
-	var parseErr error
-	switch schema.Operation {
-	case model.OperationTypeCreate:
-		op, parseErr = operation.ParseCreateOperation(operationBuffer, protocol)
-	case model.OperationTypeUpdate:
-		op, parseErr = operation.ParseUpdateOperation(operationBuffer, protocol)
-	case model.OperationTypeDeactivate:
-		op, parseErr = operation.ParseDeactivateOperation(operationBuffer, protocol)
-	case model.OperationTypeRecover:
-		op, parseErr = operation.ParseRecoverOperation(operationBuffer, protocol)
+	if h.limiter.Allow(req, operation, pv.Protocol()) {
+		return nil
+	}
+
+	logger.Infof("%s operation for [%s] rejected since the rate limit was exceeded", operation.Type, operation.ID)
+
+	return common.NewHTTPErrorWithCode(http.StatusTooManyRequests, rateLimitedCode,
+		fmt.Errorf("rate limit exceeded for [%s]", operation.ID))
+}
+
+// processError maps the error returned by the document handler to an HTTP error. Rejections of the suffix
+// of a create operation are permanent so they are returned as 403 (deny-list) and 409 (collision). An operation
+// that exceeds the size limits of the protocol could never be anchored so it is returned as 413.
+func processError(err error) *common.HTTPError {
+	switch {
+	case errors.Is(err, dochandler.ErrOperationTooLarge):
+		logger.Infof("operation rejected: %s", err)
+		return common.NewHTTPErrorWithCode(http.StatusRequestEntityTooLarge, operationTooLargeCode, err)
+	case errors.Is(err, dochandler.ErrSuffixDenied):
+		logger.Infof("operation rejected: %s", err)
+		return common.NewHTTPErrorWithCode(http.StatusForbidden, suffixDeniedCode, err)
+	case errors.Is(err, dochandler.ErrSuffixCollision):
+		logger.Infof("operation rejected: %s", err)
+		return common.NewHTTPErrorWithCode(http.StatusConflict, suffixCollisionCode, err)
 	default:
-		return nil, fmt.Errorf("operation type [%s] not implemented", schema.Operation)
+		logger.Errorf("internal server error:  %s", err.Error())
+		return common.NewHTTPError(http.StatusInternalServerError, err)
 	}
+}
 
-	if parseErr != nil {
-		return nil, parseErr
+// releaseRateLimit releases the operation that was counted toward the rate limit (by checkRateLimit)
+// since it was not accepted
+func (h *UpdateHandler) releaseRateLimit(req *http.Request, operation *batch.Operation) {
+	if h.limiter == nil {
+		return
 	}
 
-	op.ID = h.processor.Namespace() + docutil.NamespaceDelimiter + op.UniqueSuffix
+	pv, err := h.processor.Protocol().Current()
+	if err != nil {
+		logger.Warnf("Unable to release rate limit for [%s]: %s", operation.ID, err)
+		return
+	}
 
-	return op, nil
+	h.limiter.Release(req, operation, pv.Protocol())
 }
 
-// operationSchema is used to get operation type
-type operationSchema struct {
+func (h *UpdateHandler) getOperation(operationBuffer []byte) (*batch.Operation, error) {
+	pv, err := h.processor.Protocol().Current()
+	if err != nil {
+		return nil, err
+	}
 
-	// operation
-	Operation model.OperationType `json:"type"`
+	return pv.OperationParser().Parse(h.processor.Namespace(), operationBuffer)
 }
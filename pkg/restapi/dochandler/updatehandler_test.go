@@ -13,6 +13,7 @@ import (
 	"crypto/rand"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -20,10 +21,14 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
@@ -75,6 +80,37 @@ func TestUpdateHandler_Update(t *testing.T) {
 		require.Equal(t, id, doc.ID())
 		require.Equal(t, len(doc.PublicKeys()), 1)
 	})
+	t.Run("Create - legacy reveal values", func(t *testing.T) {
+		pc := mocks.NewMockProtocolClient()
+		pc.Protocol.LegacyRevealValues = true
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(getLegacyCreateRequest(t)))
+		NewUpdateHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(pc)).Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, legacyRevealValuesWarning, rw.Header().Get(WarningHeader))
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Empty(t, rw.Header().Get(WarningHeader))
+	})
+	t.Run("Create - generic document", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		NewUpdateHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace), WithGenericDocuments()).Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("content-type"))
+
+		var result map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &result))
+		require.NotContains(t, result, "didDocument")
+
+		doc, err := document.FromBytes(result["document"])
+		require.NoError(t, err)
+		require.Equal(t, id, doc.ID())
+	})
 	t.Run("Update", func(t *testing.T) {
 		update, err := helper.NewUpdateRequest(getUpdateRequestInfo(uniqueSuffix))
 		require.NoError(t, err)
@@ -117,6 +153,183 @@ func TestUpdateHandler_Update(t *testing.T) {
 		handler.Update(rw, req)
 		require.Equal(t, http.StatusBadRequest, rw.Code)
 	})
+	t.Run("Invalid patch", func(t *testing.T) {
+		info := getUpdateRequestInfo(uniqueSuffix)
+		info.Patch = patch.Patch{patch.ActionKey: "replace"}
+
+		update, err := helper.NewUpdateRequest(info)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(update))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), `"code":"invalid_patch"`)
+		require.Contains(t, rw.Body.String(), "patch[0]: path /action")
+	})
+	t.Run("Metrics", func(t *testing.T) {
+		m := mocks.NewMockMetrics()
+		handler := NewUpdateHandler(docHandler, WithMetrics(m))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, 1, m.OperationRequests(batch.OperationTypeCreate))
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader([]byte(badRequest)))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Equal(t, 1, m.OperationParseFailures())
+	})
+	t.Run("Auth middleware and error mapper", func(t *testing.T) {
+		handler := NewUpdateHandler(docHandler,
+			WithMiddleware(common.NewAuthTokenMiddleware(func(token string) error {
+				if token != "secret" {
+					return errors.New("invalid token")
+				}
+				return nil
+			})),
+			WithErrorMapper(func(err *common.HTTPError) *common.HTTPError {
+				return common.NewHTTPError(http.StatusUnprocessableEntity, err)
+			}),
+		)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader([]byte(badRequest)))
+		req.Header.Set("Authorization", "Bearer secret")
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusUnprocessableEntity, rw.Code)
+	})
+	t.Run("Authorizer", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		handler := NewUpdateHandler(docHandler,
+			WithAuthorizer(NewDIDAllowlistAuthorizer(newMockDIDResolver(t, privateKey, "key-1", "ops"), "did:example:allowed")),
+		)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("content-type"))
+
+		var errResp common.ErrorResponse
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &errResp))
+		require.Equal(t, "unauthorized", errResp.Code)
+		require.Contains(t, errResp.Message, "missing requester DID")
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		req.Header.Set(RequesterDIDHeader, "did:example:other")
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusForbidden, rw.Code)
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &errResp))
+		require.Equal(t, "forbidden", errResp.Code)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		req.Header.Set(RequesterDIDHeader, "did:example:allowed")
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &errResp))
+		require.Contains(t, errResp.Message, "missing proof of control of requester DID")
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		req.Header.Set(RequesterDIDHeader, "did:example:allowed")
+		req.Header.Set(RequesterProofHeader, newRequesterProof(t, privateKey, "key-1", "did:example:allowed", create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		handler = NewUpdateHandler(docHandler,
+			WithAuthorizer(AuthorizerFunc(func(*http.Request, *batch.Operation) error {
+				return errors.New("authorizer error")
+			})),
+		)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+	t.Run("Rate limiter", func(t *testing.T) {
+		pc := mocks.NewMockProtocolClient()
+		pc.Protocol.MaxOperationsPerDID = 1
+
+		handler := NewUpdateHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(pc),
+			WithRateLimiter(NewWindowRateLimiter()),
+		)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusTooManyRequests, rw.Code)
+
+		var errResp common.ErrorResponse
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &errResp))
+		require.Equal(t, "rate_limited", errResp.Code)
+		require.Contains(t, errResp.Message, "rate limit exceeded")
+	})
+	t.Run("Rate limiter - rejected operations are not counted", func(t *testing.T) {
+		pc := mocks.NewMockProtocolClient()
+		pc.Protocol.MaxOperationsPerDID = 1
+
+		handler := NewUpdateHandler(
+			mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(pc).WithError(errors.New("process error")),
+			WithRateLimiter(NewWindowRateLimiter()),
+		)
+
+		for i := 0; i < 2; i++ {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+			handler.Update(rw, req)
+			require.Equal(t, http.StatusInternalServerError, rw.Code)
+		}
+	})
+	t.Run("Deactivate confirmation", func(t *testing.T) {
+		pc := mocks.NewMockProtocolClient()
+		pc.Protocol.DeactivateConfirmation = true
+
+		handler := NewUpdateHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace).WithProtocolClient(pc))
+
+		deactivate, err := helper.NewDeactivateRequest(getDeactivateRequestInfo(uniqueSuffix))
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(deactivate))
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusPreconditionRequired, rw.Code)
+
+		var errResp common.ErrorResponse
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &errResp))
+		require.Equal(t, "confirmation_required", errResp.Code)
+		require.Contains(t, errResp.Message, DeactivateConfirmationHeader)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(deactivate))
+		req.Header.Set(DeactivateConfirmationHeader, "other")
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusPreconditionRequired, rw.Code)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(deactivate))
+		req.Header.Set(DeactivateConfirmationHeader, "other, "+uniqueSuffix)
+		handler.Update(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+	})
 	t.Run("Error", func(t *testing.T) {
 		errExpected := errors.New("create doc error")
 		docHandlerWithErr := mocks.NewMockDocumentHandler().WithNamespace(namespace).WithError(errExpected)
@@ -128,6 +341,35 @@ func TestUpdateHandler_Update(t *testing.T) {
 		require.Equal(t, http.StatusInternalServerError, rw.Code)
 		require.Contains(t, rw.Body.String(), errExpected.Error())
 	})
+	t.Run("Operation rejected", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+			code   string
+		}{
+			{err: dochandler.ErrSuffixDenied, status: http.StatusForbidden, code: "suffix_denied"},
+			{err: fmt.Errorf("validate: %w", dochandler.ErrSuffixCollision), status: http.StatusConflict, code: "suffix_collision"},
+			{err: dochandler.ErrOperationTooLarge, status: http.StatusRequestEntityTooLarge, code: "operation_too_large"},
+		}
+
+		for _, tc := range tests {
+			handler := NewUpdateHandler(mocks.NewMockDocumentHandler().WithNamespace(namespace).WithError(tc.err))
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/document", bytes.NewReader(create))
+			handler.Update(rw, req)
+			require.Equal(t, tc.status, rw.Code)
+
+			var errResp common.ErrorResponse
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &errResp))
+			require.Equal(t, tc.code, errResp.Code)
+
+			// the rejection is permanent
+			status := client.StatusFromResponse(rw.Code, rw.Body.Bytes())
+			require.Equal(t, client.StatusRejected, status.Status)
+			require.False(t, status.Rejection.Retryable())
+		}
+	})
 }
 
 func TestGetOperation(t *testing.T) {
@@ -284,8 +526,37 @@ func computeMultihash(data string) string {
 	return docutil.EncodeToString(mh)
 }
 
+// getLegacyCreateRequest returns a create request in which the next recovery reveal value is provided
+// instead of the recovery commitment
+func getLegacyCreateRequest(t *testing.T) []byte {
+	create, err := helper.NewCreateRequest(getCreateRequestInfo())
+	require.NoError(t, err)
+
+	var createReq model.CreateRequest
+	require.NoError(t, json.Unmarshal(create, &createReq))
+
+	suffixDataBytes, err := docutil.DecodeString(createReq.SuffixData)
+	require.NoError(t, err)
+
+	var suffixData model.SuffixDataModel
+	require.NoError(t, json.Unmarshal(suffixDataBytes, &suffixData))
+
+	suffixData.RecoveryCommitment = ""
+	suffixData.NextRecoveryRevealValue = docutil.EncodeToString(recoveryReveal)
+
+	suffixDataBytes, err = docutil.MarshalCanonical(suffixData)
+	require.NoError(t, err)
+
+	createReq.SuffixData = docutil.EncodeToString(suffixDataBytes)
+
+	request, err := json.Marshal(createReq)
+	require.NoError(t, err)
+
+	return request
+}
+
 func getUnsupportedRequest() []byte {
-	schema := &operationSchema{
+	schema := &model.CreateRequest{
 		Operation: "unsupported",
 	}
 
@@ -0,0 +1,68 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+// ProtocolProvider provides the protocol client of a namespace
+type ProtocolProvider interface {
+	Namespace() string
+	Protocol() protocol.Client
+}
+
+// VersionResponse contains the current protocol parameters of a namespace
+type VersionResponse struct {
+	Namespace string            `json:"namespace"`
+	Protocol  protocol.Protocol `json:"protocol"`
+}
+
+// VersionHandler returns the current protocol parameters (including the allowed patch actions)
+type VersionHandler struct {
+	provider    ProtocolProvider
+	errorMapper common.ErrorMapper
+	generic     bool
+	handler     common.HTTPRequestHandler
+}
+
+// NewVersionHandler returns a new version handler
+func NewVersionHandler(provider ProtocolProvider, opts ...Option) *VersionHandler {
+	options := getOptions(opts...)
+
+	h := &VersionHandler{
+		provider:    provider,
+		errorMapper: options.ErrorMapper,
+		generic:     options.GenericDocuments,
+	}
+
+	h.handler = common.Chain(h.version, options.Middleware...)
+
+	return h
+}
+
+// Version returns the current protocol parameters
+func (h *VersionHandler) Version(rw http.ResponseWriter, req *http.Request) {
+	h.handler(rw, req)
+}
+
+func (h *VersionHandler) version(rw http.ResponseWriter, _ *http.Request) {
+	pv, err := h.provider.Protocol().Current()
+	if err != nil {
+		logger.Errorf("Unable to get current protocol version: %s", err)
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusInternalServerError, err))
+		return
+	}
+
+	writeResponse(rw, h.generic, &VersionResponse{
+		Namespace: h.provider.Namespace(),
+		Protocol:  pv.Protocol(),
+	})
+}
@@ -0,0 +1,77 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+func TestVersionHandler_Version(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		pc := mocks.NewMockProtocolClient()
+		pc.Protocol.Patches = []string{"add-public-keys", "remove-public-keys"}
+
+		docHandler := mocks.NewMockDocumentHandler().
+			WithNamespace(namespace).
+			WithProtocolClient(pc)
+
+		handler := NewVersionHandler(docHandler)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/version", nil)
+		handler.Version(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		var resp VersionResponse
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &resp))
+		require.Equal(t, namespace, resp.Namespace)
+		require.Equal(t, pc.Protocol, resp.Protocol)
+	})
+	t.Run("success - generic documents", func(t *testing.T) {
+		docHandler := mocks.NewMockDocumentHandler().WithNamespace(namespace)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/version", nil)
+		NewVersionHandler(docHandler, WithGenericDocuments()).Version(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("content-type"))
+	})
+	t.Run("protocol error", func(t *testing.T) {
+		docHandler := mocks.NewMockDocumentHandler().
+			WithNamespace(namespace).
+			WithProtocolClient(&errProtocolClient{err: errors.New("protocol error")})
+
+		handler := NewVersionHandler(docHandler)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/version", nil)
+		handler.Version(rw, req)
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		require.Contains(t, rw.Body.String(), "protocol error")
+	})
+}
+
+type errProtocolClient struct {
+	err error
+}
+
+func (m *errProtocolClient) Current() (protocol.Version, error) {
+	return nil, m.err
+}
+
+func (m *errProtocolClient) Get(uint64) (protocol.Version, error) {
+	return nil, m.err
+}
@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/sidetree-core-go/pkg/webhook"
+)
+
+// WebhookRegistrar registers the callback URLs of DIDs (implemented by webhook.Registrar)
+type WebhookRegistrar interface {
+	Register(request []byte) (*webhook.Registration, error)
+	Unregister(request []byte) error
+}
+
+// WebhookHandler registers and unregisters the callback URL that is notified when the operations of a DID
+// are anchored (or fail to be anchored). Requests must be signed with an operations key of the DID.
+type WebhookHandler struct {
+	registrar         WebhookRegistrar
+	errorMapper       common.ErrorMapper
+	registerHandler   common.HTTPRequestHandler
+	unregisterHandler common.HTTPRequestHandler
+}
+
+// NewWebhookHandler returns a new webhook registration handler
+func NewWebhookHandler(registrar WebhookRegistrar, opts ...Option) *WebhookHandler {
+	options := getOptions(opts...)
+
+	h := &WebhookHandler{
+		registrar:   registrar,
+		errorMapper: options.ErrorMapper,
+	}
+
+	h.registerHandler = common.Chain(h.register, options.Middleware...)
+	h.unregisterHandler = common.Chain(h.unregister, options.Middleware...)
+
+	return h
+}
+
+// Register registers the callback URL of a DID
+func (h *WebhookHandler) Register(rw http.ResponseWriter, req *http.Request) {
+	h.registerHandler(rw, req)
+}
+
+// Unregister removes the registered callback URL of a DID
+func (h *WebhookHandler) Unregister(rw http.ResponseWriter, req *http.Request) {
+	h.unregisterHandler(rw, req)
+}
+
+func (h *WebhookHandler) register(rw http.ResponseWriter, req *http.Request) {
+	request, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusBadRequest, err))
+		return
+	}
+
+	registration, err := h.registrar.Register(request)
+	if err != nil {
+		writeError(rw, h.errorMapper, webhookError(err))
+		return
+	}
+
+	common.WriteResponseWithContentType(rw, http.StatusOK, common.JSONContentType, registration)
+}
+
+func (h *WebhookHandler) unregister(rw http.ResponseWriter, req *http.Request) {
+	request, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		writeError(rw, h.errorMapper, common.NewHTTPError(http.StatusBadRequest, err))
+		return
+	}
+
+	if err := h.registrar.Unregister(request); err != nil {
+		writeError(rw, h.errorMapper, webhookError(err))
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func webhookError(err error) *common.HTTPError {
+	switch {
+	case errors.Is(err, webhook.ErrInvalidRequest):
+		return common.NewHTTPError(http.StatusBadRequest, err)
+	case errors.Is(err, webhook.ErrUnauthorized):
+		return common.NewHTTPErrorWithCode(http.StatusUnauthorized, unauthorizedCode, err)
+	case errors.Is(err, webhook.ErrNotFound):
+		return common.NewHTTPError(http.StatusNotFound, err)
+	default:
+		logger.Errorf("Webhook request failed: %s", err)
+		return common.NewHTTPError(http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dochandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/webhook"
+)
+
+func TestWebhookHandler_Register(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		registrar := &mockWebhookRegistrar{
+			registration: &webhook.Registration{UniqueSuffix: "suffix", CallbackURL: "https://example.com/callback"},
+		}
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader([]byte("request")))
+		NewWebhookHandler(registrar).Register(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("content-type"))
+		require.Equal(t, []byte("request"), registrar.request)
+
+		registration := &webhook.Registration{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), registration))
+		require.Equal(t, "https://example.com/callback", registration.CallbackURL)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+		}{
+			{fmt.Errorf("%w: bad request", webhook.ErrInvalidRequest), http.StatusBadRequest},
+			{fmt.Errorf("%w: bad signature", webhook.ErrUnauthorized), http.StatusUnauthorized},
+			{fmt.Errorf("%w: unknown did", webhook.ErrNotFound), http.StatusNotFound},
+			{errors.New("store error"), http.StatusInternalServerError},
+		}
+
+		for _, tc := range tests {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader([]byte("request")))
+			NewWebhookHandler(&mockWebhookRegistrar{err: tc.err}).Register(rw, req)
+			require.Equal(t, tc.status, rw.Code)
+			require.Contains(t, rw.Body.String(), tc.err.Error())
+		}
+	})
+}
+
+func TestWebhookHandler_Unregister(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/webhooks", bytes.NewReader([]byte("request")))
+		NewWebhookHandler(&mockWebhookRegistrar{}).Unregister(rw, req)
+		require.Equal(t, http.StatusNoContent, rw.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/webhooks", bytes.NewReader([]byte("request")))
+		NewWebhookHandler(&mockWebhookRegistrar{err: webhook.ErrNotFound}).Unregister(rw, req)
+		require.Equal(t, http.StatusNotFound, rw.Code)
+	})
+}
+
+type mockWebhookRegistrar struct {
+	registration *webhook.Registration
+	request      []byte
+	err          error
+}
+
+func (m *mockWebhookRegistrar) Register(request []byte) (*webhook.Registration, error) {
+	m.request = request
+
+	return m.registration, m.err
+}
+
+func (m *mockWebhookRegistrar) Unregister(request []byte) error {
+	m.request = request
+
+	return m.err
+}
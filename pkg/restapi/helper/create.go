@@ -8,9 +8,11 @@ package helper
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/canonicalizer"
+	internal "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/patch"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
@@ -35,6 +37,13 @@ type CreateRequestInfo struct {
 
 	// latest hashing algorithm supported by protocol
 	MultihashCode uint
+
+	// optional type of the DID (not set if zero)
+	Type uint
+
+	// minimum and maximum length of reveal values allowed by protocol (not checked if zero)
+	MinRevealValueLength uint
+	MaxRevealValueLength uint
 }
 
 // NewCreateRequest is utility function to create payload for 'create' request
@@ -67,6 +76,7 @@ func NewCreateRequest(info *CreateRequestInfo) ([]byte, error) {
 		DeltaHash:          mhDelta,
 		RecoveryKey:        info.RecoveryKey,
 		RecoveryCommitment: mhNextRecoveryCommitmentHash,
+		Type:               info.Type,
 	}
 
 	suffixDataBytes, err := canonicalizer.MarshalCanonical(suffixData)
@@ -88,6 +98,12 @@ func validateCreateRequest(info *CreateRequestInfo) error {
 		return errors.New("missing opaque document")
 	}
 
+	if err := validateRevealValues(info.MinRevealValueLength, info.MaxRevealValueLength,
+		revealValue{"next recovery", info.NextRecoveryRevealValue},
+		revealValue{"next update", info.NextUpdateRevealValue}); err != nil {
+		return err
+	}
+
 	return validateRecoveryKey(info.RecoveryKey)
 }
 
@@ -96,7 +112,11 @@ func validateRecoveryKey(key *jws.JWK) error {
 		return errors.New("missing recovery key")
 	}
 
-	return key.Validate()
+	if err := key.Validate(); err != nil {
+		return err
+	}
+
+	return internal.ValidatePublicKey(key)
 }
 
 func getEncodedMultihash(mhCode uint, bytes []byte) (string, error) {
@@ -121,3 +141,18 @@ func getDeltaBytes(mhCode uint, reveal []byte, patches []patch.Patch) ([]byte, e
 
 	return canonicalizer.MarshalCanonical(delta)
 }
+
+type revealValue struct {
+	name  string
+	value []byte
+}
+
+func validateRevealValues(minLength, maxLength uint, values ...revealValue) error {
+	for _, v := range values {
+		if err := docutil.ValidateRevealValue(v.value, minLength, maxLength); err != nil {
+			return fmt.Errorf("%s %s", v.name, err.Error())
+		}
+	}
+
+	return nil
+}
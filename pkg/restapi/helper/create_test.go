@@ -10,10 +10,13 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
 )
 
@@ -53,6 +56,19 @@ func TestNewCreateRequest(t *testing.T) {
 		require.Empty(t, request)
 		require.Contains(t, err.Error(), "algorithm not supported")
 	})
+	t.Run("reveal value too short", func(t *testing.T) {
+		info := &CreateRequestInfo{OpaqueDocument: "{}",
+			RecoveryKey:             jwk,
+			MultihashCode:           sha2_256,
+			NextRecoveryRevealValue: []byte("recoveryReveal"),
+			NextUpdateRevealValue:   []byte("short"),
+			MinRevealValueLength:    8}
+
+		request, err := NewCreateRequest(info)
+		require.Error(t, err)
+		require.Empty(t, request)
+		require.Contains(t, err.Error(), "next update reveal value length [5] is less than the minimum length [8]")
+	})
 	t.Run("success", func(t *testing.T) {
 		info := &CreateRequestInfo{OpaqueDocument: "{}",
 			RecoveryKey:   jwk,
@@ -62,4 +78,23 @@ func TestNewCreateRequest(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, request)
 	})
+	t.Run("success - with DID type", func(t *testing.T) {
+		info := &CreateRequestInfo{OpaqueDocument: "{}",
+			RecoveryKey:   jwk,
+			MultihashCode: sha2_256,
+			Type:          3}
+
+		request, err := NewCreateRequest(info)
+		require.NoError(t, err)
+
+		var create model.CreateRequest
+		require.NoError(t, json.Unmarshal(request, &create))
+
+		suffixDataBytes, err := docutil.DecodeString(create.SuffixData)
+		require.NoError(t, err)
+
+		var suffixData model.SuffixDataModel
+		require.NoError(t, json.Unmarshal(suffixDataBytes, &suffixData))
+		require.Equal(t, uint(3), suffixData.Type)
+	})
 }
@@ -8,6 +8,7 @@ package helper
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/internal/canonicalizer"
@@ -34,6 +35,10 @@ type DeactivateRequestInfo struct {
 	// reveal value for this deactivate operation
 	RecoveryRevealValue []byte
 
+	// optional window of transaction times within which the operation may be anchored (not checked if zero)
+	AnchorFrom  uint64
+	AnchorUntil uint64
+
 	// Signer that will be used for signing specific subset of request data
 	// Signer for recover operation must be recovery key
 	Signer Signer
@@ -46,6 +51,7 @@ func NewDeactivateRequest(info *DeactivateRequestInfo) ([]byte, error) {
 	}
 
 	signedDataModel := model.DeactivateSignedDataModel{
+		AnchorWindow:        model.AnchorWindow{AnchorFrom: info.AnchorFrom, AnchorUntil: info.AnchorUntil},
 		DidSuffix:           info.DidSuffix,
 		RecoveryRevealValue: docutil.EncodeToString(info.RecoveryRevealValue),
 	}
@@ -70,9 +76,21 @@ func validateDeactivateRequest(info *DeactivateRequestInfo) error {
 		return errors.New("missing did unique suffix")
 	}
 
+	if err := validateAnchorWindow(info.AnchorFrom, info.AnchorUntil); err != nil {
+		return err
+	}
+
 	return validateSigner(info.Signer, true)
 }
 
+func validateAnchorWindow(anchorFrom, anchorUntil uint64) error {
+	if anchorFrom > 0 && anchorUntil > 0 && anchorFrom > anchorUntil {
+		return fmt.Errorf("anchor from [%d] is greater than anchor until [%d]", anchorFrom, anchorUntil)
+	}
+
+	return nil
+}
+
 func validateSigner(signer Signer, recovery bool) error {
 	if signer == nil {
 		return errors.New("missing signer")
@@ -10,12 +10,15 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
 	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
 )
 
@@ -48,6 +51,43 @@ func TestNewDeactivateRequest(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, request)
 	})
+	t.Run("success - anchor window", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		info := &DeactivateRequestInfo{
+			DidSuffix:   "whatever",
+			AnchorFrom:  10,
+			AnchorUntil: 20,
+			Signer:      ecsigner.New(privateKey, "ES256", ""),
+		}
+
+		request, err := NewDeactivateRequest(info)
+		require.NoError(t, err)
+
+		req := &model.DeactivateRequest{}
+		require.NoError(t, json.Unmarshal(request, req))
+
+		payload, err := docutil.DecodeString(req.SignedData.Payload)
+		require.NoError(t, err)
+
+		signedData := &model.DeactivateSignedDataModel{}
+		require.NoError(t, json.Unmarshal(payload, signedData))
+		require.Equal(t, model.AnchorWindow{AnchorFrom: 10, AnchorUntil: 20}, signedData.AnchorWindow)
+	})
+	t.Run("invalid anchor window", func(t *testing.T) {
+		info := &DeactivateRequestInfo{
+			DidSuffix:   "whatever",
+			AnchorFrom:  20,
+			AnchorUntil: 10,
+			Signer:      NewMockSigner(nil, true),
+		}
+
+		request, err := NewDeactivateRequest(info)
+		require.Error(t, err)
+		require.Empty(t, request)
+		require.Contains(t, err.Error(), "anchor from [20] is greater than anchor until [10]")
+	})
 }
 
 func TestValidateSigner(t *testing.T) {
@@ -26,7 +26,8 @@ type RecoverRequestInfo struct {
 	// reveal value for this recovery operation
 	RecoveryRevealValue []byte
 
-	// the new recovery public key as a HEX string
+	// the new recovery public key which may be of a different key type than the current
+	// recovery key (e.g. P-256 to Ed25519 or secp256k1)
 	RecoveryKey *jws.JWK
 
 	// opaque content
@@ -41,8 +42,16 @@ type RecoverRequestInfo struct {
 	// latest hashing algorithm supported by protocol
 	MultihashCode uint
 
+	// minimum and maximum length of reveal values allowed by protocol (not checked if zero)
+	MinRevealValueLength uint
+	MaxRevealValueLength uint
+
+	// optional window of transaction times within which the operation may be anchored (not checked if zero)
+	AnchorFrom  uint64
+	AnchorUntil uint64
+
 	// Signer will be used for signing specific subset of request data
-	// Signer for recover operation must be recovery key
+	// Signer for recover operation must be the current recovery key (not the new recovery key)
 	Signer Signer
 }
 
@@ -74,6 +83,7 @@ func NewRecoverRequest(info *RecoverRequestInfo) ([]byte, error) {
 	}
 
 	signedDataModel := model.RecoverSignedDataModel{
+		AnchorWindow:       model.AnchorWindow{AnchorFrom: info.AnchorFrom, AnchorUntil: info.AnchorUntil},
 		DeltaHash:          docutil.EncodeToString(mhDelta),
 		RecoveryKey:        info.RecoveryKey,
 		RecoveryCommitment: mhNextRecoveryCommitmentHash,
@@ -104,6 +114,16 @@ func validateRecoverRequest(info *RecoverRequestInfo) error {
 		return errors.New("missing opaque document")
 	}
 
+	if err := validateRevealValues(info.MinRevealValueLength, info.MaxRevealValueLength,
+		revealValue{"next recovery", info.NextRecoveryRevealValue},
+		revealValue{"next update", info.NextUpdateRevealValue}); err != nil {
+		return err
+	}
+
+	if err := validateAnchorWindow(info.AnchorFrom, info.AnchorUntil); err != nil {
+		return err
+	}
+
 	if err := validateSigner(info.Signer, true); err != nil {
 		return err
 	}
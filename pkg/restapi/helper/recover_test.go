@@ -8,6 +8,7 @@ package helper
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/json"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
 	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
 )
@@ -39,6 +41,25 @@ func TestNewRecoverRequest(t *testing.T) {
 		require.Empty(t, request)
 		require.Contains(t, err.Error(), "missing opaque document")
 	})
+	t.Run("reveal value too short", func(t *testing.T) {
+		info := getRecoverRequestInfo()
+		info.MinRevealValueLength = 8
+
+		request, err := NewRecoverRequest(info)
+		require.Error(t, err)
+		require.Empty(t, request)
+		require.Contains(t, err.Error(), "next recovery reveal value length [0] is less than the minimum length [8]")
+	})
+	t.Run("invalid anchor window", func(t *testing.T) {
+		info := getRecoverRequestInfo()
+		info.AnchorFrom = 20
+		info.AnchorUntil = 10
+
+		request, err := NewRecoverRequest(info)
+		require.Error(t, err)
+		require.Empty(t, request)
+		require.Contains(t, err.Error(), "anchor from [20] is greater than anchor until [10]")
+	})
 	t.Run("missing recovery key", func(t *testing.T) {
 		info := getRecoverRequestInfo()
 		info.RecoveryKey = nil
@@ -48,6 +69,15 @@ func TestNewRecoverRequest(t *testing.T) {
 		require.Empty(t, request)
 		require.Contains(t, err.Error(), "missing recovery key")
 	})
+	t.Run("unsupported recovery key", func(t *testing.T) {
+		info := getRecoverRequestInfo()
+		info.RecoveryKey = &jws.JWK{Kty: "RSA", Crv: "crv", X: "x"}
+
+		request, err := NewRecoverRequest(info)
+		require.Error(t, err)
+		require.Empty(t, request)
+		require.Contains(t, err.Error(), "'RSA' key type is not supported")
+	})
 	t.Run("missing signer", func(t *testing.T) {
 		info := getRecoverRequestInfo()
 		info.Signer = nil
@@ -90,6 +120,20 @@ func TestNewRecoverRequest(t *testing.T) {
 		require.Equal(t, "recover", request["type"])
 		require.Equal(t, didSuffix, request["did_suffix"])
 	})
+	t.Run("success - new recovery key of different key type", func(t *testing.T) {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := pubkey.GetPublicKeyJWK(pubKey)
+		require.NoError(t, err)
+
+		info := getRecoverRequestInfo()
+		info.RecoveryKey = jwk
+
+		bytes, err := NewRecoverRequest(info)
+		require.NoError(t, err)
+		require.NotEmpty(t, bytes)
+	})
 }
 
 func getRecoverRequestInfo() *RecoverRequestInfo {
@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+// SizeReport contains the encoded sizes (in bytes) of the parts of an operation request
+// along with the protocol limits that apply to them
+type SizeReport struct {
+	// Operation is the operation type of the request
+	Operation model.OperationType
+
+	// SuffixData is the size of the encoded suffix data (create only)
+	SuffixData int
+
+	// Delta is the size of the encoded delta (create, update and recover)
+	Delta int
+
+	// SignedData is the size of the serialized signed data (update, recover and deactivate)
+	SignedData int
+
+	// Total is the size of the whole request
+	Total int
+
+	// MaxDeltaByteSize is the maximum size of the encoded delta allowed by the protocol
+	MaxDeltaByteSize uint
+}
+
+// DeltaExceedsLimit returns true if the encoded delta exceeds the protocol limit
+func (r *SizeReport) DeltaExceedsLimit() bool {
+	return r.Delta > int(r.MaxDeltaByteSize)
+}
+
+// Validate returns an error if any of the sizes exceeds the protocol limits
+func (r *SizeReport) Validate() error {
+	if r.DeltaExceedsLimit() {
+		return fmt.Errorf("delta byte size [%d] exceeds protocol max delta byte size [%d]", r.Delta, r.MaxDeltaByteSize)
+	}
+
+	return nil
+}
+
+// GetCreateRequestSizeReport creates the 'create' request for the given info and reports its sizes
+func GetCreateRequestSizeReport(info *CreateRequestInfo, p protocol.Protocol) (*SizeReport, error) {
+	request, err := NewCreateRequest(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSizeReport(request, p)
+}
+
+// GetUpdateRequestSizeReport creates the 'update' request for the given info and reports its sizes.
+// Note that the request is signed using the signer provided in the info.
+func GetUpdateRequestSizeReport(info *UpdateRequestInfo, p protocol.Protocol) (*SizeReport, error) {
+	request, err := NewUpdateRequest(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSizeReport(request, p)
+}
+
+// GetRecoverRequestSizeReport creates the 'recover' request for the given info and reports its sizes.
+// Note that the request is signed using the signer provided in the info.
+func GetRecoverRequestSizeReport(info *RecoverRequestInfo, p protocol.Protocol) (*SizeReport, error) {
+	request, err := NewRecoverRequest(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSizeReport(request, p)
+}
+
+// GetDeactivateRequestSizeReport creates the 'deactivate' request for the given info and reports its sizes.
+// Note that the request is signed using the signer provided in the info.
+func GetDeactivateRequestSizeReport(info *DeactivateRequestInfo, p protocol.Protocol) (*SizeReport, error) {
+	request, err := NewDeactivateRequest(info)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSizeReport(request, p)
+}
+
+// requestParts contains the parts of any operation request that are subject to size limits
+type requestParts struct {
+	Operation  model.OperationType `json:"type"`
+	SuffixData string              `json:"suffix_data"`
+	Delta      string              `json:"delta"`
+	SignedData json.RawMessage     `json:"signed_data"`
+}
+
+// GetSizeReport reports the sizes of the given operation request
+func GetSizeReport(request []byte, p protocol.Protocol) (*SizeReport, error) {
+	parts := &requestParts{}
+	if err := json.Unmarshal(request, parts); err != nil {
+		return nil, err
+	}
+
+	return &SizeReport{
+		Operation:        parts.Operation,
+		SuffixData:       len(parts.SuffixData),
+		Delta:            len(parts.Delta),
+		SignedData:       len(parts.SignedData),
+		Total:            len(request),
+		MaxDeltaByteSize: p.MaxDeltaByteSize,
+	}, nil
+}
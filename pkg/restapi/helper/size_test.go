@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package helper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+func TestGetSizeReport(t *testing.T) {
+	p := protocol.Protocol{MaxDeltaByteSize: 2000}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("create", func(t *testing.T) {
+		jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		info := &CreateRequestInfo{OpaqueDocument: "{}", RecoveryKey: jwk, MultihashCode: sha2_256}
+
+		report, err := GetCreateRequestSizeReport(info, p)
+		require.NoError(t, err)
+		require.Equal(t, model.OperationTypeCreate, report.Operation)
+
+		request, err := NewCreateRequest(info)
+		require.NoError(t, err)
+
+		var createReq model.CreateRequest
+		require.NoError(t, json.Unmarshal(request, &createReq))
+
+		require.Equal(t, len(createReq.SuffixData), report.SuffixData)
+		require.Equal(t, len(createReq.Delta), report.Delta)
+		require.Zero(t, report.SignedData)
+		require.Equal(t, len(request), report.Total)
+		require.Equal(t, p.MaxDeltaByteSize, report.MaxDeltaByteSize)
+		require.False(t, report.DeltaExceedsLimit())
+		require.NoError(t, report.Validate())
+	})
+	t.Run("create - delta exceeds limit", func(t *testing.T) {
+		jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		info := &CreateRequestInfo{OpaqueDocument: "{}", RecoveryKey: jwk, MultihashCode: sha2_256}
+
+		report, err := GetCreateRequestSizeReport(info, protocol.Protocol{MaxDeltaByteSize: 10})
+		require.NoError(t, err)
+		require.True(t, report.DeltaExceedsLimit())
+
+		err = report.Validate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "exceeds protocol max delta byte size [10]")
+	})
+	t.Run("create - error", func(t *testing.T) {
+		report, err := GetCreateRequestSizeReport(&CreateRequestInfo{}, p)
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+	t.Run("update", func(t *testing.T) {
+		patch, err := getTestPatch()
+		require.NoError(t, err)
+
+		info := &UpdateRequestInfo{
+			DidSuffix:     didSuffix,
+			Patch:         patch,
+			MultihashCode: sha2_256,
+			Signer:        ecsigner.New(privateKey, "ES256", "key-1"),
+		}
+
+		report, err := GetUpdateRequestSizeReport(info, p)
+		require.NoError(t, err)
+		require.Equal(t, model.OperationTypeUpdate, report.Operation)
+		require.Zero(t, report.SuffixData)
+		require.NotZero(t, report.Delta)
+		require.NotZero(t, report.SignedData)
+		require.True(t, report.Total > report.Delta+report.SignedData)
+	})
+	t.Run("update - error", func(t *testing.T) {
+		report, err := GetUpdateRequestSizeReport(&UpdateRequestInfo{}, p)
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+	t.Run("recover", func(t *testing.T) {
+		report, err := GetRecoverRequestSizeReport(getRecoverRequestInfo(), p)
+		require.NoError(t, err)
+		require.Equal(t, model.OperationTypeRecover, report.Operation)
+		require.NotZero(t, report.Delta)
+		require.NotZero(t, report.SignedData)
+	})
+	t.Run("recover - error", func(t *testing.T) {
+		report, err := GetRecoverRequestSizeReport(&RecoverRequestInfo{}, p)
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+	t.Run("deactivate", func(t *testing.T) {
+		info := &DeactivateRequestInfo{DidSuffix: didSuffix, Signer: ecsigner.New(privateKey, "ES256", "")}
+
+		report, err := GetDeactivateRequestSizeReport(info, p)
+		require.NoError(t, err)
+		require.Equal(t, model.OperationTypeDeactivate, report.Operation)
+		require.Zero(t, report.Delta)
+		require.NotZero(t, report.SignedData)
+	})
+	t.Run("deactivate - error", func(t *testing.T) {
+		report, err := GetDeactivateRequestSizeReport(&DeactivateRequestInfo{}, p)
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+	t.Run("invalid request", func(t *testing.T) {
+		report, err := GetSizeReport([]byte("invalid"), p)
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+}
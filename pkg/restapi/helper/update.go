@@ -35,6 +35,14 @@ type UpdateRequestInfo struct {
 	// latest hashing algorithm supported by protocol
 	MultihashCode uint
 
+	// minimum and maximum length of reveal values allowed by protocol (not checked if zero)
+	MinRevealValueLength uint
+	MaxRevealValueLength uint
+
+	// optional window of transaction times within which the operation may be anchored (not checked if zero)
+	AnchorFrom  uint64
+	AnchorUntil uint64
+
 	// Signer that will be used for signing request specific subset of data
 	Signer Signer
 }
@@ -57,7 +65,8 @@ func NewUpdateRequest(info *UpdateRequestInfo) ([]byte, error) {
 	}
 
 	signedDataModel := model.UpdateSignedDataModel{
-		DeltaHash: mhDelta,
+		AnchorWindow: model.AnchorWindow{AnchorFrom: info.AnchorFrom, AnchorUntil: info.AnchorUntil},
+		DeltaHash:    mhDelta,
 	}
 
 	jws, err := signutil.SignModel(signedDataModel, info.Signer)
@@ -85,5 +94,14 @@ func validateUpdateRequest(info *UpdateRequestInfo) error {
 		return errors.New("missing update information")
 	}
 
+	if err := validateRevealValues(info.MinRevealValueLength, info.MaxRevealValueLength,
+		revealValue{"next update", info.NextUpdateRevealValue}); err != nil {
+		return err
+	}
+
+	if err := validateAnchorWindow(info.AnchorFrom, info.AnchorUntil); err != nil {
+		return err
+	}
+
 	return validateSigner(info.Signer, false)
 }
@@ -43,6 +43,33 @@ func TestNewUpdateRequest(t *testing.T) {
 		require.Empty(t, request)
 		require.Contains(t, err.Error(), "missing update information")
 	})
+	t.Run("reveal value too long", func(t *testing.T) {
+		info := &UpdateRequestInfo{
+			DidSuffix:             didSuffix,
+			Patch:                 patch,
+			UpdateRevealValue:     []byte("reveal"),
+			NextUpdateRevealValue: []byte("updateReveal"),
+			MaxRevealValueLength:  8,
+			Signer:                signer}
+
+		request, err := NewUpdateRequest(info)
+		require.Error(t, err)
+		require.Empty(t, request)
+		require.Contains(t, err.Error(), "next update reveal value length [12] exceeds the maximum length [8]")
+	})
+	t.Run("invalid anchor window", func(t *testing.T) {
+		info := &UpdateRequestInfo{
+			DidSuffix:   didSuffix,
+			Patch:       patch,
+			AnchorFrom:  20,
+			AnchorUntil: 10,
+			Signer:      signer}
+
+		request, err := NewUpdateRequest(info)
+		require.Error(t, err)
+		require.Empty(t, request)
+		require.Contains(t, err.Error(), "anchor from [20] is greater than anchor until [10]")
+	})
 	t.Run("multihash not supported", func(t *testing.T) {
 		info := &UpdateRequestInfo{
 			DidSuffix: didSuffix,
@@ -37,6 +37,12 @@ type SuffixDataModel struct {
 
 	// Initial recovery commitment
 	RecoveryCommitment string `json:"recovery_commitment"`
+
+	// Deprecated: legacy clients send the next recovery reveal value instead of the recovery commitment
+	NextRecoveryRevealValue string `json:"next_recovery_reveal_value,omitempty"`
+
+	// Type is the optional type of the DID (classifies the DID at creation time). Zero means that no type is set.
+	Type uint `json:"type,omitempty"`
 }
 
 // DeltaModel contains patch data (patches used for create, recover, update)
@@ -45,6 +51,9 @@ type DeltaModel struct {
 	// Commitment hash for the next update operation
 	UpdateCommitment string `json:"update_commitment"`
 
+	// Deprecated: legacy clients send the next update reveal value instead of the update commitment
+	NextUpdateRevealValue string `json:"next_update_reveal_value,omitempty"`
+
 	// Patches defines document patches
 	Patches []patch.Patch `json:"patches"`
 }
@@ -84,8 +93,20 @@ type DeactivateRequest struct {
 	SignedData *JWS `json:"signed_data"`
 }
 
+// AnchorWindow defines the window of transaction times within which an operation may be anchored. This prevents
+// an operation that was signed but never submitted from being anchored (replayed) indefinitely.
+type AnchorWindow struct {
+
+	// AnchorFrom is the earliest transaction time at which the operation may be anchored. Not checked if zero.
+	AnchorFrom uint64 `json:"anchor_from,omitempty"`
+
+	// AnchorUntil is the latest transaction time at which the operation may be anchored. Not checked if zero.
+	AnchorUntil uint64 `json:"anchor_until,omitempty"`
+}
+
 // UpdateSignedDataModel defines signed data model for update
 type UpdateSignedDataModel struct {
+	AnchorWindow
 
 	// Hash of the unsigned delta object
 	DeltaHash string `json:"delta_hash"`
@@ -93,6 +114,7 @@ type UpdateSignedDataModel struct {
 
 // RecoverSignedDataModel defines signed data model for recovery
 type RecoverSignedDataModel struct {
+	AnchorWindow
 
 	// Hash of the unsigned delta object
 	DeltaHash string `json:"delta_hash"`
@@ -102,10 +124,14 @@ type RecoverSignedDataModel struct {
 
 	// Recovery commitment be used for the next recovery/deactivate
 	RecoveryCommitment string `json:"recovery_commitment"`
+
+	// Deprecated: legacy clients send the next recovery reveal value instead of the recovery commitment
+	NextRecoveryRevealValue string `json:"next_recovery_reveal_value,omitempty"`
 }
 
 // DeactivateSignedDataModel defines data model for deactivate
 type DeactivateSignedDataModel struct {
+	AnchorWindow
 
 	//The suffix of the DID
 	// Required: true
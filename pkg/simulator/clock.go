@@ -0,0 +1,105 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simulator
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a deterministic clock that only moves forward when Advance or Set is called.
+// Timers created with After fire when the clock is advanced past their deadline.
+type Clock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+type timer struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewClock returns a new deterministic clock set to the given time
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the current time of the clock
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// After returns a channel that receives the clock time once the clock has been advanced by at least d
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch := make(chan time.Time, 1)
+
+	deadline := c.now.Add(d)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.timers = append(c.timers, &timer{deadline: deadline, ch: ch})
+
+	return ch
+}
+
+// Since returns the time elapsed since t according to the clock
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Advance moves the clock forward by d and fires all timers whose deadline has passed
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set sets the clock to the given time (which must not be before the current time) and fires
+// all timers whose deadline has passed
+func (c *Clock) Set(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if t.Before(c.now) {
+		return
+	}
+
+	c.now = t
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+
+	var pending []*timer
+
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+
+		t.ch <- c.now
+	}
+
+	c.timers = pending
+}
+
+// Timers returns the number of timers that have not fired yet
+func (c *Clock) Timers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.timers)
+}
@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewClock(start)
+	require.Equal(t, start, c.Now())
+
+	t1 := c.After(time.Second)
+	t2 := c.After(2 * time.Second)
+	require.Equal(t, 2, c.Timers())
+
+	c.Advance(500 * time.Millisecond)
+	require.Equal(t, 500*time.Millisecond, c.Since(start))
+
+	select {
+	case <-t1:
+		t.Fatal("timer should not have fired")
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+	require.Equal(t, start.Add(time.Second), <-t1)
+	require.Equal(t, 1, c.Timers())
+
+	// setting the clock back is ignored
+	c.Set(start)
+	require.Equal(t, start.Add(time.Second), c.Now())
+
+	c.Set(start.Add(time.Minute))
+	require.Equal(t, start.Add(time.Minute), <-t2)
+	require.Equal(t, 0, c.Timers())
+
+	require.Equal(t, c.Now(), <-c.After(0))
+}
@@ -0,0 +1,14 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package simulator provides a deterministic clock and a scriptable ledger that the batch writer and
+// the observer can run against in order to write reproducible end-to-end scenario tests.
+//
+// The ledger implements both the batch writer's BlockchainClient and the observer's Ledger. Anchors that
+// are written remain pending until a block is mined (Mine or MineAt) and the chain may be reorganized
+// (Reorg), in which case the orphaned anchors are returned to the pending anchors and re-mined in a
+// subsequent block with a new transaction time and number.
+package simulator
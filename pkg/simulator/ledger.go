@@ -0,0 +1,287 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+const defaultChannelSize = 100
+
+// Block is a block of the simulated ledger
+type Block struct {
+	Time         uint64
+	Transactions []observer.SidetreeTxn
+}
+
+// LedgerOption is a ledger option
+type LedgerOption func(l *Ledger)
+
+// WithAutoMine mines a new block (containing the anchor) for every anchor that is written
+func WithAutoMine() LedgerOption {
+	return func(l *Ledger) {
+		l.autoMine = true
+	}
+}
+
+// WithWriteError sets the error that is returned when an anchor is written (nil to clear the error)
+func WithWriteError(err error) LedgerOption {
+	return func(l *Ledger) {
+		l.writeErr = err
+	}
+}
+
+// Ledger is a scriptable ledger that can be used by the batch writer (as the blockchain client) and the
+// observer (as the ledger). Anchors that are written to the ledger remain pending until a block is mined.
+// Transaction times are the block times, which can be programmed with MineAt, and the chain can be
+// reorganized with Reorg.
+type Ledger struct {
+	mutex       sync.RWMutex
+	blocks      []*Block
+	pending     []string
+	subscribers []chan []observer.SidetreeTxn
+	autoMine    bool
+	writeErr    error
+}
+
+// NewLedger returns a new simulated ledger
+func NewLedger(opts ...LedgerOption) *Ledger {
+	l := &Ledger{}
+
+	l.Configure(opts...)
+
+	return l
+}
+
+// Configure applies the given options to the ledger
+func (l *Ledger) Configure(opts ...LedgerOption) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, opt := range opts {
+		opt(l)
+	}
+}
+
+// WriteAnchor adds the anchor to the pending anchors (or mines a new block if auto-mine is enabled)
+func (l *Ledger) WriteAnchor(anchor string) error {
+	l.mutex.Lock()
+
+	if l.writeErr != nil {
+		l.mutex.Unlock()
+		return l.writeErr
+	}
+
+	l.pending = append(l.pending, anchor)
+
+	if !l.autoMine {
+		l.mutex.Unlock()
+		return nil
+	}
+
+	block, subscribers := l.mine(l.nextTime())
+
+	l.mutex.Unlock()
+
+	publish(subscribers, block)
+
+	return nil
+}
+
+// Read returns the transaction that follows the given transaction number and a flag indicating
+// whether or not there are more transactions after the returned transaction
+func (l *Ledger) Read(sinceTransactionNumber int) (bool, *observer.SidetreeTxn) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	txns := l.transactions()
+
+	for i, txn := range txns {
+		if int(txn.TransactionNumber) > sinceTransactionNumber {
+			t := txn
+			return i < len(txns)-1, &t
+		}
+	}
+
+	return false, nil
+}
+
+// RegisterForSidetreeTxn returns a channel that receives the transactions of every block that is mined
+func (l *Ledger) RegisterForSidetreeTxn() <-chan []observer.SidetreeTxn {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	ch := make(chan []observer.SidetreeTxn, defaultChannelSize)
+	l.subscribers = append(l.subscribers, ch)
+
+	return ch
+}
+
+// Mine mines a new block containing all pending anchors. The block time is the time of
+// the previous block plus one.
+func (l *Ledger) Mine() *Block {
+	l.mutex.Lock()
+
+	block, subscribers := l.mine(l.nextTime())
+
+	l.mutex.Unlock()
+
+	publish(subscribers, block)
+
+	return block
+}
+
+// MineAt mines a new block with the given time containing all pending anchors. The time must
+// be greater than the time of the previous block.
+func (l *Ledger) MineAt(time uint64) (*Block, error) {
+	l.mutex.Lock()
+
+	if time < l.nextTime() {
+		l.mutex.Unlock()
+		return nil, fmt.Errorf("block time [%d] must be greater than the time of the last block [%d]", time, l.nextTime()-1)
+	}
+
+	block, subscribers := l.mine(time)
+
+	l.mutex.Unlock()
+
+	publish(subscribers, block)
+
+	return block, nil
+}
+
+// Reorg removes the given number of blocks from the tip of the chain and returns the orphaned
+// transactions. The anchors of the orphaned transactions are returned to the pending anchors
+// (in their original order) so that they are included in the next mined block.
+func (l *Ledger) Reorg(depth int) ([]observer.SidetreeTxn, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if depth <= 0 || depth > len(l.blocks) {
+		return nil, fmt.Errorf("invalid reorg depth [%d] - chain has %d blocks", depth, len(l.blocks))
+	}
+
+	orphaned := l.blocks[len(l.blocks)-depth:]
+	l.blocks = l.blocks[:len(l.blocks)-depth]
+
+	var txns []observer.SidetreeTxn
+	var anchors []string
+
+	for _, block := range orphaned {
+		for _, txn := range block.Transactions {
+			txns = append(txns, txn)
+			anchors = append(anchors, txn.AnchorAddress)
+		}
+	}
+
+	l.pending = append(anchors, l.pending...)
+
+	return txns, nil
+}
+
+// Drop removes the given anchor from the pending anchors (e.g. to simulate an anchor that was orphaned
+// by a reorg and never re-mined)
+func (l *Ledger) Drop(anchor string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for i, a := range l.pending {
+		if a == anchor {
+			l.pending = append(l.pending[:i], l.pending[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New("anchor not found")
+}
+
+// Blocks returns the blocks of the chain
+func (l *Ledger) Blocks() []*Block {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return append([]*Block(nil), l.blocks...)
+}
+
+// Transactions returns all of the transactions in the chain
+func (l *Ledger) Transactions() []observer.SidetreeTxn {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.transactions()
+}
+
+// Pending returns the anchors that have been written but not yet mined
+func (l *Ledger) Pending() []string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return append([]string(nil), l.pending...)
+}
+
+func (l *Ledger) transactions() []observer.SidetreeTxn {
+	var txns []observer.SidetreeTxn
+	for _, block := range l.blocks {
+		txns = append(txns, block.Transactions...)
+	}
+
+	return txns
+}
+
+func (l *Ledger) nextTime() uint64 {
+	if len(l.blocks) == 0 {
+		return 1
+	}
+
+	return l.blocks[len(l.blocks)-1].Time + 1
+}
+
+func (l *Ledger) nextTransactionNumber() uint64 {
+	for i := len(l.blocks) - 1; i >= 0; i-- {
+		txns := l.blocks[i].Transactions
+		if len(txns) > 0 {
+			return txns[len(txns)-1].TransactionNumber + 1
+		}
+	}
+
+	return 0
+}
+
+func (l *Ledger) mine(time uint64) (*Block, []chan []observer.SidetreeTxn) {
+	block := &Block{Time: time}
+
+	txnNum := l.nextTransactionNumber()
+
+	for _, anchor := range l.pending {
+		block.Transactions = append(block.Transactions, observer.SidetreeTxn{
+			TransactionTime:   time,
+			TransactionNumber: txnNum,
+			AnchorAddress:     anchor,
+		})
+
+		txnNum++
+	}
+
+	l.pending = nil
+	l.blocks = append(l.blocks, block)
+
+	return block, append([]chan []observer.SidetreeTxn(nil), l.subscribers...)
+}
+
+func publish(subscribers []chan []observer.SidetreeTxn, block *Block) {
+	if len(block.Transactions) == 0 {
+		return
+	}
+
+	for _, ch := range subscribers {
+		ch <- block.Transactions
+	}
+}
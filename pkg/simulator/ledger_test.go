@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simulator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+)
+
+func TestLedger_Mine(t *testing.T) {
+	l := NewLedger()
+	txnsCh := l.RegisterForSidetreeTxn()
+
+	require.NoError(t, l.WriteAnchor("a1"))
+	require.NoError(t, l.WriteAnchor("a2"))
+	require.Equal(t, []string{"a1", "a2"}, l.Pending())
+
+	more, txn := l.Read(-1)
+	require.False(t, more)
+	require.Nil(t, txn)
+
+	block := l.Mine()
+	require.Equal(t, uint64(1), block.Time)
+	require.Len(t, block.Transactions, 2)
+	require.Empty(t, l.Pending())
+
+	txns := <-txnsCh
+	require.Equal(t, []observer.SidetreeTxn{
+		{TransactionTime: 1, TransactionNumber: 0, AnchorAddress: "a1"},
+		{TransactionTime: 1, TransactionNumber: 1, AnchorAddress: "a2"},
+	}, txns)
+
+	// empty blocks are not published
+	require.Equal(t, uint64(2), l.Mine().Time)
+
+	require.NoError(t, l.WriteAnchor("a3"))
+
+	_, err := l.MineAt(2)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "must be greater than the time of the last block [2]")
+
+	block, err = l.MineAt(100)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), block.Time)
+
+	txns = <-txnsCh
+	require.Equal(t, []observer.SidetreeTxn{{TransactionTime: 100, TransactionNumber: 2, AnchorAddress: "a3"}}, txns)
+
+	more, txn = l.Read(-1)
+	require.True(t, more)
+	require.Equal(t, "a1", txn.AnchorAddress)
+
+	more, txn = l.Read(1)
+	require.False(t, more)
+	require.Equal(t, "a3", txn.AnchorAddress)
+
+	more, txn = l.Read(2)
+	require.False(t, more)
+	require.Nil(t, txn)
+
+	require.Len(t, l.Blocks(), 3)
+	require.Len(t, l.Transactions(), 3)
+}
+
+func TestLedger_AutoMine(t *testing.T) {
+	l := NewLedger(WithAutoMine())
+	txnsCh := l.RegisterForSidetreeTxn()
+
+	require.NoError(t, l.WriteAnchor("a1"))
+	require.NoError(t, l.WriteAnchor("a2"))
+
+	require.Equal(t, []observer.SidetreeTxn{{TransactionTime: 1, TransactionNumber: 0, AnchorAddress: "a1"}}, <-txnsCh)
+	require.Equal(t, []observer.SidetreeTxn{{TransactionTime: 2, TransactionNumber: 1, AnchorAddress: "a2"}}, <-txnsCh)
+
+	errExpected := errors.New("injected write error")
+	l.Configure(WithWriteError(errExpected))
+	require.Equal(t, errExpected, l.WriteAnchor("a3"))
+
+	l.Configure(WithWriteError(nil))
+	require.NoError(t, l.WriteAnchor("a3"))
+}
+
+func TestLedger_Reorg(t *testing.T) {
+	l := NewLedger()
+
+	require.NoError(t, l.WriteAnchor("a1"))
+	l.Mine()
+	require.NoError(t, l.WriteAnchor("a2"))
+	require.NoError(t, l.WriteAnchor("a3"))
+	l.Mine()
+	require.NoError(t, l.WriteAnchor("a4"))
+
+	_, err := l.Reorg(3)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid reorg depth [3]")
+
+	orphaned, err := l.Reorg(1)
+	require.NoError(t, err)
+	require.Equal(t, []observer.SidetreeTxn{
+		{TransactionTime: 2, TransactionNumber: 1, AnchorAddress: "a2"},
+		{TransactionTime: 2, TransactionNumber: 2, AnchorAddress: "a3"},
+	}, orphaned)
+	require.Equal(t, []string{"a2", "a3", "a4"}, l.Pending())
+
+	require.NoError(t, l.Drop("a3"))
+	require.Error(t, l.Drop("a3"))
+
+	block, err := l.MineAt(5)
+	require.NoError(t, err)
+	require.Equal(t, []observer.SidetreeTxn{
+		{TransactionTime: 5, TransactionNumber: 1, AnchorAddress: "a2"},
+		{TransactionTime: 5, TransactionNumber: 2, AnchorAddress: "a4"},
+	}, block.Transactions)
+}
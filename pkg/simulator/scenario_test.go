@@ -0,0 +1,303 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simulator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	writer "github.com/trustbloc/sidetree-core-go/pkg/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/batch/cutter"
+	"github.com/trustbloc/sidetree-core-go/pkg/batch/opqueue"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/observer"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+const (
+	sha2_256     = 18
+	namespace    = "did:sidetree"
+	batchTimeout = 10 * time.Second
+)
+
+func TestScenario_Reorg(t *testing.T) {
+	clock := NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	ledger := NewLedger()
+	cas := mocks.NewMockCasClient(nil)
+	opStore := newMockOpStore()
+
+	ctx := &context{
+		pc:     mocks.NewMockProtocolClient(),
+		cas:    cas,
+		ledger: ledger,
+		queue:  &opqueue.MemQueue{},
+	}
+
+	w, err := writer.New("test", ctx, writer.WithBatchTimeout(batchTimeout), writer.WithClock(clock))
+	require.NoError(t, err)
+
+	// operations that are queued before the writer is started are anchored immediately on startup
+	require.NoError(t, w.Add(newOperation(t, "suffix1")))
+
+	w.Start()
+	defer w.Stop()
+
+	o := observer.New(&observer.Providers{
+		Ledger:           ledger,
+		DCASClient:       cas,
+		OpStoreProvider:  opStore,
+		OpFilterProvider: &observer.NoopOperationFilterProvider{},
+	})
+
+	o.Start()
+	defer o.Stop()
+
+	require.Eventually(t, func() bool { return len(ledger.Pending()) == 1 }, time.Second, 10*time.Millisecond)
+	timers := clock.Timers()
+
+	// a single operation doesn't fill a batch, so nothing is anchored until the batch timeout
+	require.NoError(t, w.Add(newOperation(t, "suffix2")))
+	require.Eventually(t, func() bool { return clock.Timers() == timers+1 }, time.Second, 10*time.Millisecond)
+	require.Len(t, ledger.Pending(), 1)
+
+	clock.Advance(batchTimeout)
+	require.Eventually(t, func() bool { return len(ledger.Pending()) == 2 }, time.Second, 10*time.Millisecond)
+
+	_, err = ledger.MineAt(10)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(opStore.get("suffix1")) == 1 && len(opStore.get("suffix2")) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, uint64(10), opStore.get("suffix1")[0].TransactionTime)
+	require.Equal(t, uint64(10), opStore.get("suffix2")[0].TransactionTime)
+
+	// reorg the block - the anchors are re-mined in a later block with a new transaction time
+	orphaned, err := ledger.Reorg(1)
+	require.NoError(t, err)
+	require.Len(t, orphaned, 2)
+
+	_, err = ledger.MineAt(12)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(opStore.get("suffix1")) == 2 && len(opStore.get("suffix2")) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, uint64(12), opStore.get("suffix1")[1].TransactionTime)
+	require.Equal(t, uint64(12), opStore.get("suffix2")[1].TransactionTime)
+	require.Equal(t, orphaned[0].AnchorAddress, ledger.Transactions()[0].AnchorAddress)
+}
+
+func TestScenario_ProtocolVersionTransition(t *testing.T) {
+	v1 := protocol.Protocol{
+		StartingBlockChainTime:       0,
+		HashAlgorithmInMultiHashCode: sha2_256,
+		MaxOperationsPerBatch:        1,
+		MaxDeltaByteSize:             2000,
+	}
+
+	// version 2 only allows public keys to be added
+	v2 := v1
+	v2.StartingBlockChainTime = 100
+	v2.Patches = []string{"add-public-keys"}
+
+	pc, err := mocks.NewMockProtocolClientWithVersions(v2, v1)
+	require.NoError(t, err)
+
+	ledger := mocks.NewMockLedger()
+	cas := mocks.NewMockCasClient(nil)
+	opStore := newMockOpStore()
+	dlq := observer.NewMemDeadLetterStore()
+
+	w, err := writer.New("test", &context{pc: pc, cas: cas, ledger: ledger, queue: &opqueue.MemQueue{}})
+	require.NoError(t, err)
+
+	w.Start()
+	defer w.Stop()
+
+	o := observer.New(&observer.Providers{
+		Ledger:           ledger,
+		DCASClient:       cas,
+		OpStoreProvider:  opStore,
+		OpFilterProvider: &observer.NoopOperationFilterProvider{},
+		PcProvider:       &pcProvider{pc: pc},
+		DeadLetterStore:  dlq,
+	}, observer.WithMaxRetries(0))
+
+	o.Start()
+	defer o.Stop()
+
+	// anchored while version 1 is in effect
+	ledger.SetTransactionTime(50)
+
+	op1 := newCreateOperation(t, pc)
+	require.NoError(t, w.Add(op1))
+	require.Eventually(t, func() bool { return len(opStore.get(op1.UniqueSuffix)) == 1 }, time.Second, 10*time.Millisecond)
+	require.Equal(t, uint64(50), opStore.get(op1.UniqueSuffix)[0].TransactionTime)
+
+	// anchored after the transition to version 2 - the JSON patch in the create request is no longer allowed
+	ledger.SetTransactionTime(150)
+
+	op2 := newCreateOperation(t, pc)
+	require.NoError(t, w.Add(op2))
+	require.Eventually(t, func() bool { return len(ledger.Transactions()) == 2 }, time.Second, 10*time.Millisecond)
+
+	// transactions are processed in order so once the next operation is stored the invalid operation was processed
+	ledger.SetTransactionTime(60)
+
+	op3 := newCreateOperation(t, pc)
+	require.NoError(t, w.Add(op3))
+	require.Eventually(t, func() bool { return len(opStore.get(op3.UniqueSuffix)) == 1 }, time.Second, 10*time.Millisecond)
+
+	// the invalid operation is skipped rather than failing the (anchored) transaction
+	require.Empty(t, opStore.get(op2.UniqueSuffix))
+
+	failed, err := dlq.Get()
+	require.NoError(t, err)
+	require.Empty(t, failed)
+
+	pv, err := pc.Get(99)
+	require.NoError(t, err)
+	require.Equal(t, v1, pv.Protocol())
+
+	pv, err = pc.Current()
+	require.NoError(t, err)
+	require.Equal(t, v2, pv.Protocol())
+}
+
+func TestMockProtocolClientWithVersions(t *testing.T) {
+	v1 := protocol.Protocol{StartingBlockChainTime: 10, HashAlgorithmInMultiHashCode: sha2_256}
+
+	_, err := mocks.NewMockProtocolClientWithVersions()
+	require.Error(t, err)
+
+	_, err = mocks.NewMockProtocolClientWithVersions(v1, v1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "duplicate protocol version")
+
+	pc, err := mocks.NewMockProtocolClientWithVersions(v1)
+	require.NoError(t, err)
+
+	_, err = pc.Get(5)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "protocol parameters are not defined for blockchain time [5]")
+}
+
+func newCreateOperation(t *testing.T, pc protocol.Client) *batch.OperationInfo {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	recoveryKey, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	request, err := helper.NewCreateRequest(&helper.CreateRequestInfo{
+		OpaqueDocument:          `{"name": "value"}`,
+		RecoveryKey:             recoveryKey,
+		NextRecoveryRevealValue: []byte("recoveryReveal"),
+		NextUpdateRevealValue:   []byte("updateReveal"),
+		MultihashCode:           sha2_256,
+	})
+	require.NoError(t, err)
+
+	pv, err := pc.Get(0)
+	require.NoError(t, err)
+
+	op, err := pv.OperationParser().Parse(namespace, request)
+	require.NoError(t, err)
+
+	bytes, err := json.Marshal(op)
+	require.NoError(t, err)
+
+	return &batch.OperationInfo{Data: bytes, UniqueSuffix: op.UniqueSuffix}
+}
+
+func newOperation(t *testing.T, suffix string) *batch.OperationInfo {
+	op := &batch.Operation{
+		ID:           namespace + ":" + suffix,
+		UniqueSuffix: suffix,
+		Type:         batch.OperationTypeCreate,
+	}
+
+	bytes, err := json.Marshal(op)
+	require.NoError(t, err)
+
+	return &batch.OperationInfo{Data: bytes, UniqueSuffix: suffix}
+}
+
+type context struct {
+	pc     protocol.Client
+	cas    writer.CASClient
+	ledger writer.BlockchainClient
+	queue  cutter.OperationQueue
+}
+
+func (c *context) Protocol() protocol.Client {
+	return c.pc
+}
+
+func (c *context) CAS() writer.CASClient {
+	return c.cas
+}
+
+func (c *context) Blockchain() writer.BlockchainClient {
+	return c.ledger
+}
+
+func (c *context) OperationQueue() cutter.OperationQueue {
+	return c.queue
+}
+
+type mockOpStore struct {
+	mutex sync.RWMutex
+	ops   map[string][]*batch.Operation
+}
+
+func newMockOpStore() *mockOpStore {
+	return &mockOpStore{ops: make(map[string][]*batch.Operation)}
+}
+
+func (m *mockOpStore) ForNamespace(string) (observer.OperationStore, error) {
+	return m, nil
+}
+
+func (m *mockOpStore) Put(ops []*batch.Operation) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, op := range ops {
+		m.ops[op.UniqueSuffix] = append(m.ops[op.UniqueSuffix], op)
+	}
+
+	return nil
+}
+
+func (m *mockOpStore) Get(suffix string) ([]*batch.Operation, error) {
+	return m.get(suffix), nil
+}
+
+func (m *mockOpStore) get(suffix string) []*batch.Operation {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.ops[suffix]
+}
+
+type pcProvider struct {
+	pc protocol.Client
+}
+
+func (p *pcProvider) ForNamespace(string) (protocol.Client, error) {
+	return p.pc, nil
+}
@@ -0,0 +1,252 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package archive exports the anchored operations of a namespace as a portable archive and imports the archive
+// into the operation store of another node, so that a node may be migrated (or restored) without replaying the
+// ledger from genesis.
+//
+// An archive contains the original request of each operation along with the transaction time, transaction number
+// and operation index at which the operation was anchored. The archive is hash-verifiable: it contains the
+// multihash of its (canonical) operations, which is verified when the archive is read and before it is imported.
+// Since anyone who is able to modify an archive is also able to recompute its hash (and, for example, reorder the
+// operations of a DID by changing their transaction times), the exporting node signs the hash and an archive is
+// only imported if it was signed by one of the trusted nodes of the importer.
+//
+// Operations are revalidated on import by parsing each request again with the protocol version that was in effect
+// at the transaction time of the operation (as the observer does when it processes anchored operations). Nothing
+// is imported if any of the operations is invalid. Operations that already exist in the operation store are
+// skipped so that an import may be retried.
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/signutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/verifier"
+)
+
+// FormatVersion is the version of the archive format
+const FormatVersion uint = 1
+
+var (
+	// ErrHashMismatch is returned if the hash of an archive doesn't match its operations
+	ErrHashMismatch = errors.New("archive hash doesn't match the archived operations")
+
+	// ErrUntrusted is returned if an archive isn't signed by a trusted node
+	ErrUntrusted = errors.New("archive is not signed by a trusted node")
+)
+
+// Signer signs the archive hash
+type Signer interface {
+	// Sign signs data and returns signature value
+	Sign(data []byte) ([]byte, error)
+
+	// Headers provides required JWS protected headers. It provides information about signing key and algorithm.
+	Headers() jws.Headers
+}
+
+// Archive contains the anchored operations of a namespace
+type Archive struct {
+	FormatVersion uint   `json:"formatVersion"`
+	Namespace     string `json:"namespace"`
+	// HashAlgorithm is the multihash code of the hash algorithm of the archive hash
+	HashAlgorithm uint         `json:"hashAlgorithm"`
+	Operations    []*Operation `json:"operations"`
+	// Hash is the encoded multihash of the canonical JSON of the operations
+	Hash string `json:"hash"`
+	// Signature is the compact JWS (signed by the exporting node) whose payload is the encoded Proof
+	Signature string `json:"signature,omitempty"`
+}
+
+// Proof is the signed payload of an archive
+type Proof struct {
+	Namespace     string `json:"namespace"`
+	FormatVersion uint   `json:"formatVersion"`
+	HashAlgorithm uint   `json:"hashAlgorithm"`
+	Hash          string `json:"hash"`
+}
+
+// Operation is an archived operation
+type Operation struct {
+	UniqueSuffix      string              `json:"uniqueSuffix"`
+	Type              batch.OperationType `json:"type"`
+	TransactionTime   uint64              `json:"transactionTime"`
+	TransactionNumber uint64              `json:"transactionNumber"`
+	OperationIndex    uint                `json:"operationIndex"`
+	// Request is the encoded operation request
+	Request string `json:"request"`
+}
+
+// Verify verifies the format version and the hash of the archive
+func (a *Archive) Verify() error {
+	if a.FormatVersion != FormatVersion {
+		return fmt.Errorf("unsupported archive format version [%d]", a.FormatVersion)
+	}
+
+	hash, err := computeHash(a.HashAlgorithm, a.Operations)
+	if err != nil {
+		return err
+	}
+
+	if hash != a.Hash {
+		return ErrHashMismatch
+	}
+
+	return nil
+}
+
+// VerifySignature verifies that the archive was signed by one of the given (trusted) keys. The hash must be
+// verified separately (see Verify).
+func (a *Archive) VerifySignature(trusted ...*jws.JWK) error {
+	if a.Signature == "" {
+		return fmt.Errorf("%w: archive is not signed", ErrUntrusted)
+	}
+
+	for _, jwk := range trusted {
+		payload, err := verifier.VerifyJWS(a.Signature, jwk)
+		if err != nil {
+			continue
+		}
+
+		decoded, err := docutil.DecodeString(string(payload))
+		if err != nil {
+			return fmt.Errorf("%w: invalid signature payload: %s", ErrUntrusted, err)
+		}
+
+		proof := &Proof{}
+		if err := json.Unmarshal(decoded, proof); err != nil {
+			return fmt.Errorf("%w: invalid signature payload: %s", ErrUntrusted, err)
+		}
+
+		if *proof != a.proof() {
+			return fmt.Errorf("%w: signed proof doesn't match the archive", ErrUntrusted)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: signature can't be verified with any of the %d trusted key(s)", ErrUntrusted, len(trusted))
+}
+
+func (a *Archive) sign(signer Signer) error {
+	proof := a.proof()
+
+	signed, err := signutil.SignModel(&proof, signer)
+	if err != nil {
+		return fmt.Errorf("failed to sign archive: %w", err)
+	}
+
+	a.Signature = signed.Signature
+
+	return nil
+}
+
+func (a *Archive) proof() Proof {
+	return Proof{
+		Namespace:     a.Namespace,
+		FormatVersion: a.FormatVersion,
+		HashAlgorithm: a.HashAlgorithm,
+		Hash:          a.Hash,
+	}
+}
+
+// Write writes the archive as JSON
+func (a *Archive) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(a)
+}
+
+// Read reads and verifies an archive
+func Read(r io.Reader) (*Archive, error) {
+	a := &Archive{}
+	if err := json.NewDecoder(r).Decode(a); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal archive: %w", err)
+	}
+
+	if err := a.Verify(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func newArchive(namespace string, hashAlgorithm uint, ops []*batch.Operation) (*Archive, error) {
+	sortOperations(ops)
+
+	archived := make([]*Operation, len(ops))
+
+	for i, op := range ops {
+		if len(op.OperationBuffer) == 0 {
+			return nil, fmt.Errorf("%s operation for [%s] at transaction time [%d] has no operation request",
+				op.Type, op.UniqueSuffix, op.TransactionTime)
+		}
+
+		archived[i] = &Operation{
+			UniqueSuffix:      op.UniqueSuffix,
+			Type:              op.Type,
+			TransactionTime:   op.TransactionTime,
+			TransactionNumber: op.TransactionNumber,
+			OperationIndex:    op.OperationIndex,
+			Request:           docutil.EncodeToString(op.OperationBuffer),
+		}
+	}
+
+	hash, err := computeHash(hashAlgorithm, archived)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Archive{
+		FormatVersion: FormatVersion,
+		Namespace:     namespace,
+		HashAlgorithm: hashAlgorithm,
+		Operations:    archived,
+		Hash:          hash,
+	}, nil
+}
+
+func computeHash(hashAlgorithm uint, ops []*Operation) (string, error) {
+	if ops == nil {
+		ops = []*Operation{}
+	}
+
+	opsBytes, err := docutil.MarshalCanonical(ops)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := docutil.ComputeMultihash(hashAlgorithm, opsBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute archive hash: %w", err)
+	}
+
+	return docutil.EncodeToString(hash), nil
+}
+
+// sortOperations sorts the operations by unique suffix and then in anchoring order so that the archive
+// (and its hash) doesn't depend on the iteration order of the store
+func sortOperations(ops []*batch.Operation) {
+	sort.SliceStable(ops, func(i, j int) bool {
+		a, b := ops[i], ops[j]
+
+		switch {
+		case a.UniqueSuffix != b.UniqueSuffix:
+			return a.UniqueSuffix < b.UniqueSuffix
+		case a.TransactionTime != b.TransactionTime:
+			return a.TransactionTime < b.TransactionTime
+		case a.TransactionNumber != b.TransactionNumber:
+			return a.TransactionNumber < b.TransactionNumber
+		default:
+			return a.OperationIndex < b.OperationIndex
+		}
+	})
+}
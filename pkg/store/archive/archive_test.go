@@ -0,0 +1,233 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package archive
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/helper"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+const namespace = "did:sidetree"
+
+func TestArchive_WriteRead(t *testing.T) {
+	ops := newOperations(t, mocks.NewMockProtocolClient())
+
+	a, err := newArchive(namespace, sha2_256, ops)
+	require.NoError(t, err)
+	require.Equal(t, FormatVersion, a.FormatVersion)
+	require.Equal(t, namespace, a.Namespace)
+	require.Len(t, a.Operations, len(ops))
+	require.NotEmpty(t, a.Hash)
+
+	t.Run("success", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, a.Write(buf))
+
+		read, err := Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, a, read)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		read, err := Read(strings.NewReader("{"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal archive")
+		require.Nil(t, read)
+	})
+
+	t.Run("tampered archive", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, a.Write(buf))
+
+		tampered := strings.Replace(buf.String(), `"transactionTime":1`, `"transactionTime":2`, 1)
+
+		read, err := Read(strings.NewReader(tampered))
+		require.True(t, errors.Is(err, ErrHashMismatch))
+		require.Nil(t, read)
+	})
+
+	t.Run("unsupported format version", func(t *testing.T) {
+		unsupported := *a
+		unsupported.FormatVersion = FormatVersion + 1
+
+		err := unsupported.Verify()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported archive format version [2]")
+	})
+
+	t.Run("unsupported hash algorithm", func(t *testing.T) {
+		unsupported := *a
+		unsupported.HashAlgorithm = 55
+
+		err := unsupported.Verify()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to compute archive hash")
+	})
+}
+
+func TestArchive_VerifySignature(t *testing.T) {
+	signer, jwk := newSigner(t)
+	_, otherJWK := newSigner(t)
+
+	a, err := newArchive(namespace, sha2_256, newOperations(t, mocks.NewMockProtocolClient()))
+	require.NoError(t, err)
+
+	t.Run("not signed", func(t *testing.T) {
+		err := a.VerifySignature(jwk)
+		require.True(t, errors.Is(err, ErrUntrusted))
+		require.Contains(t, err.Error(), "archive is not signed")
+	})
+
+	signed := *a
+	require.NoError(t, signed.sign(signer))
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, signed.VerifySignature(otherJWK, jwk))
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		err := signed.VerifySignature(otherJWK)
+		require.True(t, errors.Is(err, ErrUntrusted))
+		require.Contains(t, err.Error(), "can't be verified with any of the 1 trusted key(s)")
+
+		require.True(t, errors.Is(signed.VerifySignature(), ErrUntrusted))
+	})
+
+	t.Run("proof mismatch", func(t *testing.T) {
+		other := signed
+		other.Namespace = "did:other"
+
+		err := other.VerifySignature(jwk)
+		require.True(t, errors.Is(err, ErrUntrusted))
+		require.Contains(t, err.Error(), "signed proof doesn't match the archive")
+	})
+}
+
+func TestNewArchive(t *testing.T) {
+	t.Run("operations are sorted", func(t *testing.T) {
+		ops := []*batch.Operation{
+			{UniqueSuffix: "b", TransactionTime: 1, OperationBuffer: []byte("b1")},
+			{UniqueSuffix: "a", TransactionTime: 2, TransactionNumber: 1, OperationBuffer: []byte("a3")},
+			{UniqueSuffix: "a", TransactionTime: 2, TransactionNumber: 0, OperationIndex: 1, OperationBuffer: []byte("a2")},
+			{UniqueSuffix: "a", TransactionTime: 1, OperationBuffer: []byte("a1")},
+		}
+
+		a, err := newArchive(namespace, sha2_256, ops)
+		require.NoError(t, err)
+
+		reversed := []*batch.Operation{ops[3], ops[2], ops[1], ops[0]}
+
+		b, err := newArchive(namespace, sha2_256, reversed)
+		require.NoError(t, err)
+		require.Equal(t, a.Hash, b.Hash)
+
+		var suffixes []string
+		for _, op := range a.Operations {
+			suffixes = append(suffixes, op.UniqueSuffix)
+		}
+
+		require.Equal(t, []string{"a", "a", "a", "b"}, suffixes)
+		require.Equal(t, uint64(1), a.Operations[0].TransactionTime)
+		require.Equal(t, uint(1), a.Operations[1].OperationIndex)
+		require.Equal(t, uint64(1), a.Operations[2].TransactionNumber)
+	})
+
+	t.Run("no operations", func(t *testing.T) {
+		a, err := newArchive(namespace, sha2_256, nil)
+		require.NoError(t, err)
+		require.Empty(t, a.Operations)
+		require.NoError(t, a.Verify())
+	})
+
+	t.Run("missing operation request", func(t *testing.T) {
+		a, err := newArchive(namespace, sha2_256, []*batch.Operation{
+			{UniqueSuffix: "a", Type: batch.OperationTypeCreate, TransactionTime: 1},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create operation for [a] at transaction time [1] has no operation request")
+		require.Nil(t, a)
+	})
+}
+
+func newSigner(t *testing.T) (Signer, *jws.JWK) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	return ecsigner.New(privateKey, "ES256", "node-key"), jwk
+}
+
+// newOperations returns anchored create and update operations for two documents
+func newOperations(t *testing.T, pc protocol.Client) []*batch.Operation {
+	pv, err := pc.Get(0)
+	require.NoError(t, err)
+
+	var ops []*batch.Operation
+
+	for i := 0; i < 2; i++ {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		recoveryKey, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		create, err := helper.NewCreateRequest(&helper.CreateRequestInfo{
+			OpaqueDocument:          `{"name": "value"}`,
+			RecoveryKey:             recoveryKey,
+			NextRecoveryRevealValue: []byte("recoveryReveal"),
+			NextUpdateRevealValue:   []byte("updateReveal"),
+			MultihashCode:           sha2_256,
+		})
+		require.NoError(t, err)
+
+		createOp, err := pv.OperationParser().Parse(namespace, create)
+		require.NoError(t, err)
+
+		jsonPatch, err := patch.NewJSONPatch(`[{"op": "replace", "path": "/name", "value": "updated"}]`)
+		require.NoError(t, err)
+
+		update, err := helper.NewUpdateRequest(&helper.UpdateRequestInfo{
+			DidSuffix:             createOp.UniqueSuffix,
+			Patch:                 jsonPatch,
+			UpdateRevealValue:     []byte("updateReveal"),
+			NextUpdateRevealValue: []byte("nextUpdateReveal"),
+			MultihashCode:         sha2_256,
+			Signer:                ecsigner.New(privateKey, "ES256", "key-1"),
+		})
+		require.NoError(t, err)
+
+		updateOp, err := pv.OperationParser().Parse(namespace, update)
+		require.NoError(t, err)
+
+		createOp.TransactionTime = 1
+		createOp.TransactionNumber = uint64(i)
+		updateOp.TransactionTime = 2
+		updateOp.TransactionNumber = uint64(i)
+
+		ops = append(ops, updateOp, createOp)
+	}
+
+	return ops
+}
@@ -0,0 +1,102 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package archive
+
+import (
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+// sha2_256 is the multihash code of the default hash algorithm of the archive hash
+const sha2_256 = 18
+
+// OperationIterator is implemented by operation stores whose operations can be exported
+type OperationIterator interface {
+	// IterateOperations invokes the given function for each stored operation of the namespace. Iteration
+	// stops if the function returns an error.
+	IterateOperations(fn func(op *batch.Operation) error) error
+}
+
+// Exporter exports the operations of a namespace
+type Exporter struct {
+	namespace     string
+	store         OperationIterator
+	hashAlgorithm uint
+	signer        Signer
+	logger        log.Logger
+}
+
+// ExportOption is an exporter option
+type ExportOption func(e *Exporter)
+
+// WithHashAlgorithm sets the multihash code of the hash algorithm of the archive hash (SHA2-256 by default)
+func WithHashAlgorithm(code uint) ExportOption {
+	return func(e *Exporter) {
+		e.hashAlgorithm = code
+	}
+}
+
+// WithSigner sets the signer of the archive. Importers only import archives that were signed by a trusted node,
+// so archives that are not signed may only be used as backups.
+func WithSigner(signer Signer) ExportOption {
+	return func(e *Exporter) {
+		e.signer = signer
+	}
+}
+
+// WithExportLogger sets the logger of the exporter
+func WithExportLogger(logger log.Logger) ExportOption {
+	return func(e *Exporter) {
+		e.logger = logger
+	}
+}
+
+// NewExporter returns a new exporter for the operation store of the given namespace
+func NewExporter(namespace string, store OperationIterator, opts ...ExportOption) *Exporter {
+	e := &Exporter{
+		namespace:     namespace,
+		store:         store,
+		hashAlgorithm: sha2_256,
+		logger:        log.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Export returns an archive of all of the stored operations of the namespace
+func (e *Exporter) Export() (*Archive, error) {
+	var ops []*batch.Operation
+
+	err := e.store.IterateOperations(func(op *batch.Operation) error {
+		ops = append(ops, op)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operations: %w", err)
+	}
+
+	a, err := newArchive(e.namespace, e.hashAlgorithm, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.signer != nil {
+		if err := a.sign(e.signer); err != nil {
+			return nil, err
+		}
+	}
+
+	e.logger.Infof("Exported %d operations of namespace [%s]", len(a.Operations), e.namespace)
+
+	return a, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package archive
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+)
+
+func TestExporter_Export(t *testing.T) {
+	ops := newOperations(t, mocks.NewMockProtocolClient())
+
+	t.Run("success", func(t *testing.T) {
+		a, err := NewExporter(namespace, &memStore{ops: ops}).Export()
+		require.NoError(t, err)
+		require.Equal(t, namespace, a.Namespace)
+		require.Equal(t, uint(sha2_256), a.HashAlgorithm)
+		require.Len(t, a.Operations, len(ops))
+		require.NoError(t, a.Verify())
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		signer, jwk := newSigner(t)
+
+		a, err := NewExporter(namespace, &memStore{ops: ops}, WithSigner(signer)).Export()
+		require.NoError(t, err)
+		require.NotEmpty(t, a.Signature)
+		require.NoError(t, a.VerifySignature(jwk))
+	})
+
+	t.Run("signer error", func(t *testing.T) {
+		a, err := NewExporter(namespace, &memStore{ops: ops}, WithSigner(ecsigner.New(nil, "", ""))).Export()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to sign archive")
+		require.Nil(t, a)
+	})
+
+	t.Run("unsupported hash algorithm", func(t *testing.T) {
+		a, err := NewExporter(namespace, &memStore{ops: ops}, WithHashAlgorithm(55)).Export()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to compute archive hash")
+		require.Nil(t, a)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		a, err := NewExporter(namespace, &memStore{err: errors.New("store error")}).Export()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read operations: store error")
+		require.Nil(t, a)
+	})
+}
+
+type memStore struct {
+	ops    []*batch.Operation
+	puts   int
+	err    error
+	putErr error
+	// okPuts is the number of puts that succeed before putErr is returned
+	okPuts int
+	getErr error
+}
+
+func (m *memStore) IterateOperations(fn func(op *batch.Operation) error) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	for _, op := range m.ops {
+		if err := fn(op); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *memStore) Put(ops []*batch.Operation) error {
+	if m.putErr != nil && m.puts >= m.okPuts {
+		return m.putErr
+	}
+
+	m.puts++
+	m.ops = append(m.ops, ops...)
+
+	return nil
+}
+
+func (m *memStore) Get(uniqueSuffix string) ([]*batch.Operation, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+
+	var ops []*batch.Operation
+
+	for _, op := range m.ops {
+		if op.UniqueSuffix == uniqueSuffix {
+			ops = append(ops, op)
+		}
+	}
+
+	if len(ops) == 0 {
+		return nil, errors.New("uniqueSuffix not found in the store")
+	}
+
+	return ops, nil
+}
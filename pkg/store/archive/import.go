@@ -0,0 +1,237 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+const defaultBatchSize = 1000
+
+// ErrInvalidArchive is returned (wrapped) if an archive can't be imported since it is invalid
+var ErrInvalidArchive = errors.New("invalid archive")
+
+// OperationStore stores the imported operations (see observer.OperationStore)
+type OperationStore interface {
+	Put(ops []*batch.Operation) error
+
+	// Get returns the stored operations of the document with the given unique suffix (or an error that
+	// contains "not found" if no operations are stored)
+	Get(uniqueSuffix string) ([]*batch.Operation, error)
+}
+
+// Importer imports archived operations into the operation store of a namespace
+type Importer struct {
+	namespace string
+	pc        protocol.Client
+	store     OperationStore
+	batchSize int
+	trusted   []*jws.JWK
+	logger    log.Logger
+}
+
+// anchoredKey identifies an anchored operation of a document
+type anchoredKey struct {
+	transactionTime   uint64
+	transactionNumber uint64
+	operationIndex    uint
+}
+
+// ImportOption is an importer option
+type ImportOption func(i *Importer)
+
+// WithBatchSize sets the maximum number of operations that are put into the operation store at once (default 1000)
+func WithBatchSize(size int) ImportOption {
+	return func(i *Importer) {
+		i.batchSize = size
+	}
+}
+
+// WithTrustedKeys sets the public keys of the trusted nodes. Only archives that were signed by one of
+// the trusted nodes are imported, so no archive may be imported unless trusted keys are provided.
+func WithTrustedKeys(keys ...*jws.JWK) ImportOption {
+	return func(i *Importer) {
+		i.trusted = append(i.trusted, keys...)
+	}
+}
+
+// WithImportLogger sets the logger of the importer
+func WithImportLogger(logger log.Logger) ImportOption {
+	return func(i *Importer) {
+		i.logger = logger
+	}
+}
+
+// ImportResult contains the number of imported operations and documents
+type ImportResult struct {
+	Operations int `json:"operations"`
+	Documents  int `json:"documents"`
+	// Existing is the number of archived operations that were skipped since they were already stored
+	Existing int `json:"existing"`
+}
+
+// NewImporter returns a new importer for the operation store of the given namespace. The protocol client is used
+// to revalidate the operations.
+func NewImporter(namespace string, pc protocol.Client, store OperationStore, opts ...ImportOption) *Importer {
+	i := &Importer{
+		namespace: namespace,
+		pc:        pc,
+		store:     store,
+		batchSize: defaultBatchSize,
+		logger:    log.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+// Import verifies the archive, revalidates the archived operations and puts them into the operation store.
+// The returned error wraps ErrInvalidArchive or ErrHashMismatch if the archive is invalid and ErrUntrusted
+// if the archive isn't signed by a trusted node, in which case no operations are imported. Operations that
+// already exist in the store are skipped, so an import that failed to store some of the operations may be retried.
+func (i *Importer) Import(a *Archive) (*ImportResult, error) {
+	if a.Namespace != i.namespace {
+		return nil, fmt.Errorf("%w: archive namespace [%s] doesn't match namespace [%s]",
+			ErrInvalidArchive, a.Namespace, i.namespace)
+	}
+
+	if err := a.Verify(); err != nil {
+		if errors.Is(err, ErrHashMismatch) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+
+	if err := a.VerifySignature(i.trusted...); err != nil {
+		return nil, err
+	}
+
+	ops := make([]*batch.Operation, len(a.Operations))
+	suffixes := make(map[string]struct{})
+
+	for n, archived := range a.Operations {
+		op, err := i.revalidate(archived)
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation [%d] (%s operation for [%s]): %s",
+				ErrInvalidArchive, n, archived.Type, archived.UniqueSuffix, err)
+		}
+
+		ops[n] = op
+		suffixes[op.UniqueSuffix] = struct{}{}
+	}
+
+	newOps, err := i.skipExisting(ops, suffixes)
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(newOps); start += i.batchSize {
+		end := start + i.batchSize
+		if end > len(newOps) {
+			end = len(newOps)
+		}
+
+		if err := i.store.Put(newOps[start:end]); err != nil {
+			return nil, fmt.Errorf("failed to store operations (%d of %d operations were stored): %w",
+				start, len(newOps), err)
+		}
+	}
+
+	i.logger.Infof("Imported %d operations for %d documents into namespace [%s] (%d operations already existed)",
+		len(newOps), len(suffixes), i.namespace, len(ops)-len(newOps))
+
+	return &ImportResult{Operations: len(newOps), Documents: len(suffixes), Existing: len(ops) - len(newOps)}, nil
+}
+
+// skipExisting returns the operations that are not stored yet
+func (i *Importer) skipExisting(ops []*batch.Operation, suffixes map[string]struct{}) ([]*batch.Operation, error) {
+	existing := make(map[string]map[anchoredKey]struct{})
+
+	for suffix := range suffixes {
+		stored, err := i.store.Get(suffix)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to read stored operations for [%s]: %w", suffix, err)
+		}
+
+		keys := make(map[anchoredKey]struct{}, len(stored))
+		for _, op := range stored {
+			keys[keyOf(op)] = struct{}{}
+		}
+
+		existing[suffix] = keys
+	}
+
+	var newOps []*batch.Operation
+
+	for _, op := range ops {
+		if _, ok := existing[op.UniqueSuffix][keyOf(op)]; ok {
+			continue
+		}
+
+		newOps = append(newOps, op)
+	}
+
+	return newOps, nil
+}
+
+func keyOf(op *batch.Operation) anchoredKey {
+	return anchoredKey{
+		transactionTime:   op.TransactionTime,
+		transactionNumber: op.TransactionNumber,
+		operationIndex:    op.OperationIndex,
+	}
+}
+
+// revalidate parses the archived operation request with the protocol version at the transaction time of the
+// operation and checks that the parsed operation matches the archived operation
+func (i *Importer) revalidate(archived *Operation) (*batch.Operation, error) {
+	request, err := docutil.DecodeString(archived.Request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode operation request: %w", err)
+	}
+
+	pv, err := i.pc.Get(archived.TransactionTime)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := pv.OperationParser().Parse(i.namespace, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if op.UniqueSuffix != archived.UniqueSuffix {
+		return nil, fmt.Errorf("unique suffix of operation request [%s] doesn't match archived suffix",
+			op.UniqueSuffix)
+	}
+
+	if op.Type != archived.Type {
+		return nil, fmt.Errorf("type of operation request [%s] doesn't match archived type", op.Type)
+	}
+
+	op.TransactionTime = archived.TransactionTime
+	op.TransactionNumber = archived.TransactionNumber
+	op.OperationIndex = archived.OperationIndex
+
+	return op, nil
+}
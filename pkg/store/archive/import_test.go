@@ -0,0 +1,262 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+)
+
+func TestImporter_Import(t *testing.T) {
+	pc := mocks.NewMockProtocolClient()
+	ops := newOperations(t, pc)
+
+	signer, jwk := newSigner(t)
+
+	a, err := NewExporter(namespace, &memStore{ops: ops}, WithSigner(signer)).Export()
+	require.NoError(t, err)
+
+	trusted := WithTrustedKeys(jwk)
+
+	t.Run("success", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, a.Write(buf))
+
+		read, err := Read(buf)
+		require.NoError(t, err)
+
+		store := &memStore{}
+
+		result, err := NewImporter(namespace, pc, store, trusted).Import(read)
+		require.NoError(t, err)
+		require.Equal(t, &ImportResult{Operations: 4, Documents: 2}, result)
+		require.Equal(t, 1, store.puts)
+		require.Len(t, store.ops, 4)
+
+		for i, op := range store.ops {
+			archived := a.Operations[i]
+			require.Equal(t, archived.UniqueSuffix, op.UniqueSuffix)
+			require.Equal(t, archived.Type, op.Type)
+			require.Equal(t, archived.TransactionTime, op.TransactionTime)
+			require.Equal(t, archived.TransactionNumber, op.TransactionNumber)
+			require.Equal(t, archived.Request, docutil.EncodeToString(op.OperationBuffer))
+		}
+
+		// the imported operations may be exported again with the same hash
+		exported, err := NewExporter(namespace, store).Export()
+		require.NoError(t, err)
+		require.Equal(t, a.Hash, exported.Hash)
+	})
+
+	t.Run("with batch size", func(t *testing.T) {
+		store := &memStore{}
+
+		result, err := NewImporter(namespace, pc, store, trusted, WithBatchSize(3)).Import(a)
+		require.NoError(t, err)
+		require.Equal(t, 4, result.Operations)
+		require.Equal(t, 2, store.puts)
+	})
+
+	t.Run("existing operations are skipped", func(t *testing.T) {
+		store := &memStore{}
+
+		_, err := NewImporter(namespace, pc, store, trusted).Import(a)
+		require.NoError(t, err)
+
+		result, err := NewImporter(namespace, pc, store, trusted).Import(a)
+		require.NoError(t, err)
+		require.Equal(t, &ImportResult{Operations: 0, Documents: 2, Existing: 4}, result)
+		require.Len(t, store.ops, 4)
+		require.Equal(t, 1, store.puts)
+	})
+
+	t.Run("retry after partial failure", func(t *testing.T) {
+		storeErr := errors.New("store error")
+		store := &memStore{putErr: storeErr, okPuts: 1}
+
+		result, err := NewImporter(namespace, pc, store, trusted, WithBatchSize(3)).Import(a)
+		require.True(t, errors.Is(err, storeErr))
+		require.Contains(t, err.Error(), "3 of 4 operations were stored")
+		require.Nil(t, result)
+		require.Len(t, store.ops, 3)
+
+		store.putErr = nil
+
+		result, err = NewImporter(namespace, pc, store, trusted, WithBatchSize(3)).Import(a)
+		require.NoError(t, err)
+		require.Equal(t, &ImportResult{Operations: 1, Documents: 2, Existing: 3}, result)
+		require.Len(t, store.ops, 4)
+	})
+
+	t.Run("untrusted archive", func(t *testing.T) {
+		_, otherJWK := newSigner(t)
+
+		store := &memStore{}
+
+		for _, importer := range []*Importer{
+			NewImporter(namespace, pc, store),
+			NewImporter(namespace, pc, store, WithTrustedKeys(otherJWK)),
+		} {
+			result, err := importer.Import(a)
+			require.True(t, errors.Is(err, ErrUntrusted))
+			require.Nil(t, result)
+		}
+
+		unsigned := copyArchive(a)
+		unsigned.Signature = ""
+
+		result, err := NewImporter(namespace, pc, store, trusted).Import(unsigned)
+		require.True(t, errors.Is(err, ErrUntrusted))
+		require.Nil(t, result)
+		require.Empty(t, store.ops)
+	})
+
+	t.Run("reordered operations", func(t *testing.T) {
+		// an archive whose transaction times were changed (and whose hash was recomputed) is rejected
+		reordered := copyArchive(a)
+		reordered.Operations[0].TransactionTime = 100
+
+		hash, err := computeHash(reordered.HashAlgorithm, reordered.Operations)
+		require.NoError(t, err)
+
+		reordered.Hash = hash
+
+		store := &memStore{}
+
+		result, err := NewImporter(namespace, pc, store, trusted).Import(reordered)
+		require.True(t, errors.Is(err, ErrUntrusted))
+		require.Contains(t, err.Error(), "signed proof doesn't match the archive")
+		require.Nil(t, result)
+		require.Empty(t, store.ops)
+	})
+
+	t.Run("store read error", func(t *testing.T) {
+		result, err := NewImporter(namespace, pc, &memStore{getErr: errors.New("get error")}, trusted).Import(a)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read stored operations")
+		require.Nil(t, result)
+	})
+
+	t.Run("namespace mismatch", func(t *testing.T) {
+		result, err := NewImporter("did:other", pc, &memStore{}, trusted).Import(a)
+		require.True(t, errors.Is(err, ErrInvalidArchive))
+		require.Contains(t, err.Error(), "archive namespace [did:sidetree] doesn't match namespace [did:other]")
+		require.Nil(t, result)
+	})
+
+	t.Run("hash mismatch", func(t *testing.T) {
+		tampered := copyArchive(a)
+		tampered.Operations[0].TransactionTime = 100
+
+		store := &memStore{}
+
+		result, err := NewImporter(namespace, pc, store, trusted).Import(tampered)
+		require.True(t, errors.Is(err, ErrHashMismatch))
+		require.Nil(t, result)
+		require.Empty(t, store.ops)
+	})
+
+	t.Run("unsupported format version", func(t *testing.T) {
+		unsupported := copyArchive(a)
+		unsupported.FormatVersion = 0
+
+		result, err := NewImporter(namespace, pc, &memStore{}, trusted).Import(unsupported)
+		require.True(t, errors.Is(err, ErrInvalidArchive))
+		require.Contains(t, err.Error(), "unsupported archive format version [0]")
+		require.Nil(t, result)
+	})
+
+	t.Run("invalid operation", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(op *Operation)
+			err    string
+		}{
+			{
+				name:   "invalid encoding",
+				modify: func(op *Operation) { op.Request = "!" },
+				err:    "failed to decode operation request",
+			},
+			{
+				name:   "invalid request",
+				modify: func(op *Operation) { op.Request = docutil.EncodeToString([]byte("{}")) },
+				err:    "operation [0]",
+			},
+			{
+				name:   "suffix mismatch",
+				modify: func(op *Operation) { op.UniqueSuffix = "other" },
+				err:    "doesn't match archived suffix",
+			},
+			{
+				name:   "type mismatch",
+				modify: func(op *Operation) { op.Type = batch.OperationTypeDeactivate },
+				err:    "doesn't match archived type",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				invalid := copyArchive(a)
+				tc.modify(invalid.Operations[0])
+
+				// re-hash and re-sign so that the operation is rejected by revalidation rather than by the
+				// hash or signature check
+				hash, err := computeHash(invalid.HashAlgorithm, invalid.Operations)
+				require.NoError(t, err)
+
+				invalid.Hash = hash
+				require.NoError(t, invalid.sign(signer))
+
+				store := &memStore{}
+
+				result, err := NewImporter(namespace, pc, store, trusted).Import(invalid)
+				require.True(t, errors.Is(err, ErrInvalidArchive))
+				require.Contains(t, err.Error(), tc.err)
+				require.Nil(t, result)
+				require.Empty(t, store.ops)
+			})
+		}
+	})
+
+	t.Run("protocol error", func(t *testing.T) {
+		pcWithErr := mocks.NewMockProtocolClient()
+		pcWithErr.Err = errors.New("protocol error")
+
+		result, err := NewImporter(namespace, pcWithErr, &memStore{}, trusted).Import(a)
+		require.True(t, errors.Is(err, ErrInvalidArchive))
+		require.Contains(t, err.Error(), "protocol error")
+		require.Nil(t, result)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		storeErr := errors.New("store error")
+
+		result, err := NewImporter(namespace, pc, &memStore{putErr: storeErr}, trusted).Import(a)
+		require.True(t, errors.Is(err, storeErr))
+		require.Contains(t, err.Error(), "failed to store operations (0 of 4 operations were stored)")
+		require.Nil(t, result)
+	})
+}
+
+func copyArchive(a *Archive) *Archive {
+	c := *a
+	c.Operations = make([]*Operation, len(a.Operations))
+
+	for i, op := range a.Operations {
+		opCopy := *op
+		c.Operations[i] = &opCopy
+	}
+
+	return &c
+}
@@ -0,0 +1,42 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"sync"
+)
+
+// MemVersionStore implements an in-memory schema version store
+type MemVersionStore struct {
+	versions map[string]uint
+	mutex    sync.RWMutex
+}
+
+// NewMemVersionStore returns a new in-memory schema version store
+func NewMemVersionStore() *MemVersionStore {
+	return &MemVersionStore{
+		versions: make(map[string]uint),
+	}
+}
+
+// GetVersion returns the current schema version of the given store (zero if not found)
+func (s *MemVersionStore) GetVersion(store string) (uint, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.versions[store], nil
+}
+
+// PutVersion stores the schema version of the given store
+func (s *MemVersionStore) PutVersion(store string, version uint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.versions[store] = version
+
+	return nil
+}
@@ -0,0 +1,224 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package migration applies ordered schema migrations to persistent stores (operation store, operation queue,
+// watermark, etc.) on node start-up.
+//
+// Each store keeps track of its schema version in a version store. When the runner is invoked, all migrations
+// registered for a store with a version greater than the persisted version are applied in ascending order and the
+// persisted version is advanced after each successful migration. If a migration fails then the runner stops and the
+// store is left at the version of the last successful migration so that the remaining migrations are retried
+// on the next run.
+//
+// Persisted operations (operation store and operation queue) are serialized in a versioned format by the
+// OperationCodec, which migrates operations persisted in older formats when they are read. The codec also
+// provides migrations that rewrite all of the operations of a store in the current format on start-up.
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+const (
+	// OperationStore is the name of the operation store
+	OperationStore = "operation"
+
+	// OperationQueue is the name of the operation queue store
+	OperationQueue = "queue"
+
+	// Watermark is the name of the store that holds the observer watermark (last processed transaction)
+	Watermark = "watermark"
+)
+
+// Migration defines a schema migration for a persistent store
+type Migration struct {
+	// Version is the schema version of the store after the migration has been applied. Must be greater than zero.
+	Version uint
+
+	// Description describes the migration (used for logging)
+	Description string
+
+	// Migrate applies the migration
+	Migrate func() error
+}
+
+// VersionStore persists the schema version of each store
+type VersionStore interface {
+	// GetVersion returns the current schema version of the given store. Zero is returned if the store
+	// has never been migrated.
+	GetVersion(store string) (uint, error)
+
+	// PutVersion persists the schema version of the given store
+	PutVersion(store string, version uint) error
+}
+
+// Runner applies ordered migrations to persistent stores
+type Runner struct {
+	versions   VersionStore
+	migrations map[string][]*Migration
+	logger     log.Logger
+	mutex      sync.Mutex
+}
+
+// RunnerOption is a migration runner option
+type RunnerOption func(r *Runner)
+
+// WithLogger sets the logger of the migration runner
+func WithLogger(logger log.Logger) RunnerOption {
+	return func(r *Runner) {
+		r.logger = logger
+	}
+}
+
+// NewRunner returns a new migration runner that tracks schema versions in the given version store
+func NewRunner(versions VersionStore, opts ...RunnerOption) *Runner {
+	r := &Runner{
+		versions:   versions,
+		migrations: make(map[string][]*Migration),
+		logger:     log.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Register registers migrations for the given store. Migrations may be registered in any order;
+// they are applied in ascending version order.
+func (r *Runner) Register(store string, migrations ...*Migration) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	registered := r.migrations[store]
+
+	for _, m := range migrations {
+		if err := validateMigration(m); err != nil {
+			return errors.WithMessagef(err, "invalid migration for store [%s]", store)
+		}
+
+		for _, existing := range registered {
+			if existing.Version == m.Version {
+				return fmt.Errorf("duplicate migration version [%d] for store [%s]", m.Version, store)
+			}
+		}
+
+		registered = append(registered, m)
+	}
+
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].Version < registered[j].Version
+	})
+
+	r.migrations[store] = registered
+
+	return nil
+}
+
+// LatestVersion returns the latest schema version known for the given store
+func (r *Runner) LatestVersion(store string) uint {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return latestVersion(r.migrations[store])
+}
+
+// Run applies all pending migrations for the given store and returns the resulting schema version
+func (r *Runner) Run(store string) (uint, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.run(store)
+}
+
+// RunAll applies all pending migrations for all registered stores
+func (r *Runner) RunAll() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var stores []string
+	for store := range r.migrations {
+		stores = append(stores, store)
+	}
+
+	sort.Strings(stores)
+
+	for _, store := range stores {
+		if _, err := r.run(store); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) run(store string) (uint, error) {
+	current, err := r.versions.GetVersion(store)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "failed to get schema version for store [%s]", store)
+	}
+
+	migrations := r.migrations[store]
+
+	latest := latestVersion(migrations)
+	if current > latest {
+		return current, fmt.Errorf("schema version [%d] of store [%s] is newer than the latest supported version [%d]", current, store, latest)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+
+		r.logger.Infof("Migrating store [%s] from schema version [%d] to [%d]: %s", store, current, m.Version, m.Description)
+
+		if err := m.Migrate(); err != nil {
+			return current, errors.WithMessagef(err, "migration of store [%s] to schema version [%d] failed", store, m.Version)
+		}
+
+		if err := r.versions.PutVersion(store, m.Version); err != nil {
+			return current, errors.WithMessagef(err, "failed to persist schema version [%d] for store [%s]", m.Version, store)
+		}
+
+		current = m.Version
+	}
+
+	r.logger.Debugf("Store [%s] is at schema version [%d]", store, current)
+
+	return current, nil
+}
+
+func validateMigration(m *Migration) error {
+	if m == nil {
+		return errors.New("migration is nil")
+	}
+
+	if m.Version == 0 {
+		return errors.New("migration version must be greater than zero")
+	}
+
+	if m.Migrate == nil {
+		return fmt.Errorf("migration function is missing for version [%d]", m.Version)
+	}
+
+	return nil
+}
+
+// pre-condition: migrations have to be sorted
+func latestVersion(migrations []*Migration) uint {
+	if len(migrations) == 0 {
+		return 0
+	}
+
+	return migrations[len(migrations)-1].Version
+}
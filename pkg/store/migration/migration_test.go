@@ -0,0 +1,221 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+func TestRunner_Register(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		r := NewRunner(NewMemVersionStore())
+		require.NoError(t, r.Register(OperationStore, newMigration(2, nil), newMigration(1, nil)))
+		require.NoError(t, r.Register(OperationStore, newMigration(3, nil)))
+		require.Equal(t, uint(3), r.LatestVersion(OperationStore))
+		require.Equal(t, uint(0), r.LatestVersion(Watermark))
+	})
+	t.Run("duplicate version", func(t *testing.T) {
+		r := NewRunner(NewMemVersionStore())
+		require.NoError(t, r.Register(OperationStore, newMigration(1, nil)))
+
+		err := r.Register(OperationStore, newMigration(1, nil))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "duplicate migration version [1]")
+	})
+	t.Run("invalid migration", func(t *testing.T) {
+		r := NewRunner(NewMemVersionStore())
+
+		err := r.Register(OperationStore, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "migration is nil")
+
+		err = r.Register(OperationStore, newMigration(0, nil))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "migration version must be greater than zero")
+
+		err = r.Register(OperationStore, &Migration{Version: 1})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "migration function is missing")
+	})
+}
+
+func TestRunner_Run(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var applied []uint
+
+		versions := NewMemVersionStore()
+		r := NewRunner(versions)
+		require.NoError(t, r.Register(OperationStore,
+			newMigration(3, &applied), newMigration(1, &applied), newMigration(2, &applied)))
+
+		version, err := r.Run(OperationStore)
+		require.NoError(t, err)
+		require.Equal(t, uint(3), version)
+		require.Equal(t, []uint{1, 2, 3}, applied)
+
+		// subsequent run is a no-op
+		applied = nil
+		version, err = r.Run(OperationStore)
+		require.NoError(t, err)
+		require.Equal(t, uint(3), version)
+		require.Empty(t, applied)
+
+		// new migration (e.g. after upgrade) is applied
+		require.NoError(t, r.Register(OperationStore, newMigration(4, &applied)))
+		version, err = r.Run(OperationStore)
+		require.NoError(t, err)
+		require.Equal(t, uint(4), version)
+		require.Equal(t, []uint{4}, applied)
+	})
+	t.Run("with logger", func(t *testing.T) {
+		l, hook := logtest.NewNullLogger()
+
+		r := NewRunner(NewMemVersionStore(), WithLogger(log.New(l)))
+		require.NoError(t, r.Register(OperationStore, newMigration(1, nil)))
+
+		_, err := r.Run(OperationStore)
+		require.NoError(t, err)
+
+		require.Len(t, hook.AllEntries(), 1)
+		require.Contains(t, hook.LastEntry().Message, "Migrating store [operation] from schema version [0] to [1]")
+	})
+	t.Run("only pending migrations are applied", func(t *testing.T) {
+		var applied []uint
+
+		versions := NewMemVersionStore()
+		require.NoError(t, versions.PutVersion(OperationQueue, 1))
+
+		r := NewRunner(versions)
+		require.NoError(t, r.Register(OperationQueue, newMigration(1, &applied), newMigration(2, &applied)))
+
+		version, err := r.Run(OperationQueue)
+		require.NoError(t, err)
+		require.Equal(t, uint(2), version)
+		require.Equal(t, []uint{2}, applied)
+	})
+	t.Run("no migrations", func(t *testing.T) {
+		r := NewRunner(NewMemVersionStore())
+
+		version, err := r.Run(Watermark)
+		require.NoError(t, err)
+		require.Equal(t, uint(0), version)
+	})
+	t.Run("migration error", func(t *testing.T) {
+		var applied []uint
+
+		versions := NewMemVersionStore()
+		r := NewRunner(versions)
+
+		errExpected := errors.New("migration error")
+		require.NoError(t, r.Register(OperationStore,
+			newMigration(1, &applied),
+			&Migration{Version: 2, Migrate: func() error { return errExpected }},
+			newMigration(3, &applied)))
+
+		version, err := r.Run(OperationStore)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Equal(t, uint(1), version)
+		require.Equal(t, []uint{1}, applied)
+
+		persisted, err := versions.GetVersion(OperationStore)
+		require.NoError(t, err)
+		require.Equal(t, uint(1), persisted)
+	})
+	t.Run("store version is newer than supported", func(t *testing.T) {
+		versions := NewMemVersionStore()
+		require.NoError(t, versions.PutVersion(OperationStore, 5))
+
+		r := NewRunner(versions)
+		require.NoError(t, r.Register(OperationStore, newMigration(1, nil)))
+
+		version, err := r.Run(OperationStore)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is newer than the latest supported version [1]")
+		require.Equal(t, uint(5), version)
+	})
+	t.Run("get version error", func(t *testing.T) {
+		errExpected := errors.New("get error")
+		r := NewRunner(&mockVersionStore{getErr: errExpected})
+
+		_, err := r.Run(OperationStore)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+	t.Run("put version error", func(t *testing.T) {
+		errExpected := errors.New("put error")
+		r := NewRunner(&mockVersionStore{putErr: errExpected})
+		require.NoError(t, r.Register(OperationStore, newMigration(1, nil)))
+
+		version, err := r.Run(OperationStore)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Equal(t, uint(0), version)
+	})
+}
+
+func TestRunner_RunAll(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var applied []uint
+
+		versions := NewMemVersionStore()
+		r := NewRunner(versions)
+		require.NoError(t, r.Register(OperationStore, newMigration(1, &applied)))
+		require.NoError(t, r.Register(OperationQueue, newMigration(1, &applied), newMigration(2, &applied)))
+		require.NoError(t, r.Register(Watermark, newMigration(1, &applied)))
+
+		require.NoError(t, r.RunAll())
+		require.Len(t, applied, 4)
+
+		for store, expected := range map[string]uint{OperationStore: 1, OperationQueue: 2, Watermark: 1} {
+			version, err := versions.GetVersion(store)
+			require.NoError(t, err)
+			require.Equal(t, expected, version)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		errExpected := errors.New("migration error")
+		r := NewRunner(NewMemVersionStore())
+		require.NoError(t, r.Register(OperationStore, &Migration{Version: 1, Migrate: func() error { return errExpected }}))
+
+		err := r.RunAll()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func newMigration(version uint, applied *[]uint) *Migration {
+	return &Migration{
+		Version:     version,
+		Description: "test migration",
+		Migrate: func() error {
+			if applied != nil {
+				*applied = append(*applied, version)
+			}
+			return nil
+		},
+	}
+}
+
+type mockVersionStore struct {
+	getErr error
+	putErr error
+}
+
+func (m *mockVersionStore) GetVersion(string) (uint, error) {
+	return 0, m.getErr
+}
+
+func (m *mockVersionStore) PutVersion(string, uint) error {
+	return m.putErr
+}
@@ -0,0 +1,389 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+const (
+	// UnversionedFormat is the format version of operations that were persisted before the serialization format
+	// was versioned, i.e. the operation (or the queued operation info) was persisted as is
+	UnversionedFormat uint = 0
+
+	// CurrentOperationFormat is the current version of the serialization format of persisted operations
+	CurrentOperationFormat uint = 1
+
+	formatVersionField = "formatVersion"
+)
+
+// PersistedOperation is the versioned envelope in which operations are persisted to the operation store
+// and the operation queue
+type PersistedOperation struct {
+	// FormatVersion is the version of the serialization format of the operation
+	FormatVersion uint `json:"formatVersion"`
+
+	// ProtocolVersion identifies the protocol version (by its starting blockchain time) that the operation
+	// was parsed with
+	ProtocolVersion uint `json:"protocolVersion"`
+
+	// UniqueSuffix is the unique suffix of the document (only set for queued operations)
+	UniqueSuffix string `json:"uniqueSuffix,omitempty"`
+
+	// QueuedTime is the time at which the operation was added to the operation queue (only set for queued operations)
+	QueuedTime *time.Time `json:"queuedTime,omitempty"`
+
+	// Operation is the JSON-encoded operation (batch.Operation)
+	Operation json.RawMessage `json:"operation"`
+}
+
+// OperationMigrationFunc migrates a persisted operation from its format version to the next format version.
+// The operation is modified in place; the format version is advanced by the codec.
+type OperationMigrationFunc func(op *PersistedOperation) error
+
+// OperationRewriter is implemented by persistent stores (operation store, operation queue) in order to
+// have their persisted operations migrated to the current serialization format on start-up
+type OperationRewriter interface {
+	// RewriteOperations invokes the given function for each persisted operation and replaces the persisted
+	// operation with the returned bytes
+	RewriteOperations(rewrite func(data []byte) ([]byte, error)) error
+}
+
+// OperationCodec serializes operations in the current (versioned) format and deserializes operations
+// that were persisted in any supported format, migrating them to the current format
+type OperationCodec struct {
+	migrations map[uint][]OperationMigrationFunc
+}
+
+// OperationCodecOption is an option for the operation codec
+type OperationCodecOption func(c *OperationCodec)
+
+// WithOperationMigration adds a hook that migrates persisted operations from the given format version to the
+// next format version. Hooks for the same format version are invoked in the order in which they were added
+// (after the built-in migrations).
+func WithOperationMigration(fromVersion uint, migrate OperationMigrationFunc) OperationCodecOption {
+	return func(c *OperationCodec) {
+		c.migrations[fromVersion] = append(c.migrations[fromVersion], migrate)
+	}
+}
+
+// NewOperationCodec returns a new operation codec. Unversioned operations are migrated by translating legacy
+// next reveal values into commitments (see NewLegacyRevealValueMigration).
+func NewOperationCodec(pc protocol.Client, opts ...OperationCodecOption) *OperationCodec {
+	c := &OperationCodec{
+		migrations: map[uint][]OperationMigrationFunc{
+			UnversionedFormat: {NewLegacyRevealValueMigration(pc)},
+		},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// EncodeOperation serializes the given operation (for the operation store) in the current format
+func (c *OperationCodec) EncodeOperation(op *batch.Operation, protocolVersion uint) ([]byte, error) {
+	opBytes, err := docutil.MarshalCanonical(op)
+	if err != nil {
+		return nil, err
+	}
+
+	return docutil.MarshalCanonical(&PersistedOperation{
+		FormatVersion:   CurrentOperationFormat,
+		ProtocolVersion: protocolVersion,
+		Operation:       opBytes,
+	})
+}
+
+// DecodeOperation deserializes an operation that was persisted to the operation store and returns the operation
+// along with the protocol version that it was parsed with (zero if unknown)
+func (c *OperationCodec) DecodeOperation(data []byte) (*batch.Operation, uint, error) {
+	persisted, err := c.decode(data, func() (*PersistedOperation, error) {
+		return &PersistedOperation{FormatVersion: UnversionedFormat, Operation: data}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	op := &batch.Operation{}
+	if err := json.Unmarshal(persisted.Operation, op); err != nil {
+		return nil, 0, errors.Wrap(err, "failed to unmarshal persisted operation")
+	}
+
+	return op, persisted.ProtocolVersion, nil
+}
+
+// EncodeQueuedOperation serializes the given queued operation in the current format
+func (c *OperationCodec) EncodeQueuedOperation(info *batch.OperationInfo, protocolVersion uint) ([]byte, error) {
+	persisted := &PersistedOperation{
+		FormatVersion:   CurrentOperationFormat,
+		ProtocolVersion: protocolVersion,
+		UniqueSuffix:    info.UniqueSuffix,
+		Operation:       info.Data,
+	}
+
+	if !info.QueuedTime.IsZero() {
+		queuedTime := info.QueuedTime
+		persisted.QueuedTime = &queuedTime
+	}
+
+	return docutil.MarshalCanonical(persisted)
+}
+
+// DecodeQueuedOperation deserializes a queued operation and returns the operation info along with the protocol
+// version that the operation was parsed with (zero if unknown)
+func (c *OperationCodec) DecodeQueuedOperation(data []byte) (*batch.OperationInfo, uint, error) {
+	persisted, err := c.decode(data, func() (*PersistedOperation, error) {
+		info := &batch.OperationInfo{}
+		if err := json.Unmarshal(data, info); err != nil {
+			return nil, err
+		}
+
+		persisted := &PersistedOperation{
+			FormatVersion: UnversionedFormat,
+			UniqueSuffix:  info.UniqueSuffix,
+			Operation:     info.Data,
+		}
+
+		if !info.QueuedTime.IsZero() {
+			persisted.QueuedTime = &info.QueuedTime
+		}
+
+		return persisted, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info := &batch.OperationInfo{
+		Data:         persisted.Operation,
+		UniqueSuffix: persisted.UniqueSuffix,
+	}
+
+	if persisted.QueuedTime != nil {
+		info.QueuedTime = *persisted.QueuedTime
+	}
+
+	return info, persisted.ProtocolVersion, nil
+}
+
+// StoreMigration returns a migration to the given schema version which rewrites all of the operations in the
+// given operation store in the current format
+func (c *OperationCodec) StoreMigration(version uint, store OperationRewriter) *Migration {
+	return &Migration{
+		Version:     version,
+		Description: fmt.Sprintf("rewrite persisted operations in format version %d", CurrentOperationFormat),
+		Migrate: func() error {
+			return store.RewriteOperations(func(data []byte) ([]byte, error) {
+				op, protocolVersion, err := c.DecodeOperation(data)
+				if err != nil {
+					return nil, err
+				}
+
+				return c.EncodeOperation(op, protocolVersion)
+			})
+		},
+	}
+}
+
+// QueueMigration returns a migration to the given schema version which rewrites all of the operations in the
+// given operation queue in the current format
+func (c *OperationCodec) QueueMigration(version uint, queue OperationRewriter) *Migration {
+	return &Migration{
+		Version:     version,
+		Description: fmt.Sprintf("rewrite queued operations in format version %d", CurrentOperationFormat),
+		Migrate: func() error {
+			return queue.RewriteOperations(func(data []byte) ([]byte, error) {
+				info, protocolVersion, err := c.DecodeQueuedOperation(data)
+				if err != nil {
+					return nil, err
+				}
+
+				return c.EncodeQueuedOperation(info, protocolVersion)
+			})
+		},
+	}
+}
+
+// decode unmarshals the versioned envelope (or, if the data isn't versioned, the envelope returned by the
+// given function) and migrates it to the current format
+func (c *OperationCodec) decode(data []byte, unversioned func() (*PersistedOperation, error)) (*PersistedOperation, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal persisted operation")
+	}
+
+	var persisted *PersistedOperation
+
+	if _, ok := fields[formatVersionField]; ok {
+		persisted = &PersistedOperation{}
+		if err := json.Unmarshal(data, persisted); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal persisted operation")
+		}
+	} else {
+		p, err := unversioned()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal unversioned persisted operation")
+		}
+
+		persisted = p
+	}
+
+	if err := c.migrate(persisted); err != nil {
+		return nil, err
+	}
+
+	return persisted, nil
+}
+
+func (c *OperationCodec) migrate(op *PersistedOperation) error {
+	if op.FormatVersion > CurrentOperationFormat {
+		return fmt.Errorf("format version [%d] of persisted operation is newer than the latest supported version [%d]",
+			op.FormatVersion, CurrentOperationFormat)
+	}
+
+	for op.FormatVersion < CurrentOperationFormat {
+		for _, migrate := range c.migrations[op.FormatVersion] {
+			if err := migrate(op); err != nil {
+				return errors.WithMessagef(err, "failed to migrate persisted operation from format version [%d]", op.FormatVersion)
+			}
+		}
+
+		op.FormatVersion++
+	}
+
+	return nil
+}
+
+// NewLegacyRevealValueMigration returns a migration that translates the next reveal values of operations that
+// were persisted before commitments were introduced into the update/recovery commitments. Operations that were
+// persisted without a multihash code are hashed with the multihash code of the protocol version in effect at
+// their transaction time (or of the current protocol version if the operation wasn't anchored yet).
+func NewLegacyRevealValueMigration(pc protocol.Client) OperationMigrationFunc {
+	return func(persisted *PersistedOperation) error {
+		op := &batch.Operation{}
+		if err := json.Unmarshal(persisted.Operation, op); err != nil {
+			return errors.Wrap(err, "failed to unmarshal operation")
+		}
+
+		changed, err := translateLegacyRevealValues(op, pc)
+		if err != nil {
+			return err
+		}
+
+		if !changed {
+			return nil
+		}
+
+		opBytes, err := docutil.MarshalCanonical(op)
+		if err != nil {
+			return err
+		}
+
+		persisted.Operation = opBytes
+
+		return nil
+	}
+}
+
+func translateLegacyRevealValues(op *batch.Operation, pc protocol.Client) (bool, error) {
+	var nextUpdateReveal, nextRecoveryReveal string
+
+	if op.Delta != nil {
+		nextUpdateReveal = op.Delta.NextUpdateRevealValue
+	}
+
+	if op.SuffixData != nil {
+		nextRecoveryReveal = op.SuffixData.NextRecoveryRevealValue
+	}
+
+	if op.Type == batch.OperationTypeRecover && op.SignedData != nil {
+		signedData, err := recoverSignedData(op.SignedData)
+		if err != nil {
+			return false, err
+		}
+
+		nextRecoveryReveal = signedData.NextRecoveryRevealValue
+	}
+
+	translateUpdate := nextUpdateReveal != "" && op.UpdateCommitment == ""
+	translateRecovery := nextRecoveryReveal != "" && op.RecoveryCommitment == ""
+
+	if !translateUpdate && !translateRecovery {
+		return false, nil
+	}
+
+	multihashCode, err := multihashCode(op, pc)
+	if err != nil {
+		return false, err
+	}
+
+	if translateUpdate {
+		op.UpdateCommitment, err = operation.CommitmentFromRevealValue(nextUpdateReveal, multihashCode)
+		if err != nil {
+			return false, errors.WithMessage(err, "invalid next update reveal value")
+		}
+	}
+
+	if translateRecovery {
+		op.RecoveryCommitment, err = operation.CommitmentFromRevealValue(nextRecoveryReveal, multihashCode)
+		if err != nil {
+			return false, errors.WithMessage(err, "invalid next recovery reveal value")
+		}
+	}
+
+	return true, nil
+}
+
+// multihashCode returns the persisted multihash code of the operation or, if it wasn't persisted, the multihash
+// code of the protocol version that applies to the operation
+func multihashCode(op *batch.Operation, pc protocol.Client) (uint, error) {
+	if op.HashAlgorithmInMultiHashCode != 0 {
+		return op.HashAlgorithmInMultiHashCode, nil
+	}
+
+	var pv protocol.Version
+	var err error
+
+	if op.TransactionTime > 0 {
+		pv, err = pc.Get(op.TransactionTime)
+	} else {
+		pv, err = pc.Current()
+	}
+
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to get protocol version for operation")
+	}
+
+	return pv.Protocol().HashAlgorithmInMultiHashCode, nil
+}
+
+func recoverSignedData(jws *model.JWS) (*model.RecoverSignedDataModel, error) {
+	payload, err := docutil.DecodeString(jws.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode signed data")
+	}
+
+	signedData := &model.RecoverSignedDataModel{}
+	if err := json.Unmarshal(payload, signedData); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal signed data")
+	}
+
+	return signedData, nil
+}
@@ -0,0 +1,385 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+const sha2_256 = 18
+
+func TestOperationCodec_Operation(t *testing.T) {
+	op := &batch.Operation{
+		Type:                         batch.OperationTypeUpdate,
+		ID:                           "did:sidetree:suffix",
+		UniqueSuffix:                 "suffix",
+		HashAlgorithmInMultiHashCode: sha2_256,
+		UpdateCommitment:             "commitment",
+		TransactionTime:              10,
+	}
+
+	c := NewOperationCodec(mocks.NewMockProtocolClient())
+
+	t.Run("current format", func(t *testing.T) {
+		data, err := c.EncodeOperation(op, 5)
+		require.NoError(t, err)
+
+		persisted := &PersistedOperation{}
+		require.NoError(t, json.Unmarshal(data, persisted))
+		require.Equal(t, CurrentOperationFormat, persisted.FormatVersion)
+		require.Equal(t, uint(5), persisted.ProtocolVersion)
+
+		decoded, protocolVersion, err := c.DecodeOperation(data)
+		require.NoError(t, err)
+		require.Equal(t, op, decoded)
+		require.Equal(t, uint(5), protocolVersion)
+	})
+
+	t.Run("unversioned format", func(t *testing.T) {
+		data, err := json.Marshal(op)
+		require.NoError(t, err)
+
+		decoded, protocolVersion, err := c.DecodeOperation(data)
+		require.NoError(t, err)
+		require.Equal(t, op, decoded)
+		require.Zero(t, protocolVersion)
+	})
+
+	t.Run("unversioned format with legacy reveal values", func(t *testing.T) {
+		legacyOp := &batch.Operation{
+			Type:                         batch.OperationTypeCreate,
+			ID:                           "did:sidetree:suffix",
+			UniqueSuffix:                 "suffix",
+			HashAlgorithmInMultiHashCode: sha2_256,
+			Delta:                        &model.DeltaModel{NextUpdateRevealValue: docutil.EncodeToString([]byte("update"))},
+			SuffixData:                   &model.SuffixDataModel{NextRecoveryRevealValue: docutil.EncodeToString([]byte("recovery"))},
+		}
+
+		data, err := json.Marshal(legacyOp)
+		require.NoError(t, err)
+
+		decoded, _, err := c.DecodeOperation(data)
+		require.NoError(t, err)
+		require.Equal(t, commitment(t, "update"), decoded.UpdateCommitment)
+		require.Equal(t, commitment(t, "recovery"), decoded.RecoveryCommitment)
+	})
+
+	t.Run("newer format", func(t *testing.T) {
+		data, err := json.Marshal(&PersistedOperation{FormatVersion: CurrentOperationFormat + 1, Operation: []byte(`{}`)})
+		require.NoError(t, err)
+
+		decoded, _, err := c.DecodeOperation(data)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is newer than the latest supported version")
+		require.Nil(t, decoded)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		decoded, _, err := c.DecodeOperation([]byte("invalid"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal persisted operation")
+		require.Nil(t, decoded)
+
+		decoded, _, err = c.DecodeOperation([]byte(`{"formatVersion":1,"operation":"op"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal persisted operation")
+		require.Nil(t, decoded)
+	})
+}
+
+func TestOperationCodec_QueuedOperation(t *testing.T) {
+	opBytes, err := docutil.MarshalCanonical(&batch.Operation{Type: batch.OperationTypeUpdate, UniqueSuffix: "suffix"})
+	require.NoError(t, err)
+
+	queuedTime := time.Now().UTC().Truncate(time.Second)
+
+	info := &batch.OperationInfo{
+		Data:         opBytes,
+		UniqueSuffix: "suffix",
+		QueuedTime:   queuedTime,
+	}
+
+	c := NewOperationCodec(mocks.NewMockProtocolClient())
+
+	t.Run("current format", func(t *testing.T) {
+		data, err := c.EncodeQueuedOperation(info, 7)
+		require.NoError(t, err)
+
+		decoded, protocolVersion, err := c.DecodeQueuedOperation(data)
+		require.NoError(t, err)
+		require.Equal(t, uint(7), protocolVersion)
+		require.Equal(t, info.UniqueSuffix, decoded.UniqueSuffix)
+		require.Equal(t, info.Data, []byte(decoded.Data))
+		require.True(t, queuedTime.Equal(decoded.QueuedTime))
+	})
+
+	t.Run("current format without queued time", func(t *testing.T) {
+		data, err := c.EncodeQueuedOperation(&batch.OperationInfo{Data: opBytes, UniqueSuffix: "suffix"}, 7)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "queuedTime")
+
+		decoded, _, err := c.DecodeQueuedOperation(data)
+		require.NoError(t, err)
+		require.True(t, decoded.QueuedTime.IsZero())
+	})
+
+	t.Run("unversioned format", func(t *testing.T) {
+		data, err := json.Marshal(info)
+		require.NoError(t, err)
+
+		decoded, protocolVersion, err := c.DecodeQueuedOperation(data)
+		require.NoError(t, err)
+		require.Zero(t, protocolVersion)
+		require.Equal(t, info.UniqueSuffix, decoded.UniqueSuffix)
+		require.Equal(t, info.Data, []byte(decoded.Data))
+		require.True(t, queuedTime.Equal(decoded.QueuedTime))
+	})
+
+	t.Run("invalid unversioned format", func(t *testing.T) {
+		decoded, _, err := c.DecodeQueuedOperation([]byte(`{"Data":1}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal unversioned persisted operation")
+		require.Nil(t, decoded)
+	})
+}
+
+func TestOperationCodec_MigrationHooks(t *testing.T) {
+	var invoked []uint
+
+	hook := func(op *PersistedOperation) error {
+		invoked = append(invoked, op.FormatVersion)
+		op.ProtocolVersion = 100
+
+		return nil
+	}
+
+	data, err := json.Marshal(&batch.Operation{ID: "did:sidetree:suffix"})
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		c := NewOperationCodec(mocks.NewMockProtocolClient(), WithOperationMigration(UnversionedFormat, hook))
+
+		_, protocolVersion, err := c.DecodeOperation(data)
+		require.NoError(t, err)
+		require.Equal(t, uint(100), protocolVersion)
+		require.Equal(t, []uint{UnversionedFormat}, invoked)
+
+		invoked = nil
+
+		current, err := c.EncodeOperation(&batch.Operation{}, 1)
+		require.NoError(t, err)
+
+		_, _, err = c.DecodeOperation(current)
+		require.NoError(t, err)
+		require.Empty(t, invoked)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errExpected := errors.New("injected migration error")
+
+		c := NewOperationCodec(mocks.NewMockProtocolClient(), WithOperationMigration(UnversionedFormat, func(*PersistedOperation) error {
+			return errExpected
+		}))
+
+		op, _, err := c.DecodeOperation(data)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Contains(t, err.Error(), "failed to migrate persisted operation from format version [0]")
+		require.Nil(t, op)
+	})
+}
+
+func TestOperationCodec_Migrations(t *testing.T) {
+	legacyOp, err := json.Marshal(&batch.Operation{
+		Type:                         batch.OperationTypeUpdate,
+		UniqueSuffix:                 "suffix",
+		HashAlgorithmInMultiHashCode: sha2_256,
+		Delta:                        &model.DeltaModel{NextUpdateRevealValue: docutil.EncodeToString([]byte("update"))},
+	})
+	require.NoError(t, err)
+
+	legacyQueued, err := json.Marshal(&batch.OperationInfo{Data: legacyOp, UniqueSuffix: "suffix"})
+	require.NoError(t, err)
+
+	c := NewOperationCodec(mocks.NewMockProtocolClient())
+
+	t.Run("operation store", func(t *testing.T) {
+		store := &mockRewriter{data: [][]byte{legacyOp}}
+
+		r := NewRunner(NewMemVersionStore())
+		require.NoError(t, r.Register(OperationStore, c.StoreMigration(1, store)))
+		require.NoError(t, r.RunAll())
+
+		persisted := &PersistedOperation{}
+		require.NoError(t, json.Unmarshal(store.data[0], persisted))
+		require.Equal(t, CurrentOperationFormat, persisted.FormatVersion)
+
+		op, _, err := c.DecodeOperation(store.data[0])
+		require.NoError(t, err)
+		require.Equal(t, commitment(t, "update"), op.UpdateCommitment)
+	})
+
+	t.Run("operation queue", func(t *testing.T) {
+		queue := &mockRewriter{data: [][]byte{legacyQueued}}
+
+		r := NewRunner(NewMemVersionStore())
+		require.NoError(t, r.Register(OperationQueue, c.QueueMigration(1, queue)))
+		require.NoError(t, r.RunAll())
+
+		info, _, err := c.DecodeQueuedOperation(queue.data[0])
+		require.NoError(t, err)
+		require.Equal(t, "suffix", info.UniqueSuffix)
+
+		op := &batch.Operation{}
+		require.NoError(t, json.Unmarshal(info.Data, op))
+		require.Equal(t, commitment(t, "update"), op.UpdateCommitment)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		r := NewRunner(NewMemVersionStore())
+		require.NoError(t, r.Register(OperationStore, c.StoreMigration(1, &mockRewriter{data: [][]byte{[]byte("invalid")}})))
+		require.NoError(t, r.Register(OperationQueue, c.QueueMigration(1, &mockRewriter{data: [][]byte{[]byte("invalid")}})))
+
+		_, err := r.Run(OperationStore)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal persisted operation")
+
+		_, err = r.Run(OperationQueue)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal persisted operation")
+	})
+}
+
+func TestNewLegacyRevealValueMigration(t *testing.T) {
+	pc := mocks.NewMockProtocolClient()
+	migrate := NewLegacyRevealValueMigration(pc)
+
+	t.Run("recover", func(t *testing.T) {
+		signedData, err := json.Marshal(&model.RecoverSignedDataModel{
+			NextRecoveryRevealValue: docutil.EncodeToString([]byte("recovery")),
+		})
+		require.NoError(t, err)
+
+		op, err := json.Marshal(&batch.Operation{
+			Type:                         batch.OperationTypeRecover,
+			HashAlgorithmInMultiHashCode: sha2_256,
+			SignedData:                   &model.JWS{Payload: docutil.EncodeToString(signedData)},
+		})
+		require.NoError(t, err)
+
+		persisted := &PersistedOperation{Operation: op}
+		require.NoError(t, migrate(persisted))
+
+		migrated := &batch.Operation{}
+		require.NoError(t, json.Unmarshal(persisted.Operation, migrated))
+		require.Equal(t, commitment(t, "recovery"), migrated.RecoveryCommitment)
+	})
+
+	t.Run("no legacy reveal values", func(t *testing.T) {
+		op := []byte(`{"type":"update","updateCommitment":"commitment"}`)
+
+		persisted := &PersistedOperation{Operation: op}
+		require.NoError(t, migrate(persisted))
+		require.Equal(t, op, []byte(persisted.Operation))
+	})
+
+	t.Run("multihash code of protocol", func(t *testing.T) {
+		op, err := json.Marshal(&batch.Operation{
+			Type:  batch.OperationTypeUpdate,
+			Delta: &model.DeltaModel{NextUpdateRevealValue: docutil.EncodeToString([]byte("update"))},
+		})
+		require.NoError(t, err)
+
+		persisted := &PersistedOperation{Operation: op}
+		require.NoError(t, migrate(persisted))
+
+		migrated := &batch.Operation{}
+		require.NoError(t, json.Unmarshal(persisted.Operation, migrated))
+		require.Equal(t, commitment(t, "update"), migrated.UpdateCommitment)
+	})
+
+	t.Run("protocol client error", func(t *testing.T) {
+		errPC := mocks.NewMockProtocolClient()
+		errPC.Err = errors.New("injected protocol error")
+
+		op, err := json.Marshal(&batch.Operation{
+			Type:            batch.OperationTypeUpdate,
+			TransactionTime: 10,
+			Delta:           &model.DeltaModel{NextUpdateRevealValue: docutil.EncodeToString([]byte("update"))},
+		})
+		require.NoError(t, err)
+
+		err = NewLegacyRevealValueMigration(errPC)(&PersistedOperation{Operation: op})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected protocol error")
+	})
+
+	t.Run("invalid reveal value", func(t *testing.T) {
+		op, err := json.Marshal(&batch.Operation{
+			Type:                         batch.OperationTypeUpdate,
+			HashAlgorithmInMultiHashCode: 100,
+			Delta:                        &model.DeltaModel{NextUpdateRevealValue: docutil.EncodeToString([]byte("update"))},
+		})
+		require.NoError(t, err)
+
+		err = migrate(&PersistedOperation{Operation: op})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid next update reveal value")
+	})
+
+	t.Run("invalid signed data", func(t *testing.T) {
+		op, err := json.Marshal(&batch.Operation{
+			Type:       batch.OperationTypeRecover,
+			SignedData: &model.JWS{Payload: "{"},
+		})
+		require.NoError(t, err)
+
+		err = migrate(&PersistedOperation{Operation: op})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode signed data")
+	})
+
+	t.Run("invalid operation", func(t *testing.T) {
+		err := migrate(&PersistedOperation{Operation: []byte(`{"type":1}`)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal operation")
+	})
+}
+
+func commitment(t *testing.T, revealValue string) string {
+	mh, err := docutil.ComputeMultihash(sha2_256, []byte(revealValue))
+	require.NoError(t, err)
+
+	return docutil.EncodeToString(mh)
+}
+
+type mockRewriter struct {
+	data [][]byte
+}
+
+func (m *mockRewriter) RewriteOperations(rewrite func(data []byte) ([]byte, error)) error {
+	for i, d := range m.data {
+		rewritten, err := rewrite(d)
+		if err != nil {
+			return err
+		}
+
+		m.data[i] = rewritten
+	}
+
+	return nil
+}
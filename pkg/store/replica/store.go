@@ -0,0 +1,120 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package replica provides an operation store that spreads resolution reads across read replicas.
+//
+// A replica is only read if it is within the configured staleness bound (i.e. it is not lagging behind
+// the primary by more than the bound); otherwise (or if the replica read fails) the primary is read.
+// Intake validation should always read from the primary (see Store.Primary) since a stale read could
+// cause a valid operation to be rejected or an invalid operation to be accepted.
+package replica
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+const defaultMaxStaleness = time.Second
+
+// OperationStore retrieves all operations related to a document
+type OperationStore interface {
+	Get(uniqueSuffix string) ([]*batch.Operation, error)
+}
+
+// Replica is a read replica of the primary operation store
+type Replica interface {
+	OperationStore
+
+	// Lag returns how far the replica is behind the primary
+	Lag() (time.Duration, error)
+}
+
+// Option is a replica store option
+type Option func(s *Store)
+
+// WithMaxStaleness sets the maximum lag of a replica for it to be used for reads (default 1s)
+func WithMaxStaleness(maxStaleness time.Duration) Option {
+	return func(s *Store) {
+		s.maxStaleness = maxStaleness
+	}
+}
+
+// WithLogger sets the logger of the replica store
+func WithLogger(logger log.Logger) Option {
+	return func(s *Store) {
+		s.logger = logger
+	}
+}
+
+// Store reads operations from the read replicas (round robin) that are within the staleness bound
+// and falls back to the primary
+type Store struct {
+	primary      OperationStore
+	replicas     []Replica
+	maxStaleness time.Duration
+	logger       log.Logger
+	next         uint32
+}
+
+// New returns a new replica store
+func New(primary OperationStore, replicas []Replica, opts ...Option) *Store {
+	s := &Store{
+		primary:      primary,
+		replicas:     replicas,
+		maxStaleness: defaultMaxStaleness,
+		logger:       log.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Get retrieves all operations related to the document from a replica that is within the staleness
+// bound or from the primary if no such replica is available
+func (s *Store) Get(uniqueSuffix string) ([]*batch.Operation, error) {
+	n := len(s.replicas)
+	if n == 0 {
+		return s.primary.Get(uniqueSuffix)
+	}
+
+	start := int(atomic.AddUint32(&s.next, 1)-1) % n
+
+	for i := 0; i < n; i++ {
+		r := s.replicas[(start+i)%n]
+
+		lag, err := r.Lag()
+		if err != nil {
+			s.logger.Debugf("Unable to get replica lag: %s", err)
+			continue
+		}
+
+		if lag > s.maxStaleness {
+			s.logger.Debugf("Replica lag [%s] exceeds the maximum staleness [%s]", lag, s.maxStaleness)
+			continue
+		}
+
+		ops, err := r.Get(uniqueSuffix)
+		if err != nil {
+			s.logger.Debugf("Error reading operations for [%s] from replica: %s", uniqueSuffix, err)
+			continue
+		}
+
+		return ops, nil
+	}
+
+	return s.primary.Get(uniqueSuffix)
+}
+
+// Primary returns the primary store, which should be used for intake validation
+func (s *Store) Primary() OperationStore {
+	return s.primary
+}
@@ -0,0 +1,141 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replica
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+const uniqueSuffix = "suffix"
+
+func TestStore_Get(t *testing.T) {
+	primary := &mockStore{name: "primary"}
+
+	t.Run("no replicas", func(t *testing.T) {
+		s := New(primary, nil)
+
+		ops, err := s.Get(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "primary", ops[0].ID)
+	})
+
+	t.Run("replicas within staleness bound are read round robin", func(t *testing.T) {
+		r1 := &mockReplica{mockStore: mockStore{name: "r1"}}
+		r2 := &mockReplica{mockStore: mockStore{name: "r2"}, lag: 500 * time.Millisecond}
+
+		s := New(primary, []Replica{r1, r2})
+
+		var names []string
+		for i := 0; i < 4; i++ {
+			ops, err := s.Get(uniqueSuffix)
+			require.NoError(t, err)
+			names = append(names, ops[0].ID)
+		}
+
+		require.Equal(t, []string{"r1", "r2", "r1", "r2"}, names)
+	})
+
+	t.Run("stale replica is skipped", func(t *testing.T) {
+		r1 := &mockReplica{mockStore: mockStore{name: "r1"}, lag: 10 * time.Second}
+		r2 := &mockReplica{mockStore: mockStore{name: "r2"}}
+
+		s := New(primary, []Replica{r1, r2})
+
+		for i := 0; i < 2; i++ {
+			ops, err := s.Get(uniqueSuffix)
+			require.NoError(t, err)
+			require.Equal(t, "r2", ops[0].ID)
+		}
+	})
+
+	t.Run("with logger", func(t *testing.T) {
+		l, hook := logtest.NewNullLogger()
+		l.SetLevel(logrus.DebugLevel)
+
+		r1 := &mockReplica{mockStore: mockStore{name: "r1"}, lag: 10 * time.Second}
+
+		s := New(primary, []Replica{r1}, WithLogger(log.New(l)))
+
+		ops, err := s.Get(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "primary", ops[0].ID)
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		require.Equal(t, "Replica lag [10s] exceeds the maximum staleness [1s]", entry.Message)
+	})
+
+	t.Run("max staleness option", func(t *testing.T) {
+		r := &mockReplica{mockStore: mockStore{name: "r1"}, lag: 10 * time.Second}
+
+		ops, err := New(primary, []Replica{r}).Get(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "primary", ops[0].ID)
+
+		ops, err = New(primary, []Replica{r}, WithMaxStaleness(time.Minute)).Get(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "r1", ops[0].ID)
+	})
+
+	t.Run("falls back to primary on replica errors", func(t *testing.T) {
+		r1 := &mockReplica{mockStore: mockStore{name: "r1"}, lagErr: errors.New("lag error")}
+		r2 := &mockReplica{mockStore: mockStore{name: "r2", err: errors.New("read error")}}
+
+		ops, err := New(primary, []Replica{r1, r2}).Get(uniqueSuffix)
+		require.NoError(t, err)
+		require.Equal(t, "primary", ops[0].ID)
+	})
+
+	t.Run("primary error", func(t *testing.T) {
+		s := New(&mockStore{err: errors.New("primary error")}, nil)
+
+		ops, err := s.Get(uniqueSuffix)
+		require.EqualError(t, err, "primary error")
+		require.Nil(t, ops)
+	})
+}
+
+func TestStore_Primary(t *testing.T) {
+	primary := &mockStore{name: "primary"}
+	r := &mockReplica{mockStore: mockStore{name: "r1"}}
+
+	ops, err := New(primary, []Replica{r}).Primary().Get(uniqueSuffix)
+	require.NoError(t, err)
+	require.Equal(t, "primary", ops[0].ID)
+}
+
+type mockStore struct {
+	name string
+	err  error
+}
+
+func (m *mockStore) Get(uniqueSuffix string) ([]*batch.Operation, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return []*batch.Operation{{ID: m.name, UniqueSuffix: uniqueSuffix}}, nil
+}
+
+type mockReplica struct {
+	mockStore
+	lag    time.Duration
+	lagErr error
+}
+
+func (m *mockReplica) Lag() (time.Duration, error) {
+	return m.lag, m.lagErr
+}
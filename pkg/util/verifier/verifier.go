@@ -0,0 +1,87 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifier
+
+import (
+	"fmt"
+
+	internaljws "github.com/trustbloc/sidetree-core-go/pkg/internal/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+const (
+	// ES256 is ECDSA using the P-256 curve and SHA-256
+	ES256 = "ES256"
+
+	// ES256K is ECDSA using the secp256k1 curve and SHA-256
+	ES256K = "ES256K"
+
+	// EdDSA is EdDSA using the Ed25519 curve
+	EdDSA = "EdDSA"
+)
+
+type keyType struct {
+	kty string
+	crv string
+}
+
+var algorithms = map[string]keyType{
+	ES256:  {kty: "EC", crv: "P-256"},
+	ES256K: {kty: "EC", crv: "secp256k1"},
+	EdDSA:  {kty: "OKP", crv: "Ed25519"},
+}
+
+// VerifySignature verifies the signature of msg using the given public key and algorithm (ES256, ES256K or EdDSA).
+// An error is returned if the key doesn't match the algorithm.
+func VerifySignature(alg string, jwk *jws.JWK, signature, msg []byte) error {
+	if err := checkAlgorithm(alg, jwk); err != nil {
+		return err
+	}
+
+	return internaljws.VerifySignature(jwk, signature, msg)
+}
+
+// VerifyJWS verifies the compact JWS using the given public key and returns the payload. The algorithm
+// in the protected header must match the key.
+func VerifyJWS(compactJWS string, jwk *jws.JWK) ([]byte, error) {
+	if jwk == nil {
+		return nil, fmt.Errorf("public key is required")
+	}
+
+	signature, err := internaljws.ParseJWS(compactJWS, jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	alg, ok := signature.ProtectedHeaders.Algorithm()
+	if !ok {
+		return nil, fmt.Errorf("algorithm is missing from protected header")
+	}
+
+	if err := checkAlgorithm(alg, jwk); err != nil {
+		return nil, err
+	}
+
+	return signature.Payload, nil
+}
+
+func checkAlgorithm(alg string, jwk *jws.JWK) error {
+	if jwk == nil {
+		return fmt.Errorf("public key is required")
+	}
+
+	kt, ok := algorithms[alg]
+	if !ok {
+		return fmt.Errorf("algorithm '%s' is not supported", alg)
+	}
+
+	if jwk.Kty != kt.kty || jwk.Crv != kt.crv {
+		return fmt.Errorf("key type '%s' with curve '%s' cannot be used with algorithm '%s'", jwk.Kty, jwk.Crv, alg)
+	}
+
+	return nil
+}
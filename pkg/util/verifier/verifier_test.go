@@ -0,0 +1,130 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/signutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+func TestVerifySignature(t *testing.T) {
+	msg := []byte("test message")
+
+	t.Run("success ES256", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		signature, err := ecsigner.New(privateKey, ES256, "key-1").Sign(msg)
+		require.NoError(t, err)
+
+		require.NoError(t, VerifySignature(ES256, jwk, signature, msg))
+
+		err = VerifySignature(ES256, jwk, signature, []byte("other message"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ecdsa: invalid signature")
+	})
+	t.Run("success ES256K", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		signature, err := ecsigner.New(privateKey, ES256K, "key-1").Sign(msg)
+		require.NoError(t, err)
+
+		require.NoError(t, VerifySignature(ES256K, jwk, signature, msg))
+	})
+	t.Run("success EdDSA", func(t *testing.T) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := pubkey.GetPublicKeyJWK(publicKey)
+		require.NoError(t, err)
+
+		signature, err := edsigner.New(privateKey, EdDSA, "key-1").Sign(msg)
+		require.NoError(t, err)
+
+		require.NoError(t, VerifySignature(EdDSA, jwk, signature, msg))
+
+		err = VerifySignature(EdDSA, jwk, signature, []byte("other message"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ed25519: invalid signature")
+	})
+	t.Run("key doesn't match algorithm", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+		require.NoError(t, err)
+
+		err = VerifySignature(ES256K, jwk, nil, msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key type 'EC' with curve 'P-256' cannot be used with algorithm 'ES256K'")
+	})
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		err := VerifySignature("ES384", &jws.JWK{Kty: "EC", Crv: "P-384"}, nil, msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "algorithm 'ES384' is not supported")
+	})
+	t.Run("missing key", func(t *testing.T) {
+		err := VerifySignature(ES256, nil, nil, msg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "public key is required")
+	})
+}
+
+func TestVerifyJWS(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		signed, err := signutil.SignModel(map[string]string{"key": "value"}, ecsigner.New(privateKey, ES256, "key-1"))
+		require.NoError(t, err)
+
+		payload, err := VerifyJWS(signed.Signature, jwk)
+		require.NoError(t, err)
+		require.Equal(t, signed.Payload, string(payload))
+	})
+	t.Run("algorithm doesn't match key", func(t *testing.T) {
+		signed, err := signutil.SignModel(map[string]string{"key": "value"}, ecsigner.New(privateKey, ES256K, "key-1"))
+		require.NoError(t, err)
+
+		payload, err := VerifyJWS(signed.Signature, jwk)
+		require.Error(t, err)
+		require.Nil(t, payload)
+		require.Contains(t, err.Error(), "cannot be used with algorithm 'ES256K'")
+	})
+	t.Run("invalid JWS", func(t *testing.T) {
+		payload, err := VerifyJWS("invalid", jwk)
+		require.Error(t, err)
+		require.Nil(t, payload)
+	})
+	t.Run("missing key", func(t *testing.T) {
+		payload, err := VerifyJWS("invalid", nil)
+		require.Error(t, err)
+		require.Nil(t, payload)
+	})
+}
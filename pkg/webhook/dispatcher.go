@@ -0,0 +1,317 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+	defaultQueueSize      = 1000
+	defaultWorkers        = 2
+	defaultTimeout        = 10 * time.Second
+)
+
+// Event is the notification that is posted (as JSON) to the callback URL of a DID
+type Event struct {
+	DidSuffix     string               `json:"did_suffix"`
+	State         batch.OperationState `json:"state"`
+	BatchAddress  string               `json:"batch_address,omitempty"`
+	AnchorAddress string               `json:"anchor_address,omitempty"`
+	Error         string               `json:"error,omitempty"`
+	Time          time.Time            `json:"time"`
+}
+
+// HTTPClient posts events to the callback URLs
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Dispatcher delivers operation lifecycle events (anchored, failed and rejected) to the callback URLs that are registered
+// for the DIDs of the operations. Events are queued by the lifecycle listener and delivered asynchronously by
+// worker goroutines so that the batch writer is never blocked by a slow callback.
+type Dispatcher struct {
+	store          Store
+	client         HTTPClient
+	allowPrivate   bool
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	workers        int
+	logger         log.Logger
+	queue          chan *Event
+	stopCh         chan struct{}
+	wg             sync.WaitGroup
+}
+
+// DispatcherOption is a dispatcher option
+type DispatcherOption func(d *Dispatcher)
+
+// WithMaxRetries sets the number of times that a failed delivery is retried before the event is dropped
+// (zero disables retries)
+func WithMaxRetries(maxRetries int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.maxRetries = maxRetries
+	}
+}
+
+// WithBackoff sets the backoff before the first retry. The backoff is doubled for every subsequent retry
+// up to the given maximum.
+func WithBackoff(initial, max time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.initialBackoff = initial
+		d.maxBackoff = max
+	}
+}
+
+// WithHTTPClient sets the HTTP client that posts the events. By default, an HTTP client with a 10s timeout is used
+// which doesn't follow redirects and refuses to connect to loopback, link-local and private addresses (since the
+// host of a callback URL may resolve to a different address than the one that was validated on registration).
+func WithHTTPClient(client HTTPClient) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithPrivateCallbackDelivery allows the default HTTP client to connect to loopback, link-local and private
+// addresses. This option should only be used for testing.
+func WithPrivateCallbackDelivery() DispatcherOption {
+	return func(d *Dispatcher) {
+		d.allowPrivate = true
+	}
+}
+
+// WithQueueSize sets the maximum number of events that may be pending delivery. Events are dropped
+// if the queue is full.
+func WithQueueSize(size int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.queue = make(chan *Event, size)
+	}
+}
+
+// WithWorkers sets the number of goroutines that deliver events
+func WithWorkers(workers int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.workers = workers
+	}
+}
+
+// WithDispatcherLogger sets the logger of the dispatcher
+func WithDispatcherLogger(logger log.Logger) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.logger = logger
+	}
+}
+
+// NewDispatcher returns a new dispatcher that delivers events to the callback URLs in the given store
+func NewDispatcher(store Store, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		store:          store,
+		maxRetries:     defaultMaxRetries,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		workers:        defaultWorkers,
+		logger:         log.Default(),
+		queue:          make(chan *Event, defaultQueueSize),
+		stopCh:         make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if d.client == nil {
+		d.client = newHTTPClient(d.allowPrivate)
+	}
+
+	return d
+}
+
+// newHTTPClient returns an HTTP client that doesn't follow redirects and checks the address of every connection
+// (unless private addresses are allowed), so that neither a redirect nor a DNS change of the callback host can
+// be used to reach internal services. Proxies are not used since the address of the callback host must be checked.
+func newHTTPClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("connection to address [%s] is not allowed", host)
+			}
+
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   defaultTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Listener returns the lifecycle listener that should be registered with the batch writer
+// (see batch.WithLifecycleListener)
+func (d *Dispatcher) Listener() batch.LifecycleListener {
+	return d.notify
+}
+
+// Start starts the delivery workers
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+
+		go d.listen()
+	}
+}
+
+// Stop stops the delivery workers. Pending events are dropped.
+func (d *Dispatcher) Stop() {
+	close(d.stopCh)
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) notify(event *batch.OperationEvent) {
+	switch event.State {
+	case batch.OperationStateAnchored, batch.OperationStateFailed, batch.OperationStateRejected:
+	default:
+		return
+	}
+
+	e := &Event{
+		DidSuffix:     event.UniqueSuffix,
+		State:         event.State,
+		BatchAddress:  event.BatchAddress,
+		AnchorAddress: event.AnchorAddress,
+		Time:          time.Now().UTC(),
+	}
+
+	if event.Error != nil {
+		e.Error = event.Error.Error()
+	}
+
+	select {
+	case d.queue <- e:
+	default:
+		d.logger.Warnf("Webhook queue is full. Dropping %s event for [%s]", e.State, e.DidSuffix)
+	}
+}
+
+func (d *Dispatcher) listen() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case event := <-d.queue:
+			if stopped := d.dispatch(event); stopped {
+				return
+			}
+		}
+	}
+}
+
+// dispatch delivers the event to the callback URL of the DID (if registered), retrying with exponential backoff
+// on failure. Returns true if the dispatcher was stopped while waiting to retry.
+func (d *Dispatcher) dispatch(event *Event) bool {
+	registration, err := d.store.Get(event.DidSuffix)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			d.logger.Errorf("Unable to get webhook registration for [%s]: %s", event.DidSuffix, err)
+		}
+
+		return false
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.logger.Errorf("Unable to marshal %s event for [%s]: %s", event.State, event.DidSuffix, err)
+		return false
+	}
+
+	backoff := d.initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := d.post(registration.CallbackURL, body)
+		if err == nil {
+			d.logger.Debugf("Delivered %s event for [%s] to [%s]", event.State, event.DidSuffix, registration.CallbackURL)
+			return false
+		}
+
+		if attempt > d.maxRetries {
+			d.logger.Warnf("Failed to deliver %s event for [%s] to [%s] after %d attempt(s): %s",
+				event.State, event.DidSuffix, registration.CallbackURL, attempt, err)
+			return false
+		}
+
+		d.logger.Infof("Failed to deliver %s event for [%s] on attempt %d: %s. Retrying in %s",
+			event.State, event.DidSuffix, attempt, err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-d.stopCh:
+			return true
+		}
+
+		backoff *= 2
+		if backoff > d.maxBackoff {
+			backoff = d.maxBackoff
+		}
+	}
+}
+
+func (d *Dispatcher) post(callbackURL string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			d.logger.Warnf("Failed to close response body: %s", err)
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("callback returned status [%d]", resp.StatusCode)
+	}
+
+	return nil
+}
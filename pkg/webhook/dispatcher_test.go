@@ -0,0 +1,222 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/batch"
+)
+
+func TestDispatcher_Post(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if req.URL.Path == "/redirect" {
+			http.Redirect(rw, req, "/callback", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("private address", func(t *testing.T) {
+		err := NewDispatcher(NewMemStore()).post(server.URL+"/callback", []byte("{}"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "connection to address [127.0.0.1] is not allowed")
+		require.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("redirects are not followed", func(t *testing.T) {
+		d := NewDispatcher(NewMemStore(), WithPrivateCallbackDelivery())
+
+		require.NoError(t, d.post(server.URL+"/callback", []byte("{}")))
+		require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+		err := d.post(server.URL+"/redirect", []byte("{}"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "callback returned status [302]")
+		require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		events := make(chan *Event, 10)
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			require.Equal(t, http.MethodPost, req.Method)
+
+			body, err := ioutil.ReadAll(req.Body)
+			require.NoError(t, err)
+
+			event := &Event{}
+			require.NoError(t, json.Unmarshal(body, event))
+
+			events <- event
+		}))
+		defer server.Close()
+
+		store := NewMemStore()
+		require.NoError(t, store.Put(&Registration{UniqueSuffix: didSuffix, CallbackURL: server.URL}))
+
+		d := NewDispatcher(store, WithPrivateCallbackDelivery())
+		d.Start()
+		defer d.Stop()
+
+		listener := d.Listener()
+
+		listener(&batch.OperationEvent{UniqueSuffix: didSuffix, State: batch.OperationStateQueued})
+		listener(&batch.OperationEvent{UniqueSuffix: "other", State: batch.OperationStateAnchored})
+		listener(&batch.OperationEvent{
+			UniqueSuffix:  didSuffix,
+			State:         batch.OperationStateAnchored,
+			BatchAddress:  "batch",
+			AnchorAddress: "anchor",
+		})
+		listener(&batch.OperationEvent{
+			UniqueSuffix: didSuffix,
+			State:        batch.OperationStateFailed,
+			Error:        errors.New("anchor error"),
+		})
+		listener(&batch.OperationEvent{
+			UniqueSuffix: didSuffix,
+			State:        batch.OperationStateRejected,
+			Error:        errors.New("too large"),
+		})
+
+		received := map[batch.OperationState]*Event{}
+
+		for i := 0; i < 3; i++ {
+			select {
+			case event := <-events:
+				received[event.State] = event
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for event")
+			}
+		}
+
+		anchored := received[batch.OperationStateAnchored]
+		require.NotNil(t, anchored)
+		require.Equal(t, didSuffix, anchored.DidSuffix)
+		require.Equal(t, "batch", anchored.BatchAddress)
+		require.Equal(t, "anchor", anchored.AnchorAddress)
+
+		failed := received[batch.OperationStateFailed]
+		require.NotNil(t, failed)
+		require.Equal(t, "anchor error", failed.Error)
+
+		rejected := received[batch.OperationStateRejected]
+		require.NotNil(t, rejected)
+		require.Equal(t, "too large", rejected.Error)
+
+		select {
+		case event := <-events:
+			t.Fatalf("unexpected event: %+v", event)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		var attempts int32
+
+		delivered := make(chan struct{}, 1)
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			delivered <- struct{}{}
+		}))
+		defer server.Close()
+
+		store := NewMemStore()
+		require.NoError(t, store.Put(&Registration{UniqueSuffix: didSuffix, CallbackURL: server.URL}))
+
+		d := NewDispatcher(store, WithPrivateCallbackDelivery(), WithBackoff(time.Millisecond, 2*time.Millisecond), WithWorkers(1))
+		d.Start()
+		defer d.Stop()
+
+		d.Listener()(&batch.OperationEvent{UniqueSuffix: didSuffix, State: batch.OperationStateAnchored})
+
+		select {
+		case <-delivered:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for delivery")
+		}
+
+		require.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		var attempts int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			rw.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		store := NewMemStore()
+		require.NoError(t, store.Put(&Registration{UniqueSuffix: didSuffix, CallbackURL: server.URL}))
+
+		d := NewDispatcher(store, WithPrivateCallbackDelivery(), WithMaxRetries(1), WithBackoff(time.Millisecond, time.Millisecond))
+
+		stopped := d.dispatch(&Event{DidSuffix: didSuffix, State: batch.OperationStateAnchored})
+		require.False(t, stopped)
+		require.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("stopped while waiting to retry", func(t *testing.T) {
+		store := NewMemStore()
+		require.NoError(t, store.Put(&Registration{UniqueSuffix: didSuffix, CallbackURL: "http://127.0.0.1:0"}))
+
+		d := NewDispatcher(store, WithBackoff(time.Minute, time.Minute))
+		close(d.stopCh)
+
+		require.True(t, d.dispatch(&Event{DidSuffix: didSuffix, State: batch.OperationStateAnchored}))
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		var attempts int32
+
+		d := NewDispatcher(&mockStore{err: errors.New("store error")}, WithHTTPClient(&mockHTTPClient{attempts: &attempts}))
+
+		require.False(t, d.dispatch(&Event{DidSuffix: didSuffix, State: batch.OperationStateAnchored}))
+		require.Zero(t, atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		d := NewDispatcher(NewMemStore(), WithQueueSize(1))
+
+		d.Listener()(&batch.OperationEvent{UniqueSuffix: didSuffix, State: batch.OperationStateAnchored})
+		d.Listener()(&batch.OperationEvent{UniqueSuffix: didSuffix, State: batch.OperationStateAnchored})
+
+		require.Len(t, d.queue, 1)
+	})
+}
+
+type mockHTTPClient struct {
+	attempts *int32
+}
+
+func (m *mockHTTPClient) Do(*http.Request) (*http.Response, error) {
+	atomic.AddInt32(m.attempts, 1)
+
+	return nil, errors.New("not implemented")
+}
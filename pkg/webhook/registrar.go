@@ -0,0 +1,364 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/verifier"
+)
+
+// Resolver resolves the internal document of a DID (implemented by the operation processor)
+type Resolver interface {
+	Resolve(uniqueSuffix string) (*document.ResolutionResult, error)
+}
+
+const defaultMaxRequestAge = 5 * time.Minute
+
+// privateNetworks are the IPv4 and IPv6 private address ranges (RFC 1918, RFC 6598 and RFC 4193)
+var privateNetworks = parseCIDRs("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7")
+
+// Registrar registers and unregisters the callback URLs of DIDs
+type Registrar struct {
+	resolver         Resolver
+	store            Store
+	allowInsecureURL bool
+	allowPrivateHost bool
+	maxRequestAge    time.Duration
+	lookupIP         func(host string) ([]net.IP, error)
+	now              func() time.Time
+	logger           log.Logger
+
+	mutex  sync.Mutex
+	nonces map[string]time.Time
+}
+
+// RegistrarOption is a registrar option
+type RegistrarOption func(r *Registrar)
+
+// WithInsecureCallbacks allows callback URLs with the http scheme (only https callbacks are allowed by default).
+// This option should only be used for testing.
+func WithInsecureCallbacks() RegistrarOption {
+	return func(r *Registrar) {
+		r.allowInsecureURL = true
+	}
+}
+
+// WithPrivateCallbacks allows callback URLs whose host is a loopback, link-local or private address (such callback
+// URLs are rejected by default so that the server can't be used to post requests to internal services).
+// This option should only be used for testing.
+func WithPrivateCallbacks() RegistrarOption {
+	return func(r *Registrar) {
+		r.allowPrivateHost = true
+	}
+}
+
+// WithMaxRequestAge sets the maximum age of a request (5 minutes by default). Requests whose signed timestamp
+// differs from the current time by more than the maximum age are rejected, as are requests whose nonce was already
+// used within the maximum age.
+func WithMaxRequestAge(age time.Duration) RegistrarOption {
+	return func(r *Registrar) {
+		r.maxRequestAge = age
+	}
+}
+
+// WithRegistrarLogger sets the logger of the registrar
+func WithRegistrarLogger(logger log.Logger) RegistrarOption {
+	return func(r *Registrar) {
+		r.logger = logger
+	}
+}
+
+// NewRegistrar returns a new registrar which verifies requests against the documents returned by the given
+// resolver and persists the registrations in the given store
+func NewRegistrar(resolver Resolver, store Store, opts ...RegistrarOption) *Registrar {
+	r := &Registrar{
+		resolver:      resolver,
+		store:         store,
+		maxRequestAge: defaultMaxRequestAge,
+		lookupIP:      lookupIP,
+		now:           time.Now,
+		logger:        log.Default(),
+		nonces:        make(map[string]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Register verifies the given (JSON-encoded) request and registers the callback URL of the DID,
+// replacing the existing registration (if any)
+func (r *Registrar) Register(request []byte) (*Registration, error) {
+	signedData, err := r.verify(request, ActionRegister)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.validateCallbackURL(signedData.CallbackURL); err != nil {
+		return nil, err
+	}
+
+	registration := &Registration{
+		UniqueSuffix: signedData.DidSuffix,
+		CallbackURL:  signedData.CallbackURL,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	if err := r.store.Put(registration); err != nil {
+		return nil, fmt.Errorf("failed to store webhook registration for [%s]: %w", signedData.DidSuffix, err)
+	}
+
+	r.logger.Infof("Registered webhook for [%s]", signedData.DidSuffix)
+
+	return registration, nil
+}
+
+// Unregister verifies the given (JSON-encoded) request and removes the registered callback URL of the DID
+func (r *Registrar) Unregister(request []byte) error {
+	signedData, err := r.verify(request, ActionUnregister)
+	if err != nil {
+		return err
+	}
+
+	if err := r.store.Delete(signedData.DidSuffix); err != nil {
+		return fmt.Errorf("failed to delete webhook registration for [%s]: %w", signedData.DidSuffix, err)
+	}
+
+	r.logger.Infof("Unregistered webhook for [%s]", signedData.DidSuffix)
+
+	return nil
+}
+
+// verify parses the request and verifies that it was signed by an operations key of the DID and that it
+// is neither stale nor replayed
+func (r *Registrar) verify(request []byte, action string) (*SignedDataModel, error) {
+	req := &Request{}
+	if err := json.Unmarshal(request, req); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err)
+	}
+
+	if req.DidSuffix == "" {
+		return nil, fmt.Errorf("%w: missing did suffix", ErrInvalidRequest)
+	}
+
+	if req.SignedData == nil || req.SignedData.Protected == nil || req.SignedData.Signature == "" {
+		return nil, fmt.Errorf("%w: missing signed data", ErrInvalidRequest)
+	}
+
+	if req.SignedData.Protected.Kid == "" {
+		return nil, fmt.Errorf("%w: missing kid in protected header", ErrInvalidRequest)
+	}
+
+	result, err := r.resolver.Resolve(req.DidSuffix)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("%w: document [%s] not found", ErrNotFound, req.DidSuffix)
+		}
+
+		return nil, fmt.Errorf("failed to resolve document [%s]: %w", req.DidSuffix, err)
+	}
+
+	jwk, err := document.GetOperationsKey(result.Document, req.SignedData.Protected.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnauthorized, err)
+	}
+
+	payload, err := verifier.VerifyJWS(req.SignedData.Signature, jwk)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnauthorized, err)
+	}
+
+	signedData, err := parseSignedData(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err)
+	}
+
+	if signedData.Action != action {
+		return nil, fmt.Errorf("%w: signed action [%s] doesn't match [%s]", ErrInvalidRequest, signedData.Action, action)
+	}
+
+	if signedData.DidSuffix != req.DidSuffix {
+		return nil, fmt.Errorf("%w: signed did suffix mismatch", ErrInvalidRequest)
+	}
+
+	if err := r.checkReplay(signedData); err != nil {
+		return nil, err
+	}
+
+	return signedData, nil
+}
+
+// checkReplay rejects requests whose timestamp is outside of the maximum request age and requests whose
+// nonce was already used for the DID. Nonces are only remembered for the maximum request age since older
+// requests are rejected by the timestamp check.
+func (r *Registrar) checkReplay(signedData *SignedDataModel) error {
+	if signedData.Nonce == "" {
+		return fmt.Errorf("%w: missing nonce", ErrInvalidRequest)
+	}
+
+	if signedData.Timestamp == 0 {
+		return fmt.Errorf("%w: missing timestamp", ErrInvalidRequest)
+	}
+
+	now := r.now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, expiry := range r.nonces {
+		if now.After(expiry) {
+			delete(r.nonces, key)
+		}
+	}
+
+	age := now.Sub(time.Unix(signedData.Timestamp, 0))
+	if age > r.maxRequestAge || age < -r.maxRequestAge {
+		return fmt.Errorf("%w: request timestamp [%d] is outside of the allowed window of %s",
+			ErrUnauthorized, signedData.Timestamp, r.maxRequestAge)
+	}
+
+	key := signedData.DidSuffix + ":" + signedData.Nonce
+
+	if _, ok := r.nonces[key]; ok {
+		return fmt.Errorf("%w: nonce [%s] was already used", ErrUnauthorized, signedData.Nonce)
+	}
+
+	// the timestamp may be in the future so the nonce is remembered until the request would be stale
+	r.nonces[key] = time.Unix(signedData.Timestamp, 0).Add(r.maxRequestAge)
+
+	return nil
+}
+
+func (r *Registrar) validateCallbackURL(callbackURL string) error {
+	if callbackURL == "" {
+		return fmt.Errorf("%w: missing callback URL", ErrInvalidRequest)
+	}
+
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid callback URL: %s", ErrInvalidRequest, err)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("%w: callback URL [%s] must be absolute", ErrInvalidRequest, callbackURL)
+	}
+
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && r.allowInsecureURL:
+	default:
+		return fmt.Errorf("%w: scheme [%s] of callback URL is not supported", ErrInvalidRequest, u.Scheme)
+	}
+
+	if r.allowPrivateHost {
+		return nil
+	}
+
+	return r.validateCallbackHost(u.Hostname())
+}
+
+// validateCallbackHost rejects hosts that are (or resolve to) loopback, link-local or private addresses
+func (r *Registrar) validateCallbackHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: callback host [%s] is not allowed", ErrInvalidRequest, host)
+	}
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		var err error
+
+		ips, err = r.lookupIP(host)
+		if err != nil {
+			return fmt.Errorf("%w: failed to resolve callback host [%s]: %s", ErrInvalidRequest, host, err)
+		}
+	}
+
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("%w: callback host [%s] resolves to address [%s] which is not allowed",
+				ErrInvalidRequest, host, ip)
+		}
+	}
+
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func lookupIP(host string) ([]net.IP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+
+	return ips, nil
+}
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		networks[i] = n
+	}
+
+	return networks
+}
+
+func parseSignedData(payload []byte) (*SignedDataModel, error) {
+	decoded, err := docutil.DecodeString(string(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	signedData := &SignedDataModel{}
+	if err := json.Unmarshal(decoded, signedData); err != nil {
+		return nil, err
+	}
+
+	return signedData, nil
+}
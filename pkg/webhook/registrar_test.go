@@ -0,0 +1,453 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/internal/signutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+)
+
+const (
+	didSuffix   = "suffix"
+	kid         = "key-1"
+	callbackURL = "https://example.com/callback"
+)
+
+func TestRegistrar_Register(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	resolver := newMockResolver(t, privateKey, "ops")
+
+	t.Run("success", func(t *testing.T) {
+		store := NewMemStore()
+		r := newRegistrar(resolver, store)
+
+		registration, err := r.Register(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.NoError(t, err)
+		require.Equal(t, didSuffix, registration.UniqueSuffix)
+		require.Equal(t, callbackURL, registration.CallbackURL)
+
+		stored, err := store.Get(didSuffix)
+		require.NoError(t, err)
+		require.Equal(t, registration, stored)
+	})
+
+	t.Run("http callback", func(t *testing.T) {
+		request := newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: "http://example.com/callback",
+		})
+
+		_, err := newRegistrar(resolver, NewMemStore()).Register(request)
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "scheme [http] of callback URL is not supported")
+
+		_, err = newRegistrar(resolver, NewMemStore(), WithInsecureCallbacks()).Register(request)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid callback URL", func(t *testing.T) {
+		r := newRegistrar(resolver, NewMemStore())
+
+		for _, u := range []string{"", "/callback", "ftp://example.com", "https://exa mple.com"} {
+			_, err := r.Register(newRequest(t, privateKey, kid, &SignedDataModel{
+				Action:      ActionRegister,
+				DidSuffix:   didSuffix,
+				CallbackURL: u,
+			}))
+			require.Truef(t, errors.Is(err, ErrInvalidRequest), "expecting invalid request for [%s]", u)
+		}
+	})
+
+	t.Run("private callback host", func(t *testing.T) {
+		r := NewRegistrar(resolver, NewMemStore(), WithInsecureCallbacks())
+
+		for _, u := range []string{
+			"http://localhost/callback",
+			"http://api.localhost/callback",
+			"http://127.0.0.1/callback",
+			"http://[::1]:8080/callback",
+			"http://169.254.169.254/latest/meta-data",
+			"http://10.0.0.1/callback",
+			"http://172.16.5.4/callback",
+			"http://192.168.1.1/callback",
+			"http://[fd00::1]/callback",
+			"http://0.0.0.0/callback",
+		} {
+			_, err := r.Register(newRequest(t, privateKey, kid, &SignedDataModel{
+				Action:      ActionRegister,
+				DidSuffix:   didSuffix,
+				CallbackURL: u,
+			}))
+			require.Truef(t, errors.Is(err, ErrInvalidRequest), "expecting invalid request for [%s]", u)
+			require.Contains(t, err.Error(), "is not allowed")
+		}
+
+		_, err := NewRegistrar(resolver, NewMemStore(), WithInsecureCallbacks(), WithPrivateCallbacks()).Register(
+			newRequest(t, privateKey, kid, &SignedDataModel{
+				Action:      ActionRegister,
+				DidSuffix:   didSuffix,
+				CallbackURL: "http://127.0.0.1:8080/callback",
+			}))
+		require.NoError(t, err)
+	})
+
+	t.Run("callback host resolves to private address", func(t *testing.T) {
+		r := NewRegistrar(resolver, NewMemStore())
+		r.lookupIP = func(host string) ([]net.IP, error) {
+			require.Equal(t, "internal.example.com", host)
+			return []net.IP{net.ParseIP("93.184.216.34"), net.ParseIP("10.1.2.3")}, nil
+		}
+
+		_, err := r.Register(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: "https://internal.example.com/callback",
+		}))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "resolves to address [10.1.2.3] which is not allowed")
+
+		r.lookupIP = func(string) ([]net.IP, error) {
+			return nil, errors.New("no such host")
+		}
+
+		_, err = r.Register(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "failed to resolve callback host [example.com]: no such host")
+	})
+
+	t.Run("replayed request", func(t *testing.T) {
+		r := newRegistrar(resolver, NewMemStore())
+
+		request := newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		})
+
+		_, err := r.Register(request)
+		require.NoError(t, err)
+
+		_, err = r.Register(request)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "was already used")
+
+		// the nonce is forgotten once the request is stale
+		r.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+
+		_, err = r.Register(request)
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "is outside of the allowed window of 5m0s")
+		require.Empty(t, r.nonces)
+	})
+
+	t.Run("stale request", func(t *testing.T) {
+		r := newRegistrar(resolver, NewMemStore(), WithMaxRequestAge(time.Minute))
+
+		for _, timestamp := range []time.Time{time.Now().Add(-2 * time.Minute), time.Now().Add(2 * time.Minute)} {
+			_, err := r.Register(signRequest(t, privateKey, kid, &SignedDataModel{
+				Action:      ActionRegister,
+				DidSuffix:   didSuffix,
+				CallbackURL: callbackURL,
+				Nonce:       newNonce(t),
+				Timestamp:   timestamp.Unix(),
+			}))
+			require.True(t, errors.Is(err, ErrUnauthorized))
+			require.Contains(t, err.Error(), "is outside of the allowed window of 1m0s")
+		}
+	})
+
+	t.Run("missing nonce or timestamp", func(t *testing.T) {
+		r := newRegistrar(resolver, NewMemStore())
+
+		_, err := r.Register(signRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+			Timestamp:   time.Now().Unix(),
+		}))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "missing nonce")
+
+		_, err = r.Register(signRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+			Nonce:       newNonce(t),
+		}))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "missing timestamp")
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		r := newRegistrar(resolver, NewMemStore())
+
+		_, err := r.Register([]byte("{"))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+
+		_, err = r.Register([]byte(`{}`))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "missing did suffix")
+
+		_, err = r.Register([]byte(`{"did_suffix":"suffix"}`))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "missing signed data")
+
+		_, err = r.Register(newRequest(t, privateKey, "", &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "missing kid")
+	})
+
+	t.Run("action mismatch", func(t *testing.T) {
+		_, err := newRegistrar(resolver, NewMemStore()).Register(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:    ActionUnregister,
+			DidSuffix: didSuffix,
+		}))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "signed action [unregister] doesn't match [register]")
+	})
+
+	t.Run("did suffix mismatch", func(t *testing.T) {
+		_, err := newRegistrar(resolver, NewMemStore()).Register(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   "other",
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+		require.Contains(t, err.Error(), "signed did suffix mismatch")
+	})
+
+	t.Run("signed with another key", func(t *testing.T) {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = newRegistrar(resolver, NewMemStore()).Register(newRequest(t, otherKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, ErrUnauthorized))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := newRegistrar(resolver, NewMemStore()).Register(newRequest(t, privateKey, "key-2", &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "signing public key [key-2] not found")
+	})
+
+	t.Run("not an operations key", func(t *testing.T) {
+		r := newRegistrar(newMockResolver(t, privateKey, "auth"), NewMemStore())
+
+		_, err := r.Register(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, ErrUnauthorized))
+		require.Contains(t, err.Error(), "is not an operations key")
+	})
+
+	t.Run("document not found", func(t *testing.T) {
+		r := newRegistrar(&mockResolver{err: errors.New("document not found")}, NewMemStore())
+
+		_, err := r.Register(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		errExpected := errors.New("resolver error")
+
+		r := newRegistrar(&mockResolver{err: errExpected}, NewMemStore())
+
+		_, err := r.Register(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		errExpected := errors.New("store error")
+
+		r := newRegistrar(resolver, &mockStore{Store: NewMemStore(), err: errExpected})
+
+		_, err := r.Register(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, errExpected))
+	})
+}
+
+func TestRegistrar_Unregister(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	resolver := newMockResolver(t, privateKey, "ops")
+
+	t.Run("success", func(t *testing.T) {
+		store := NewMemStore()
+		require.NoError(t, store.Put(&Registration{UniqueSuffix: didSuffix, CallbackURL: callbackURL}))
+
+		err := newRegistrar(resolver, store).Unregister(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:    ActionUnregister,
+			DidSuffix: didSuffix,
+		}))
+		require.NoError(t, err)
+
+		_, err = store.Get(didSuffix)
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		err := newRegistrar(resolver, NewMemStore()).Unregister(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:    ActionUnregister,
+			DidSuffix: didSuffix,
+		}))
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("action mismatch", func(t *testing.T) {
+		err := newRegistrar(resolver, NewMemStore()).Unregister(newRequest(t, privateKey, kid, &SignedDataModel{
+			Action:      ActionRegister,
+			DidSuffix:   didSuffix,
+			CallbackURL: callbackURL,
+		}))
+		require.True(t, errors.Is(err, ErrInvalidRequest))
+	})
+}
+
+// newRegistrar returns a registrar which resolves all callback hosts to a public address
+func newRegistrar(resolver Resolver, store Store, opts ...RegistrarOption) *Registrar {
+	r := NewRegistrar(resolver, store, opts...)
+	r.lookupIP = func(string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("93.184.216.34")}, nil
+	}
+
+	return r
+}
+
+// newRequest returns a signed request with a unique nonce and the current timestamp
+func newRequest(t *testing.T, privateKey *ecdsa.PrivateKey, keyID string, signedData *SignedDataModel) []byte {
+	signedData.Nonce = newNonce(t)
+	signedData.Timestamp = time.Now().Unix()
+
+	return signRequest(t, privateKey, keyID, signedData)
+}
+
+func signRequest(t *testing.T, privateKey *ecdsa.PrivateKey, keyID string, signedData *SignedDataModel) []byte {
+	jws, err := signutil.SignModel(signedData, ecsigner.New(privateKey, "ES256", keyID))
+	require.NoError(t, err)
+
+	request, err := json.Marshal(&Request{
+		DidSuffix:  didSuffix,
+		SignedData: jws,
+	})
+	require.NoError(t, err)
+
+	return request
+}
+
+func newNonce(t *testing.T) string {
+	nonce := make([]byte, 16)
+
+	_, err := rand.Read(nonce)
+	require.NoError(t, err)
+
+	return hex.EncodeToString(nonce)
+}
+
+type mockResolver struct {
+	doc document.Document
+	err error
+}
+
+func newMockResolver(t *testing.T, privateKey *ecdsa.PrivateKey, usage string) *mockResolver {
+	jwk, err := pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	return &mockResolver{
+		doc: document.Document{
+			"publicKey": []interface{}{
+				map[string]interface{}{
+					"id":    kid,
+					"type":  "JwsVerificationKey2020",
+					"usage": []interface{}{usage},
+					"jwk": map[string]interface{}{
+						"kty": jwk.Kty,
+						"crv": jwk.Crv,
+						"x":   jwk.X,
+						"y":   jwk.Y,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (m *mockResolver) Resolve(string) (*document.ResolutionResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &document.ResolutionResult{Document: m.doc}, nil
+}
+
+type mockStore struct {
+	Store
+	err error
+}
+
+func (m *mockStore) Put(*Registration) error {
+	return m.err
+}
+
+func (m *mockStore) Get(uniqueSuffix string) (*Registration, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.Store.Get(uniqueSuffix)
+}
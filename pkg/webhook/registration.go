@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package webhook allows clients to register a callback URL in order to be notified when the operations for
+// their DID are anchored (or fail to be anchored).
+//
+// Registration requests are signed with one of the DID's operations (update) keys so that only the controller
+// of the DID may register or unregister a callback. The signed data includes a nonce and a timestamp so that
+// a request can't be replayed. The Dispatcher listens to the operation lifecycle events
+// of the batch writer and delivers the events to the registered callbacks, retrying failed deliveries
+// with exponential backoff.
+package webhook
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/model"
+)
+
+const (
+	// ActionRegister is the action of a request that registers a callback URL
+	ActionRegister = "register"
+
+	// ActionUnregister is the action of a request that removes the registered callback URL
+	ActionUnregister = "unregister"
+)
+
+var (
+	// ErrInvalidRequest is returned if the registration request is malformed
+	ErrInvalidRequest = errors.New("invalid webhook request")
+
+	// ErrUnauthorized is returned if the registration request isn't signed by an operations key of the DID
+	ErrUnauthorized = errors.New("webhook request is not authorized")
+
+	// ErrNotFound is returned if a callback isn't registered for the DID
+	ErrNotFound = errors.New("webhook not found")
+)
+
+// Request is a request to register (or unregister) the callback URL of a DID
+type Request struct {
+	// DidSuffix is the unique suffix of the DID
+	DidSuffix string `json:"did_suffix"`
+
+	// SignedData is the JWS (signed by an operations key of the DID) whose payload is the encoded SignedDataModel
+	SignedData *model.JWS `json:"signed_data"`
+}
+
+// SignedDataModel is the payload of the signed data of a webhook request
+type SignedDataModel struct {
+	// Action is either "register" or "unregister"
+	Action string `json:"action"`
+
+	// DidSuffix must match the DID suffix of the request
+	DidSuffix string `json:"did_suffix"`
+
+	// CallbackURL is the URL that operation events are posted to (register only)
+	CallbackURL string `json:"callback_url,omitempty"`
+
+	// Nonce is a unique value that prevents the request from being replayed
+	Nonce string `json:"nonce"`
+
+	// Timestamp is the time (in seconds since the Unix epoch) at which the request was signed.
+	// Stale requests are rejected.
+	Timestamp int64 `json:"timestamp"`
+}
+
+// Registration is the callback URL that is registered for a DID
+type Registration struct {
+	UniqueSuffix string    `json:"did_suffix"`
+	CallbackURL  string    `json:"callback_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Store persists webhook registrations
+type Store interface {
+	// Put stores the registration (replacing the existing registration of the DID, if any)
+	Put(registration *Registration) error
+
+	// Get returns the registration of the given DID suffix or ErrNotFound
+	Get(uniqueSuffix string) (*Registration, error)
+
+	// Delete removes the registration of the given DID suffix or returns ErrNotFound
+	Delete(uniqueSuffix string) error
+}
+
+// MemStore is an in-memory registration store
+type MemStore struct {
+	mutex         sync.RWMutex
+	registrations map[string]*Registration
+}
+
+// NewMemStore returns a new in-memory registration store
+func NewMemStore() *MemStore {
+	return &MemStore{registrations: make(map[string]*Registration)}
+}
+
+// Put stores the registration
+func (s *MemStore) Put(registration *Registration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.registrations[registration.UniqueSuffix] = registration
+
+	return nil
+}
+
+// Get returns the registration of the given DID suffix
+func (s *MemStore) Get(uniqueSuffix string) (*Registration, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	registration, ok := s.registrations[uniqueSuffix]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return registration, nil
+}
+
+// Delete removes the registration of the given DID suffix
+func (s *MemStore) Delete(uniqueSuffix string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.registrations[uniqueSuffix]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.registrations, uniqueSuffix)
+
+	return nil
+}
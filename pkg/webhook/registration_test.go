@@ -0,0 +1,34 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStore(t *testing.T) {
+	store := NewMemStore()
+
+	_, err := store.Get(didSuffix)
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.True(t, errors.Is(store.Delete(didSuffix), ErrNotFound))
+
+	registration := &Registration{UniqueSuffix: didSuffix, CallbackURL: callbackURL}
+	require.NoError(t, store.Put(registration))
+
+	stored, err := store.Get(didSuffix)
+	require.NoError(t, err)
+	require.Equal(t, registration, stored)
+
+	require.NoError(t, store.Delete(didSuffix))
+
+	_, err = store.Get(didSuffix)
+	require.True(t, errors.Is(err, ErrNotFound))
+}